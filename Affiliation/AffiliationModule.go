@@ -0,0 +1,42 @@
+// Package affiliation lets clinic owners invite registered doctors and doctors accept, decline or end working at a clinic.
+package affiliation
+
+import (
+	controller "AlShifa/Affiliation/Controller"
+	repository "AlShifa/Affiliation/Repository"
+	service "AlShifa/Affiliation/Service"
+	clinicInterfaces "AlShifa/Clinic/Interfaces"
+	internals "AlShifa/Internals"
+	middleware "AlShifa/Middleware"
+	notifications "AlShifa/Notifications"
+	utils "AlShifa/Utils"
+	"context"
+	"log"
+)
+
+// InitialiseAffiliationModule registers affiliation routes, the other side is notified of every invite and response.
+// affiliations of clinics which stop being active are ended
+func InitialiseAffiliationModule(app *internals.App, clinics clinicInterfaces.IService) {
+	repository := repository.NewRepository(app.DB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout*5)
+	defer cancel()
+	if err := repository.CreateIndexes(ctx); err != nil {
+		log.Fatal("Failed to create affiliation indexes", err)
+	}
+
+	notifier, err := notifications.NewNotifierFromEnv(notifications.NewMongoContactBook(app.DB))
+	if err != nil {
+		log.Fatal("Failed to configure notifier", err)
+	}
+
+	service := service.NewAffiliationService(repository, notifier)
+	clinics.AddDeactivationListener(service)
+	controller := controller.NewController(service)
+	app.Server.HandleFunc(utils.MakeURL("POST", "/clinic/{id}/affiliations"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.InviteDoctor, utils.RoleClinicOwner)))
+	app.Server.HandleFunc(utils.MakeURL("GET", "/clinic/{id}/affiliations"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.GetClinicAffiliations, utils.RoleClinicOwner)))
+	app.Server.HandleFunc(utils.MakeURL("GET", "/doctor/affiliations"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.GetDoctorAffiliations, utils.RoleDoctor)))
+	app.Server.HandleFunc(utils.MakeURL("POST", "/affiliation/{id}/accept"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.AcceptAffiliation, utils.RoleDoctor)))
+	app.Server.HandleFunc(utils.MakeURL("POST", "/affiliation/{id}/decline"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.DeclineAffiliation, utils.RoleDoctor)))
+	app.Server.HandleFunc(utils.MakeURL("POST", "/affiliation/{id}/end"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.EndAffiliation, utils.RoleDoctor, utils.RoleClinicOwner)))
+}
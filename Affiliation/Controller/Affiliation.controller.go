@@ -0,0 +1,203 @@
+// Package controller provides HTTP handlers for doctor clinic affiliations.
+package controller
+
+import (
+	interfaces "AlShifa/Affiliation/Interfaces"
+	affiliationStructs "AlShifa/Affiliation/Structs"
+	validators "AlShifa/Affiliation/Validators"
+	middleware "AlShifa/Middleware"
+	utils "AlShifa/Utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Controller struct {
+	Service interfaces.IService
+}
+
+func NewController(service interfaces.IService) *Controller {
+	return &Controller{
+		Service: service,
+	}
+}
+
+func (controller *Controller) InviteDoctor(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	ownerID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	clinicID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Clinic ID", "Invalid ID"))
+		return
+	}
+
+	var invite affiliationStructs.InviteRequest
+	if err := json.NewDecoder(req.Body).Decode(&invite); err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invite Failed", "Invalid Json"))
+		return
+	}
+
+	validationErrors := validators.ValidateInvite(&invite)
+	if validationErrors != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(validationErrors, 400, "Invite Failed", "Invalid Details"))
+		return
+	}
+
+	affiliation, appErr := controller.Service.InviteDoctor(ctx, ownerID, clinicID, invite)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusCreated, utils.ReturnAppSuccess(201, "Doctor Invited Successfully", affiliation))
+}
+
+func (controller *Controller) GetClinicAffiliations(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	ownerID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	clinicID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Clinic ID", "Invalid ID"))
+		return
+	}
+
+	affiliations, appErr := controller.Service.GetClinicAffiliations(ctx, ownerID, clinicID, req.URL.Query().Get("status"))
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Affiliations Fetched Successfully", affiliations))
+}
+
+func (controller *Controller) GetDoctorAffiliations(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	doctorID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	affiliations, appErr := controller.Service.GetDoctorAffiliations(ctx, doctorID, req.URL.Query().Get("status"))
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Affiliations Fetched Successfully", affiliations))
+}
+
+func (controller *Controller) AcceptAffiliation(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	doctorID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	affiliationID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Affiliation ID", "Invalid ID"))
+		return
+	}
+
+	affiliation, appErr := controller.Service.AcceptAffiliation(ctx, doctorID, affiliationID)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Invite Accepted Successfully", affiliation))
+}
+
+func (controller *Controller) DeclineAffiliation(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	doctorID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	affiliationID, action, ok := readAction(res, req, "Unable To Decline Invite")
+	if !ok {
+		return
+	}
+
+	if appErr := controller.Service.DeclineAffiliation(ctx, doctorID, affiliationID, action.Reason); appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Invite Declined Successfully", nil))
+}
+
+func (controller *Controller) EndAffiliation(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	actorID, actorRole, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	affiliationID, action, ok := readAction(res, req, "Unable To End Affiliation")
+	if !ok {
+		return
+	}
+
+	if appErr := controller.Service.EndAffiliation(ctx, actorID, actorRole, affiliationID, action.Reason); appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Affiliation Ended Successfully", nil))
+}
+
+// readAction reads affiliation id from path and the optional reason from body, writes the error response itself
+func readAction(res http.ResponseWriter, req *http.Request, failure string) (primitive.ObjectID, affiliationStructs.AffiliationAction, bool) {
+	var action affiliationStructs.AffiliationAction
+
+	affiliationID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Affiliation ID", "Invalid ID"))
+		return affiliationID, action, false
+	}
+
+	//body is optional, no reason has to be given
+	if err := json.NewDecoder(req.Body).Decode(&action); err != nil && !errors.Is(err, io.EOF) {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, failure, "Invalid Json"))
+		return affiliationID, action, false
+	}
+
+	validationErrors := validators.ValidateAffiliationAction(&action)
+	if validationErrors != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(validationErrors, 400, failure, "Invalid Details"))
+		return affiliationID, action, false
+	}
+	return affiliationID, action, true
+}
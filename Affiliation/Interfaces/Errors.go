@@ -0,0 +1,10 @@
+package interfaces
+
+import "errors"
+
+var (
+	// ErrAffiliationExists is returned when clinic and doctor already have a pending or accepted affiliation
+	ErrAffiliationExists = errors.New("affiliation already exists")
+	// ErrSessionExists is returned on acceptance when doctor already has a session at the clinic
+	ErrSessionExists = errors.New("doctor already has a session at clinic")
+)
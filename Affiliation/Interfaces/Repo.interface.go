@@ -0,0 +1,23 @@
+// Package interfaces contains interfaces for Affiliation module
+package interfaces
+
+import (
+	"AlShifa/Clinic/models"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IRepository defines the methods for loose coupling between the affiliation repository and its implementation.
+type IRepository interface {
+	InsertAffiliation(ctx context.Context, affiliation models.Affiliation) error
+	GetAffiliation(ctx context.Context, filter bson.M) (models.Affiliation, error)
+	GetAffiliations(ctx context.Context, filter bson.M) ([]models.Affiliation, error)
+	AcceptAffiliation(ctx context.Context, affiliation models.Affiliation, at time.Time) error
+	CloseAffiliation(ctx context.Context, affiliation models.Affiliation, status string, actorRole string, reason string, at time.Time) error
+	CountUpcomingAppointments(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID) (int64, error)
+	FindDoctor(ctx context.Context, filter bson.M) (models.Doctor, error)
+	GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error)
+}
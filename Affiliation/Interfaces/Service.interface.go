@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	affiliationStructs "AlShifa/Affiliation/Structs"
+	"AlShifa/Clinic/models"
+	structs "AlShifa/Structs"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IService interface contains functions that affiliation service layer must implement( to be used by handlers)
+type IService interface {
+	InviteDoctor(ctx context.Context, ownerID primitive.ObjectID, clinicID primitive.ObjectID, invite affiliationStructs.InviteRequest) (*models.Affiliation, *structs.IAppError)
+	GetClinicAffiliations(ctx context.Context, ownerID primitive.ObjectID, clinicID primitive.ObjectID, status string) ([]models.Affiliation, *structs.IAppError)
+	GetDoctorAffiliations(ctx context.Context, doctorID primitive.ObjectID, status string) ([]models.Affiliation, *structs.IAppError)
+	AcceptAffiliation(ctx context.Context, doctorID primitive.ObjectID, affiliationID primitive.ObjectID) (*models.Affiliation, *structs.IAppError)
+	DeclineAffiliation(ctx context.Context, doctorID primitive.ObjectID, affiliationID primitive.ObjectID, reason string) *structs.IAppError
+	EndAffiliation(ctx context.Context, actorID primitive.ObjectID, actorRole string, affiliationID primitive.ObjectID, reason string) *structs.IAppError
+}
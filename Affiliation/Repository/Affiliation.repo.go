@@ -0,0 +1,217 @@
+// Package repository provides the implementation of the repository layer for doctor clinic affiliations in MongoDB.
+package repository
+
+import (
+	interfaces "AlShifa/Affiliation/Interfaces"
+	"AlShifa/Clinic/models"
+	utils "AlShifa/Utils"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Repo is the MongoDB implementation of the affiliation IRepository interface.
+type Repo struct {
+	DB *mongo.Database
+}
+
+// this ensures this repo implements all methods of repository interface
+var _ interfaces.IRepository = (*Repo)(nil)
+
+// NewRepository creates a new affiliation repository with the specified database.
+func NewRepository(db *mongo.Database) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+// CreateIndexes creates indexes affiliation module relies on, the partial unique index allows only one pending or
+// accepted affiliation per clinic and doctor while keeping history of the closed ones
+func (r *Repo) CreateIndexes(ctx context.Context) error {
+	_, err := r.DB.Collection("Affiliation").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "clinic", Value: 1}, {Key: "doctor", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"active": true}),
+		},
+		{
+			Keys: bson.D{{Key: "doctor", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "clinic", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+	})
+	return err
+}
+
+func (r *Repo) InsertAffiliation(ctx context.Context, affiliation models.Affiliation) error {
+	_, err := r.DB.Collection("Affiliation").InsertOne(ctx, affiliation)
+	if mongo.IsDuplicateKeyError(err) {
+		return interfaces.ErrAffiliationExists
+	}
+	return err
+}
+
+func (r *Repo) GetAffiliation(ctx context.Context, filter bson.M) (models.Affiliation, error) {
+	var affiliation models.Affiliation
+	err := r.DB.Collection("Affiliation").FindOne(ctx, filter).Decode(&affiliation)
+	return affiliation, err
+}
+
+// GetAffiliations returns affiliations matching filter, latest first
+func (r *Repo) GetAffiliations(ctx context.Context, filter bson.M) ([]models.Affiliation, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	cursor, err := r.DB.Collection("Affiliation").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	affiliations := []models.Affiliation{}
+	if err := cursor.All(ctx, &affiliations); err != nil {
+		return nil, err
+	}
+	return affiliations, nil
+}
+
+// AcceptAffiliation marks pending affiliation accepted and in the same transaction adds its session to the doctor and the
+// doctor to the clinic. returns mongo.ErrNoDocuments if affiliation is no longer pending or the clinic is gone and
+// ErrSessionExists if doctor already has a session at the clinic
+func (r *Repo) AcceptAffiliation(ctx context.Context, affiliation models.Affiliation, at time.Time) error {
+	session, err := r.DB.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	callback := func(sessCtx mongo.SessionContext) (any, error) {
+		res, err := r.DB.Collection("Affiliation").UpdateOne(sessCtx,
+			bson.M{"_id": affiliation.ID, "status": utils.AffiliationStatusPending},
+			bson.M{"$set": bson.M{"status": utils.AffiliationStatusAccepted, "respondedAt": at}},
+		)
+		if err != nil {
+			return nil, err
+		}
+		if res.MatchedCount == 0 {
+			return nil, mongo.ErrNoDocuments
+		}
+
+		//doctors are registered with null clinics so the session is appended with a pipeline
+		res, err = r.DB.Collection("Doctor").UpdateOne(sessCtx,
+			bson.M{"_id": affiliation.Doctor, "clinics.clinic": bson.M{"$ne": affiliation.Clinic}},
+			bson.A{bson.M{"$set": bson.M{"clinics": bson.M{"$concatArrays": bson.A{
+				bson.M{"$ifNull": bson.A{"$clinics", bson.A{}}},
+				bson.A{bson.M{"$literal": affiliation.Session}},
+			}}}}},
+		)
+		if err != nil {
+			return nil, err
+		}
+		if res.MatchedCount == 0 {
+			return nil, interfaces.ErrSessionExists
+		}
+
+		res, err = r.DB.Collection("Clinic").UpdateOne(sessCtx,
+			bson.M{"_id": affiliation.Clinic},
+			bson.A{bson.M{"$set": bson.M{"doctors": bson.M{"$setUnion": bson.A{
+				bson.M{"$ifNull": bson.A{"$doctors", bson.A{}}},
+				bson.A{affiliation.Doctor},
+			}}}}},
+		)
+		if err != nil {
+			return nil, err
+		}
+		if res.MatchedCount == 0 {
+			return nil, mongo.ErrNoDocuments
+		}
+		return nil, nil
+	}
+
+	_, err = session.WithTransaction(ctx, callback)
+	return err
+}
+
+// CloseAffiliation moves affiliation from its current status to status. an accepted affiliation is unlinked from the doctor
+// and clinic in the same transaction. returns mongo.ErrNoDocuments if affiliation changed meanwhile
+func (r *Repo) CloseAffiliation(ctx context.Context, affiliation models.Affiliation, status string, actorRole string, reason string, at time.Time) error {
+	set := bson.M{"status": status, "endedBy": actorRole}
+	if reason != "" {
+		set["reason"] = reason
+	}
+	if affiliation.Status == utils.AffiliationStatusPending {
+		set["respondedAt"] = at
+	} else {
+		set["endedAt"] = at
+	}
+	update := bson.M{"$set": set, "$unset": bson.M{"active": ""}}
+
+	closeAffiliation := func(ctx context.Context) error {
+		res, err := r.DB.Collection("Affiliation").UpdateOne(ctx, bson.M{"_id": affiliation.ID, "status": affiliation.Status}, update)
+		if err != nil {
+			return err
+		}
+		if res.MatchedCount == 0 {
+			return mongo.ErrNoDocuments
+		}
+		return nil
+	}
+
+	if affiliation.Status != utils.AffiliationStatusAccepted {
+		return closeAffiliation(ctx)
+	}
+
+	session, err := r.DB.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	callback := func(sessCtx mongo.SessionContext) (any, error) {
+		if err := closeAffiliation(sessCtx); err != nil {
+			return nil, err
+		}
+		if _, err := r.DB.Collection("Doctor").UpdateOne(sessCtx, bson.M{"_id": affiliation.Doctor}, bson.M{"$pull": bson.M{"clinics": bson.M{"clinic": affiliation.Clinic}}}); err != nil {
+			return nil, err
+		}
+		if _, err := r.DB.Collection("Clinic").UpdateOne(sessCtx, bson.M{"_id": affiliation.Clinic}, bson.M{"$pull": bson.M{"doctors": affiliation.Doctor}}); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	_, err = session.WithTransaction(ctx, callback)
+	return err
+}
+
+// CountUpcomingAppointments counts appointments of doctor at clinic which are still to be consulted
+func (r *Repo) CountUpcomingAppointments(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID) (int64, error) {
+	return r.DB.Collection("Appointment").CountDocuments(ctx, bson.M{
+		"doctor":          doctorID,
+		"clinic":          clinicID,
+		"appointmentDate": bson.M{"$gte": time.Now().UTC()},
+		"status": bson.M{"$in": bson.A{
+			utils.AppointmentStatusRequested,
+			utils.AppointmentStatusConfirmed,
+			utils.AppointmentStatusCheckedIn,
+			utils.AppointmentStatusInConsultation,
+			utils.AppointmentStatusNeedsReschedule,
+		}},
+	})
+}
+
+// FindDoctor returns the doctor matching filter without password
+func (r *Repo) FindDoctor(ctx context.Context, filter bson.M) (models.Doctor, error) {
+	var doctor models.Doctor
+	opts := options.FindOne().SetProjection(bson.M{"password": 0})
+	err := r.DB.Collection("Doctor").FindOne(ctx, filter, opts).Decode(&doctor)
+	return doctor, err
+}
+
+func (r *Repo) GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+	var clinic models.Clinic
+	err := r.DB.Collection("Clinic").FindOne(ctx, bson.M{"_id": clinicID}).Decode(&clinic)
+	return clinic, err
+}
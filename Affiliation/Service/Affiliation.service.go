@@ -0,0 +1,261 @@
+// Package service contains service layer implementation for affiliation module
+package service
+
+import (
+	interfaces "AlShifa/Affiliation/Interfaces"
+	affiliationStructs "AlShifa/Affiliation/Structs"
+	"AlShifa/Clinic/models"
+	notifications "AlShifa/Notifications"
+	scheduling "AlShifa/Scheduling"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type AffiliationService struct {
+	Repo     interfaces.IRepository
+	Notifier notifications.Notifier
+}
+
+// NewAffiliationService creates affiliation service, notifier tells the other side about invites and responses and can be nil
+func NewAffiliationService(repo interfaces.IRepository, notifier notifications.Notifier) *AffiliationService {
+	return &AffiliationService{
+		Repo:     repo,
+		Notifier: notifier,
+	}
+}
+
+// this ensures this service layer implements all methods of service layer interface
+var _ interfaces.IService = (*AffiliationService)(nil)
+
+// InviteDoctor invites a registered doctor to clinic of owner with the proposed session
+func (service *AffiliationService) InviteDoctor(ctx context.Context, ownerID primitive.ObjectID, clinicID primitive.ObjectID, invite affiliationStructs.InviteRequest) (*models.Affiliation, *structs.IAppError) {
+	clinic, appErr := service.ownClinic(ctx, ownerID, clinicID)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if !scheduling.ClinicActive(clinic) {
+		return nil, utils.ReturnAppError(errors.New("clinic not active"), http.StatusConflict, "Invite Failed", "Clinic Is Suspended Or Closed")
+	}
+
+	filter := bson.M{"_id": invite.Doctor}
+	if invite.Doctor == primitive.NilObjectID {
+		filter = bson.M{"email": strings.TrimSpace(invite.Email)}
+	}
+	doctor, err := service.Repo.FindDoctor(ctx, filter)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.ReturnAppError(err, http.StatusNotFound, "Doctor Not Found", "No Doctor Registered With These Details")
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Doctor", "Server Error")
+	}
+
+	if _, found := scheduling.DoctorSession(doctor, clinicID.Hex()); found {
+		return nil, utils.ReturnAppError(interfaces.ErrSessionExists, http.StatusConflict, "Invite Failed", "Doctor Already Works At This Clinic")
+	}
+
+	affiliationID := primitive.NewObjectID()
+	affiliation := models.Affiliation{
+		ID:        affiliationID,
+		Clinic:    clinicID,
+		Doctor:    doctor.ID,
+		InvitedBy: ownerID,
+		Session: models.ClinicDetails{
+			ID:           affiliationID,
+			Clinic:       clinicID,
+			StartTime:    invite.StartTime.UTC(),
+			EndTime:      invite.EndTime.UTC(),
+			WorkingDays:  invite.WorkingDays,
+			SlotCapacity: invite.SlotCapacity,
+		},
+		Status:    utils.AffiliationStatusPending,
+		Active:    true,
+		CreatedAt: time.Now().UTC(),
+	}
+	if scheduling.SessionSlots(affiliation.Session, scheduling.SlotDuration(doctor)) > utils.MaxSlotsPerSession {
+		return nil, utils.ReturnAppError(errors.New("too many slots in session"), http.StatusBadRequest, "Invite Failed", fmt.Sprintf("Session Cannot Have More Than %d Slots Of The Doctor", utils.MaxSlotsPerSession))
+	}
+
+	if err := service.Repo.InsertAffiliation(ctx, affiliation); err != nil {
+		if errors.Is(err, interfaces.ErrAffiliationExists) {
+			return nil, utils.ReturnAppError(err, http.StatusConflict, "Invite Failed", "Doctor Already Has A Pending Invite Or Works At This Clinic")
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Invite Failed", "Server Error")
+	}
+
+	service.notify(ctx, doctor.ID, "Clinic Invite", fmt.Sprintf("%s has invited you to consult at the clinic, accept or decline the invite from your affiliations", clinic.Name))
+	return &affiliation, nil
+}
+
+// GetClinicAffiliations lists affiliations of clinic of owner, all of them when status is empty
+func (service *AffiliationService) GetClinicAffiliations(ctx context.Context, ownerID primitive.ObjectID, clinicID primitive.ObjectID, status string) ([]models.Affiliation, *structs.IAppError) {
+	if _, appErr := service.ownClinic(ctx, ownerID, clinicID); appErr != nil {
+		return nil, appErr
+	}
+	return service.getAffiliations(ctx, bson.M{"clinic": clinicID}, status)
+}
+
+// GetDoctorAffiliations lists invites and affiliations of doctor, all of them when status is empty
+func (service *AffiliationService) GetDoctorAffiliations(ctx context.Context, doctorID primitive.ObjectID, status string) ([]models.Affiliation, *structs.IAppError) {
+	return service.getAffiliations(ctx, bson.M{"doctor": doctorID}, status)
+}
+
+// AcceptAffiliation accepts pending invite of doctor, the proposed session becomes a session of the doctor at the clinic
+func (service *AffiliationService) AcceptAffiliation(ctx context.Context, doctorID primitive.ObjectID, affiliationID primitive.ObjectID) (*models.Affiliation, *structs.IAppError) {
+	affiliation, appErr := service.getAffiliationOfDoctor(ctx, doctorID, affiliationID)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if affiliation.Status != utils.AffiliationStatusPending {
+		return nil, utils.ReturnAppError(errors.New("invite not pending"), http.StatusConflict, "Cannot Accept Invite", "Invite Is Already "+affiliation.Status)
+	}
+
+	now := time.Now().UTC()
+	if err := service.Repo.AcceptAffiliation(ctx, affiliation, now); err != nil {
+		switch {
+		case errors.Is(err, interfaces.ErrSessionExists):
+			return nil, utils.ReturnAppError(err, http.StatusConflict, "Cannot Accept Invite", "You Already Have A Session At This Clinic")
+		case err == mongo.ErrNoDocuments:
+			return nil, utils.ReturnAppError(err, http.StatusConflict, "Invite Was Updated Meanwhile", "Invite Is Withdrawn Or Clinic Doesnt Exist")
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Cannot Accept Invite", "Server Error")
+	}
+
+	affiliation.Status = utils.AffiliationStatusAccepted
+	affiliation.RespondedAt = &now
+	service.notify(ctx, affiliation.InvitedBy, "Invite Accepted", "Doctor has accepted your invite and can now be booked at your clinic")
+	return &affiliation, nil
+}
+
+// DeclineAffiliation declines pending invite of doctor
+func (service *AffiliationService) DeclineAffiliation(ctx context.Context, doctorID primitive.ObjectID, affiliationID primitive.ObjectID, reason string) *structs.IAppError {
+	affiliation, appErr := service.getAffiliationOfDoctor(ctx, doctorID, affiliationID)
+	if appErr != nil {
+		return appErr
+	}
+	if affiliation.Status != utils.AffiliationStatusPending {
+		return utils.ReturnAppError(errors.New("invite not pending"), http.StatusConflict, "Cannot Decline Invite", "Invite Is Already "+affiliation.Status)
+	}
+
+	if appErr := service.closeAffiliation(ctx, affiliation, utils.AffiliationStatusDeclined, utils.RoleDoctor, reason); appErr != nil {
+		return appErr
+	}
+	service.notify(ctx, affiliation.InvitedBy, "Invite Declined", "Doctor has declined your invite")
+	return nil
+}
+
+// EndAffiliation lets doctor or owner of clinic end an accepted affiliation, a pending invite is withdrawn by owner and
+// declined by doctor instead. doctor is only unlinked once they have no upcoming appointments at the clinic
+func (service *AffiliationService) EndAffiliation(ctx context.Context, actorID primitive.ObjectID, actorRole string, affiliationID primitive.ObjectID, reason string) *structs.IAppError {
+	affiliation, err := service.Repo.GetAffiliation(ctx, bson.M{"_id": affiliationID})
+	if err != nil && err != mongo.ErrNoDocuments {
+		return utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Affiliation", "Server Error")
+	}
+
+	//affiliations of others are reported as not found so ids cant be probed
+	isParty := false
+	var otherSide primitive.ObjectID
+	if err == nil {
+		switch actorRole {
+		case utils.RoleDoctor:
+			isParty = affiliation.Doctor == actorID
+			otherSide = affiliation.InvitedBy
+		case utils.RoleClinicOwner:
+			_, appErr := service.ownClinic(ctx, actorID, affiliation.Clinic)
+			isParty = appErr == nil
+			otherSide = affiliation.Doctor
+		}
+	}
+	if !isParty {
+		return utils.ReturnAppError(errors.New("affiliation of someone else"), http.StatusNotFound, "Affiliation Not Found", "Affiliation Doesnt Exist")
+	}
+
+	status := utils.AffiliationStatusEnded
+	switch affiliation.Status {
+	case utils.AffiliationStatusPending:
+		status = utils.AffiliationStatusWithdrawn
+		if actorRole == utils.RoleDoctor {
+			status = utils.AffiliationStatusDeclined
+		}
+	case utils.AffiliationStatusAccepted:
+		upcoming, err := service.Repo.CountUpcomingAppointments(ctx, affiliation.Doctor, affiliation.Clinic)
+		if err != nil {
+			return utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To End Affiliation", "Server Error")
+		}
+		if upcoming > 0 {
+			return utils.ReturnAppError(errors.New("upcoming appointments"), http.StatusConflict, "Unable To End Affiliation", fmt.Sprintf("Doctor Has %d Upcoming Appointments At This Clinic, Cancel Or Move Them First", upcoming))
+		}
+	default:
+		return utils.ReturnAppError(errors.New("affiliation closed"), http.StatusConflict, "Unable To End Affiliation", "Affiliation Is Already "+affiliation.Status)
+	}
+
+	if appErr := service.closeAffiliation(ctx, affiliation, status, actorRole, reason); appErr != nil {
+		return appErr
+	}
+	service.notify(ctx, otherSide, "Affiliation "+status, "Affiliation between the doctor and the clinic is "+strings.ToLower(status))
+	return nil
+}
+
+func (service *AffiliationService) closeAffiliation(ctx context.Context, affiliation models.Affiliation, status string, actorRole string, reason string) *structs.IAppError {
+	if err := service.Repo.CloseAffiliation(ctx, affiliation, status, actorRole, reason, time.Now().UTC()); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return utils.ReturnAppError(err, http.StatusConflict, "Affiliation Was Updated Meanwhile", "Status Changed, Please Retry")
+		}
+		return utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Update Affiliation", "Server Error")
+	}
+	return nil
+}
+
+func (service *AffiliationService) getAffiliations(ctx context.Context, filter bson.M, status string) ([]models.Affiliation, *structs.IAppError) {
+	if status != "" {
+		filter["status"] = status
+	}
+	affiliations, err := service.Repo.GetAffiliations(ctx, filter)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Affiliations", "Server Error")
+	}
+	return affiliations, nil
+}
+
+func (service *AffiliationService) getAffiliationOfDoctor(ctx context.Context, doctorID primitive.ObjectID, affiliationID primitive.ObjectID) (models.Affiliation, *structs.IAppError) {
+	affiliation, err := service.Repo.GetAffiliation(ctx, bson.M{"_id": affiliationID, "doctor": doctorID})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return models.Affiliation{}, utils.ReturnAppError(err, http.StatusNotFound, "Affiliation Not Found", "Affiliation Doesnt Exist")
+		}
+		return models.Affiliation{}, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Affiliation", "Server Error")
+	}
+	return affiliation, nil
+}
+
+// ownClinic returns clinic after making sure it belongs to owner, clinics of others are reported as not found
+func (service *AffiliationService) ownClinic(ctx context.Context, ownerID primitive.ObjectID, clinicID primitive.ObjectID) (models.Clinic, *structs.IAppError) {
+	clinic, err := service.Repo.GetClinic(ctx, clinicID)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return models.Clinic{}, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Clinic", "Server Error")
+	}
+	if err == mongo.ErrNoDocuments || clinic.Owner != ownerID {
+		return models.Clinic{}, utils.ReturnAppError(errors.New("clinic of someone else"), http.StatusNotFound, "Clinic Not Found", "Invalid Clinic")
+	}
+	return clinic, nil
+}
+
+// notify tells the other side about a change, failures dont undo the change so they are only logged
+func (service *AffiliationService) notify(ctx context.Context, recipient primitive.ObjectID, subject string, body string) {
+	if service.Notifier == nil {
+		return
+	}
+	if err := service.Notifier.Notify(ctx, notifications.Message{Recipient: recipient, Subject: subject, Body: body}); err != nil {
+		log.Printf("failed to notify %s: %v", recipient.Hex(), err)
+	}
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	interfaces "AlShifa/Affiliation/Interfaces"
+	affiliationStructs "AlShifa/Affiliation/Structs"
+	"AlShifa/Clinic/models"
+	utils "AlShifa/Utils"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestInviteDoctor(t *testing.T) {
+	ownerID := primitive.NewObjectID()
+	clinicID := primitive.NewObjectID()
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		Name               string
+		OwnerID            primitive.ObjectID
+		ClinicStatus       string
+		DoctorClinics      []models.ClinicDetails
+		SlotMinutes        int32
+		InsertErr          error
+		ExpectedStatusCode int
+	}{
+		{Name: "Owner invites doctor by email", OwnerID: ownerID},
+		{Name: "Closed clinic cannot invite", OwnerID: ownerID, ClinicStatus: utils.ClinicStatusClosed, ExpectedStatusCode: http.StatusConflict},
+		{Name: "Owner of another clinic cannot invite", OwnerID: primitive.NewObjectID(), ExpectedStatusCode: http.StatusNotFound},
+		{Name: "Doctor already working at clinic", OwnerID: ownerID, DoctorClinics: []models.ClinicDetails{{Clinic: clinicID}}, ExpectedStatusCode: http.StatusConflict},
+		{Name: "Doctor already has a pending invite", OwnerID: ownerID, InsertErr: interfaces.ErrAffiliationExists, ExpectedStatusCode: http.StatusConflict},
+		{Name: "Session with more slots of doctor than can be numbered", OwnerID: ownerID, SlotMinutes: 1, ExpectedStatusCode: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			doctorID := primitive.NewObjectID()
+			var inserted models.Affiliation
+			mockRepo := &MockAffiliationRepo{
+				GetClinicFn: func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+					return models.Clinic{ID: clinicID, Owner: ownerID, Name: "Shifa Clinic", Status: tc.ClinicStatus}, nil
+				},
+				FindDoctorFn: func(ctx context.Context, filter bson.M) (models.Doctor, error) {
+					if filter["email"] != "doctor@alshifa.com" {
+						t.Fatalf("expected doctor to be found by email got %v", filter)
+					}
+					return models.Doctor{ID: doctorID, Clinics: tc.DoctorClinics, SlotDuration: tc.SlotMinutes}, nil
+				},
+				InsertAffiliationFn: func(ctx context.Context, affiliation models.Affiliation) error {
+					inserted = affiliation
+					return tc.InsertErr
+				},
+			}
+
+			affiliation, appErr := NewAffiliationService(mockRepo, nil).InviteDoctor(context.Background(), tc.OwnerID, clinicID, affiliationStructs.InviteRequest{
+				Email:       " doctor@alshifa.com ",
+				StartTime:   start,
+				EndTime:     start.Add(3 * time.Hour),
+				WorkingDays: []string{"Mon", "Thu"},
+			})
+
+			if tc.ExpectedStatusCode != 0 {
+				if appErr == nil || appErr.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status %d got %v", tc.ExpectedStatusCode, appErr)
+				}
+				return
+			}
+			if appErr != nil {
+				t.Fatalf("expected no error but got %v", appErr)
+			}
+			if affiliation.Status != utils.AffiliationStatusPending || !inserted.Active || inserted.Doctor != doctorID {
+				t.Fatalf("unexpected affiliation %+v", inserted)
+			}
+			//session keeps the id of affiliation so it can be traced back once accepted
+			if inserted.Session.ID != inserted.ID || inserted.Session.Clinic != clinicID || !inserted.Session.StartTime.Equal(start) {
+				t.Fatalf("unexpected proposed session %+v", inserted.Session)
+			}
+		})
+	}
+}
+
+func TestAcceptAffiliation(t *testing.T) {
+	doctorID := primitive.NewObjectID()
+
+	testCases := []struct {
+		Name               string
+		Status             string
+		AcceptErr          error
+		ExpectedStatusCode int
+	}{
+		{Name: "Doctor accepts pending invite", Status: utils.AffiliationStatusPending},
+		{Name: "Withdrawn invite cannot be accepted", Status: utils.AffiliationStatusWithdrawn, ExpectedStatusCode: http.StatusConflict},
+		{Name: "Doctor already has a session at clinic", Status: utils.AffiliationStatusPending, AcceptErr: interfaces.ErrSessionExists, ExpectedStatusCode: http.StatusConflict},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			accepted := false
+			mockRepo := &MockAffiliationRepo{
+				GetAffiliationFn: func(ctx context.Context, filter bson.M) (models.Affiliation, error) {
+					if filter["doctor"] != doctorID {
+						t.Fatalf("expected invite to be looked up for doctor got %v", filter)
+					}
+					return models.Affiliation{ID: filter["_id"].(primitive.ObjectID), Doctor: doctorID, Status: tc.Status}, nil
+				},
+				AcceptAffiliationFn: func(ctx context.Context, affiliation models.Affiliation, at time.Time) error {
+					accepted = true
+					return tc.AcceptErr
+				},
+			}
+
+			affiliation, appErr := NewAffiliationService(mockRepo, nil).AcceptAffiliation(context.Background(), doctorID, primitive.NewObjectID())
+			if tc.ExpectedStatusCode != 0 {
+				if appErr == nil || appErr.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status %d got %v", tc.ExpectedStatusCode, appErr)
+				}
+				return
+			}
+			if appErr != nil {
+				t.Fatalf("expected no error but got %v", appErr)
+			}
+			if !accepted || affiliation.Status != utils.AffiliationStatusAccepted || affiliation.RespondedAt == nil {
+				t.Fatalf("unexpected affiliation %+v", affiliation)
+			}
+		})
+	}
+}
+
+func TestEndAffiliation(t *testing.T) {
+	ownerID := primitive.NewObjectID()
+	doctorID := primitive.NewObjectID()
+
+	testCases := []struct {
+		Name               string
+		ActorID            primitive.ObjectID
+		ActorRole          string
+		Status             string
+		Upcoming           int64
+		ExpectedStatus     string
+		ExpectedStatusCode int
+	}{
+		{Name: "Owner withdraws pending invite", ActorID: ownerID, ActorRole: utils.RoleClinicOwner, Status: utils.AffiliationStatusPending, ExpectedStatus: utils.AffiliationStatusWithdrawn},
+		{Name: "Doctor ends accepted affiliation", ActorID: doctorID, ActorRole: utils.RoleDoctor, Status: utils.AffiliationStatusAccepted, ExpectedStatus: utils.AffiliationStatusEnded},
+		{Name: "Upcoming appointments block ending", ActorID: ownerID, ActorRole: utils.RoleClinicOwner, Status: utils.AffiliationStatusAccepted, Upcoming: 2, ExpectedStatusCode: http.StatusConflict},
+		{Name: "Ended affiliation cannot be ended again", ActorID: doctorID, ActorRole: utils.RoleDoctor, Status: utils.AffiliationStatusEnded, ExpectedStatusCode: http.StatusConflict},
+		{Name: "Another doctor cannot end affiliation", ActorID: primitive.NewObjectID(), ActorRole: utils.RoleDoctor, Status: utils.AffiliationStatusAccepted, ExpectedStatusCode: http.StatusNotFound},
+		{Name: "Owner of another clinic cannot end affiliation", ActorID: primitive.NewObjectID(), ActorRole: utils.RoleClinicOwner, Status: utils.AffiliationStatusAccepted, ExpectedStatusCode: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			closedAs := ""
+			mockRepo := &MockAffiliationRepo{
+				GetAffiliationFn: func(ctx context.Context, filter bson.M) (models.Affiliation, error) {
+					return models.Affiliation{ID: primitive.NewObjectID(), Clinic: primitive.NewObjectID(), Doctor: doctorID, InvitedBy: ownerID, Status: tc.Status}, nil
+				},
+				GetClinicFn: func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+					return models.Clinic{ID: clinicID, Owner: ownerID}, nil
+				},
+				CountUpcomingAppointmentsFn: func(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID) (int64, error) {
+					return tc.Upcoming, nil
+				},
+				CloseAffiliationFn: func(ctx context.Context, affiliation models.Affiliation, status string, actorRole string, reason string, at time.Time) error {
+					closedAs = status
+					return nil
+				},
+			}
+
+			appErr := NewAffiliationService(mockRepo, nil).EndAffiliation(context.Background(), tc.ActorID, tc.ActorRole, primitive.NewObjectID(), "")
+			if tc.ExpectedStatusCode != 0 {
+				if appErr == nil || appErr.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status %d got %v", tc.ExpectedStatusCode, appErr)
+				}
+				if closedAs != "" {
+					t.Fatalf("expected affiliation to stay open but it was closed as %s", closedAs)
+				}
+				return
+			}
+			if appErr != nil {
+				t.Fatalf("expected no error but got %v", appErr)
+			}
+			if closedAs != tc.ExpectedStatus {
+				t.Fatalf("expected affiliation closed as %s got %s", tc.ExpectedStatus, closedAs)
+			}
+		})
+	}
+}
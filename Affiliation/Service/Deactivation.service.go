@@ -0,0 +1,42 @@
+package service
+
+import (
+	clinicInterfaces "AlShifa/Clinic/Interfaces"
+	"AlShifa/Clinic/models"
+	utils "AlShifa/Utils"
+	"context"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// this ensures doctors are unlinked from a clinic which stops being active
+var _ clinicInterfaces.DeactivationListener = (*AffiliationService)(nil)
+
+// ClinicDeactivated ends accepted affiliations of clinic which stopped being active and withdraws its pending invites,
+// doctors are notified. unlike ending an affiliation by hand upcoming appointments dont hold it back, they are cancelled
+// along with the clinic
+func (service *AffiliationService) ClinicDeactivated(ctx context.Context, clinic models.Clinic, change models.ClinicStatusChange) error {
+	affiliations, err := service.Repo.GetAffiliations(ctx, bson.M{"clinic": clinic.ID, "active": true})
+	if err != nil {
+		return err
+	}
+
+	for _, affiliation := range affiliations {
+		status := utils.AffiliationStatusEnded
+		if affiliation.Status == utils.AffiliationStatusPending {
+			status = utils.AffiliationStatusWithdrawn
+		}
+		if err := service.Repo.CloseAffiliation(ctx, affiliation, status, change.ActorRole, change.Reason, time.Now().UTC()); err != nil {
+			//doctor responded meanwhile, winding down the clinic again picks it up
+			if err == mongo.ErrNoDocuments {
+				continue
+			}
+			return err
+		}
+		service.notify(ctx, affiliation.Doctor, "Affiliation "+status, clinic.Name+" is no longer active so your affiliation with it is "+strings.ToLower(status))
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	utils "AlShifa/Utils"
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestClinicDeactivated(t *testing.T) {
+	clinicID := primitive.NewObjectID()
+	accepted := models.Affiliation{ID: primitive.NewObjectID(), Clinic: clinicID, Doctor: primitive.NewObjectID(), Status: utils.AffiliationStatusAccepted, Active: true}
+	pending := models.Affiliation{ID: primitive.NewObjectID(), Clinic: clinicID, Doctor: primitive.NewObjectID(), Status: utils.AffiliationStatusPending, Active: true}
+	//doctor declined it meanwhile
+	responded := models.Affiliation{ID: primitive.NewObjectID(), Clinic: clinicID, Doctor: primitive.NewObjectID(), Status: utils.AffiliationStatusPending, Active: true}
+
+	closed := map[primitive.ObjectID]string{}
+	mockRepo := &MockAffiliationRepo{
+		GetAffiliationsFn: func(ctx context.Context, filter bson.M) ([]models.Affiliation, error) {
+			if filter["clinic"] != clinicID || filter["active"] != true {
+				t.Fatalf("expected active affiliations of clinic got %v", filter)
+			}
+			return []models.Affiliation{accepted, pending, responded}, nil
+		},
+		CloseAffiliationFn: func(ctx context.Context, affiliation models.Affiliation, status string, actorRole string, reason string, at time.Time) error {
+			if affiliation.ID == responded.ID {
+				return mongo.ErrNoDocuments
+			}
+			if actorRole != utils.RoleAdmin || reason != "Licence expired" {
+				t.Fatalf("unexpected closing by %s for %q", actorRole, reason)
+			}
+			closed[affiliation.ID] = status
+			return nil
+		},
+	}
+
+	change := models.ClinicStatusChange{From: utils.ClinicStatusActive, To: utils.ClinicStatusSuspended, Actor: primitive.NewObjectID(), ActorRole: utils.RoleAdmin, Reason: "Licence expired"}
+	if err := NewAffiliationService(mockRepo, nil).ClinicDeactivated(context.Background(), models.Clinic{ID: clinicID}, change); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+
+	if len(closed) != 2 || closed[accepted.ID] != utils.AffiliationStatusEnded || closed[pending.ID] != utils.AffiliationStatusWithdrawn {
+		t.Fatalf("expected accepted affiliation ended and invite withdrawn got %v", closed)
+	}
+}
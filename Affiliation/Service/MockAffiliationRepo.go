@@ -0,0 +1,80 @@
+package service
+
+import (
+	interfaces "AlShifa/Affiliation/Interfaces"
+	"AlShifa/Clinic/models"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockAffiliationRepo struct {
+	InsertAffiliationFn         func(ctx context.Context, affiliation models.Affiliation) error
+	GetAffiliationFn            func(ctx context.Context, filter bson.M) (models.Affiliation, error)
+	GetAffiliationsFn           func(ctx context.Context, filter bson.M) ([]models.Affiliation, error)
+	AcceptAffiliationFn         func(ctx context.Context, affiliation models.Affiliation, at time.Time) error
+	CloseAffiliationFn          func(ctx context.Context, affiliation models.Affiliation, status string, actorRole string, reason string, at time.Time) error
+	CountUpcomingAppointmentsFn func(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID) (int64, error)
+	FindDoctorFn                func(ctx context.Context, filter bson.M) (models.Doctor, error)
+	GetClinicFn                 func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error)
+}
+
+var _ interfaces.IRepository = (*MockAffiliationRepo)(nil)
+
+func (m *MockAffiliationRepo) InsertAffiliation(ctx context.Context, affiliation models.Affiliation) error {
+	if m.InsertAffiliationFn == nil {
+		panic("InsertAffiliationFn not implemented inside mock")
+	}
+	return m.InsertAffiliationFn(ctx, affiliation)
+}
+
+func (m *MockAffiliationRepo) GetAffiliation(ctx context.Context, filter bson.M) (models.Affiliation, error) {
+	if m.GetAffiliationFn == nil {
+		panic("GetAffiliationFn not implemented inside mock")
+	}
+	return m.GetAffiliationFn(ctx, filter)
+}
+
+func (m *MockAffiliationRepo) GetAffiliations(ctx context.Context, filter bson.M) ([]models.Affiliation, error) {
+	if m.GetAffiliationsFn == nil {
+		panic("GetAffiliationsFn not implemented inside mock")
+	}
+	return m.GetAffiliationsFn(ctx, filter)
+}
+
+func (m *MockAffiliationRepo) AcceptAffiliation(ctx context.Context, affiliation models.Affiliation, at time.Time) error {
+	if m.AcceptAffiliationFn == nil {
+		panic("AcceptAffiliationFn not implemented inside mock")
+	}
+	return m.AcceptAffiliationFn(ctx, affiliation, at)
+}
+
+func (m *MockAffiliationRepo) CloseAffiliation(ctx context.Context, affiliation models.Affiliation, status string, actorRole string, reason string, at time.Time) error {
+	if m.CloseAffiliationFn == nil {
+		panic("CloseAffiliationFn not implemented inside mock")
+	}
+	return m.CloseAffiliationFn(ctx, affiliation, status, actorRole, reason, at)
+}
+
+func (m *MockAffiliationRepo) CountUpcomingAppointments(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID) (int64, error) {
+	if m.CountUpcomingAppointmentsFn == nil {
+		panic("CountUpcomingAppointmentsFn not implemented inside mock")
+	}
+	return m.CountUpcomingAppointmentsFn(ctx, doctorID, clinicID)
+}
+
+func (m *MockAffiliationRepo) FindDoctor(ctx context.Context, filter bson.M) (models.Doctor, error) {
+	if m.FindDoctorFn == nil {
+		panic("FindDoctorFn not implemented inside mock")
+	}
+	return m.FindDoctorFn(ctx, filter)
+}
+
+func (m *MockAffiliationRepo) GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+	if m.GetClinicFn == nil {
+		panic("GetClinicFn not implemented inside mock")
+	}
+	return m.GetClinicFn(ctx, clinicID)
+}
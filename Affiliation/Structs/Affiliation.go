@@ -0,0 +1,24 @@
+// Package structs contains request structures of the affiliation module
+package structs
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InviteRequest is sent by a clinic owner to invite a registered doctor, doctor is found by id or else by email.
+// timings are proposed the same way doctors keep them in clinic details
+type InviteRequest struct {
+	Doctor       primitive.ObjectID `json:"doctor"`
+	Email        string             `json:"email"`
+	StartTime    time.Time          `json:"startTiming"`
+	EndTime      time.Time          `json:"endTime"`
+	WorkingDays  []string           `json:"workingDays"`
+	SlotCapacity int32              `json:"slotCapacity"`
+}
+
+// AffiliationAction carries optional reason for declining or ending an affiliation
+type AffiliationAction struct {
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,75 @@
+// Package validators contains validation functions for affiliation module
+package validators
+
+import (
+	affiliationStructs "AlShifa/Affiliation/Structs"
+	scheduling "AlShifa/Scheduling"
+	utils "AlShifa/Utils"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ValidateInvite validates invite sent by clinic owner and returns a map of field errors
+func ValidateInvite(invite *affiliationStructs.InviteRequest) map[string]string {
+	errors := make(map[string]string)
+
+	invite.Email = strings.TrimSpace(invite.Email)
+	if invite.Doctor == primitive.NilObjectID {
+		if invite.Email == "" {
+			errors["doctor"] = "doctor id or email is required"
+		} else if _, err := mail.ParseAddress(invite.Email); err != nil {
+			errors["email"] = utils.InvalidEmailFormatMsg
+		}
+	}
+
+	if invite.StartTime.IsZero() {
+		errors["startTiming"] = "start time is required"
+	}
+	if invite.EndTime.IsZero() {
+		errors["endTime"] = "end time is required"
+	} else if !invite.EndTime.After(invite.StartTime) {
+		errors["endTime"] = "end time must be after start time"
+	}
+
+	if len(invite.WorkingDays) == 0 {
+		errors["workingDays"] = "at least one working day is required"
+	}
+	for _, day := range invite.WorkingDays {
+		if !validWeekday(day) {
+			errors["workingDays"] = "invalid working day " + day
+			break
+		}
+	}
+
+	if invite.SlotCapacity < 0 {
+		errors["slotCapacity"] = "slot capacity cannot be negative"
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
+
+// ValidateAffiliationAction validates reason given for declining or ending an affiliation
+func ValidateAffiliationAction(action *affiliationStructs.AffiliationAction) map[string]string {
+	action.Reason = strings.TrimSpace(action.Reason)
+	if utf8.RuneCountInString(action.Reason) > utils.MaxAffiliationReasonLength {
+		return map[string]string{"reason": "reason is too long"}
+	}
+	return nil
+}
+
+// validWeekday accepts the same day names scheduling does
+func validWeekday(day string) bool {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if scheduling.WorksOn([]string{day}, weekday) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+// Package appointment provides functionalities related to doctor slots and booking, viewing and cancelling appointments.
+package appointment
+
+import (
+	controller "AlShifa/Appointment/Controller"
+	interfaces "AlShifa/Appointment/Interfaces"
+	repository "AlShifa/Appointment/Repository"
+	service "AlShifa/Appointment/Service"
+	clinicInterfaces "AlShifa/Clinic/Interfaces"
+	internals "AlShifa/Internals"
+	middleware "AlShifa/Middleware"
+	notifications "AlShifa/Notifications"
+	teleconsult "AlShifa/Teleconsult"
+	utils "AlShifa/Utils"
+	"context"
+	"log"
+)
+
+// InitialiseAppointmentModule registers appointment routes and returns the service so other modules can hook into the appointment lifecycle.
+// appointments at clinics which stop being active are cancelled
+func InitialiseAppointmentModule(app *internals.App, clinics clinicInterfaces.IService) interfaces.IService {
+	repository := repository.NewRepository(app.DB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout*5)
+	defer cancel()
+	if err := repository.CreateIndexes(ctx); err != nil {
+		log.Fatal("Failed to create appointment indexes", err)
+	}
+
+	notifier, err := notifications.NewNotifierFromEnv(notifications.NewMongoContactBook(app.DB))
+	if err != nil {
+		log.Fatal("Failed to configure notifier", err)
+	}
+
+	rooms, err := teleconsult.NewRoomProviderFromEnv()
+	if err != nil {
+		log.Fatal("Failed to configure teleconsult rooms", err)
+	}
+
+	service := service.NewAppointmentService(repository, notifier, rooms)
+	clinics.AddDeactivationListener(service)
+	controller := controller.NewController(service)
+
+	//offers which are not accepted in time move on to the next patient
+	go service.RunWaitlistSweeper(context.Background(), utils.WaitlistSweepPeriod)
+
+	app.Server.HandleFunc(utils.MakeURL("POST", "/appointment/book"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.BookAppointment, utils.RoleUser)))
+	app.Server.HandleFunc(utils.MakeURL("GET", "/appointment/details"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.GetAppointments, utils.RoleUser)))
+	app.Server.HandleFunc(utils.MakeURL("POST", "/appointment/{id}/cancel"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.CancelAppointment, utils.RoleUser)))
+	app.Server.HandleFunc(utils.MakeURL("POST", "/appointment/{id}/reschedule"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.RescheduleAppointment, utils.RoleUser)))
+	app.Server.HandleFunc(utils.MakeURL("GET", "/appointment/{id}"), middleware.JwtAuthMiddleware(middleware.PermissionGuardMiddleware(controller.GetAppointment, utils.PermissionManageAppointments, utils.RoleUser, utils.RoleDoctor, utils.RoleClinicOwner)))
+	app.Server.HandleFunc(utils.MakeURL("PATCH", "/appointment/{id}/status"), middleware.JwtAuthMiddleware(middleware.PermissionGuardMiddleware(controller.UpdateAppointmentStatus, utils.PermissionManageAppointments, utils.RoleUser, utils.RoleDoctor, utils.RoleClinicOwner)))
+	app.Server.HandleFunc(utils.MakeURL("POST", "/waitlist/join"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.JoinWaitlist, utils.RoleUser)))
+	app.Server.HandleFunc(utils.MakeURL("GET", "/waitlist"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.GetWaitlist, utils.RoleUser)))
+	app.Server.HandleFunc(utils.MakeURL("POST", "/waitlist/{id}/accept"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.AcceptWaitlistOffer, utils.RoleUser)))
+	app.Server.HandleFunc(utils.MakeURL("POST", "/waitlist/{id}/leave"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.LeaveWaitlist, utils.RoleUser)))
+	app.Server.HandleFunc(utils.MakeURL("GET", "/doctor/{id}/slots"), middleware.JwtAuthMiddleware(controller.GetDoctorSlots))
+	app.Server.HandleFunc(utils.MakeURL("GET", "/doctor/{id}/next-available"), middleware.JwtAuthMiddleware(controller.GetNextAvailableSlots))
+	app.Server.HandleFunc(utils.MakeURL("GET", "/doctors/next-available"), middleware.JwtAuthMiddleware(controller.GetNextAvailable))
+	app.Server.HandleFunc(utils.MakeURL("POST", "/doctor/leave"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.AddDoctorLeave, utils.RoleDoctor)))
+	app.Server.HandleFunc(utils.MakeURL("POST", "/clinic/{id}/closure"), middleware.JwtAuthMiddleware(middleware.PermissionGuardMiddleware(controller.AddClinicClosure, utils.PermissionEditClinic, utils.RoleClinicOwner)))
+	app.Server.HandleFunc(utils.MakeURL("GET", "/doctor/{id}/leaves"), middleware.JwtAuthMiddleware(controller.GetDoctorLeaves))
+	app.Server.HandleFunc(utils.MakeURL("DELETE", "/leave/{id}"), middleware.JwtAuthMiddleware(middleware.PermissionGuardMiddleware(controller.DeleteLeave, utils.PermissionEditClinic, utils.RoleDoctor, utils.RoleClinicOwner)))
+	app.Server.HandleFunc(utils.MakeURL("PUT", "/clinic/{id}/no-show-policy"), middleware.JwtAuthMiddleware(middleware.PermissionGuardMiddleware(controller.SetNoShowPolicy, utils.PermissionEditClinic, utils.RoleClinicOwner)))
+	app.Server.HandleFunc(utils.MakeURL("GET", "/clinic/{id}/no-shows"), middleware.JwtAuthMiddleware(middleware.PermissionGuardMiddleware(controller.GetNoShowReport, utils.PermissionManageAppointments, utils.RoleClinicOwner)))
+	app.Server.HandleFunc(utils.MakeURL("PUT", "/doctor/clinic/{id}/mode"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.SetSessionMode, utils.RoleDoctor)))
+	app.Server.HandleFunc(utils.MakeURL("GET", "/appointment/{id}/join"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.GetJoinLink, utils.RoleUser, utils.RoleDoctor)))
+
+	//join links of the local provider are the credential so its rooms need no jwt
+	if local, ok := rooms.(*teleconsult.LocalRoomProvider); ok {
+		app.Server.HandleFunc(utils.MakeURL("GET", teleconsult.LocalRoomPath+"/{room}"), local.ServeRoom)
+	}
+
+	return service
+}
@@ -0,0 +1,741 @@
+// Package controller provides HTTP handlers for managing appointments.
+package controller
+
+import (
+	interfaces "AlShifa/Appointment/Interfaces"
+	appointmentStructs "AlShifa/Appointment/Structs"
+	validators "AlShifa/Appointment/Validators"
+	"AlShifa/Clinic/models"
+	middleware "AlShifa/Middleware"
+	utils "AlShifa/Utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Controller struct {
+	Service interfaces.IService
+}
+
+type StatusUpdate struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+type RescheduleRequest struct {
+	AppointmentDate time.Time `json:"appointmentDate"`
+	Slot            int8      `json:"slot"`
+}
+
+type WaitlistRequest struct {
+	Doctor primitive.ObjectID `json:"doctor"`
+	Clinic primitive.ObjectID `json:"clinic"`
+	Date   string             `json:"date"` // YYYY-MM-DD
+}
+
+type LeaveRequest struct {
+	Clinic *primitive.ObjectID `json:"clinic"` // doctor leave only, empty means all clinics
+	Start  time.Time           `json:"start"`
+	End    time.Time           `json:"end"`
+	Reason string              `json:"reason"`
+	Action string              `json:"action"` // cancel or reschedule the appointments falling in the leave
+}
+
+type LeaveResponse struct {
+	Leave                *models.Leave        `json:"leave"`
+	AffectedAppointments []models.Appointment `json:"affectedAppointments"`
+}
+
+func NewController(service interfaces.IService) *Controller {
+	return &Controller{
+		Service: service,
+	}
+}
+
+func (controller *Controller) BookAppointment(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	userID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	var appointment models.Appointment
+	if err := json.NewDecoder(req.Body).Decode(&appointment); err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Booking Failed", "Invalid Json"))
+		return
+	}
+
+	validationErrors := validators.ValidateAppointment(&appointment)
+	if validationErrors != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(validationErrors, 400, "Booking Failed", "Invalid Details"))
+		return
+	}
+
+	bookedAppointment, err := controller.Service.BookAppointment(ctx, userID, appointment)
+	if err != nil {
+		_ = utils.WriteResponse(res, err.StatusCode, err)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusCreated, utils.ReturnAppSuccess(201, "Appointment Booked Successfully", bookedAppointment))
+}
+
+func (controller *Controller) GetAppointments(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	userID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	//optional id query param to fetch a single appointment
+	var appointmentID *primitive.ObjectID
+	if id := req.URL.Query().Get("id"); id != "" {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Appointment ID", "Invalid ID"))
+			return
+		}
+		appointmentID = &objID
+	}
+
+	appointments, err := controller.Service.GetUserAppointments(ctx, userID, appointmentID)
+	if err != nil {
+		_ = utils.WriteResponse(res, err.StatusCode, err)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Fetched Successfully", appointments))
+}
+
+func (controller *Controller) CancelAppointment(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	userID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	appointmentID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Appointment ID", "Invalid ID"))
+		return
+	}
+
+	if err := controller.Service.CancelAppointment(ctx, userID, appointmentID); err != nil {
+		_ = utils.WriteResponse(res, err.StatusCode, err)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Appointment Cancelled Successfully", nil))
+}
+
+func (controller *Controller) GetDoctorSlots(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	doctorID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Doctor ID", "Invalid ID"))
+		return
+	}
+
+	params := req.URL.Query()
+	clinicID, err := primitive.ObjectIDFromHex(params.Get("clinic"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Clinic ID", "Invalid ID"))
+		return
+	}
+
+	//from defaults to today and to defaults to a week from the from date
+	from := time.Now()
+	if value := params.Get("from"); value != "" {
+		if from, err = time.Parse(utils.DateLayout, value); err != nil {
+			_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid From Date", "Date must be in YYYY-MM-DD format"))
+			return
+		}
+	}
+
+	to := from.AddDate(0, 0, 6)
+	if value := params.Get("to"); value != "" {
+		if to, err = time.Parse(utils.DateLayout, value); err != nil {
+			_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid To Date", "Date must be in YYYY-MM-DD format"))
+			return
+		}
+	}
+
+	//mode narrows slots down to the ones open for InClinic or Video consultation
+	mode := params.Get("mode")
+	if mode != "" && mode != utils.ConsultationModeInClinic && mode != utils.ConsultationModeVideo {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(errors.New("invalid mode"), 400, "Invalid Mode", "Mode must be InClinic or Video"))
+		return
+	}
+
+	slots, appErr := controller.Service.GetDoctorSlots(ctx, doctorID, clinicID, from, to, mode)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Fetched Successfully", slots))
+}
+
+func (controller *Controller) GetNextAvailableSlots(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	doctorID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Doctor ID", "Invalid ID"))
+		return
+	}
+
+	count, days, mode, ok := readNextAvailable(res, req)
+	if !ok {
+		return
+	}
+
+	slots, appErr := controller.Service.GetNextAvailableSlots(ctx, doctorID, count, days, mode)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Fetched Successfully", slots))
+}
+
+// GetNextAvailable returns next free slots of a page of doctors given as comma separated ids
+func (controller *Controller) GetNextAvailable(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	doctorIDs := []primitive.ObjectID{}
+	for _, hex := range strings.Split(req.URL.Query().Get("ids"), ",") {
+		if hex = strings.TrimSpace(hex); hex == "" {
+			continue
+		}
+		doctorID, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Doctor ID", "Invalid ID "+hex))
+			return
+		}
+		doctorIDs = append(doctorIDs, doctorID)
+	}
+	if len(doctorIDs) == 0 || len(doctorIDs) > utils.MaxNextAvailableDoctors {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(fmt.Errorf("between 1 and %d doctor ids are required", utils.MaxNextAvailableDoctors), 400, "Unable To Fetch Slots", "Invalid Doctor IDs"))
+		return
+	}
+
+	count, days, mode, ok := readNextAvailable(res, req)
+	if !ok {
+		return
+	}
+
+	next, appErr := controller.Service.GetNextAvailable(ctx, doctorIDs, count, days, mode)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Fetched Successfully", next))
+}
+
+// readNextAvailable reads how many slots, within how many days and in which mode are wanted
+func readNextAvailable(res http.ResponseWriter, req *http.Request) (int, int, string, bool) {
+	params := req.URL.Query()
+
+	count := utils.DefaultNextSlots
+	if value := params.Get("count"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > utils.MaxNextSlots {
+			_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(fmt.Errorf("count must be between 1 and %d", utils.MaxNextSlots), 400, "Unable To Fetch Slots", "Invalid Count"))
+			return 0, 0, "", false
+		}
+		count = parsed
+	}
+
+	days := utils.DefaultNextSlotDays
+	if value := params.Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > utils.MaxSlotRangeDays {
+			_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(fmt.Errorf("days must be between 1 and %d", utils.MaxSlotRangeDays), 400, "Unable To Fetch Slots", "Invalid Days"))
+			return 0, 0, "", false
+		}
+		days = parsed
+	}
+
+	mode := params.Get("mode")
+	if mode != "" && mode != utils.ConsultationModeInClinic && mode != utils.ConsultationModeVideo {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(errors.New("invalid mode"), 400, "Invalid Mode", "Mode must be InClinic or Video"))
+		return 0, 0, "", false
+	}
+	return count, days, mode, true
+}
+
+func (controller *Controller) GetAppointment(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	actorID, actorRole, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	appointmentID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Appointment ID", "Invalid ID"))
+		return
+	}
+
+	appointment, appErr := controller.Service.GetAppointment(ctx, actorID, actorRole, appointmentID)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Fetched Successfully", appointment))
+}
+
+func (controller *Controller) UpdateAppointmentStatus(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	actorID, actorRole, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	appointmentID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Appointment ID", "Invalid ID"))
+		return
+	}
+
+	var statusUpdate StatusUpdate
+	if err := json.NewDecoder(req.Body).Decode(&statusUpdate); err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Status Update Failed", "Invalid Json"))
+		return
+	}
+	if statusUpdate.Status == "" {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(map[string]string{"status": "status is required"}, 400, "Status Update Failed", "Invalid Details"))
+		return
+	}
+
+	appointment, appErr := controller.Service.UpdateAppointmentStatus(ctx, actorID, actorRole, appointmentID, statusUpdate.Status, statusUpdate.Reason)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Status Updated Successfully", appointment))
+}
+
+func (controller *Controller) RescheduleAppointment(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	userID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	appointmentID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Appointment ID", "Invalid ID"))
+		return
+	}
+
+	var rescheduleRequest RescheduleRequest
+	if err := json.NewDecoder(req.Body).Decode(&rescheduleRequest); err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Reschedule Failed", "Invalid Json"))
+		return
+	}
+	if rescheduleRequest.AppointmentDate.IsZero() || rescheduleRequest.Slot < 0 {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(map[string]string{"slot": "appointment date and slot are required"}, 400, "Reschedule Failed", "Invalid Details"))
+		return
+	}
+
+	appointment, appErr := controller.Service.RescheduleAppointment(ctx, userID, appointmentID, rescheduleRequest.AppointmentDate, rescheduleRequest.Slot)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Appointment Rescheduled Successfully", appointment))
+}
+
+func (controller *Controller) JoinWaitlist(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	userID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	var waitlistRequest WaitlistRequest
+	if err := json.NewDecoder(req.Body).Decode(&waitlistRequest); err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Failed To Join Waitlist", "Invalid Json"))
+		return
+	}
+	if waitlistRequest.Doctor.IsZero() || waitlistRequest.Clinic.IsZero() {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(map[string]string{"doctor": "doctor and clinic are required"}, 400, "Failed To Join Waitlist", "Invalid Details"))
+		return
+	}
+
+	date, err := time.Parse(utils.DateLayout, waitlistRequest.Date)
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Date", "Date must be in YYYY-MM-DD format"))
+		return
+	}
+
+	entry, appErr := controller.Service.JoinWaitlist(ctx, userID, waitlistRequest.Doctor, waitlistRequest.Clinic, date)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusCreated, utils.ReturnAppSuccess(201, "Joined Waitlist Successfully", entry))
+}
+
+func (controller *Controller) GetWaitlist(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	userID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	entries, appErr := controller.Service.GetUserWaitlist(ctx, userID)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Fetched Successfully", entries))
+}
+
+func (controller *Controller) AcceptWaitlistOffer(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	userID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	entryID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Waitlist ID", "Invalid ID"))
+		return
+	}
+
+	appointment, appErr := controller.Service.AcceptWaitlistOffer(ctx, userID, entryID)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusCreated, utils.ReturnAppSuccess(201, "Appointment Booked Successfully", appointment))
+}
+
+func (controller *Controller) LeaveWaitlist(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	userID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	entryID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Waitlist ID", "Invalid ID"))
+		return
+	}
+
+	if appErr := controller.Service.LeaveWaitlist(ctx, userID, entryID); appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Left Waitlist Successfully", nil))
+}
+
+func (controller *Controller) AddDoctorLeave(res http.ResponseWriter, req *http.Request) {
+	//every appointment in the leave is updated so this takes longer than usual requests
+	ctx, cancel := context.WithTimeout(req.Context(), utils.BulkRequestTimeout)
+	defer cancel()
+
+	doctorID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	var leaveRequest LeaveRequest
+	if err := json.NewDecoder(req.Body).Decode(&leaveRequest); err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Failed To Add Leave", "Invalid Json"))
+		return
+	}
+
+	leave := models.Leave{Clinic: leaveRequest.Clinic, Start: leaveRequest.Start, End: leaveRequest.End, Reason: leaveRequest.Reason}
+	savedLeave, affected, appErr := controller.Service.AddDoctorLeave(ctx, doctorID, leave, leaveRequest.Action)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusCreated, utils.ReturnAppSuccess(201, "Leave Added Successfully", LeaveResponse{Leave: savedLeave, AffectedAppointments: affected}))
+}
+
+func (controller *Controller) AddClinicClosure(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.BulkRequestTimeout)
+	defer cancel()
+
+	ownerID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	clinicID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Clinic ID", "Invalid ID"))
+		return
+	}
+
+	var leaveRequest LeaveRequest
+	if err := json.NewDecoder(req.Body).Decode(&leaveRequest); err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Failed To Add Closure", "Invalid Json"))
+		return
+	}
+
+	leave := models.Leave{Start: leaveRequest.Start, End: leaveRequest.End, Reason: leaveRequest.Reason}
+	savedLeave, affected, appErr := controller.Service.AddClinicClosure(ctx, ownerID, clinicID, leave, leaveRequest.Action)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusCreated, utils.ReturnAppSuccess(201, "Closure Added Successfully", LeaveResponse{Leave: savedLeave, AffectedAppointments: affected}))
+}
+
+func (controller *Controller) GetDoctorLeaves(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	doctorID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Doctor ID", "Invalid ID"))
+		return
+	}
+
+	//from defaults to today and to defaults to a month from the from date, to is inclusive
+	params := req.URL.Query()
+	from := time.Now()
+	if value := params.Get("from"); value != "" {
+		if from, err = time.Parse(utils.DateLayout, value); err != nil {
+			_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid From Date", "Date must be in YYYY-MM-DD format"))
+			return
+		}
+	}
+
+	to := from.AddDate(0, 1, 0)
+	if value := params.Get("to"); value != "" {
+		if to, err = time.Parse(utils.DateLayout, value); err != nil {
+			_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid To Date", "Date must be in YYYY-MM-DD format"))
+			return
+		}
+		to = to.AddDate(0, 0, 1)
+	}
+
+	leaves, appErr := controller.Service.GetDoctorLeaves(ctx, doctorID, from, to)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Fetched Successfully", leaves))
+}
+
+func (controller *Controller) DeleteLeave(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	actorID, actorRole, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	leaveID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Leave ID", "Invalid ID"))
+		return
+	}
+
+	if appErr := controller.Service.DeleteLeave(ctx, actorID, actorRole, leaveID); appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Leave Deleted Successfully", nil))
+}
+
+// SetNoShowPolicy replaces the no show policy of a clinic of the owner
+func (controller *Controller) SetNoShowPolicy(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	ownerID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	clinicID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Clinic ID", "Invalid ID"))
+		return
+	}
+
+	var policy models.NoShowPolicy
+	if err := json.NewDecoder(req.Body).Decode(&policy); err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Failed To Update No Show Policy", "Invalid Json"))
+		return
+	}
+
+	validationErrors := validators.ValidateNoShowPolicy(&policy)
+	if validationErrors != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(validationErrors, 400, "Failed To Update No Show Policy", "Invalid Details"))
+		return
+	}
+
+	if appErr := controller.Service.SetNoShowPolicy(ctx, ownerID, clinicID, policy); appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "No Show Policy Updated Successfully", policy))
+}
+
+// GetNoShowReport lists patients with most no shows at a clinic of the owner, days defaults to 90
+func (controller *Controller) GetNoShowReport(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	ownerID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	clinicID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Clinic ID", "Invalid ID"))
+		return
+	}
+
+	days := utils.DefaultNoShowReportDays
+	if value := req.URL.Query().Get("days"); value != "" {
+		days, err = strconv.Atoi(value)
+		if err != nil || days < 1 || days > utils.MaxNoShowWindowDays {
+			_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Days", fmt.Sprintf("Days must be between 1 and %d", utils.MaxNoShowWindowDays)))
+			return
+		}
+	}
+
+	report, appErr := controller.Service.GetNoShowReport(ctx, ownerID, clinicID, days)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "No Show Report Fetched Successfully", report))
+}
+
+// SetSessionMode sets how session of logged in doctor at a clinic is consulted
+func (controller *Controller) SetSessionMode(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	doctorID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	clinicID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Clinic ID", "Invalid ID"))
+		return
+	}
+
+	var sessionMode appointmentStructs.SessionMode
+	if err := json.NewDecoder(req.Body).Decode(&sessionMode); err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Failed To Update Session", "Invalid Json"))
+		return
+	}
+
+	validationErrors := validators.ValidateSessionMode(&sessionMode)
+	if validationErrors != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(validationErrors, 400, "Failed To Update Session", "Invalid Details"))
+		return
+	}
+
+	if appErr := controller.Service.SetSessionMode(ctx, doctorID, clinicID, sessionMode); appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Session Updated Successfully", sessionMode))
+}
+
+// GetJoinLink returns signed link for the logged in doctor or patient to join room of a video appointment
+func (controller *Controller) GetJoinLink(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	actorID, actorRole, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	appointmentID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Appointment ID", "Invalid ID"))
+		return
+	}
+
+	link, appErr := controller.Service.GetJoinLink(ctx, actorID, actorRole, appointmentID)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Fetched Successfully", link))
+}
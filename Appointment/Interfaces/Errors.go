@@ -0,0 +1,10 @@
+package interfaces
+
+import "errors"
+
+var (
+	// ErrSlotFull is returned by repository when all seats of a slot are reserved
+	ErrSlotFull = errors.New("slot capacity exceeded")
+	// ErrHoldExpired is returned by repository when a hold expired before it could be confirmed
+	ErrHoldExpired = errors.New("slot hold expired")
+)
@@ -0,0 +1,43 @@
+// Package interfaces contains interfaces for Appointment module
+package interfaces
+
+import (
+	appointmentStructs "AlShifa/Appointment/Structs"
+	"AlShifa/Clinic/models"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IRepository defines the methods for loose coupling between the appointment repository and its implementation.
+type IRepository interface {
+	BookAppointment(ctx context.Context, appointment models.Appointment, reservation models.SlotReservation, capacity int) error
+	GetAppointments(ctx context.Context, filter bson.M) ([]models.Appointment, error)
+	GetAppointment(ctx context.Context, filter bson.M) (models.Appointment, error)
+	TransitionAppointmentStatus(ctx context.Context, appointmentID primitive.ObjectID, from string, transition models.StatusTransition, releaseSlot bool) error
+	GetReservations(ctx context.Context, filter bson.M) ([]models.SlotReservation, error)
+	RescheduleAppointment(ctx context.Context, oldAppointmentID primitive.ObjectID, from string, transition models.StatusTransition, newAppointment models.Appointment, reservation models.SlotReservation, capacity int) error
+	HoldSeat(ctx context.Context, reservation models.SlotReservation, capacity int, ttl time.Duration) (primitive.ObjectID, error)
+	ReleaseHold(ctx context.Context, holdID primitive.ObjectID) error
+	InsertWaitlistEntry(ctx context.Context, entry models.WaitlistEntry) error
+	GetWaitlistEntries(ctx context.Context, filter bson.M) ([]models.WaitlistEntry, error)
+	GetWaitlistEntry(ctx context.Context, filter bson.M) (models.WaitlistEntry, error)
+	ClaimNextWaitlistEntry(ctx context.Context, filter bson.M, offer models.WaitlistOffer) (models.WaitlistEntry, error)
+	SetWaitlistStatus(ctx context.Context, entryID primitive.ObjectID, from string, to string) error
+	AcceptWaitlistOffer(ctx context.Context, entry models.WaitlistEntry, appointment models.Appointment) error
+	InsertLeave(ctx context.Context, leave models.Leave) error
+	GetLeaves(ctx context.Context, filter bson.M) ([]models.Leave, error)
+	GetLeave(ctx context.Context, filter bson.M) (models.Leave, error)
+	DeleteLeave(ctx context.Context, leaveID primitive.ObjectID) error
+	GetNoShows(ctx context.Context, filter bson.M) ([]models.NoShowRecord, error)
+	CountNoShows(ctx context.Context, filter bson.M) (int64, error)
+	GetNoShowOffenders(ctx context.Context, clinicID primitive.ObjectID, since time.Time, limit int64) ([]appointmentStructs.NoShowOffender, error)
+	SetNoShowPolicy(ctx context.Context, clinicID primitive.ObjectID, policy models.NoShowPolicy) error
+	SetSessionMode(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID, mode string, onlineHours []models.TimeWindow) error
+	GetDoctor(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error)
+	GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error)
+	GetDoctors(ctx context.Context, filter bson.M) ([]models.Doctor, error)
+	GetClinics(ctx context.Context, filter bson.M) ([]models.Clinic, error)
+}
@@ -0,0 +1,44 @@
+package interfaces
+
+import (
+	appointmentStructs "AlShifa/Appointment/Structs"
+	"AlShifa/Clinic/models"
+	scheduling "AlShifa/Scheduling"
+	structs "AlShifa/Structs"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IService interface contains functions that appointment service layer must implement( to be used by handlers)
+type IService interface {
+	BookAppointment(ctx context.Context, userID primitive.ObjectID, appointment models.Appointment) (*models.Appointment, *structs.IAppError)
+	BookWalkInAppointment(ctx context.Context, actorID primitive.ObjectID, actorRole string, userID primitive.ObjectID, appointment models.Appointment) (*models.Appointment, *structs.IAppError)
+	GetUserAppointments(ctx context.Context, userID primitive.ObjectID, appointmentID *primitive.ObjectID) ([]models.Appointment, *structs.IAppError)
+	CancelAppointment(ctx context.Context, userID primitive.ObjectID, appointmentID primitive.ObjectID) *structs.IAppError
+	RescheduleAppointment(ctx context.Context, userID primitive.ObjectID, appointmentID primitive.ObjectID, date time.Time, slot int8) (*models.Appointment, *structs.IAppError)
+	GetAppointment(ctx context.Context, actorID primitive.ObjectID, actorRole string, appointmentID primitive.ObjectID) (*models.Appointment, *structs.IAppError)
+	UpdateAppointmentStatus(ctx context.Context, actorID primitive.ObjectID, actorRole string, appointmentID primitive.ObjectID, status string, reason string) (*models.Appointment, *structs.IAppError)
+	JoinWaitlist(ctx context.Context, userID primitive.ObjectID, doctorID primitive.ObjectID, clinicID primitive.ObjectID, date time.Time) (*models.WaitlistEntry, *structs.IAppError)
+	GetUserWaitlist(ctx context.Context, userID primitive.ObjectID) ([]models.WaitlistEntry, *structs.IAppError)
+	LeaveWaitlist(ctx context.Context, userID primitive.ObjectID, entryID primitive.ObjectID) *structs.IAppError
+	AcceptWaitlistOffer(ctx context.Context, userID primitive.ObjectID, entryID primitive.ObjectID) (*models.Appointment, *structs.IAppError)
+	GetDoctorSlots(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID, from time.Time, to time.Time, mode string) ([]scheduling.Slot, *structs.IAppError)
+	GetNextAvailableSlots(ctx context.Context, doctorID primitive.ObjectID, count int, days int, mode string) ([]appointmentStructs.AvailableSlot, *structs.IAppError)
+	GetNextAvailable(ctx context.Context, doctorIDs []primitive.ObjectID, count int, days int, mode string) ([]appointmentStructs.NextAvailable, *structs.IAppError)
+	AddDoctorLeave(ctx context.Context, doctorID primitive.ObjectID, leave models.Leave, action string) (*models.Leave, []models.Appointment, *structs.IAppError)
+	AddClinicClosure(ctx context.Context, ownerID primitive.ObjectID, clinicID primitive.ObjectID, leave models.Leave, action string) (*models.Leave, []models.Appointment, *structs.IAppError)
+	GetDoctorLeaves(ctx context.Context, doctorID primitive.ObjectID, from time.Time, to time.Time) ([]models.Leave, *structs.IAppError)
+	DeleteLeave(ctx context.Context, actorID primitive.ObjectID, actorRole string, leaveID primitive.ObjectID) *structs.IAppError
+	SetNoShowPolicy(ctx context.Context, ownerID primitive.ObjectID, clinicID primitive.ObjectID, policy models.NoShowPolicy) *structs.IAppError
+	GetNoShowReport(ctx context.Context, ownerID primitive.ObjectID, clinicID primitive.ObjectID, days int) (*appointmentStructs.NoShowReport, *structs.IAppError)
+	SetSessionMode(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID, sessionMode appointmentStructs.SessionMode) *structs.IAppError
+	GetJoinLink(ctx context.Context, actorID primitive.ObjectID, actorRole string, appointmentID primitive.ObjectID) (*appointmentStructs.JoinLink, *structs.IAppError)
+	AddStatusListener(listener StatusListener)
+}
+
+// StatusListener is notified after an appointment status change is saved, other modules use it to react to the lifecycle
+type StatusListener interface {
+	AppointmentStatusChanged(ctx context.Context, appointment models.Appointment, transition models.StatusTransition)
+}
@@ -0,0 +1,481 @@
+// Package repository provides the implementation of the repository layer for managing appointments in MongoDB.
+package repository
+
+import (
+	interfaces "AlShifa/Appointment/Interfaces"
+	appointmentStructs "AlShifa/Appointment/Structs"
+	"AlShifa/Clinic/models"
+	utils "AlShifa/Utils"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Repo is the MongoDB implementation of the appointment IRepository interface.
+type Repo struct {
+	DB *mongo.Database
+}
+
+// this ensures this repo implements all methods of repository interface
+var _ interfaces.IRepository = (*Repo)(nil)
+
+// NewRepository creates a new appointment repository with the specified database.
+func NewRepository(db *mongo.Database) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+// appendToArray returns an update pipeline which appends value to field, it also works when field is null
+// (users and doctors are stored with null appointment arrays at registration so plain $push would fail)
+func appendToArray(field string, value any) bson.A {
+	return bson.A{
+		bson.M{"$set": bson.M{
+			field: bson.M{"$concatArrays": bson.A{
+				bson.M{"$ifNull": bson.A{"$" + field, bson.A{}}},
+				bson.A{bson.M{"$literal": value}},
+			}},
+		}},
+	}
+}
+
+// CreateIndexes creates indexes appointment module relies on, unique index on reservations is what prevents double booking
+// and TTL index removes holds which were never confirmed
+func (r *Repo) CreateIndexes(ctx context.Context) error {
+	_, err := r.DB.Collection("SlotReservation").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "doctor", Value: 1},
+				{Key: "clinic", Value: 1},
+				{Key: "date", Value: 1},
+				{Key: "slot", Value: 1},
+				{Key: "unit", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "appointment", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.DB.Collection("Waitlist").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "doctor", Value: 1},
+				{Key: "clinic", Value: 1},
+				{Key: "date", Value: 1},
+				{Key: "status", Value: 1},
+				{Key: "createdAt", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "offer.expiresAt", Value: 1}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.DB.Collection("Leave").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "doctor", Value: 1}, {Key: "start", Value: 1}, {Key: "end", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "clinic", Value: 1}, {Key: "start", Value: 1}, {Key: "end", Value: 1}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.DB.Collection("NoShow").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "appointment", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "user", Value: 1}, {Key: "appointmentDate", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "clinic", Value: 1}, {Key: "appointmentDate", Value: -1}},
+		},
+	})
+	return err
+}
+
+// HoldSeat takes the first free seat of slot as a hold which expires after ttl. every seat is a separate document so concurrent
+// holds race on the unique index and only one of them can take a seat. returns ErrSlotFull when all seats are taken
+func (r *Repo) HoldSeat(ctx context.Context, reservation models.SlotReservation, capacity int, ttl time.Duration) (primitive.ObjectID, error) {
+	now := time.Now().UTC()
+
+	//TTL monitor runs only once a minute so clear holds of this slot which have already expired
+	if _, err := r.DB.Collection("SlotReservation").DeleteMany(ctx, bson.M{
+		"doctor":    reservation.Doctor,
+		"clinic":    reservation.Clinic,
+		"date":      reservation.Date,
+		"slot":      reservation.Slot,
+		"expiresAt": bson.M{"$lte": now},
+	}); err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	expiresAt := now.Add(ttl)
+	reservation.ExpiresAt = &expiresAt
+	reservation.CreatedAt = now
+
+	for unit := range capacity {
+		reservation.ID = primitive.NewObjectID()
+		reservation.Unit = int32(unit)
+
+		_, err := r.DB.Collection("SlotReservation").InsertOne(ctx, reservation)
+		if err == nil {
+			return reservation.ID, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return primitive.NilObjectID, err
+		}
+	}
+	return primitive.NilObjectID, interfaces.ErrSlotFull
+}
+
+// ReleaseHold gives back a seat which is still on hold, confirmed seats are not touched
+func (r *Repo) ReleaseHold(ctx context.Context, holdID primitive.ObjectID) error {
+	_, err := r.DB.Collection("SlotReservation").DeleteOne(ctx, bson.M{"_id": holdID, "expiresAt": bson.M{"$exists": true}})
+	return err
+}
+
+// confirmHold turns hold into a permanent reservation of appointment, it fails with ErrHoldExpired if hold is gone or expired
+func (r *Repo) confirmHold(ctx context.Context, holdID primitive.ObjectID, appointmentID primitive.ObjectID) error {
+	res, err := r.DB.Collection("SlotReservation").UpdateOne(ctx,
+		bson.M{"_id": holdID, "expiresAt": bson.M{"$gt": time.Now().UTC()}},
+		bson.M{
+			"$set":   bson.M{"appointment": appointmentID},
+			"$unset": bson.M{"expiresAt": ""},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return interfaces.ErrHoldExpired
+	}
+	return nil
+}
+
+// linkAppointment inserts appointment and links it to user and doctor
+func (r *Repo) linkAppointment(ctx context.Context, appointment models.Appointment) error {
+	// 1️⃣ Insert appointment
+	if _, err := r.DB.Collection("Appointment").InsertOne(ctx, appointment); err != nil {
+		return err
+	}
+
+	// 2️⃣ Link appointment to user
+	userRes, err := r.DB.Collection("User").UpdateOne(ctx, bson.M{"_id": appointment.User}, appendToArray("appointmentIDS", appointment.ID))
+	if err != nil {
+		return err
+	}
+	if userRes.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	// 3️⃣ Link appointment to doctor
+	doctorRes, err := r.DB.Collection("Doctor").UpdateOne(ctx, bson.M{"_id": appointment.Doctor}, appendToArray("appointments", appointment.ID))
+	if err != nil {
+		return err
+	}
+	if doctorRes.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *Repo) BookAppointment(ctx context.Context, appointment models.Appointment, reservation models.SlotReservation, capacity int) error {
+	//seat is held outside transaction because a duplicate key error aborts the whole transaction,
+	//if anything below fails the hold is released (or expires on its own if we crash)
+	holdID, err := r.HoldSeat(ctx, reservation, capacity, utils.SlotHoldDuration)
+	if err != nil {
+		return err
+	}
+
+	session, err := r.DB.Client().StartSession()
+	if err != nil {
+		_ = r.ReleaseHold(ctx, holdID)
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	callback := func(sessCtx mongo.SessionContext) (any, error) {
+		if err := r.linkAppointment(sessCtx, appointment); err != nil {
+			return nil, err
+		}
+		return nil, r.confirmHold(sessCtx, holdID, appointment.ID)
+	}
+
+	if _, err = session.WithTransaction(ctx, callback); err != nil {
+		_ = r.ReleaseHold(ctx, holdID)
+		return err
+	}
+	return nil
+}
+
+// RescheduleAppointment holds a seat in the new slot and then in one transaction moves old appointment to Rescheduled,
+// frees its seat and creates the new appointment on the held seat. if anything fails old appointment stays as it was
+func (r *Repo) RescheduleAppointment(ctx context.Context, oldAppointmentID primitive.ObjectID, from string, transition models.StatusTransition, newAppointment models.Appointment, reservation models.SlotReservation, capacity int) error {
+	holdID, err := r.HoldSeat(ctx, reservation, capacity, utils.SlotHoldDuration)
+	if err != nil {
+		return err
+	}
+
+	session, err := r.DB.Client().StartSession()
+	if err != nil {
+		_ = r.ReleaseHold(ctx, holdID)
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	callback := func(sessCtx mongo.SessionContext) (any, error) {
+		if err := r.transitionStatus(sessCtx, oldAppointmentID, from, transition, true); err != nil {
+			return nil, err
+		}
+		if err := r.linkAppointment(sessCtx, newAppointment); err != nil {
+			return nil, err
+		}
+		return nil, r.confirmHold(sessCtx, holdID, newAppointment.ID)
+	}
+
+	if _, err = session.WithTransaction(ctx, callback); err != nil {
+		_ = r.ReleaseHold(ctx, holdID)
+		return err
+	}
+	return nil
+}
+
+func (r *Repo) GetReservations(ctx context.Context, filter bson.M) ([]models.SlotReservation, error) {
+	cursor, err := r.DB.Collection("SlotReservation").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var reservations []models.SlotReservation
+	if err := cursor.All(ctx, &reservations); err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+func (r *Repo) GetAppointments(ctx context.Context, filter bson.M) ([]models.Appointment, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "appointmentDate", Value: 1}})
+	cursor, err := r.DB.Collection("Appointment").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var appointments []models.Appointment
+	if err := cursor.All(ctx, &appointments); err != nil {
+		return nil, err
+	}
+
+	return appointments, nil
+}
+
+func (r *Repo) GetAppointment(ctx context.Context, filter bson.M) (models.Appointment, error) {
+	var appointment models.Appointment
+	err := r.DB.Collection("Appointment").FindOne(ctx, filter).Decode(&appointment)
+	return appointment, err
+}
+
+// TransitionAppointmentStatus moves appointment from status to transition.To and records the transition in its history.
+// the update only matches when appointment is still in from status so two concurrent changes cant both win,
+// mongo.ErrNoDocuments is returned in that case. seat of appointment is freed in same transaction when releaseSlot is true
+func (r *Repo) TransitionAppointmentStatus(ctx context.Context, appointmentID primitive.ObjectID, from string, transition models.StatusTransition, releaseSlot bool) error {
+	session, err := r.DB.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	callback := func(sessCtx mongo.SessionContext) (any, error) {
+		return nil, r.transitionStatus(sessCtx, appointmentID, from, transition, releaseSlot)
+	}
+
+	_, err = session.WithTransaction(ctx, callback)
+	return err
+}
+
+// transitionStatus moves appointment to transition.To, a no show is also recorded against the user in the same transaction
+func (r *Repo) transitionStatus(ctx context.Context, appointmentID primitive.ObjectID, from string, transition models.StatusTransition, releaseSlot bool) error {
+	update := append(appendToArray("statusHistory", transition), bson.M{"$set": bson.M{"status": transition.To}})
+	var appointment models.Appointment
+	err := r.DB.Collection("Appointment").FindOneAndUpdate(ctx, bson.M{"_id": appointmentID, "status": from}, update).Decode(&appointment)
+	if err != nil {
+		return err
+	}
+
+	if transition.To == utils.AppointmentStatusNoShow {
+		_, err := r.DB.Collection("NoShow").InsertOne(ctx, models.NoShowRecord{
+			ID:              primitive.NewObjectID(),
+			User:            appointment.User,
+			Clinic:          appointment.Clinic,
+			Doctor:          appointment.Doctor,
+			Appointment:     appointment.ID,
+			AppointmentDate: appointment.AppointmentDate,
+			RecordedBy:      transition.Actor,
+			RecordedAt:      transition.At,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if releaseSlot {
+		if _, err := r.DB.Collection("SlotReservation").DeleteOne(ctx, bson.M{"appointment": appointmentID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetNoShows returns no show records matching filter, latest first
+func (r *Repo) GetNoShows(ctx context.Context, filter bson.M) ([]models.NoShowRecord, error) {
+	opts := options.Find().SetSort(bson.M{"appointmentDate": -1})
+	cursor, err := r.DB.Collection("NoShow").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []models.NoShowRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (r *Repo) CountNoShows(ctx context.Context, filter bson.M) (int64, error) {
+	return r.DB.Collection("NoShow").CountDocuments(ctx, filter)
+}
+
+// GetNoShowOffenders groups no shows at clinic since since by user and returns the limit users with most of them
+func (r *Repo) GetNoShowOffenders(ctx context.Context, clinicID primitive.ObjectID, since time.Time, limit int64) ([]appointmentStructs.NoShowOffender, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"clinic": clinicID, "appointmentDate": bson.M{"$gte": since}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":        "$user",
+			"noShows":    bson.M{"$sum": 1},
+			"lastNoShow": bson.M{"$max": "$appointmentDate"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "noShows", Value: -1}, {Key: "lastNoShow", Value: -1}}}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "User",
+			"localField":   "_id",
+			"foreignField": "_id",
+			"as":           "details",
+		}}},
+		{{Key: "$unwind", Value: bson.M{"path": "$details", "preserveNullAndEmptyArrays": true}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":        0,
+			"user":       "$_id",
+			"name":       "$details.name",
+			"mobile":     "$details.mobile",
+			"noShows":    1,
+			"lastNoShow": 1,
+		}}},
+	}
+
+	cursor, err := r.DB.Collection("NoShow").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	offenders := []appointmentStructs.NoShowOffender{}
+	if err := cursor.All(ctx, &offenders); err != nil {
+		return nil, err
+	}
+	return offenders, nil
+}
+
+func (r *Repo) SetNoShowPolicy(ctx context.Context, clinicID primitive.ObjectID, policy models.NoShowPolicy) error {
+	res, err := r.DB.Collection("Clinic").UpdateOne(ctx, bson.M{"_id": clinicID}, bson.M{"$set": bson.M{"bookingRules.noShowPolicy": policy}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// SetSessionMode sets consultation mode and online hours of session of doctor at clinic
+func (r *Repo) SetSessionMode(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID, mode string, onlineHours []models.TimeWindow) error {
+	res, err := r.DB.Collection("Doctor").UpdateOne(ctx,
+		bson.M{"_id": doctorID, "clinics.clinic": clinicID},
+		bson.M{"$set": bson.M{"clinics.$.mode": mode, "clinics.$.onlineHours": onlineHours}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// GetDoctor returns the doctor without password
+func (r *Repo) GetDoctor(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error) {
+	var doctor models.Doctor
+	opts := options.FindOne().SetProjection(bson.M{"password": 0})
+	err := r.DB.Collection("Doctor").FindOne(ctx, bson.M{"_id": doctorID}, opts).Decode(&doctor)
+	return doctor, err
+}
+
+func (r *Repo) GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+	var clinic models.Clinic
+	err := r.DB.Collection("Clinic").FindOne(ctx, bson.M{"_id": clinicID}).Decode(&clinic)
+	return clinic, err
+}
+
+// GetDoctors returns doctors matching filter without passwords
+func (r *Repo) GetDoctors(ctx context.Context, filter bson.M) ([]models.Doctor, error) {
+	opts := options.Find().SetProjection(bson.M{"password": 0})
+	cursor, err := r.DB.Collection("Doctor").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var doctors []models.Doctor
+	if err := cursor.All(ctx, &doctors); err != nil {
+		return nil, err
+	}
+	return doctors, nil
+}
+
+func (r *Repo) GetClinics(ctx context.Context, filter bson.M) ([]models.Clinic, error) {
+	cursor, err := r.DB.Collection("Clinic").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var clinics []models.Clinic
+	if err := cursor.All(ctx, &clinics); err != nil {
+		return nil, err
+	}
+	return clinics, nil
+}
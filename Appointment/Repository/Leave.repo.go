@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"AlShifa/Clinic/models"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (r *Repo) InsertLeave(ctx context.Context, leave models.Leave) error {
+	_, err := r.DB.Collection("Leave").InsertOne(ctx, leave)
+	return err
+}
+
+// GetLeaves returns leaves matching filter ordered by their start
+func (r *Repo) GetLeaves(ctx context.Context, filter bson.M) ([]models.Leave, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "start", Value: 1}})
+	cursor, err := r.DB.Collection("Leave").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var leaves []models.Leave
+	if err := cursor.All(ctx, &leaves); err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
+
+func (r *Repo) GetLeave(ctx context.Context, filter bson.M) (models.Leave, error) {
+	var leave models.Leave
+	err := r.DB.Collection("Leave").FindOne(ctx, filter).Decode(&leave)
+	return leave, err
+}
+
+func (r *Repo) DeleteLeave(ctx context.Context, leaveID primitive.ObjectID) error {
+	res, err := r.DB.Collection("Leave").DeleteOne(ctx, bson.M{"_id": leaveID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
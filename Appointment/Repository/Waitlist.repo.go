@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"AlShifa/Clinic/models"
+	utils "AlShifa/Utils"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (r *Repo) InsertWaitlistEntry(ctx context.Context, entry models.WaitlistEntry) error {
+	_, err := r.DB.Collection("Waitlist").InsertOne(ctx, entry)
+	return err
+}
+
+// GetWaitlistEntries returns entries matching filter in the order they joined
+func (r *Repo) GetWaitlistEntries(ctx context.Context, filter bson.M) ([]models.WaitlistEntry, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	cursor, err := r.DB.Collection("Waitlist").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []models.WaitlistEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *Repo) GetWaitlistEntry(ctx context.Context, filter bson.M) (models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+	err := r.DB.Collection("Waitlist").FindOne(ctx, filter).Decode(&entry)
+	return entry, err
+}
+
+// ClaimNextWaitlistEntry atomically moves the oldest waiting entry matching filter to Offered with offer,
+// so two freed seats can never be offered to the same patient. mongo.ErrNoDocuments means nobody is waiting
+func (r *Repo) ClaimNextWaitlistEntry(ctx context.Context, filter bson.M, offer models.WaitlistOffer) (models.WaitlistEntry, error) {
+	filter["status"] = utils.WaitlistStatusWaiting
+
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "createdAt", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var entry models.WaitlistEntry
+	err := r.DB.Collection("Waitlist").FindOneAndUpdate(ctx, filter, bson.M{
+		"$set": bson.M{"status": utils.WaitlistStatusOffered, "offer": offer},
+	}, opts).Decode(&entry)
+	return entry, err
+}
+
+// SetWaitlistStatus moves entry from status to status, offer is kept only for Offered and Accepted entries.
+// mongo.ErrNoDocuments is returned when entry is not in from status anymore
+func (r *Repo) SetWaitlistStatus(ctx context.Context, entryID primitive.ObjectID, from string, to string) error {
+	update := bson.M{"$set": bson.M{"status": to}}
+	if to == utils.WaitlistStatusWaiting || to == utils.WaitlistStatusExpired || to == utils.WaitlistStatusLeft {
+		update["$unset"] = bson.M{"offer": ""}
+	}
+
+	res, err := r.DB.Collection("Waitlist").UpdateOne(ctx, bson.M{"_id": entryID, "status": from}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// AcceptWaitlistOffer creates appointment on the seat held for the offer and marks entry Accepted in one transaction
+func (r *Repo) AcceptWaitlistOffer(ctx context.Context, entry models.WaitlistEntry, appointment models.Appointment) error {
+	session, err := r.DB.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	callback := func(sessCtx mongo.SessionContext) (any, error) {
+		res, err := r.DB.Collection("Waitlist").UpdateOne(sessCtx,
+			bson.M{"_id": entry.ID, "status": utils.WaitlistStatusOffered, "offer.expiresAt": bson.M{"$gt": time.Now().UTC()}},
+			bson.M{"$set": bson.M{"status": utils.WaitlistStatusAccepted, "appointment": appointment.ID}},
+		)
+		if err != nil {
+			return nil, err
+		}
+		if res.MatchedCount == 0 {
+			return nil, mongo.ErrNoDocuments
+		}
+
+		if err := r.linkAppointment(sessCtx, appointment); err != nil {
+			return nil, err
+		}
+		return nil, r.confirmHold(sessCtx, entry.Offer.Hold, appointment.ID)
+	}
+
+	_, err = session.WithTransaction(ctx, callback)
+	return err
+}
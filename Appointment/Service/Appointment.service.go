@@ -0,0 +1,478 @@
+// Package service contains service layer implementation for appointment module
+package service
+
+import (
+	interfaces "AlShifa/Appointment/Interfaces"
+	"AlShifa/Clinic/models"
+	middleware "AlShifa/Middleware"
+	notifications "AlShifa/Notifications"
+	scheduling "AlShifa/Scheduling"
+	structs "AlShifa/Structs"
+	teleconsult "AlShifa/Teleconsult"
+	utils "AlShifa/Utils"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type AppointmentService struct {
+	Repo      interfaces.IRepository
+	Notifier  notifications.Notifier
+	Rooms     teleconsult.RoomProvider
+	Listeners []interfaces.StatusListener
+}
+
+// NewAppointmentService creates appointment service, notifier is used to tell patients about changes they didnt make and can be nil.
+// rooms creates video rooms, without it only in clinic appointments can be booked
+func NewAppointmentService(repo interfaces.IRepository, notifier notifications.Notifier, rooms teleconsult.RoomProvider) *AppointmentService {
+	return &AppointmentService{
+		Repo:     repo,
+		Notifier: notifier,
+		Rooms:    rooms,
+	}
+}
+
+// this ensures this service layer implements all methods of service layer interface
+var _ interfaces.IService = (*AppointmentService)(nil)
+
+// AddStatusListener registers listener to be notified of every status change made through UpdateAppointmentStatus
+func (service *AppointmentService) AddStatusListener(listener interfaces.StatusListener) {
+	service.Listeners = append(service.Listeners, listener)
+}
+
+func (service *AppointmentService) BookAppointment(ctx context.Context, userID primitive.ObjectID, appointment models.Appointment) (*models.Appointment, *structs.IAppError) {
+	doctor, clinic, appErr := service.getDoctorAtClinic(ctx, appointment.Doctor, appointment.Clinic)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if appErr := service.checkNoShowPolicy(ctx, userID, clinic); appErr != nil {
+		return nil, appErr
+	}
+
+	appointment.User = userID
+	return service.book(ctx, doctor, clinic, appointment, models.StatusTransition{
+		To:        utils.AppointmentStatusRequested,
+		Actor:     userID,
+		ActorRole: utils.RoleUser,
+	})
+}
+
+// BookWalkInAppointment books appointment for patient userID on behalf of the doctor or owner of the clinic. clinic
+// itself is booking so the appointment starts confirmed and no show policy, which restricts online booking, is not applied
+func (service *AppointmentService) BookWalkInAppointment(ctx context.Context, actorID primitive.ObjectID, actorRole string, userID primitive.ObjectID, appointment models.Appointment) (*models.Appointment, *structs.IAppError) {
+	doctor, clinic, appErr := service.getDoctorAtClinic(ctx, appointment.Doctor, appointment.Clinic)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	allowed := (actorRole == utils.RoleDoctor && actorID == doctor.ID) || (actorRole != utils.RoleDoctor && middleware.ActsForClinic(ctx, actorID, clinic.Owner, clinic.ID))
+	if !allowed {
+		return nil, utils.ReturnAppError(errors.New("not staff of clinic"), http.StatusForbidden, "Booking Failed", "Only Doctor Or Staff Of Clinic Can Book For Walk-in Patients")
+	}
+
+	appointment.User = userID
+	return service.book(ctx, doctor, clinic, appointment, models.StatusTransition{
+		To:        utils.AppointmentStatusConfirmed,
+		Actor:     actorID,
+		ActorRole: actorRole,
+	})
+}
+
+// book reserves the requested slot and saves appointment in status of the first transition
+func (service *AppointmentService) book(ctx context.Context, doctor models.Doctor, clinic models.Clinic, appointment models.Appointment, first models.StatusTransition) (*models.Appointment, *structs.IAppError) {
+	appointment.Mode = consultationMode(appointment)
+	slot, appErr := service.pickSlot(ctx, doctor, clinic, appointment.AppointmentDate, appointment.Slot, appointment.Mode)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	//set default values
+	appointment.ID = primitive.NewObjectID()
+	appointment.AppointmentDate = slot.Start.UTC()
+	appointment.Status = first.To
+	appointment.RegistrationDate = time.Now().UTC()
+	first.At = appointment.RegistrationDate
+	appointment.StatusHistory = []models.StatusTransition{first}
+	if appErr := service.openRoom(ctx, &appointment); appErr != nil {
+		return nil, appErr
+	}
+
+	//availability above is only a snapshot, the reservation in repo is what decides who gets the seat
+	reservation, capacity := seatOf(doctor, clinic, slot)
+	if err := service.Repo.BookAppointment(ctx, appointment, reservation, capacity); err != nil {
+		if errors.Is(err, interfaces.ErrSlotFull) {
+			return nil, slotFullError(err)
+		}
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.ReturnAppError(err, http.StatusNotFound, "Booking Failed", "User or Doctor Doesnt Exist")
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Booking Failed", "Server Error")
+	}
+
+	return &appointment, nil
+}
+
+// GetUserAppointments returns appointments of user, if appointmentID is passed only that appointment is returned
+func (service *AppointmentService) GetUserAppointments(ctx context.Context, userID primitive.ObjectID, appointmentID *primitive.ObjectID) ([]models.Appointment, *structs.IAppError) {
+	//user can only see their own appointments
+	filter := bson.M{"user": userID}
+	if appointmentID != nil {
+		filter["_id"] = *appointmentID
+	}
+
+	appointments, err := service.Repo.GetAppointments(ctx, filter)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Appointments", "Server Error")
+	}
+
+	return appointments, nil
+}
+
+func (service *AppointmentService) CancelAppointment(ctx context.Context, userID primitive.ObjectID, appointmentID primitive.ObjectID) *structs.IAppError {
+	_, err := service.UpdateAppointmentStatus(ctx, userID, utils.RoleUser, appointmentID, utils.AppointmentStatusCancelled, "")
+	return err
+}
+
+// RescheduleAppointment moves appointment of user to another slot of the same doctor at the same clinic. the new slot is
+// held first and the old appointment is only given up once the new one is created so user never ends up without a slot
+func (service *AppointmentService) RescheduleAppointment(ctx context.Context, userID primitive.ObjectID, appointmentID primitive.ObjectID, date time.Time, slotIndex int8) (*models.Appointment, *structs.IAppError) {
+	appointment, appErr := service.getAppointmentOfActor(ctx, userID, utils.RoleUser, appointmentID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if !CanTransition(appointment.Status, utils.AppointmentStatusRescheduled, utils.RoleUser) {
+		return nil, utils.ReturnAppError(errors.New("illegal status transition"), http.StatusBadRequest, "Reschedule Not Allowed", appointment.Status+" Appointment Cannot Be Rescheduled")
+	}
+
+	doctor, clinic, appErr := service.getDoctorAtClinic(ctx, appointment.Doctor, appointment.Clinic)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	//clinic defined rules dont apply when clinic itself asked for the reschedule
+	rules := clinic.BookingRules
+	if appointment.Status == utils.AppointmentStatusNeedsReschedule {
+		rules = models.BookingRules{}
+	}
+	if rules.RescheduleCutoffHours > 0 && time.Until(appointment.AppointmentDate) < time.Duration(rules.RescheduleCutoffHours)*time.Hour {
+		return nil, utils.ReturnAppError(errors.New("reschedule cutoff passed"), http.StatusUnprocessableEntity, "Reschedule Not Allowed", fmt.Sprintf("Appointments Cannot Be Rescheduled Within %d Hours Of Start", rules.RescheduleCutoffHours))
+	}
+	if rules.MaxReschedules > 0 && appointment.RescheduleCount >= rules.MaxReschedules {
+		return nil, utils.ReturnAppError(errors.New("reschedule limit reached"), http.StatusUnprocessableEntity, "Reschedule Not Allowed", fmt.Sprintf("Appointment Can Be Rescheduled At Most %d Times", rules.MaxReschedules))
+	}
+
+	slot, appErr := service.pickSlot(ctx, doctor, clinic, date, slotIndex, consultationMode(appointment))
+	if appErr != nil {
+		return nil, appErr
+	}
+	if slot.Start.Equal(appointment.AppointmentDate) {
+		return nil, utils.ReturnAppError(errors.New("same slot"), http.StatusBadRequest, "Invalid Slot", "Appointment Is Already In This Slot")
+	}
+
+	now := time.Now().UTC()
+	transition := models.StatusTransition{
+		At:        now,
+		From:      appointment.Status,
+		To:        utils.AppointmentStatusRescheduled,
+		Actor:     userID,
+		ActorRole: utils.RoleUser,
+	}
+
+	oldAppointmentID := appointment.ID
+	newAppointment := models.Appointment{
+		ID:               primitive.NewObjectID(),
+		AppointmentDate:  slot.Start.UTC(),
+		RegistrationDate: now,
+		Status:           utils.AppointmentStatusRequested,
+		Clinic:           appointment.Clinic,
+		User:             appointment.User,
+		Doctor:           appointment.Doctor,
+		Slot:             slot.Index,
+		Mode:             consultationMode(appointment),
+		RescheduledFrom:  &oldAppointmentID,
+		RescheduleCount:  appointment.RescheduleCount + 1,
+		StatusHistory: []models.StatusTransition{{
+			At:        now,
+			To:        utils.AppointmentStatusRequested,
+			Actor:     userID,
+			ActorRole: utils.RoleUser,
+			Reason:    "Rescheduled from " + appointment.AppointmentDate.Format(time.RFC3339),
+		}},
+	}
+	if appErr := service.openRoom(ctx, &newAppointment); appErr != nil {
+		return nil, appErr
+	}
+
+	reservation, capacity := seatOf(doctor, clinic, slot)
+	if err := service.Repo.RescheduleAppointment(ctx, appointment.ID, appointment.Status, transition, newAppointment, reservation, capacity); err != nil {
+		switch {
+		case errors.Is(err, interfaces.ErrSlotFull):
+			return nil, slotFullError(err)
+		case errors.Is(err, interfaces.ErrHoldExpired):
+			return nil, utils.ReturnAppError(err, http.StatusConflict, "Reschedule Failed", "Hold On New Slot Expired, Please Retry")
+		case err == mongo.ErrNoDocuments:
+			return nil, utils.ReturnAppError(err, http.StatusConflict, "Appointment Was Updated Meanwhile", "Status Changed, Please Retry")
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Reschedule Failed", "Server Error")
+	}
+
+	return &newAppointment, nil
+}
+
+// GetAppointment returns appointment with its status history to any party of the appointment
+func (service *AppointmentService) GetAppointment(ctx context.Context, actorID primitive.ObjectID, actorRole string, appointmentID primitive.ObjectID) (*models.Appointment, *structs.IAppError) {
+	appointment, appErr := service.getAppointmentOfActor(ctx, actorID, actorRole, appointmentID)
+	if appErr != nil {
+		return nil, appErr
+	}
+	return &appointment, nil
+}
+
+// UpdateAppointmentStatus moves appointment to status if the move is legal and allowed for role of actor, every move is recorded with actor and time
+func (service *AppointmentService) UpdateAppointmentStatus(ctx context.Context, actorID primitive.ObjectID, actorRole string, appointmentID primitive.ObjectID, status string, reason string) (*models.Appointment, *structs.IAppError) {
+	appointment, appErr := service.getAppointmentOfActor(ctx, actorID, actorRole, appointmentID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	//rescheduling needs a new slot so it only happens through RescheduleAppointment
+	if status == utils.AppointmentStatusRescheduled {
+		return nil, utils.ReturnAppError(errors.New("reschedule through status update"), http.StatusBadRequest, "Invalid Status Transition", "Use Reschedule Api To Reschedule Appointment")
+	}
+
+	if !IsLegalTransition(appointment.Status, status) {
+		return nil, utils.ReturnAppError(errors.New("illegal status transition"), http.StatusBadRequest, "Invalid Status Transition", "Cannot Move From "+appointment.Status+" To "+status)
+	}
+	if !CanTransition(appointment.Status, status, actorRole) {
+		return nil, utils.ReturnAppError(errors.New("transition not allowed for role"), http.StatusForbidden, "Forbidden To Change Status", actorRole+" Cannot Move Appointment To "+status)
+	}
+	//patient cant miss an appointment which hasnt started yet, no shows also restrict their future bookings
+	if status == utils.AppointmentStatusNoShow && time.Now().Before(appointment.AppointmentDate) {
+		return nil, utils.ReturnAppError(errors.New("appointment not started"), http.StatusBadRequest, "Invalid Status Transition", "Appointment Hasnt Started Yet")
+	}
+
+	transition := models.StatusTransition{
+		At:        time.Now().UTC(),
+		From:      appointment.Status,
+		To:        status,
+		Actor:     actorID,
+		ActorRole: actorRole,
+		Reason:    reason,
+	}
+	if err := service.Repo.TransitionAppointmentStatus(ctx, appointmentID, appointment.Status, transition, releasesSlot(status)); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.ReturnAppError(err, http.StatusConflict, "Appointment Was Updated Meanwhile", "Status Changed, Please Retry")
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Failed To Update Status", "Server Error")
+	}
+
+	//seat freed by cancellation or no show goes to the waitlist
+	if status == utils.AppointmentStatusCancelled || status == utils.AppointmentStatusNoShow {
+		service.promoteWaitlist(ctx, appointment)
+	}
+
+	appointment.Status = status
+	appointment.StatusHistory = append(appointment.StatusHistory, transition)
+	for _, listener := range service.Listeners {
+		listener.AppointmentStatusChanged(ctx, appointment, transition)
+	}
+	return &appointment, nil
+}
+
+// getAppointmentOfActor fetches appointment and makes sure actor is the patient, doctor or owner or staff of clinic of that appointment.
+// appointments of others are reported as not found so ids cant be probed
+func (service *AppointmentService) getAppointmentOfActor(ctx context.Context, actorID primitive.ObjectID, actorRole string, appointmentID primitive.ObjectID) (models.Appointment, *structs.IAppError) {
+	appointment, err := service.Repo.GetAppointment(ctx, bson.M{"_id": appointmentID})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return models.Appointment{}, utils.ReturnAppError(err, http.StatusNotFound, "Appointment Not Found", "Appointment Doesnt Exist")
+		}
+		return models.Appointment{}, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Appointment", "Server Error")
+	}
+
+	isParty := false
+	switch actorRole {
+	case utils.RoleUser:
+		isParty = appointment.User == actorID
+	case utils.RoleDoctor:
+		isParty = appointment.Doctor == actorID
+	case utils.RoleClinicOwner, utils.RoleStaff:
+		clinic, err := service.Repo.GetClinic(ctx, appointment.Clinic)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return models.Appointment{}, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Appointment", "Server Error")
+		}
+		isParty = err == nil && middleware.ActsForClinic(ctx, actorID, clinic.Owner, clinic.ID)
+	}
+
+	if !isParty {
+		return models.Appointment{}, utils.ReturnAppError(errors.New("appointment of someone else"), http.StatusNotFound, "Appointment Not Found", "Appointment Doesnt Exist")
+	}
+	return appointment, nil
+}
+
+// GetDoctorSlots returns slots of doctor at clinic between from and to, only the ones open for mode when mode is passed
+func (service *AppointmentService) GetDoctorSlots(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID, from time.Time, to time.Time, mode string) ([]scheduling.Slot, *structs.IAppError) {
+	if to.Before(from) {
+		return nil, utils.ReturnAppError(errors.New("invalid date range"), http.StatusBadRequest, "Invalid Date Range", "from must be before to")
+	}
+	if to.Sub(from) > utils.MaxSlotRangeDays*24*time.Hour {
+		return nil, utils.ReturnAppError(errors.New("date range too long"), http.StatusBadRequest, "Invalid Date Range", "Date Range Is Too Long")
+	}
+
+	doctor, clinic, appErr := service.getDoctorAtClinic(ctx, doctorID, clinicID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	slots, appErr := service.doctorSlots(ctx, doctor, clinic, from, to)
+	if appErr != nil {
+		return nil, appErr
+	}
+	return scheduling.FilterMode(slots, mode), nil
+}
+
+// getDoctorAtClinic fetches doctor and clinic and makes sure doctor has a session at that clinic and the clinic is active
+func (service *AppointmentService) getDoctorAtClinic(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID) (models.Doctor, models.Clinic, *structs.IAppError) {
+	doctor, err := service.Repo.GetDoctor(ctx, doctorID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return models.Doctor{}, models.Clinic{}, utils.ReturnAppError(err, http.StatusNotFound, "Doctor Not Found", "Invalid Doctor")
+		}
+		return models.Doctor{}, models.Clinic{}, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Doctor", "Server Error")
+	}
+
+	if _, found := scheduling.DoctorSession(doctor, clinicID.Hex()); !found {
+		return models.Doctor{}, models.Clinic{}, utils.ReturnAppError(errors.New("doctor not available at clinic"), http.StatusBadRequest, "Doctor Not Available At This Clinic", "Invalid Clinic")
+	}
+
+	clinic, err := service.Repo.GetClinic(ctx, clinicID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return models.Doctor{}, models.Clinic{}, utils.ReturnAppError(err, http.StatusNotFound, "Clinic Not Found", "Invalid Clinic")
+		}
+		return models.Doctor{}, models.Clinic{}, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Clinic", "Server Error")
+	}
+	if !scheduling.ClinicActive(clinic) {
+		return models.Doctor{}, models.Clinic{}, utils.ReturnAppError(errors.New("clinic not active"), http.StatusConflict, "Clinic Is Not Taking Appointments", "Clinic Is Suspended Or Closed")
+	}
+
+	return doctor, clinic, nil
+}
+
+// doctorSlots generates slots of doctor at clinic and marks the ones which are already booked
+func (service *AppointmentService) doctorSlots(ctx context.Context, doctor models.Doctor, clinic models.Clinic, from time.Time, to time.Time) ([]scheduling.Slot, *structs.IAppError) {
+	session, _ := scheduling.DoctorSession(doctor, clinic.ID.Hex())
+	slots := scheduling.GenerateSlots(clinic, session, scheduling.SlotDuration(doctor), from, to)
+	if len(slots) == 0 {
+		return slots, nil
+	}
+
+	leaves, err := service.Repo.GetLeaves(ctx, leavesOf(doctor.ID, clinic.ID, slots[0].Start, slots[len(slots)-1].End))
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Slots", "Server Error")
+	}
+	slots = scheduling.ExcludeLeaves(slots, leaves)
+	if len(slots) == 0 {
+		return slots, nil
+	}
+
+	//expired holds still lying around (TTL monitor runs once a minute) dont take a seat
+	loc := scheduling.ClinicLocation(clinic)
+	reservations, err := service.Repo.GetReservations(ctx, bson.M{
+		"doctor": doctor.ID,
+		"clinic": clinic.ID,
+		"date": bson.M{
+			"$gte": scheduling.DayKey(slots[0].Start, loc),
+			"$lte": scheduling.DayKey(slots[len(slots)-1].Start, loc),
+		},
+		"$or": bson.A{
+			bson.M{"expiresAt": bson.M{"$exists": false}},
+			bson.M{"expiresAt": bson.M{"$gt": time.Now().UTC()}},
+		},
+	})
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Slots", "Server Error")
+	}
+
+	reserved := make(map[slotKey]int, len(reservations))
+	for _, reservation := range reservations {
+		reserved[slotKey{date: reservation.Date.Unix(), slot: reservation.Slot}]++
+	}
+	for i := range slots {
+		slots[i].Remaining -= reserved[slotKey{date: scheduling.DayKey(slots[i].Start, loc).Unix(), slot: slots[i].Index}]
+		if slots[i].Remaining <= 0 {
+			slots[i].Remaining = 0
+			slots[i].Available = false
+		}
+	}
+
+	return slots, nil
+}
+
+// slotKey identifies a slot of a doctor at a clinic
+type slotKey struct {
+	date int64
+	slot int8
+}
+
+// pickSlot finds slot with index on the date and makes sure it is bookable
+func (service *AppointmentService) pickSlot(ctx context.Context, doctor models.Doctor, clinic models.Clinic, date time.Time, index int8, mode string) (scheduling.Slot, *structs.IAppError) {
+	day := clinicDay(clinic, date)
+	slots, appErr := service.doctorSlots(ctx, doctor, clinic, day, day)
+	if appErr != nil {
+		return scheduling.Slot{}, appErr
+	}
+
+	slot, found := findSlot(slots, index)
+	if !found {
+		return scheduling.Slot{}, utils.ReturnAppError(errors.New("slot not found"), http.StatusBadRequest, "Invalid Slot", "Doctor Has No Such Slot On This Day")
+	}
+	if slot.Start.Before(time.Now()) {
+		return scheduling.Slot{}, utils.ReturnAppError(errors.New("slot already started"), http.StatusBadRequest, "Invalid Slot", "Slot Time Has Passed")
+	}
+	if !slot.Supports(mode) {
+		return scheduling.Slot{}, utils.ReturnAppError(errors.New("slot not open for mode"), http.StatusBadRequest, "Invalid Slot", "Slot Is Not Open For "+mode+" Consultation")
+	}
+	if !slot.Available {
+		return scheduling.Slot{}, slotFullError(interfaces.ErrSlotFull)
+	}
+	return slot, nil
+}
+
+// clinicDay returns midnight at clinic of the calendar date of date as it was written. dates usually come in as
+// midnight UTC, converting them to the clinic zone would land on the day before at clinics west of UTC
+func clinicDay(clinic models.Clinic, date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, scheduling.ClinicLocation(clinic))
+}
+
+// seatOf returns reservation for a seat in slot along with capacity of the slot
+func seatOf(doctor models.Doctor, clinic models.Clinic, slot scheduling.Slot) (models.SlotReservation, int) {
+	session, _ := scheduling.DoctorSession(doctor, clinic.ID.Hex())
+	return models.SlotReservation{
+		Doctor: doctor.ID,
+		Clinic: clinic.ID,
+		Date:   scheduling.DayKey(slot.Start, scheduling.ClinicLocation(clinic)),
+		Slot:   slot.Index,
+	}, scheduling.SlotCapacity(session)
+}
+
+func findSlot(slots []scheduling.Slot, index int8) (scheduling.Slot, bool) {
+	for _, slot := range slots {
+		if slot.Index == index {
+			return slot, true
+		}
+	}
+	return scheduling.Slot{}, false
+}
+
+func slotFullError(err error) *structs.IAppError {
+	return utils.ReturnAppError(err, http.StatusConflict, "Slot Capacity Exceeded", "Slot Is Fully Booked")
+}
@@ -0,0 +1,395 @@
+package service
+
+import (
+	interfaces "AlShifa/Appointment/Interfaces"
+	"AlShifa/Clinic/models"
+	scheduling "AlShifa/Scheduling"
+	utils "AlShifa/Utils"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var dummyClinicID = primitive.NewObjectID()
+
+func ReturnDummyAppointment() models.Appointment {
+	return models.Appointment{
+		AppointmentDate: tomorrowsSlot(40),
+		Clinic:          dummyClinicID,
+		Doctor:          primitive.NewObjectID(),
+		Slot:            40,
+	}
+}
+
+// ReturnDummyDoctor returns a doctor who sits at dummy clinic whole day (00:00 - 23:45 IST) every day
+func ReturnDummyDoctor(doctorID primitive.ObjectID) models.Doctor {
+	return models.Doctor{
+		ID: doctorID,
+		Clinics: []models.ClinicDetails{{
+			Clinic:      dummyClinicID,
+			StartTime:   time.Date(2000, 1, 1, 18, 30, 0, 0, time.UTC),
+			EndTime:     time.Date(2000, 1, 1, 18, 15, 0, 0, time.UTC).Add(24 * time.Hour),
+			WorkingDays: []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"},
+		}},
+	}
+}
+
+func TestBookAppointment(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		mockRepo           *MockAppointmentRepo
+		ExpectedStatusCode int
+	}{
+		//test case when everything is ok
+		{
+			Name: "Appointment Booked Successfully when everything is ok",
+			mockRepo: &MockAppointmentRepo{
+				GetDoctorFn: func(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error) {
+					return ReturnDummyDoctor(doctorID), nil
+				},
+				GetClinicFn: func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+					return models.Clinic{ID: clinicID}, nil
+				},
+				GetLeavesFn: func(ctx context.Context, filter bson.M) ([]models.Leave, error) {
+					return nil, nil
+				},
+				GetReservationsFn: func(ctx context.Context, filter bson.M) ([]models.SlotReservation, error) {
+					return nil, nil
+				},
+				BookAppointmentFn: func(ctx context.Context, appointment models.Appointment, reservation models.SlotReservation, capacity int) error {
+					return nil
+				},
+			},
+			ExpectedStatusCode: 0,
+		},
+		//test case when doctor doesnt exist
+		{
+			Name: "Appointment Booking Failed because doctor doesnt exist",
+			mockRepo: &MockAppointmentRepo{
+				GetDoctorFn: func(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error) {
+					return models.Doctor{}, mongo.ErrNoDocuments
+				},
+			},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		//test case when slot is already booked by someone else
+		{
+			Name: "Appointment Booking Failed because slot is already booked",
+			mockRepo: &MockAppointmentRepo{
+				GetDoctorFn: func(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error) {
+					return ReturnDummyDoctor(doctorID), nil
+				},
+				GetClinicFn: func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+					return models.Clinic{ID: clinicID}, nil
+				},
+				GetLeavesFn: func(ctx context.Context, filter bson.M) ([]models.Leave, error) {
+					return nil, nil
+				},
+				GetReservationsFn: func(ctx context.Context, filter bson.M) ([]models.SlotReservation, error) {
+					loc, _ := time.LoadLocation(utils.DefaultTimeZone)
+					return []models.SlotReservation{{Date: scheduling.DayKey(time.Now().Add(24*time.Hour), loc), Slot: 40}}, nil
+				},
+			},
+			ExpectedStatusCode: http.StatusConflict,
+		},
+		//test case when mock repo fails to book
+		{
+			Name: "Appointment Booking Failed because mock repo returned error",
+			mockRepo: &MockAppointmentRepo{
+				GetDoctorFn: func(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error) {
+					return ReturnDummyDoctor(doctorID), nil
+				},
+				GetClinicFn: func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+					return models.Clinic{ID: clinicID}, nil
+				},
+				GetLeavesFn: func(ctx context.Context, filter bson.M) ([]models.Leave, error) {
+					return nil, nil
+				},
+				GetReservationsFn: func(ctx context.Context, filter bson.M) ([]models.SlotReservation, error) {
+					return nil, nil
+				},
+				BookAppointmentFn: func(ctx context.Context, appointment models.Appointment, reservation models.SlotReservation, capacity int) error {
+					return errors.New("error from mock repo")
+				},
+			},
+			ExpectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			service := NewAppointmentService(tc.mockRepo, nil, nil)
+			userID := primitive.NewObjectID()
+			appointment, err := service.BookAppointment(context.Background(), userID, ReturnDummyAppointment())
+
+			if tc.ExpectedStatusCode == 0 {
+				if err != nil {
+					t.Fatalf("expected no error but got %v", err)
+				}
+				if appointment.User != userID || appointment.Status != utils.AppointmentStatusRequested {
+					t.Fatalf("expected appointment to be booked for user %s but got %+v", userID.Hex(), appointment)
+				}
+				return
+			}
+
+			if err == nil || err.StatusCode != tc.ExpectedStatusCode {
+				t.Fatalf("expected status code %d but got %v", tc.ExpectedStatusCode, err)
+			}
+		})
+	}
+}
+
+func TestCancelAppointment(t *testing.T) {
+	userID := primitive.NewObjectID()
+	mockRepo := &MockAppointmentRepo{
+		GetAppointmentFn: func(ctx context.Context, filter bson.M) (models.Appointment, error) {
+			return models.Appointment{ID: filter["_id"].(primitive.ObjectID), User: userID, Status: utils.AppointmentStatusRequested}, nil
+		},
+		TransitionAppointmentStatusFn: func(ctx context.Context, appointmentID primitive.ObjectID, from string, transition models.StatusTransition, releaseSlot bool) error {
+			if transition.To != utils.AppointmentStatusCancelled || !releaseSlot {
+				t.Fatalf("expected status %s with slot released got %s", utils.AppointmentStatusCancelled, transition.To)
+			}
+			return nil
+		},
+	}
+
+	service := NewAppointmentService(mockRepo, nil, nil)
+	if err := service.CancelAppointment(context.Background(), userID, primitive.NewObjectID()); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+
+	//some other user shouldnt be able to cancel this appointment
+	err := service.CancelAppointment(context.Background(), primitive.NewObjectID(), primitive.NewObjectID())
+	if err == nil || err.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected not found error but got %v", err)
+	}
+}
+
+func TestUpdateAppointmentStatus(t *testing.T) {
+	userID := primitive.NewObjectID()
+	doctorID := primitive.NewObjectID()
+	ownerID := primitive.NewObjectID()
+
+	testCases := []struct {
+		Name               string
+		CurrentStatus      string
+		NewStatus          string
+		AppointmentDate    time.Time
+		ActorID            primitive.ObjectID
+		ActorRole          string
+		ExpectedStatusCode int
+	}{
+		{Name: "Doctor confirms requested appointment", CurrentStatus: utils.AppointmentStatusRequested, NewStatus: utils.AppointmentStatusConfirmed, ActorID: doctorID, ActorRole: utils.RoleDoctor},
+		{Name: "Owner checks in patient", CurrentStatus: utils.AppointmentStatusConfirmed, NewStatus: utils.AppointmentStatusCheckedIn, ActorID: ownerID, ActorRole: utils.RoleClinicOwner},
+		{Name: "Doctor completes consultation", CurrentStatus: utils.AppointmentStatusInConsultation, NewStatus: utils.AppointmentStatusCompleted, ActorID: doctorID, ActorRole: utils.RoleDoctor},
+		{Name: "User cant confirm own appointment", CurrentStatus: utils.AppointmentStatusRequested, NewStatus: utils.AppointmentStatusConfirmed, ActorID: userID, ActorRole: utils.RoleUser, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Owner cant start consultation", CurrentStatus: utils.AppointmentStatusCheckedIn, NewStatus: utils.AppointmentStatusInConsultation, ActorID: ownerID, ActorRole: utils.RoleClinicOwner, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Requested appointment cant be completed directly", CurrentStatus: utils.AppointmentStatusRequested, NewStatus: utils.AppointmentStatusCompleted, ActorID: doctorID, ActorRole: utils.RoleDoctor, ExpectedStatusCode: http.StatusBadRequest},
+		{Name: "Cancelled appointment is final", CurrentStatus: utils.AppointmentStatusCancelled, NewStatus: utils.AppointmentStatusConfirmed, ActorID: doctorID, ActorRole: utils.RoleDoctor, ExpectedStatusCode: http.StatusBadRequest},
+		{Name: "Doctor marks patient who didnt turn up as no show", CurrentStatus: utils.AppointmentStatusConfirmed, NewStatus: utils.AppointmentStatusNoShow, AppointmentDate: time.Now().Add(-time.Hour), ActorID: doctorID, ActorRole: utils.RoleDoctor},
+		{Name: "Appointment still to come cant be no show", CurrentStatus: utils.AppointmentStatusConfirmed, NewStatus: utils.AppointmentStatusNoShow, AppointmentDate: time.Now().Add(time.Hour), ActorID: ownerID, ActorRole: utils.RoleClinicOwner, ExpectedStatusCode: http.StatusBadRequest},
+		{Name: "Some other doctor cant touch appointment", CurrentStatus: utils.AppointmentStatusRequested, NewStatus: utils.AppointmentStatusConfirmed, ActorID: primitive.NewObjectID(), ActorRole: utils.RoleDoctor, ExpectedStatusCode: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var recorded models.StatusTransition
+			mockRepo := &MockAppointmentRepo{
+				GetAppointmentFn: func(ctx context.Context, filter bson.M) (models.Appointment, error) {
+					return models.Appointment{ID: filter["_id"].(primitive.ObjectID), User: userID, Doctor: doctorID, Clinic: dummyClinicID, AppointmentDate: tc.AppointmentDate, Status: tc.CurrentStatus}, nil
+				},
+				GetClinicFn: func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+					return models.Clinic{ID: clinicID, Owner: ownerID}, nil
+				},
+				TransitionAppointmentStatusFn: func(ctx context.Context, appointmentID primitive.ObjectID, from string, transition models.StatusTransition, releaseSlot bool) error {
+					recorded = transition
+					return nil
+				},
+			}
+
+			service := NewAppointmentService(mockRepo, nil, nil)
+			appointment, err := service.UpdateAppointmentStatus(context.Background(), tc.ActorID, tc.ActorRole, primitive.NewObjectID(), tc.NewStatus, "")
+
+			if tc.ExpectedStatusCode != 0 {
+				if err == nil || err.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status code %d but got %v", tc.ExpectedStatusCode, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			if appointment.Status != tc.NewStatus || recorded.From != tc.CurrentStatus || recorded.Actor != tc.ActorID || recorded.ActorRole != tc.ActorRole || recorded.At.IsZero() {
+				t.Fatalf("transition not recorded properly %+v", recorded)
+			}
+		})
+	}
+}
+
+// memorySeats is an in-memory stand-in of the SlotReservation collection, seats are taken under a lock
+// the same way the unique index lets only one insert win in mongodb
+type memorySeats struct {
+	mu    sync.Mutex
+	taken map[string]int
+}
+
+func (m *memorySeats) BookAppointment(ctx context.Context, appointment models.Appointment, reservation models.SlotReservation, capacity int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := fmt.Sprintf("%s-%s-%d-%d", reservation.Doctor.Hex(), reservation.Clinic.Hex(), reservation.Date.Unix(), reservation.Slot)
+	if m.taken[key] >= capacity {
+		return interfaces.ErrSlotFull
+	}
+	m.taken[key]++
+	return nil
+}
+
+func TestConcurrentBookingRespectsCapacity(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Capacity int32
+		Patients int
+	}{
+		{Name: "Consultation slot can be booked once", Capacity: 0, Patients: 20},
+		{Name: "Vaccination drive slot can be booked by N patients", Capacity: 5, Patients: 40},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			seats := &memorySeats{taken: map[string]int{}}
+			mockRepo := &MockAppointmentRepo{
+				GetDoctorFn: func(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error) {
+					doctor := ReturnDummyDoctor(doctorID)
+					doctor.Clinics[0].SlotCapacity = tc.Capacity
+					return doctor, nil
+				},
+				GetClinicFn: func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+					return models.Clinic{ID: clinicID}, nil
+				},
+				//every request sees the slot as free so only the reservation can stop double booking
+				GetLeavesFn: func(ctx context.Context, filter bson.M) ([]models.Leave, error) {
+					return nil, nil
+				},
+				GetReservationsFn: func(ctx context.Context, filter bson.M) ([]models.SlotReservation, error) {
+					return nil, nil
+				},
+				BookAppointmentFn: seats.BookAppointment,
+			}
+			service := NewAppointmentService(mockRepo, nil, nil)
+			appointment := ReturnDummyAppointment()
+
+			var wg sync.WaitGroup
+			var mu sync.Mutex
+			booked, conflicts := 0, 0
+			for range tc.Patients {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := service.BookAppointment(context.Background(), primitive.NewObjectID(), appointment)
+
+					mu.Lock()
+					defer mu.Unlock()
+					switch {
+					case err == nil:
+						booked++
+					case err.StatusCode == http.StatusConflict:
+						conflicts++
+					default:
+						t.Errorf("unexpected error %v", err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			expected := max(int(tc.Capacity), utils.DefaultSlotCapacity)
+			if booked != expected || conflicts != tc.Patients-expected {
+				t.Fatalf("expected %d bookings and %d conflicts got %d and %d", expected, tc.Patients-expected, booked, conflicts)
+			}
+		})
+	}
+}
+
+func TestRescheduleAppointment(t *testing.T) {
+	userID := primitive.NewObjectID()
+	doctorID := primitive.NewObjectID()
+	tomorrow := time.Now().Add(24 * time.Hour)
+
+	testCases := []struct {
+		Name               string
+		AppointmentIn      time.Duration
+		Rules              models.BookingRules
+		RepoErr            error
+		ExpectedStatusCode int
+	}{
+		{Name: "Rescheduled when clinic has no rules", AppointmentIn: time.Hour},
+		{Name: "Rescheduled before cutoff", AppointmentIn: 10 * time.Hour, Rules: models.BookingRules{RescheduleCutoffHours: 6}},
+		{Name: "Reschedule rejected within cutoff", AppointmentIn: 2 * time.Hour, Rules: models.BookingRules{RescheduleCutoffHours: 6}, ExpectedStatusCode: http.StatusUnprocessableEntity},
+		{Name: "Reschedule rejected after max reschedules", AppointmentIn: 10 * time.Hour, Rules: models.BookingRules{MaxReschedules: 1}, ExpectedStatusCode: http.StatusUnprocessableEntity},
+		{Name: "Reschedule fails when new slot gets taken meanwhile", AppointmentIn: time.Hour, RepoErr: interfaces.ErrSlotFull, ExpectedStatusCode: http.StatusConflict},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			mockRepo := &MockAppointmentRepo{
+				GetAppointmentFn: func(ctx context.Context, filter bson.M) (models.Appointment, error) {
+					return models.Appointment{
+						ID:              filter["_id"].(primitive.ObjectID),
+						User:            userID,
+						Doctor:          doctorID,
+						Clinic:          dummyClinicID,
+						Status:          utils.AppointmentStatusConfirmed,
+						AppointmentDate: time.Now().Add(tc.AppointmentIn),
+						RescheduleCount: 1,
+					}, nil
+				},
+				GetDoctorFn: func(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error) {
+					return ReturnDummyDoctor(doctorID), nil
+				},
+				GetClinicFn: func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+					return models.Clinic{ID: clinicID, BookingRules: tc.Rules}, nil
+				},
+				GetLeavesFn: func(ctx context.Context, filter bson.M) ([]models.Leave, error) {
+					return nil, nil
+				},
+				GetReservationsFn: func(ctx context.Context, filter bson.M) ([]models.SlotReservation, error) {
+					return nil, nil
+				},
+				RescheduleAppointmentFn: func(ctx context.Context, oldAppointmentID primitive.ObjectID, from string, transition models.StatusTransition, newAppointment models.Appointment, reservation models.SlotReservation, capacity int) error {
+					if transition.To != utils.AppointmentStatusRescheduled || from != utils.AppointmentStatusConfirmed {
+						t.Fatalf("unexpected transition %+v", transition)
+					}
+					if newAppointment.RescheduledFrom == nil || *newAppointment.RescheduledFrom != oldAppointmentID {
+						t.Fatalf("new appointment is not linked to old one")
+					}
+					return tc.RepoErr
+				},
+			}
+
+			service := NewAppointmentService(mockRepo, nil, nil)
+			appointment, err := service.RescheduleAppointment(context.Background(), userID, primitive.NewObjectID(), tomorrow, 50)
+
+			if tc.ExpectedStatusCode != 0 {
+				if err == nil || err.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status code %d but got %v", tc.ExpectedStatusCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			if appointment.Slot != 50 || appointment.RescheduleCount != 2 || appointment.Status != utils.AppointmentStatusRequested {
+				t.Fatalf("unexpected rescheduled appointment %+v", appointment)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	clinicInterfaces "AlShifa/Clinic/Interfaces"
+	"AlShifa/Clinic/models"
+	scheduling "AlShifa/Scheduling"
+	utils "AlShifa/Utils"
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// this ensures appointments are wound down when a clinic stops being active
+var _ clinicInterfaces.DeactivationListener = (*AppointmentService)(nil)
+
+// ClinicDeactivated cancels appointments still to come at clinic which stopped being active, their slots are released
+// and patients notified. appointments already cancelled are skipped so winding down a clinic again only picks up what
+// was missed
+func (service *AppointmentService) ClinicDeactivated(ctx context.Context, clinic models.Clinic, change models.ClinicStatusChange) error {
+	appointments, err := service.Repo.GetAppointments(ctx, bson.M{
+		"clinic":          clinic.ID,
+		"status":          bson.M{"$in": bson.A{utils.AppointmentStatusRequested, utils.AppointmentStatusConfirmed, utils.AppointmentStatusNeedsReschedule}},
+		"appointmentDate": bson.M{"$gt": time.Now().UTC()},
+	})
+	if err != nil {
+		return err
+	}
+
+	reason := "Clinic is no longer taking appointments"
+	if change.Reason != "" {
+		reason += ": " + change.Reason
+	}
+	for _, appointment := range appointments {
+		transition := models.StatusTransition{
+			At:        time.Now().UTC(),
+			From:      appointment.Status,
+			To:        utils.AppointmentStatusCancelled,
+			Actor:     change.Actor,
+			ActorRole: change.ActorRole,
+			Reason:    reason,
+		}
+		if err := service.Repo.TransitionAppointmentStatus(ctx, appointment.ID, appointment.Status, transition, true); err != nil {
+			//patient changed it meanwhile
+			if err == mongo.ErrNoDocuments {
+				continue
+			}
+			return err
+		}
+		service.notify(ctx, appointment.User, "Appointment Cancelled", fmt.Sprintf("Your appointment on %s has been cancelled. %s", scheduling.ClinicTime(clinic, appointment.AppointmentDate), reason))
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	utils "AlShifa/Utils"
+	"context"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestClinicDeactivated(t *testing.T) {
+	ownerID := primitive.NewObjectID()
+	confirmed := models.Appointment{ID: primitive.NewObjectID(), User: primitive.NewObjectID(), Clinic: dummyClinicID, AppointmentDate: tomorrowsSlot(40), Status: utils.AppointmentStatusConfirmed}
+	needsReschedule := models.Appointment{ID: primitive.NewObjectID(), User: primitive.NewObjectID(), Clinic: dummyClinicID, AppointmentDate: tomorrowsSlot(41), Status: utils.AppointmentStatusNeedsReschedule}
+	//patient cancelled it meanwhile
+	changed := models.Appointment{ID: primitive.NewObjectID(), User: primitive.NewObjectID(), Clinic: dummyClinicID, AppointmentDate: tomorrowsSlot(42), Status: utils.AppointmentStatusRequested}
+
+	cancelled := map[primitive.ObjectID]models.StatusTransition{}
+	notifier := &recordingNotifier{}
+	mockRepo := &MockAppointmentRepo{
+		GetAppointmentsFn: func(ctx context.Context, filter bson.M) ([]models.Appointment, error) {
+			if filter["clinic"] != dummyClinicID {
+				t.Fatalf("expected appointments of clinic got %v", filter)
+			}
+			return []models.Appointment{confirmed, needsReschedule, changed}, nil
+		},
+		TransitionAppointmentStatusFn: func(ctx context.Context, appointmentID primitive.ObjectID, from string, transition models.StatusTransition, releaseSlot bool) error {
+			if appointmentID == changed.ID {
+				return mongo.ErrNoDocuments
+			}
+			if !releaseSlot {
+				t.Fatalf("expected slot of %v to be released", appointmentID)
+			}
+			cancelled[appointmentID] = transition
+			return nil
+		},
+	}
+
+	change := models.ClinicStatusChange{From: utils.ClinicStatusActive, To: utils.ClinicStatusClosed, Actor: ownerID, ActorRole: utils.RoleClinicOwner, Reason: "Moving out"}
+	service := NewAppointmentService(mockRepo, notifier, nil)
+	if err := service.ClinicDeactivated(context.Background(), models.Clinic{ID: dummyClinicID}, change); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+
+	if len(cancelled) != 2 {
+		t.Fatalf("expected 2 appointments cancelled got %v", cancelled)
+	}
+	for _, transition := range cancelled {
+		if transition.To != utils.AppointmentStatusCancelled || transition.Actor != ownerID || !strings.Contains(transition.Reason, "Moving out") {
+			t.Fatalf("unexpected transition %+v", transition)
+		}
+	}
+	if len(notifier.messages) != 2 || notifier.messages[0].Recipient != confirmed.User {
+		t.Fatalf("expected patients of cancelled appointments notified got %+v", notifier.messages)
+	}
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	middleware "AlShifa/Middleware"
+	notifications "AlShifa/Notifications"
+	scheduling "AlShifa/Scheduling"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AddDoctorLeave saves leave of doctor at one clinic (leave.Clinic) or at all clinics, appointments falling in it
+// are cancelled or marked as needing a reschedule depending on action and their patients are notified
+func (service *AppointmentService) AddDoctorLeave(ctx context.Context, doctorID primitive.ObjectID, leave models.Leave, action string) (*models.Leave, []models.Appointment, *structs.IAppError) {
+	if appErr := validateLeave(leave, action); appErr != nil {
+		return nil, nil, appErr
+	}
+
+	if leave.Clinic != nil {
+		if _, _, appErr := service.getDoctorAtClinic(ctx, doctorID, *leave.Clinic); appErr != nil {
+			return nil, nil, appErr
+		}
+	}
+
+	leave.Doctor = &doctorID
+	return service.addLeave(ctx, leave, action, utils.RoleDoctor)
+}
+
+// AddClinicClosure saves a closure (holiday) of clinic which blocks every doctor sitting there, only owner of clinic or their staff can add it
+func (service *AppointmentService) AddClinicClosure(ctx context.Context, ownerID primitive.ObjectID, clinicID primitive.ObjectID, leave models.Leave, action string) (*models.Leave, []models.Appointment, *structs.IAppError) {
+	if appErr := validateLeave(leave, action); appErr != nil {
+		return nil, nil, appErr
+	}
+
+	if appErr := service.ownClinic(ctx, ownerID, clinicID); appErr != nil {
+		return nil, nil, appErr
+	}
+
+	leave.Doctor = nil
+	leave.Clinic = &clinicID
+	leave.CreatedBy = ownerID
+	return service.addLeave(ctx, leave, action, utils.RoleClinicOwner)
+}
+
+// GetDoctorLeaves returns leaves of doctor and closures of clinics doctor sits at which overlap from - to
+func (service *AppointmentService) GetDoctorLeaves(ctx context.Context, doctorID primitive.ObjectID, from time.Time, to time.Time) ([]models.Leave, *structs.IAppError) {
+	if !to.After(from) {
+		return nil, utils.ReturnAppError(errors.New("invalid date range"), http.StatusBadRequest, "Invalid Date Range", "from must be before to")
+	}
+
+	doctor, err := service.Repo.GetDoctor(ctx, doctorID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.ReturnAppError(err, http.StatusNotFound, "Doctor Not Found", "Invalid Doctor")
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Doctor", "Server Error")
+	}
+
+	clinicIDs := bson.A{}
+	for _, session := range doctor.Clinics {
+		clinicIDs = append(clinicIDs, session.Clinic)
+	}
+
+	leaves, err := service.Repo.GetLeaves(ctx, bson.M{
+		"start": bson.M{"$lt": to},
+		"end":   bson.M{"$gt": from},
+		"$or": bson.A{
+			bson.M{"doctor": doctorID},
+			bson.M{"doctor": bson.M{"$exists": false}, "clinic": bson.M{"$in": clinicIDs}},
+		},
+	})
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Leaves", "Server Error")
+	}
+	return leaves, nil
+}
+
+// DeleteLeave removes leave so its slots can be booked again, appointments already cancelled because of it stay cancelled
+func (service *AppointmentService) DeleteLeave(ctx context.Context, actorID primitive.ObjectID, actorRole string, leaveID primitive.ObjectID) *structs.IAppError {
+	leave, err := service.Repo.GetLeave(ctx, bson.M{"_id": leaveID})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return utils.ReturnAppError(err, http.StatusNotFound, "Leave Not Found", "Leave Doesnt Exist")
+		}
+		return utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Leave", "Server Error")
+	}
+
+	switch {
+	case leave.Doctor != nil && actorRole == utils.RoleDoctor && *leave.Doctor == actorID:
+	case leave.Doctor == nil && leave.Clinic != nil && (actorRole == utils.RoleClinicOwner || actorRole == utils.RoleStaff):
+		if appErr := service.ownClinic(ctx, actorID, *leave.Clinic); appErr != nil {
+			return appErr
+		}
+	default:
+		return utils.ReturnAppError(errors.New("leave of someone else"), http.StatusNotFound, "Leave Not Found", "Leave Doesnt Exist")
+	}
+
+	if err := service.Repo.DeleteLeave(ctx, leaveID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return utils.ReturnAppError(err, http.StatusNotFound, "Leave Not Found", "Leave Doesnt Exist")
+		}
+		return utils.ReturnAppError(err, http.StatusInternalServerError, "Failed To Delete Leave", "Server Error")
+	}
+	return nil
+}
+
+func (service *AppointmentService) addLeave(ctx context.Context, leave models.Leave, action string, actorRole string) (*models.Leave, []models.Appointment, *structs.IAppError) {
+	leave.ID = primitive.NewObjectID()
+	leave.Start = leave.Start.UTC()
+	leave.End = leave.End.UTC()
+	leave.Reason = strings.TrimSpace(leave.Reason)
+	leave.CreatedAt = time.Now().UTC()
+	if leave.Doctor != nil {
+		leave.CreatedBy = *leave.Doctor
+	}
+
+	//leave is saved first so no new booking can land in it while existing ones are being moved
+	if err := service.Repo.InsertLeave(ctx, leave); err != nil {
+		return nil, nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Failed To Add Leave", "Server Error")
+	}
+
+	affected, err := service.applyLeave(ctx, leave, action, actorRole)
+	if err != nil {
+		return &leave, affected, utils.ReturnAppError(err, http.StatusInternalServerError, "Leave Added But Some Appointments Could Not Be Updated", "Server Error")
+	}
+	return &leave, affected, nil
+}
+
+// applyLeave cancels or marks as needing reschedule every upcoming appointment whose slot overlaps leave and notifies its patient
+func (service *AppointmentService) applyLeave(ctx context.Context, leave models.Leave, action string, actorRole string) ([]models.Appointment, error) {
+	status := utils.AppointmentStatusCancelled
+	if action == utils.LeaveActionReschedule {
+		status = utils.AppointmentStatusNeedsReschedule
+	}
+
+	//appointments starting a little before leave can still run into it, exact overlap is checked with slot length of doctor
+	filter := bson.M{
+		"status":          bson.M{"$in": bson.A{utils.AppointmentStatusRequested, utils.AppointmentStatusConfirmed}},
+		"appointmentDate": bson.M{"$gt": leave.Start.Add(-24 * time.Hour), "$lt": leave.End},
+	}
+	if leave.Doctor != nil {
+		filter["doctor"] = *leave.Doctor
+	}
+	if leave.Clinic != nil {
+		filter["clinic"] = *leave.Clinic
+	}
+
+	appointments, err := service.Repo.GetAppointments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	slotDurations := map[primitive.ObjectID]time.Duration{}
+	clinics := map[primitive.ObjectID]models.Clinic{}
+	var affected []models.Appointment
+	for _, appointment := range appointments {
+		duration, ok := slotDurations[appointment.Doctor]
+		if !ok {
+			doctor, err := service.Repo.GetDoctor(ctx, appointment.Doctor)
+			if err != nil && err != mongo.ErrNoDocuments {
+				return affected, err
+			}
+			duration = scheduling.SlotDuration(doctor)
+			slotDurations[appointment.Doctor] = duration
+		}
+		if !appointment.AppointmentDate.Add(duration).After(leave.Start) {
+			continue
+		}
+
+		transition := models.StatusTransition{
+			At:        time.Now().UTC(),
+			From:      appointment.Status,
+			To:        status,
+			Actor:     leave.CreatedBy,
+			ActorRole: actorRole,
+			Reason:    leaveReason(leave),
+		}
+		if err := service.Repo.TransitionAppointmentStatus(ctx, appointment.ID, appointment.Status, transition, true); err != nil {
+			//patient changed it meanwhile
+			if err == mongo.ErrNoDocuments {
+				continue
+			}
+			return affected, err
+		}
+
+		appointment.Status = status
+		appointment.StatusHistory = append(appointment.StatusHistory, transition)
+		affected = append(affected, appointment)
+
+		clinic, ok := clinics[appointment.Clinic]
+		if !ok {
+			clinic, _ = service.Repo.GetClinic(ctx, appointment.Clinic)
+			clinics[appointment.Clinic] = clinic
+		}
+		if status == utils.AppointmentStatusCancelled {
+			service.notify(ctx, appointment.User, "Appointment Cancelled", fmt.Sprintf("Your appointment on %s has been cancelled. %s", scheduling.ClinicTime(clinic, appointment.AppointmentDate), transition.Reason))
+		} else {
+			service.notify(ctx, appointment.User, "Please Reschedule Your Appointment", fmt.Sprintf("Your appointment on %s can no longer take place. %s. Please pick another slot", scheduling.ClinicTime(clinic, appointment.AppointmentDate), transition.Reason))
+		}
+	}
+	return affected, nil
+}
+
+// ownClinic makes sure clinic exists and belongs to owner or owner is staff of it, clinics of others are reported as not found
+func (service *AppointmentService) ownClinic(ctx context.Context, ownerID primitive.ObjectID, clinicID primitive.ObjectID) *structs.IAppError {
+	clinic, err := service.Repo.GetClinic(ctx, clinicID)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Clinic", "Server Error")
+	}
+	if err == mongo.ErrNoDocuments || !middleware.ActsForClinic(ctx, ownerID, clinic.Owner, clinic.ID) {
+		return utils.ReturnAppError(errors.New("clinic of someone else"), http.StatusNotFound, "Clinic Not Found", "Invalid Clinic")
+	}
+	return nil
+}
+
+// notify tells user about a change, failures dont undo the change so they are only logged
+func (service *AppointmentService) notify(ctx context.Context, userID primitive.ObjectID, subject string, body string) {
+	if service.Notifier == nil {
+		return
+	}
+	if err := service.Notifier.Notify(ctx, notifications.Message{Recipient: userID, Subject: subject, Body: body}); err != nil {
+		log.Println("failed to notify user:", err)
+	}
+}
+
+// leavesOf returns filter for leaves which block doctor at clinic between from and to
+func leavesOf(doctorID primitive.ObjectID, clinicID primitive.ObjectID, from time.Time, to time.Time) bson.M {
+	return bson.M{
+		"start": bson.M{"$lt": to},
+		"end":   bson.M{"$gt": from},
+		"$or": bson.A{
+			bson.M{"doctor": doctorID, "clinic": bson.M{"$exists": false}},
+			bson.M{"doctor": doctorID, "clinic": clinicID},
+			bson.M{"doctor": bson.M{"$exists": false}, "clinic": clinicID},
+		},
+	}
+}
+
+func validateLeave(leave models.Leave, action string) *structs.IAppError {
+	errs := map[string]string{}
+	if leave.Start.IsZero() || leave.End.IsZero() || !leave.End.After(leave.Start) {
+		errs["end"] = "end must be after start"
+	} else if !leave.End.After(time.Now()) {
+		errs["end"] = "leave is already over"
+	} else if leave.End.Sub(leave.Start) > utils.MaxLeaveDays*24*time.Hour {
+		errs["end"] = fmt.Sprintf("leave cannot be longer than %d days", utils.MaxLeaveDays)
+	}
+	if action != utils.LeaveActionCancel && action != utils.LeaveActionReschedule {
+		errs["action"] = "action must be " + utils.LeaveActionCancel + " or " + utils.LeaveActionReschedule
+	}
+
+	if len(errs) > 0 {
+		return utils.ReturnAppError(errs, http.StatusBadRequest, "Invalid Leave", "Invalid Details")
+	}
+	return nil
+}
+
+func leaveReason(leave models.Leave) string {
+	reason := "Doctor is on leave"
+	if leave.Doctor == nil {
+		reason = "Clinic is closed"
+	}
+	if leave.Reason != "" {
+		reason += ": " + leave.Reason
+	}
+	return reason
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	notifications "AlShifa/Notifications"
+	utils "AlShifa/Utils"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// recordingNotifier stands in for a notifier and keeps messages it was asked to deliver
+type recordingNotifier struct {
+	messages []notifications.Message
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, message notifications.Message) error {
+	n.messages = append(n.messages, message)
+	return nil
+}
+
+func TestAddDoctorLeave(t *testing.T) {
+	doctorID := primitive.NewObjectID()
+	leaveStart := tomorrowsSlot(40)
+	leaveEnd := tomorrowsSlot(48)
+
+	//first runs into leave, second ends exactly when leave starts and third is after it
+	overlapping := models.Appointment{ID: primitive.NewObjectID(), User: primitive.NewObjectID(), Doctor: doctorID, Clinic: dummyClinicID, AppointmentDate: tomorrowsSlot(40).Add(-10 * time.Minute), Status: utils.AppointmentStatusConfirmed}
+	before := models.Appointment{ID: primitive.NewObjectID(), User: primitive.NewObjectID(), Doctor: doctorID, Clinic: dummyClinicID, AppointmentDate: tomorrowsSlot(39), Status: utils.AppointmentStatusRequested}
+	inside := models.Appointment{ID: primitive.NewObjectID(), User: primitive.NewObjectID(), Doctor: doctorID, Clinic: dummyClinicID, AppointmentDate: tomorrowsSlot(45), Status: utils.AppointmentStatusRequested}
+
+	testCases := []struct {
+		Name               string
+		Action             string
+		End                time.Time
+		ExpectedStatus     string
+		ExpectedStatusCode int
+	}{
+		{Name: "Appointments in leave are cancelled", Action: utils.LeaveActionCancel, End: leaveEnd, ExpectedStatus: utils.AppointmentStatusCancelled},
+		{Name: "Appointments in leave need reschedule", Action: utils.LeaveActionReschedule, End: leaveEnd, ExpectedStatus: utils.AppointmentStatusNeedsReschedule},
+		{Name: "Unknown action is rejected", Action: "ignore", End: leaveEnd, ExpectedStatusCode: http.StatusBadRequest},
+		{Name: "Leave ending before it starts is rejected", Action: utils.LeaveActionCancel, End: leaveStart.Add(-time.Hour), ExpectedStatusCode: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			moved := map[primitive.ObjectID]string{}
+			notifier := &recordingNotifier{}
+			mockRepo := &MockAppointmentRepo{
+				InsertLeaveFn: func(ctx context.Context, leave models.Leave) error {
+					if leave.Doctor == nil || *leave.Doctor != doctorID || leave.CreatedBy != doctorID {
+						t.Fatalf("leave not saved for doctor %+v", leave)
+					}
+					return nil
+				},
+				GetAppointmentsFn: func(ctx context.Context, filter bson.M) ([]models.Appointment, error) {
+					return []models.Appointment{overlapping, before, inside}, nil
+				},
+				GetDoctorFn: func(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error) {
+					return ReturnDummyDoctor(doctorID), nil
+				},
+				GetClinicFn: func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+					return models.Clinic{ID: clinicID}, nil
+				},
+				TransitionAppointmentStatusFn: func(ctx context.Context, appointmentID primitive.ObjectID, from string, transition models.StatusTransition, releaseSlot bool) error {
+					if !releaseSlot || transition.Actor != doctorID || transition.ActorRole != utils.RoleDoctor {
+						t.Fatalf("unexpected transition %+v", transition)
+					}
+					moved[appointmentID] = transition.To
+					return nil
+				},
+			}
+
+			service := NewAppointmentService(mockRepo, notifier, nil)
+			_, affected, err := service.AddDoctorLeave(context.Background(), doctorID, models.Leave{Start: leaveStart, End: tc.End, Reason: "Conference"}, tc.Action)
+
+			if tc.ExpectedStatusCode != 0 {
+				if err == nil || err.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status code %d but got %v", tc.ExpectedStatusCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+
+			if len(affected) != 2 || moved[overlapping.ID] != tc.ExpectedStatus || moved[inside.ID] != tc.ExpectedStatus || moved[before.ID] != "" {
+				t.Fatalf("unexpected appointments moved %v", moved)
+			}
+			if len(notifier.messages) != 2 || notifier.messages[0].Recipient != overlapping.User {
+				t.Fatalf("patients not notified %+v", notifier.messages)
+			}
+		})
+	}
+}
+
+func TestSlotsExcludeLeaves(t *testing.T) {
+	doctorID := primitive.NewObjectID()
+	mockRepo := &MockAppointmentRepo{
+		GetDoctorFn: func(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error) {
+			return ReturnDummyDoctor(doctorID), nil
+		},
+		GetClinicFn: func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+			return models.Clinic{ID: clinicID}, nil
+		},
+		GetLeavesFn: func(ctx context.Context, filter bson.M) ([]models.Leave, error) {
+			return []models.Leave{{Doctor: &doctorID, Start: tomorrowsSlot(0), End: tomorrowsSlot(40)}}, nil
+		},
+		GetReservationsFn: func(ctx context.Context, filter bson.M) ([]models.SlotReservation, error) {
+			return nil, nil
+		},
+	}
+
+	service := NewAppointmentService(mockRepo, nil, nil)
+	tomorrow := tomorrowsSlot(0)
+	slots, err := service.GetDoctorSlots(context.Background(), doctorID, dummyClinicID, tomorrow, tomorrow, "")
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if len(slots) == 0 || slots[0].Index != 40 {
+		t.Fatalf("expected slots to start after leave but got %+v", slots)
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	utils "AlShifa/Utils"
+	"slices"
+)
+
+// transitions lists for every status the statuses it can move to and the roles allowed to make that move.
+// statuses not present as keys (Completed, Cancelled, NoShow, Rescheduled) are final
+var transitions = map[string]map[string][]string{
+	utils.AppointmentStatusRequested: {
+		utils.AppointmentStatusConfirmed:       {utils.RoleDoctor, utils.RoleClinicOwner, utils.RoleStaff},
+		utils.AppointmentStatusCancelled:       {utils.RoleUser, utils.RoleDoctor, utils.RoleClinicOwner, utils.RoleStaff},
+		utils.AppointmentStatusRescheduled:     {utils.RoleUser},
+		utils.AppointmentStatusNeedsReschedule: {utils.RoleDoctor, utils.RoleClinicOwner, utils.RoleStaff},
+	},
+	utils.AppointmentStatusConfirmed: {
+		utils.AppointmentStatusCheckedIn:       {utils.RoleDoctor, utils.RoleClinicOwner, utils.RoleStaff},
+		utils.AppointmentStatusCancelled:       {utils.RoleUser, utils.RoleDoctor, utils.RoleClinicOwner, utils.RoleStaff},
+		utils.AppointmentStatusNoShow:          {utils.RoleDoctor, utils.RoleClinicOwner, utils.RoleStaff},
+		utils.AppointmentStatusRescheduled:     {utils.RoleUser},
+		utils.AppointmentStatusNeedsReschedule: {utils.RoleDoctor, utils.RoleClinicOwner, utils.RoleStaff},
+	},
+	//appointment fell in a leave of doctor or closure of clinic, patient picks a new slot or cancels
+	utils.AppointmentStatusNeedsReschedule: {
+		utils.AppointmentStatusRescheduled: {utils.RoleUser},
+		utils.AppointmentStatusCancelled:   {utils.RoleUser, utils.RoleDoctor, utils.RoleClinicOwner, utils.RoleStaff},
+	},
+	utils.AppointmentStatusCheckedIn: {
+		utils.AppointmentStatusInConsultation: {utils.RoleDoctor},
+		utils.AppointmentStatusCancelled:      {utils.RoleDoctor, utils.RoleClinicOwner, utils.RoleStaff},
+	},
+	utils.AppointmentStatusInConsultation: {
+		utils.AppointmentStatusCompleted: {utils.RoleDoctor},
+		//patient called from queue but not present goes back to waiting
+		utils.AppointmentStatusCheckedIn: {utils.RoleDoctor},
+	},
+}
+
+// IsLegalTransition tells if status can move from one status to another at all
+func IsLegalTransition(from string, to string) bool {
+	_, ok := transitions[from][to]
+	return ok
+}
+
+// CanTransition tells if role is allowed to move status from one status to another
+func CanTransition(from string, to string, role string) bool {
+	return slices.Contains(transitions[from][to], role)
+}
+
+// releasesSlot tells if seat of appointment should be given back when appointment moves to status
+func releasesSlot(status string) bool {
+	return status == utils.AppointmentStatusCancelled ||
+		status == utils.AppointmentStatusNoShow ||
+		status == utils.AppointmentStatusRescheduled ||
+		status == utils.AppointmentStatusNeedsReschedule
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	interfaces "AlShifa/Appointment/Interfaces"
+	appointmentStructs "AlShifa/Appointment/Structs"
+	"AlShifa/Clinic/models"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockAppointmentRepo struct {
+	BookAppointmentFn             func(ctx context.Context, appointment models.Appointment, reservation models.SlotReservation, capacity int) error
+	GetAppointmentsFn             func(ctx context.Context, filter bson.M) ([]models.Appointment, error)
+	GetAppointmentFn              func(ctx context.Context, filter bson.M) (models.Appointment, error)
+	TransitionAppointmentStatusFn func(ctx context.Context, appointmentID primitive.ObjectID, from string, transition models.StatusTransition, releaseSlot bool) error
+	RescheduleAppointmentFn       func(ctx context.Context, oldAppointmentID primitive.ObjectID, from string, transition models.StatusTransition, newAppointment models.Appointment, reservation models.SlotReservation, capacity int) error
+	GetReservationsFn             func(ctx context.Context, filter bson.M) ([]models.SlotReservation, error)
+	HoldSeatFn                    func(ctx context.Context, reservation models.SlotReservation, capacity int, ttl time.Duration) (primitive.ObjectID, error)
+	ReleaseHoldFn                 func(ctx context.Context, holdID primitive.ObjectID) error
+	InsertWaitlistEntryFn         func(ctx context.Context, entry models.WaitlistEntry) error
+	GetWaitlistEntriesFn          func(ctx context.Context, filter bson.M) ([]models.WaitlistEntry, error)
+	GetWaitlistEntryFn            func(ctx context.Context, filter bson.M) (models.WaitlistEntry, error)
+	ClaimNextWaitlistEntryFn      func(ctx context.Context, filter bson.M, offer models.WaitlistOffer) (models.WaitlistEntry, error)
+	SetWaitlistStatusFn           func(ctx context.Context, entryID primitive.ObjectID, from string, to string) error
+	AcceptWaitlistOfferFn         func(ctx context.Context, entry models.WaitlistEntry, appointment models.Appointment) error
+	InsertLeaveFn                 func(ctx context.Context, leave models.Leave) error
+	GetLeavesFn                   func(ctx context.Context, filter bson.M) ([]models.Leave, error)
+	GetLeaveFn                    func(ctx context.Context, filter bson.M) (models.Leave, error)
+	DeleteLeaveFn                 func(ctx context.Context, leaveID primitive.ObjectID) error
+	GetNoShowsFn                  func(ctx context.Context, filter bson.M) ([]models.NoShowRecord, error)
+	CountNoShowsFn                func(ctx context.Context, filter bson.M) (int64, error)
+	GetNoShowOffendersFn          func(ctx context.Context, clinicID primitive.ObjectID, since time.Time, limit int64) ([]appointmentStructs.NoShowOffender, error)
+	SetNoShowPolicyFn             func(ctx context.Context, clinicID primitive.ObjectID, policy models.NoShowPolicy) error
+	SetSessionModeFn              func(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID, mode string, onlineHours []models.TimeWindow) error
+	GetDoctorFn                   func(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error)
+	GetClinicFn                   func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error)
+	GetDoctorsFn                  func(ctx context.Context, filter bson.M) ([]models.Doctor, error)
+	GetClinicsFn                  func(ctx context.Context, filter bson.M) ([]models.Clinic, error)
+}
+
+var _ interfaces.IRepository = (*MockAppointmentRepo)(nil)
+
+func (m *MockAppointmentRepo) BookAppointment(ctx context.Context, appointment models.Appointment, reservation models.SlotReservation, capacity int) error {
+	if m.BookAppointmentFn == nil {
+		panic("BookAppointmentFn not implemented inside mock")
+	}
+	return m.BookAppointmentFn(ctx, appointment, reservation, capacity)
+}
+
+func (m *MockAppointmentRepo) GetAppointments(ctx context.Context, filter bson.M) ([]models.Appointment, error) {
+	if m.GetAppointmentsFn == nil {
+		panic("GetAppointmentsFn not implemented inside mock")
+	}
+	return m.GetAppointmentsFn(ctx, filter)
+}
+
+func (m *MockAppointmentRepo) GetAppointment(ctx context.Context, filter bson.M) (models.Appointment, error) {
+	if m.GetAppointmentFn == nil {
+		panic("GetAppointmentFn not implemented inside mock")
+	}
+	return m.GetAppointmentFn(ctx, filter)
+}
+
+func (m *MockAppointmentRepo) TransitionAppointmentStatus(ctx context.Context, appointmentID primitive.ObjectID, from string, transition models.StatusTransition, releaseSlot bool) error {
+	if m.TransitionAppointmentStatusFn == nil {
+		panic("TransitionAppointmentStatusFn not implemented inside mock")
+	}
+	return m.TransitionAppointmentStatusFn(ctx, appointmentID, from, transition, releaseSlot)
+}
+
+func (m *MockAppointmentRepo) GetReservations(ctx context.Context, filter bson.M) ([]models.SlotReservation, error) {
+	if m.GetReservationsFn == nil {
+		panic("GetReservationsFn not implemented inside mock")
+	}
+	return m.GetReservationsFn(ctx, filter)
+}
+
+func (m *MockAppointmentRepo) GetNoShows(ctx context.Context, filter bson.M) ([]models.NoShowRecord, error) {
+	if m.GetNoShowsFn == nil {
+		panic("GetNoShowsFn not implemented inside mock")
+	}
+	return m.GetNoShowsFn(ctx, filter)
+}
+
+func (m *MockAppointmentRepo) CountNoShows(ctx context.Context, filter bson.M) (int64, error) {
+	if m.CountNoShowsFn == nil {
+		panic("CountNoShowsFn not implemented inside mock")
+	}
+	return m.CountNoShowsFn(ctx, filter)
+}
+
+func (m *MockAppointmentRepo) GetNoShowOffenders(ctx context.Context, clinicID primitive.ObjectID, since time.Time, limit int64) ([]appointmentStructs.NoShowOffender, error) {
+	if m.GetNoShowOffendersFn == nil {
+		panic("GetNoShowOffendersFn not implemented inside mock")
+	}
+	return m.GetNoShowOffendersFn(ctx, clinicID, since, limit)
+}
+
+func (m *MockAppointmentRepo) SetNoShowPolicy(ctx context.Context, clinicID primitive.ObjectID, policy models.NoShowPolicy) error {
+	if m.SetNoShowPolicyFn == nil {
+		panic("SetNoShowPolicyFn not implemented inside mock")
+	}
+	return m.SetNoShowPolicyFn(ctx, clinicID, policy)
+}
+
+func (m *MockAppointmentRepo) SetSessionMode(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID, mode string, onlineHours []models.TimeWindow) error {
+	if m.SetSessionModeFn == nil {
+		panic("SetSessionModeFn not implemented inside mock")
+	}
+	return m.SetSessionModeFn(ctx, doctorID, clinicID, mode, onlineHours)
+}
+
+func (m *MockAppointmentRepo) GetDoctor(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error) {
+	if m.GetDoctorFn == nil {
+		panic("GetDoctorFn not implemented inside mock")
+	}
+	return m.GetDoctorFn(ctx, doctorID)
+}
+
+func (m *MockAppointmentRepo) GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+	if m.GetClinicFn == nil {
+		panic("GetClinicFn not implemented inside mock")
+	}
+	return m.GetClinicFn(ctx, clinicID)
+}
+
+func (m *MockAppointmentRepo) GetDoctors(ctx context.Context, filter bson.M) ([]models.Doctor, error) {
+	if m.GetDoctorsFn == nil {
+		panic("GetDoctorsFn not implemented inside mock")
+	}
+	return m.GetDoctorsFn(ctx, filter)
+}
+
+func (m *MockAppointmentRepo) GetClinics(ctx context.Context, filter bson.M) ([]models.Clinic, error) {
+	if m.GetClinicsFn == nil {
+		panic("GetClinicsFn not implemented inside mock")
+	}
+	return m.GetClinicsFn(ctx, filter)
+}
+
+func (m *MockAppointmentRepo) RescheduleAppointment(ctx context.Context, oldAppointmentID primitive.ObjectID, from string, transition models.StatusTransition, newAppointment models.Appointment, reservation models.SlotReservation, capacity int) error {
+	if m.RescheduleAppointmentFn == nil {
+		panic("RescheduleAppointmentFn not implemented inside mock")
+	}
+	return m.RescheduleAppointmentFn(ctx, oldAppointmentID, from, transition, newAppointment, reservation, capacity)
+}
+
+func (m *MockAppointmentRepo) HoldSeat(ctx context.Context, reservation models.SlotReservation, capacity int, ttl time.Duration) (primitive.ObjectID, error) {
+	if m.HoldSeatFn == nil {
+		panic("HoldSeatFn not implemented inside mock")
+	}
+	return m.HoldSeatFn(ctx, reservation, capacity, ttl)
+}
+
+func (m *MockAppointmentRepo) ReleaseHold(ctx context.Context, holdID primitive.ObjectID) error {
+	if m.ReleaseHoldFn == nil {
+		panic("ReleaseHoldFn not implemented inside mock")
+	}
+	return m.ReleaseHoldFn(ctx, holdID)
+}
+
+func (m *MockAppointmentRepo) InsertWaitlistEntry(ctx context.Context, entry models.WaitlistEntry) error {
+	if m.InsertWaitlistEntryFn == nil {
+		panic("InsertWaitlistEntryFn not implemented inside mock")
+	}
+	return m.InsertWaitlistEntryFn(ctx, entry)
+}
+
+func (m *MockAppointmentRepo) GetWaitlistEntries(ctx context.Context, filter bson.M) ([]models.WaitlistEntry, error) {
+	if m.GetWaitlistEntriesFn == nil {
+		panic("GetWaitlistEntriesFn not implemented inside mock")
+	}
+	return m.GetWaitlistEntriesFn(ctx, filter)
+}
+
+func (m *MockAppointmentRepo) GetWaitlistEntry(ctx context.Context, filter bson.M) (models.WaitlistEntry, error) {
+	if m.GetWaitlistEntryFn == nil {
+		panic("GetWaitlistEntryFn not implemented inside mock")
+	}
+	return m.GetWaitlistEntryFn(ctx, filter)
+}
+
+func (m *MockAppointmentRepo) ClaimNextWaitlistEntry(ctx context.Context, filter bson.M, offer models.WaitlistOffer) (models.WaitlistEntry, error) {
+	if m.ClaimNextWaitlistEntryFn == nil {
+		panic("ClaimNextWaitlistEntryFn not implemented inside mock")
+	}
+	return m.ClaimNextWaitlistEntryFn(ctx, filter, offer)
+}
+
+func (m *MockAppointmentRepo) SetWaitlistStatus(ctx context.Context, entryID primitive.ObjectID, from string, to string) error {
+	if m.SetWaitlistStatusFn == nil {
+		panic("SetWaitlistStatusFn not implemented inside mock")
+	}
+	return m.SetWaitlistStatusFn(ctx, entryID, from, to)
+}
+
+func (m *MockAppointmentRepo) AcceptWaitlistOffer(ctx context.Context, entry models.WaitlistEntry, appointment models.Appointment) error {
+	if m.AcceptWaitlistOfferFn == nil {
+		panic("AcceptWaitlistOfferFn not implemented inside mock")
+	}
+	return m.AcceptWaitlistOfferFn(ctx, entry, appointment)
+}
+
+func (m *MockAppointmentRepo) InsertLeave(ctx context.Context, leave models.Leave) error {
+	if m.InsertLeaveFn == nil {
+		panic("InsertLeaveFn not implemented inside mock")
+	}
+	return m.InsertLeaveFn(ctx, leave)
+}
+
+func (m *MockAppointmentRepo) GetLeaves(ctx context.Context, filter bson.M) ([]models.Leave, error) {
+	if m.GetLeavesFn == nil {
+		panic("GetLeavesFn not implemented inside mock")
+	}
+	return m.GetLeavesFn(ctx, filter)
+}
+
+func (m *MockAppointmentRepo) GetLeave(ctx context.Context, filter bson.M) (models.Leave, error) {
+	if m.GetLeaveFn == nil {
+		panic("GetLeaveFn not implemented inside mock")
+	}
+	return m.GetLeaveFn(ctx, filter)
+}
+
+func (m *MockAppointmentRepo) DeleteLeave(ctx context.Context, leaveID primitive.ObjectID) error {
+	if m.DeleteLeaveFn == nil {
+		panic("DeleteLeaveFn not implemented inside mock")
+	}
+	return m.DeleteLeaveFn(ctx, leaveID)
+}
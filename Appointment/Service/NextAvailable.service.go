@@ -0,0 +1,179 @@
+package service
+
+import (
+	appointmentStructs "AlShifa/Appointment/Structs"
+	"AlShifa/Clinic/models"
+	scheduling "AlShifa/Scheduling"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetNextAvailableSlots returns the earliest count free slots of doctor at any of their clinics within days from now
+func (service *AppointmentService) GetNextAvailableSlots(ctx context.Context, doctorID primitive.ObjectID, count int, days int, mode string) ([]appointmentStructs.AvailableSlot, *structs.IAppError) {
+	next, appErr := service.GetNextAvailable(ctx, []primitive.ObjectID{doctorID}, count, days, mode)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if len(next) == 0 {
+		return nil, utils.ReturnAppError(errors.New("doctor not found"), http.StatusNotFound, "Doctor Not Found", "Invalid Doctor")
+	}
+	return next[0].Slots, nil
+}
+
+// GetNextAvailable returns the earliest count free slots of every doctor across all their clinics within days from now.
+// listing pages call it for a whole page of doctors so doctors, clinics, leaves and bookings are fetched once for all of
+// them and slots are worked out in memory day by day until enough are found. unknown doctors are left out
+func (service *AppointmentService) GetNextAvailable(ctx context.Context, doctorIDs []primitive.ObjectID, count int, days int, mode string) ([]appointmentStructs.NextAvailable, *structs.IAppError) {
+	if count <= 0 || count > utils.MaxNextSlots {
+		return nil, utils.ReturnAppError(errors.New("invalid count"), http.StatusBadRequest, "Unable To Fetch Slots", "Count Is Out Of Range")
+	}
+	if days <= 0 || days > utils.MaxSlotRangeDays {
+		return nil, utils.ReturnAppError(errors.New("invalid horizon"), http.StatusBadRequest, "Unable To Fetch Slots", "Days Is Out Of Range")
+	}
+	if len(doctorIDs) == 0 || len(doctorIDs) > utils.MaxNextAvailableDoctors {
+		return nil, utils.ReturnAppError(errors.New("invalid doctors"), http.StatusBadRequest, "Unable To Fetch Slots", "Too Many Or No Doctors")
+	}
+
+	doctors, err := service.Repo.GetDoctors(ctx, bson.M{"_id": bson.M{"$in": doctorIDs}})
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Slots", "Server Error")
+	}
+
+	clinicIDs := []primitive.ObjectID{}
+	for _, doctor := range doctors {
+		for _, session := range doctor.Clinics {
+			if !slices.Contains(clinicIDs, session.Clinic) {
+				clinicIDs = append(clinicIDs, session.Clinic)
+			}
+		}
+	}
+
+	now := time.Now()
+	horizon := now.AddDate(0, 0, days)
+	clinics := map[primitive.ObjectID]models.Clinic{}
+	var leaves []models.Leave
+	reserved := map[seatKey]int{}
+
+	if len(clinicIDs) > 0 {
+		found, err := service.Repo.GetClinics(ctx, bson.M{"_id": bson.M{"$in": clinicIDs}})
+		if err != nil {
+			return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Slots", "Server Error")
+		}
+		for _, clinic := range found {
+			clinics[clinic.ID] = clinic
+		}
+
+		leaves, err = service.Repo.GetLeaves(ctx, bson.M{
+			"start": bson.M{"$lt": horizon},
+			"end":   bson.M{"$gt": now},
+			"$or": bson.A{
+				bson.M{"doctor": bson.M{"$in": doctorIDs}},
+				bson.M{"doctor": bson.M{"$exists": false}, "clinic": bson.M{"$in": clinicIDs}},
+			},
+		})
+		if err != nil {
+			return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Slots", "Server Error")
+		}
+
+		//dates of reservations are local to the clinic so a day either side covers every time zone
+		reservations, err := service.Repo.GetReservations(ctx, bson.M{
+			"doctor": bson.M{"$in": doctorIDs},
+			"date": bson.M{
+				"$gte": scheduling.DayKey(now.AddDate(0, 0, -1), time.UTC),
+				"$lte": scheduling.DayKey(horizon.AddDate(0, 0, 1), time.UTC),
+			},
+			"$or": bson.A{
+				bson.M{"expiresAt": bson.M{"$exists": false}},
+				bson.M{"expiresAt": bson.M{"$gt": now.UTC()}},
+			},
+		})
+		if err != nil {
+			return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Slots", "Server Error")
+		}
+		for _, reservation := range reservations {
+			reserved[seatKey{doctor: reservation.Doctor, clinic: reservation.Clinic, slot: slotKey{date: reservation.Date.Unix(), slot: reservation.Slot}}]++
+		}
+	}
+
+	next := make([]appointmentStructs.NextAvailable, 0, len(doctors))
+	for _, doctorID := range doctorIDs {
+		index := slices.IndexFunc(doctors, func(doctor models.Doctor) bool { return doctor.ID == doctorID })
+		if index == -1 || slices.ContainsFunc(next, func(found appointmentStructs.NextAvailable) bool { return found.Doctor == doctorID }) {
+			continue
+		}
+		next = append(next, appointmentStructs.NextAvailable{
+			Doctor: doctorID,
+			Slots:  nextFreeSlots(doctors[index], clinics, leaves, reserved, now, horizon, count, mode),
+		})
+	}
+	return next, nil
+}
+
+// seatKey identifies a slot of a particular doctor at a particular clinic
+type seatKey struct {
+	doctor primitive.ObjectID
+	clinic primitive.ObjectID
+	slot   slotKey
+}
+
+// nextFreeSlots walks days from now till horizon and collects free slots of every session of doctor, earliest first,
+// until count are found. sessions at clinics which arent active are skipped
+func nextFreeSlots(doctor models.Doctor, clinics map[primitive.ObjectID]models.Clinic, leaves []models.Leave, reserved map[seatKey]int, now time.Time, horizon time.Time, count int, mode string) []appointmentStructs.AvailableSlot {
+	type sessionAt struct {
+		session models.ClinicDetails
+		clinic  models.Clinic
+		leaves  []models.Leave
+	}
+	sessions := []sessionAt{}
+	for _, session := range doctor.Clinics {
+		clinic, ok := clinics[session.Clinic]
+		if !ok || !scheduling.ClinicActive(clinic) {
+			continue
+		}
+		applying := slices.DeleteFunc(slices.Clone(leaves), func(leave models.Leave) bool { return !leaveApplies(leave, doctor.ID, clinic.ID) })
+		sessions = append(sessions, sessionAt{session: session, clinic: clinic, leaves: applying})
+	}
+
+	free := []appointmentStructs.AvailableSlot{}
+	slotDuration := scheduling.SlotDuration(doctor)
+	for day := now; len(free) < count && !scheduling.DayKey(day, time.UTC).After(scheduling.DayKey(horizon, time.UTC)); day = day.AddDate(0, 0, 1) {
+		ofDay := []appointmentStructs.AvailableSlot{}
+		for _, at := range sessions {
+			loc := scheduling.ClinicLocation(at.clinic)
+			local := day.In(loc)
+			slots := scheduling.GenerateSlots(at.clinic, at.session, slotDuration, local, local)
+			slots = scheduling.FilterMode(scheduling.ExcludeLeaves(slots, at.leaves), mode)
+
+			for _, slot := range slots {
+				if !slot.Start.After(now) || slot.Start.After(horizon) {
+					continue
+				}
+				slot.Remaining -= reserved[seatKey{doctor: doctor.ID, clinic: at.clinic.ID, slot: slotKey{date: scheduling.DayKey(slot.Start, loc).Unix(), slot: slot.Index}}]
+				if slot.Remaining <= 0 {
+					continue
+				}
+				ofDay = append(ofDay, appointmentStructs.AvailableSlot{Slot: slot, Clinic: at.clinic.ID, ClinicName: at.clinic.Name})
+			}
+		}
+
+		slices.SortStableFunc(ofDay, func(a, b appointmentStructs.AvailableSlot) int { return a.Start.Compare(b.Start) })
+		free = append(free, ofDay[:min(len(ofDay), count-len(free))]...)
+	}
+	return free
+}
+
+// leaveApplies tells if leave blocks session of doctor at clinic, it matches the same leaves leavesOf queries
+func leaveApplies(leave models.Leave, doctorID primitive.ObjectID, clinicID primitive.ObjectID) bool {
+	if leave.Doctor == nil {
+		return leave.Clinic != nil && *leave.Clinic == clinicID
+	}
+	return *leave.Doctor == doctorID && (leave.Clinic == nil || *leave.Clinic == clinicID)
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	scheduling "AlShifa/Scheduling"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGetNextAvailable(t *testing.T) {
+	doctorID, otherDoctorID := primitive.NewObjectID(), primitive.NewObjectID()
+	secondClinicID := primitive.NewObjectID()
+	loc := scheduling.ClinicLocation(models.Clinic{})
+
+	//dummy doctor also sits whole day at a second clinic
+	doctor := ReturnDummyDoctor(doctorID)
+	secondSession := doctor.Clinics[0]
+	secondSession.Clinic = secondClinicID
+	doctor.Clinics = append(doctor.Clinics, secondSession)
+
+	now := time.Now()
+	mockRepo := &MockAppointmentRepo{
+		GetDoctorsFn: func(ctx context.Context, filter bson.M) ([]models.Doctor, error) {
+			return []models.Doctor{doctor, ReturnDummyDoctor(otherDoctorID)}, nil
+		},
+		GetClinicsFn: func(ctx context.Context, filter bson.M) ([]models.Clinic, error) {
+			return []models.Clinic{{ID: dummyClinicID, Name: "First"}, {ID: secondClinicID, Name: "Second"}}, nil
+		},
+		GetLeavesFn: func(ctx context.Context, filter bson.M) ([]models.Leave, error) {
+			return []models.Leave{
+				//first clinic is closed till slot 40 tomorrow, doctor is away from the second one till slot 41
+				{Clinic: &dummyClinicID, Start: now.Add(-time.Hour), End: tomorrowsSlot(40)},
+				{Doctor: &doctorID, Clinic: &secondClinicID, Start: now.Add(-time.Hour), End: tomorrowsSlot(41)},
+				//leave of another doctor doesnt block this one
+				{Doctor: &otherDoctorID, Clinic: &secondClinicID, Start: now.Add(-time.Hour), End: tomorrowsSlot(90)},
+			}, nil
+		},
+		GetReservationsFn: func(ctx context.Context, filter bson.M) ([]models.SlotReservation, error) {
+			return []models.SlotReservation{
+				{Doctor: doctorID, Clinic: dummyClinicID, Date: scheduling.DayKey(tomorrowsSlot(40), loc), Slot: 40},
+			}, nil
+		},
+	}
+	service := NewAppointmentService(mockRepo, nil, nil)
+
+	next, appErr := service.GetNextAvailable(context.Background(), []primitive.ObjectID{doctorID, primitive.NewObjectID()}, 3, 7, "")
+	if appErr != nil {
+		t.Fatalf("expected no error but got %v", appErr)
+	}
+	if len(next) != 1 || next[0].Doctor != doctorID {
+		t.Fatalf("expected only the known doctor got %+v", next)
+	}
+
+	expected := []struct {
+		clinic primitive.ObjectID
+		index  int8
+	}{{dummyClinicID, 41}, {secondClinicID, 41}, {dummyClinicID, 42}}
+	slots := next[0].Slots
+	if len(slots) != len(expected) {
+		t.Fatalf("expected %d slots got %+v", len(expected), slots)
+	}
+	for i, slot := range slots {
+		if slot.Clinic != expected[i].clinic || slot.Index != expected[i].index || !slot.Start.Equal(tomorrowsSlot(expected[i].index)) {
+			t.Fatalf("expected slot %d at %v got %d at %v", expected[i].index, expected[i].clinic, slot.Index, slot.Clinic)
+		}
+	}
+	if slots[0].ClinicName != "First" {
+		t.Fatalf("expected clinic name with slot got %q", slots[0].ClinicName)
+	}
+
+	mockRepo.GetDoctorsFn = func(ctx context.Context, filter bson.M) ([]models.Doctor, error) {
+		return nil, nil
+	}
+	if _, appErr := service.GetNextAvailableSlots(context.Background(), doctorID, 3, 7, ""); appErr == nil || appErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected unknown doctor to be not found got %v", appErr)
+	}
+	if _, appErr := service.GetNextAvailable(context.Background(), []primitive.ObjectID{doctorID}, 3, 90, ""); appErr == nil || appErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected too long horizon to be rejected got %v", appErr)
+	}
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	appointmentStructs "AlShifa/Appointment/Structs"
+	"AlShifa/Clinic/models"
+	middleware "AlShifa/Middleware"
+	scheduling "AlShifa/Scheduling"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SetNoShowPolicy replaces no show policy of clinic of owner
+func (service *AppointmentService) SetNoShowPolicy(ctx context.Context, ownerID primitive.ObjectID, clinicID primitive.ObjectID, policy models.NoShowPolicy) *structs.IAppError {
+	if appErr := service.ownClinic(ctx, ownerID, clinicID); appErr != nil {
+		return appErr
+	}
+
+	if err := service.Repo.SetNoShowPolicy(ctx, clinicID, policy); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return utils.ReturnAppError(err, http.StatusNotFound, "Clinic Not Found", "Invalid Clinic")
+		}
+		return utils.ReturnAppError(err, http.StatusInternalServerError, "Failed To Update No Show Policy", "Server Error")
+	}
+	return nil
+}
+
+// GetNoShowReport lists patients who missed most appointments at clinic of owner in the last days
+func (service *AppointmentService) GetNoShowReport(ctx context.Context, ownerID primitive.ObjectID, clinicID primitive.ObjectID, days int) (*appointmentStructs.NoShowReport, *structs.IAppError) {
+	clinic, err := service.Repo.GetClinic(ctx, clinicID)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Clinic", "Server Error")
+	}
+	if err == mongo.ErrNoDocuments || !middleware.ActsForClinic(ctx, ownerID, clinic.Owner, clinic.ID) {
+		return nil, utils.ReturnAppError(errors.New("clinic of someone else"), http.StatusNotFound, "Clinic Not Found", "Invalid Clinic")
+	}
+
+	since := time.Now().AddDate(0, 0, -days).UTC()
+	total, err := service.Repo.CountNoShows(ctx, bson.M{"clinic": clinicID, "appointmentDate": bson.M{"$gte": since}})
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch No Shows", "Server Error")
+	}
+
+	offenders, err := service.Repo.GetNoShowOffenders(ctx, clinicID, since, utils.NoShowReportLimit)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch No Shows", "Server Error")
+	}
+
+	return &appointmentStructs.NoShowReport{
+		Clinic:    clinicID,
+		Since:     since,
+		Total:     total,
+		Policy:    clinic.BookingRules.NoShowPolicy,
+		Offenders: offenders,
+	}, nil
+}
+
+// checkNoShowPolicy refuses online bookings of user at clinic while its no show policy restricts them. payments
+// cant be verified online so patients asked to prepay pay at the clinic, whose staff book for them as walk-ins
+func (service *AppointmentService) checkNoShowPolicy(ctx context.Context, userID primitive.ObjectID, clinic models.Clinic) *structs.IAppError {
+	policy := clinic.BookingRules.NoShowPolicy
+	if policy.Threshold <= 0 {
+		return nil
+	}
+
+	until, err := service.noShowRestriction(ctx, userID, clinic, time.Now())
+	if err != nil {
+		return utils.ReturnAppError(err, http.StatusInternalServerError, "Booking Failed", "Server Error")
+	}
+	if until == nil {
+		return nil
+	}
+
+	if policy.Action == utils.NoShowActionPrepay {
+		return utils.ReturnAppError(errors.New("prepayment required"), http.StatusPaymentRequired, "Prepayment Required", "Bookings At This Clinic Need Prepayment Until "+scheduling.ClinicTime(clinic, *until)+" Due To Missed Appointments, Pay At The Clinic To Book")
+	}
+	return utils.ReturnAppError(errors.New("booking blocked"), http.StatusForbidden, "Online Booking Blocked", "Online Booking At This Clinic Is Blocked Until "+scheduling.ClinicTime(clinic, *until)+" Due To Missed Appointments")
+}
+
+// noShowRestriction returns till when policy of clinic restricts user, nil when it doesnt
+func (service *AppointmentService) noShowRestriction(ctx context.Context, userID primitive.ObjectID, clinic models.Clinic, now time.Time) (*time.Time, error) {
+	policy := clinic.BookingRules.NoShowPolicy
+
+	filter := bson.M{
+		"user":            userID,
+		"appointmentDate": bson.M{"$gte": now.AddDate(0, 0, -int(policy.WindowDays)).UTC()},
+	}
+	if !policy.AcrossClinics {
+		filter["clinic"] = clinic.ID
+	}
+	records, err := service.Repo.GetNoShows(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < int(policy.Threshold) {
+		return nil, nil
+	}
+
+	//records are latest first, without a fixed restriction it lasts until the oldest counted no show leaves the window
+	until := records[policy.Threshold-1].AppointmentDate.AddDate(0, 0, int(policy.WindowDays))
+	if policy.RestrictionDays > 0 {
+		until = records[0].AppointmentDate.AddDate(0, 0, int(policy.RestrictionDays))
+	}
+	if !now.Before(until) {
+		return nil, nil
+	}
+	return &until, nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	utils "AlShifa/Utils"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// noShowsAgo returns no show records of appointments missed the given days ago, latest first
+func noShowsAgo(days ...int) []models.NoShowRecord {
+	records := make([]models.NoShowRecord, 0, len(days))
+	for _, day := range days {
+		records = append(records, models.NoShowRecord{ID: primitive.NewObjectID(), AppointmentDate: time.Now().AddDate(0, 0, -day)})
+	}
+	return records
+}
+
+func TestBookingNoShowPolicy(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		Policy             models.NoShowPolicy
+		NoShows            []models.NoShowRecord
+		ExpectedStatusCode int
+	}{
+		{
+			Name:               "Booking allowed below threshold",
+			Policy:             models.NoShowPolicy{Threshold: 3, WindowDays: 90, Action: utils.NoShowActionBlock, RestrictionDays: 30},
+			NoShows:            noShowsAgo(5, 40),
+			ExpectedStatusCode: 0,
+		},
+		{
+			Name:               "Booking blocked after threshold",
+			Policy:             models.NoShowPolicy{Threshold: 3, WindowDays: 90, Action: utils.NoShowActionBlock, RestrictionDays: 30},
+			NoShows:            noShowsAgo(5, 40, 80),
+			ExpectedStatusCode: http.StatusForbidden,
+		},
+		{
+			Name:               "Booking allowed once restriction days are over",
+			Policy:             models.NoShowPolicy{Threshold: 3, WindowDays: 90, Action: utils.NoShowActionBlock, RestrictionDays: 30},
+			NoShows:            noShowsAgo(31, 40, 80),
+			ExpectedStatusCode: 0,
+		},
+		{
+			Name:               "Restriction without days lasts while no shows stay in window",
+			Policy:             models.NoShowPolicy{Threshold: 2, WindowDays: 90, Action: utils.NoShowActionBlock},
+			NoShows:            noShowsAgo(60, 85),
+			ExpectedStatusCode: http.StatusForbidden,
+		},
+		{
+			Name:               "Prepayment required after threshold",
+			Policy:             models.NoShowPolicy{Threshold: 3, WindowDays: 90, Action: utils.NoShowActionPrepay, RestrictionDays: 30},
+			NoShows:            noShowsAgo(5, 40, 80),
+			ExpectedStatusCode: http.StatusPaymentRequired,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var noShowFilter bson.M
+			mockRepo := &MockAppointmentRepo{
+				GetDoctorFn: func(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error) {
+					return ReturnDummyDoctor(doctorID), nil
+				},
+				GetClinicFn: func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+					return models.Clinic{ID: clinicID, BookingRules: models.BookingRules{NoShowPolicy: tc.Policy}}, nil
+				},
+				GetNoShowsFn: func(ctx context.Context, filter bson.M) ([]models.NoShowRecord, error) {
+					noShowFilter = filter
+					return tc.NoShows, nil
+				},
+				GetLeavesFn: func(ctx context.Context, filter bson.M) ([]models.Leave, error) {
+					return nil, nil
+				},
+				GetReservationsFn: func(ctx context.Context, filter bson.M) ([]models.SlotReservation, error) {
+					return nil, nil
+				},
+				BookAppointmentFn: func(ctx context.Context, appointment models.Appointment, reservation models.SlotReservation, capacity int) error {
+					return nil
+				},
+			}
+			appointmentService := NewAppointmentService(mockRepo, nil, nil)
+
+			appointment := ReturnDummyAppointment()
+			_, appErr := appointmentService.BookAppointment(context.Background(), primitive.NewObjectID(), appointment)
+
+			statusCode := 0
+			if appErr != nil {
+				statusCode = appErr.StatusCode
+			}
+			if statusCode != tc.ExpectedStatusCode {
+				t.Fatalf("expected status %d got %d (%v)", tc.ExpectedStatusCode, statusCode, appErr)
+			}
+			//policy of a clinic counts only no shows at that clinic unless it says otherwise
+			if noShowFilter["clinic"] != dummyClinicID {
+				t.Errorf("expected no shows to be counted at clinic got filter %v", noShowFilter)
+			}
+		})
+	}
+}
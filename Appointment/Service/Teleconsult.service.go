@@ -0,0 +1,113 @@
+package service
+
+import (
+	appointmentStructs "AlShifa/Appointment/Structs"
+	"AlShifa/Clinic/models"
+	scheduling "AlShifa/Scheduling"
+	structs "AlShifa/Structs"
+	teleconsult "AlShifa/Teleconsult"
+	utils "AlShifa/Utils"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SetSessionMode sets how session of doctor at clinic is consulted. appointments already booked keep their mode
+func (service *AppointmentService) SetSessionMode(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID, sessionMode appointmentStructs.SessionMode) *structs.IAppError {
+	if err := service.Repo.SetSessionMode(ctx, doctorID, clinicID, sessionMode.Mode, sessionMode.OnlineHours); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return utils.ReturnAppError(err, http.StatusNotFound, "Session Not Found", "Doctor Has No Session At This Clinic")
+		}
+		return utils.ReturnAppError(err, http.StatusInternalServerError, "Failed To Update Session", "Server Error")
+	}
+	return nil
+}
+
+// GetJoinLink returns link for patient or doctor of a video appointment to join its room. the link opens a little before
+// the slot and closes a while after it ends so it is of no use for any other consultation
+func (service *AppointmentService) GetJoinLink(ctx context.Context, actorID primitive.ObjectID, actorRole string, appointmentID primitive.ObjectID) (*appointmentStructs.JoinLink, *structs.IAppError) {
+	appointment, appErr := service.getAppointmentOfActor(ctx, actorID, actorRole, appointmentID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	participant := teleconsult.ParticipantPatient
+	switch actorRole {
+	case utils.RoleUser:
+	case utils.RoleDoctor:
+		participant = teleconsult.ParticipantDoctor
+	default:
+		return nil, utils.ReturnAppError(errors.New("not a participant"), http.StatusForbidden, "Cannot Join Consultation", "Only Doctor And Patient Can Join")
+	}
+
+	if consultationMode(appointment) != utils.ConsultationModeVideo || appointment.VideoRoom == "" {
+		return nil, utils.ReturnAppError(errors.New("not a video appointment"), http.StatusBadRequest, "Not A Video Appointment", "Appointment Is Consulted In Clinic")
+	}
+	if !joinable(appointment.Status) {
+		return nil, utils.ReturnAppError(errors.New("appointment not joinable"), http.StatusBadRequest, "Cannot Join Consultation", "Appointment Is "+appointment.Status)
+	}
+	if service.Rooms == nil {
+		return nil, utils.ReturnAppError(errors.New("no room provider"), http.StatusServiceUnavailable, "Video Consultations Unavailable", "Video Consultations Are Not Configured")
+	}
+
+	doctor, err := service.Repo.GetDoctor(ctx, appointment.Doctor)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Doctor", "Server Error")
+	}
+
+	validFrom := appointment.AppointmentDate.Add(-utils.JoinLinkOpensBefore)
+	validTill := appointment.AppointmentDate.Add(scheduling.SlotDuration(doctor) + utils.JoinLinkGrace)
+	if !time.Now().Before(validTill) {
+		return nil, utils.ReturnAppError(errors.New("consultation over"), http.StatusGone, "Consultation Has Ended", "Join Link Is No Longer Available")
+	}
+
+	url, err := service.Rooms.JoinURL(appointment.VideoRoom, participant, validFrom, validTill)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusBadGateway, "Unable To Create Join Link", "Video Provider Error")
+	}
+
+	return &appointmentStructs.JoinLink{
+		URL:         url,
+		Room:        appointment.VideoRoom,
+		Participant: participant,
+		ValidFrom:   validFrom.UTC(),
+		ValidTill:   validTill.UTC(),
+	}, nil
+}
+
+// openRoom creates video room of appointment at room provider, in clinic appointments need none
+func (service *AppointmentService) openRoom(ctx context.Context, appointment *models.Appointment) *structs.IAppError {
+	if appointment.Mode != utils.ConsultationModeVideo {
+		return nil
+	}
+	if service.Rooms == nil {
+		return utils.ReturnAppError(errors.New("no room provider"), http.StatusServiceUnavailable, "Video Consultations Unavailable", "Video Consultations Are Not Configured")
+	}
+
+	room, err := service.Rooms.CreateRoom(ctx, *appointment)
+	if err != nil {
+		return utils.ReturnAppError(err, http.StatusBadGateway, "Booking Failed", "Unable To Create Video Room")
+	}
+	appointment.VideoRoom = room
+	return nil
+}
+
+// consultationMode returns mode of appointment, appointments booked before modes existed are in clinic
+func consultationMode(appointment models.Appointment) string {
+	if appointment.Mode == "" {
+		return utils.ConsultationModeInClinic
+	}
+	return appointment.Mode
+}
+
+// joinable tells if room of appointment in status can still be joined
+func joinable(status string) bool {
+	return status == utils.AppointmentStatusRequested ||
+		status == utils.AppointmentStatusConfirmed ||
+		status == utils.AppointmentStatusCheckedIn ||
+		status == utils.AppointmentStatusInConsultation
+}
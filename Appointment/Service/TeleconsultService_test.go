@@ -0,0 +1,165 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	teleconsult "AlShifa/Teleconsult"
+	utils "AlShifa/Utils"
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var dummyRooms = &teleconsult.LocalRoomProvider{BaseURL: "http://localhost/v1/teleconsult/room", Secret: []byte("secret")}
+
+func TestBookVideoAppointment(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		SessionMode        string
+		Rooms              teleconsult.RoomProvider
+		ExpectedStatusCode int
+	}{
+		{Name: "Video appointment gets a room when session is open for both", SessionMode: utils.ConsultationModeBoth, Rooms: dummyRooms},
+		{Name: "Video appointment refused in an in clinic session", SessionMode: "", Rooms: dummyRooms, ExpectedStatusCode: http.StatusBadRequest},
+		{Name: "Video appointment refused without room provider", SessionMode: utils.ConsultationModeVideo, Rooms: nil, ExpectedStatusCode: http.StatusServiceUnavailable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			mockRepo := &MockAppointmentRepo{
+				GetDoctorFn: func(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error) {
+					doctor := ReturnDummyDoctor(doctorID)
+					doctor.Clinics[0].Mode = tc.SessionMode
+					return doctor, nil
+				},
+				GetClinicFn: func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+					return models.Clinic{ID: clinicID}, nil
+				},
+				GetLeavesFn: func(ctx context.Context, filter bson.M) ([]models.Leave, error) {
+					return nil, nil
+				},
+				GetReservationsFn: func(ctx context.Context, filter bson.M) ([]models.SlotReservation, error) {
+					return nil, nil
+				},
+				BookAppointmentFn: func(ctx context.Context, appointment models.Appointment, reservation models.SlotReservation, capacity int) error {
+					return nil
+				},
+			}
+
+			request := ReturnDummyAppointment()
+			request.Mode = utils.ConsultationModeVideo
+			appointment, err := NewAppointmentService(mockRepo, nil, tc.Rooms).BookAppointment(context.Background(), primitive.NewObjectID(), request)
+
+			if tc.ExpectedStatusCode != 0 {
+				if err == nil || err.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status %d got %v", tc.ExpectedStatusCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			if appointment.Mode != utils.ConsultationModeVideo || appointment.VideoRoom != "local-"+appointment.ID.Hex() {
+				t.Fatalf("expected video appointment with room got %+v", appointment)
+			}
+		})
+	}
+}
+
+func TestGetJoinLink(t *testing.T) {
+	doctorID := primitive.NewObjectID()
+	patientID := primitive.NewObjectID()
+	start := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	testCases := []struct {
+		Name                string
+		ActorID             primitive.ObjectID
+		ActorRole           string
+		Appointment         models.Appointment
+		ExpectedParticipant string
+		ExpectedStatusCode  int
+	}{
+		{
+			Name:                "Patient gets patient link",
+			ActorID:             patientID,
+			ActorRole:           utils.RoleUser,
+			Appointment:         models.Appointment{AppointmentDate: start, Status: utils.AppointmentStatusConfirmed, Mode: utils.ConsultationModeVideo, VideoRoom: "local-room"},
+			ExpectedParticipant: teleconsult.ParticipantPatient,
+		},
+		{
+			Name:                "Doctor gets doctor link",
+			ActorID:             doctorID,
+			ActorRole:           utils.RoleDoctor,
+			Appointment:         models.Appointment{AppointmentDate: start, Status: utils.AppointmentStatusInConsultation, Mode: utils.ConsultationModeVideo, VideoRoom: "local-room"},
+			ExpectedParticipant: teleconsult.ParticipantDoctor,
+		},
+		{
+			Name:               "Someone else cant get a link",
+			ActorID:            primitive.NewObjectID(),
+			ActorRole:          utils.RoleUser,
+			Appointment:        models.Appointment{AppointmentDate: start, Status: utils.AppointmentStatusConfirmed, Mode: utils.ConsultationModeVideo, VideoRoom: "local-room"},
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Name:               "In clinic appointment has no link",
+			ActorID:            patientID,
+			ActorRole:          utils.RoleUser,
+			Appointment:        models.Appointment{AppointmentDate: start, Status: utils.AppointmentStatusConfirmed},
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:               "Cancelled appointment has no link",
+			ActorID:            patientID,
+			ActorRole:          utils.RoleUser,
+			Appointment:        models.Appointment{AppointmentDate: start, Status: utils.AppointmentStatusCancelled, Mode: utils.ConsultationModeVideo, VideoRoom: "local-room"},
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			Name:               "Link is gone once consultation is over",
+			ActorID:            patientID,
+			ActorRole:          utils.RoleUser,
+			Appointment:        models.Appointment{AppointmentDate: time.Now().Add(-2 * time.Hour), Status: utils.AppointmentStatusConfirmed, Mode: utils.ConsultationModeVideo, VideoRoom: "local-room"},
+			ExpectedStatusCode: http.StatusGone,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Appointment.ID = primitive.NewObjectID()
+			tc.Appointment.Doctor = doctorID
+			tc.Appointment.User = patientID
+			mockRepo := &MockAppointmentRepo{
+				GetAppointmentFn: func(ctx context.Context, filter bson.M) (models.Appointment, error) {
+					return tc.Appointment, nil
+				},
+				GetDoctorFn: func(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error) {
+					return ReturnDummyDoctor(doctorID), nil
+				},
+			}
+
+			link, err := NewAppointmentService(mockRepo, nil, dummyRooms).GetJoinLink(context.Background(), tc.ActorID, tc.ActorRole, tc.Appointment.ID)
+			if tc.ExpectedStatusCode != 0 {
+				if err == nil || err.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status %d got %v", tc.ExpectedStatusCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+
+			//link opens before the slot and only for the participant it was made for
+			if !link.ValidFrom.Equal(start.Add(-utils.JoinLinkOpensBefore)) || link.Participant != tc.ExpectedParticipant {
+				t.Fatalf("unexpected link %+v", link)
+			}
+			parsed, _ := url.Parse(link.URL)
+			if _, verifyErr := dummyRooms.Verify("local-room", parsed.Query(), start); verifyErr != nil {
+				t.Fatalf("expected link to open room got %v", verifyErr)
+			}
+		})
+	}
+}
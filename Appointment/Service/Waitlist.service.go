@@ -0,0 +1,315 @@
+package service
+
+import (
+	interfaces "AlShifa/Appointment/Interfaces"
+	"AlShifa/Clinic/models"
+	scheduling "AlShifa/Scheduling"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// JoinWaitlist puts user on waitlist of doctor at clinic for the date, it is only allowed when every remaining slot of that day is full
+func (service *AppointmentService) JoinWaitlist(ctx context.Context, userID primitive.ObjectID, doctorID primitive.ObjectID, clinicID primitive.ObjectID, date time.Time) (*models.WaitlistEntry, *structs.IAppError) {
+	doctor, clinic, appErr := service.getDoctorAtClinic(ctx, doctorID, clinicID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if appErr := service.checkNoShowPolicy(ctx, userID, clinic); appErr != nil {
+		return nil, appErr
+	}
+
+	loc := scheduling.ClinicLocation(clinic)
+	day := clinicDay(clinic, date)
+	slots, appErr := service.doctorSlots(ctx, doctor, clinic, day, day)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	upcoming := 0
+	for _, slot := range slots {
+		if slot.Start.Before(time.Now()) {
+			continue
+		}
+		upcoming++
+		if slot.Available {
+			return nil, utils.ReturnAppError(errors.New("slots available"), http.StatusConflict, "Slots Are Still Available", "Book An Available Slot Instead")
+		}
+	}
+	if upcoming == 0 {
+		return nil, utils.ReturnAppError(errors.New("no upcoming slots"), http.StatusBadRequest, "Cannot Join Waitlist", "Doctor Has No Upcoming Slots On This Day")
+	}
+
+	dayKey := scheduling.DayKey(day, loc)
+	_, err := service.Repo.GetWaitlistEntry(ctx, bson.M{
+		"user":   userID,
+		"doctor": doctorID,
+		"clinic": clinicID,
+		"date":   dayKey,
+		"status": bson.M{"$in": bson.A{utils.WaitlistStatusWaiting, utils.WaitlistStatusOffered}},
+	})
+	if err == nil {
+		return nil, utils.ReturnAppError(errors.New("already on waitlist"), http.StatusConflict, "Already On Waitlist", "You Are Already Waiting For This Day")
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Failed To Join Waitlist", "Server Error")
+	}
+
+	entry := models.WaitlistEntry{
+		ID:        primitive.NewObjectID(),
+		Doctor:    doctorID,
+		Clinic:    clinicID,
+		User:      userID,
+		Date:      dayKey,
+		CreatedAt: time.Now().UTC(),
+		Status:    utils.WaitlistStatusWaiting,
+	}
+	if err := service.Repo.InsertWaitlistEntry(ctx, entry); err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Failed To Join Waitlist", "Server Error")
+	}
+
+	return &entry, nil
+}
+
+// GetUserWaitlist returns waitlist entries of user, offered entries carry the slot which is held for the user
+func (service *AppointmentService) GetUserWaitlist(ctx context.Context, userID primitive.ObjectID) ([]models.WaitlistEntry, *structs.IAppError) {
+	entries, err := service.Repo.GetWaitlistEntries(ctx, bson.M{"user": userID})
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Waitlist", "Server Error")
+	}
+	return entries, nil
+}
+
+// LeaveWaitlist removes user from waitlist, a pending offer is given up and passed to the next patient
+func (service *AppointmentService) LeaveWaitlist(ctx context.Context, userID primitive.ObjectID, entryID primitive.ObjectID) *structs.IAppError {
+	entry, appErr := service.getWaitlistEntryOfUser(ctx, userID, entryID)
+	if appErr != nil {
+		return appErr
+	}
+
+	if entry.Status != utils.WaitlistStatusWaiting && entry.Status != utils.WaitlistStatusOffered {
+		return utils.ReturnAppError(errors.New("waitlist entry closed"), http.StatusBadRequest, "Cannot Leave Waitlist", "Waitlist Entry Is Already "+entry.Status)
+	}
+
+	if err := service.Repo.SetWaitlistStatus(ctx, entry.ID, entry.Status, utils.WaitlistStatusLeft); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return utils.ReturnAppError(err, http.StatusConflict, "Waitlist Entry Was Updated Meanwhile", "Status Changed, Please Retry")
+		}
+		return utils.ReturnAppError(err, http.StatusInternalServerError, "Failed To Leave Waitlist", "Server Error")
+	}
+
+	if entry.Offer != nil {
+		service.passOfferOn(ctx, entry)
+	}
+	return nil
+}
+
+// AcceptWaitlistOffer books the slot offered to user, it fails with conflict once the confirmation window is over
+func (service *AppointmentService) AcceptWaitlistOffer(ctx context.Context, userID primitive.ObjectID, entryID primitive.ObjectID) (*models.Appointment, *structs.IAppError) {
+	entry, appErr := service.getWaitlistEntryOfUser(ctx, userID, entryID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if entry.Status != utils.WaitlistStatusOffered || entry.Offer == nil {
+		return nil, utils.ReturnAppError(errors.New("no pending offer"), http.StatusBadRequest, "No Pending Offer", "Waitlist Entry Is "+entry.Status)
+	}
+	if !entry.Offer.ExpiresAt.After(time.Now()) {
+		return nil, offerExpiredError(errors.New("offer expired"))
+	}
+
+	now := time.Now().UTC()
+	appointment := models.Appointment{
+		ID:               primitive.NewObjectID(),
+		AppointmentDate:  entry.Offer.AppointmentDate,
+		RegistrationDate: now,
+		Status:           utils.AppointmentStatusRequested,
+		Clinic:           entry.Clinic,
+		User:             entry.User,
+		Doctor:           entry.Doctor,
+		Slot:             entry.Offer.Slot,
+		Mode:             entry.Offer.Mode,
+		StatusHistory: []models.StatusTransition{{
+			At:        now,
+			To:        utils.AppointmentStatusRequested,
+			Actor:     userID,
+			ActorRole: utils.RoleUser,
+			Reason:    "Booked from waitlist",
+		}},
+	}
+	if appErr := service.openRoom(ctx, &appointment); appErr != nil {
+		return nil, appErr
+	}
+
+	if err := service.Repo.AcceptWaitlistOffer(ctx, entry, appointment); err != nil {
+		if errors.Is(err, interfaces.ErrHoldExpired) || err == mongo.ErrNoDocuments {
+			return nil, offerExpiredError(err)
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Failed To Accept Offer", "Server Error")
+	}
+
+	return &appointment, nil
+}
+
+// ExpireWaitlistOffers closes offers whose confirmation window is over and offers their slot to the next patient
+func (service *AppointmentService) ExpireWaitlistOffers(ctx context.Context) error {
+	entries, err := service.Repo.GetWaitlistEntries(ctx, bson.M{
+		"status":          utils.WaitlistStatusOffered,
+		"offer.expiresAt": bson.M{"$lte": time.Now().UTC()},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := service.Repo.SetWaitlistStatus(ctx, entry.ID, utils.WaitlistStatusOffered, utils.WaitlistStatusExpired); err != nil {
+			//accepted or left meanwhile
+			if err == mongo.ErrNoDocuments {
+				continue
+			}
+			return err
+		}
+		service.passOfferOn(ctx, entry)
+	}
+	return nil
+}
+
+// RunWaitlistSweeper expires waitlist offers every period until ctx is done
+func (service *AppointmentService) RunWaitlistSweeper(ctx context.Context, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sweepCtx, cancel := context.WithTimeout(ctx, utils.RequestTimeout)
+			if err := service.ExpireWaitlistOffers(sweepCtx); err != nil {
+				log.Println("failed to expire waitlist offers:", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// promoteWaitlist offers the seat freed by appointment to the first patient waiting for that day.
+// the status change has already happened so failures are only logged
+func (service *AppointmentService) promoteWaitlist(ctx context.Context, appointment models.Appointment) {
+	if err := service.offerSlot(ctx, appointment.Doctor, appointment.Clinic, appointment.AppointmentDate, appointment.Slot); err != nil {
+		log.Println("failed to offer freed slot to waitlist:", err)
+	}
+}
+
+// passOfferOn releases the seat held for entry and offers it to the next patient
+func (service *AppointmentService) passOfferOn(ctx context.Context, entry models.WaitlistEntry) {
+	if err := service.Repo.ReleaseHold(ctx, entry.Offer.Hold); err != nil {
+		log.Println("failed to release waitlist hold:", err)
+		return
+	}
+	if err := service.offerSlot(ctx, entry.Doctor, entry.Clinic, entry.Offer.AppointmentDate, entry.Offer.Slot); err != nil {
+		log.Println("failed to pass waitlist offer on:", err)
+	}
+}
+
+// offerSlot holds a seat of slot starting at start and offers it to the oldest waiting patient of that day.
+// the offer lasts for the offer window but never beyond start of the slot
+func (service *AppointmentService) offerSlot(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID, start time.Time, slotIndex int8) error {
+	now := time.Now().UTC()
+	if !start.After(now) {
+		return nil
+	}
+
+	doctor, clinic, appErr := service.getDoctorAtClinic(ctx, doctorID, clinicID)
+	if appErr != nil {
+		return appErr
+	}
+
+	//slot may have been blocked by a leave or taken directly meanwhile
+	day := start.In(scheduling.ClinicLocation(clinic))
+	slots, appErr := service.doctorSlots(ctx, doctor, clinic, day, day)
+	if appErr != nil {
+		return appErr
+	}
+	slot, found := findSlot(slots, slotIndex)
+	if !found || !slot.Available || !slot.Start.Equal(start) {
+		return nil
+	}
+
+	expiresAt := earliestTime(now.Add(utils.WaitlistOfferWindow), start)
+	reservation, capacity := seatOf(doctor, clinic, slot)
+
+	holdID, err := service.Repo.HoldSeat(ctx, reservation, capacity, expiresAt.Sub(now))
+	if err != nil {
+		//someone booked the seat directly before it could be offered
+		if errors.Is(err, interfaces.ErrSlotFull) {
+			return nil
+		}
+		return err
+	}
+
+	entry, err := service.Repo.ClaimNextWaitlistEntry(ctx, bson.M{
+		"doctor": doctorID,
+		"clinic": clinicID,
+		"date":   reservation.Date,
+	}, models.WaitlistOffer{
+		AppointmentDate: start.UTC(),
+		ExpiresAt:       expiresAt,
+		Hold:            holdID,
+		Slot:            slotIndex,
+		Mode:            offerMode(slot),
+	})
+	if err != nil {
+		if releaseErr := service.Repo.ReleaseHold(ctx, holdID); releaseErr != nil {
+			log.Println("failed to release waitlist hold:", releaseErr)
+		}
+		//nobody is waiting
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		return err
+	}
+
+	service.notify(ctx, entry.User, "Slot Available", fmt.Sprintf("A slot on %s is held for you, accept it before %s", scheduling.ClinicTime(clinic, start), scheduling.ClinicTime(clinic, expiresAt)))
+	return nil
+}
+
+func (service *AppointmentService) getWaitlistEntryOfUser(ctx context.Context, userID primitive.ObjectID, entryID primitive.ObjectID) (models.WaitlistEntry, *structs.IAppError) {
+	entry, err := service.Repo.GetWaitlistEntry(ctx, bson.M{"_id": entryID, "user": userID})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return models.WaitlistEntry{}, utils.ReturnAppError(err, http.StatusNotFound, "Waitlist Entry Not Found", "Waitlist Entry Doesnt Exist")
+		}
+		return models.WaitlistEntry{}, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Waitlist Entry", "Server Error")
+	}
+	return entry, nil
+}
+
+// offerMode returns mode waitlisted patient gets the slot in, a slot open for both is offered in clinic
+func offerMode(slot scheduling.Slot) string {
+	if slot.Mode == utils.ConsultationModeVideo {
+		return utils.ConsultationModeVideo
+	}
+	return utils.ConsultationModeInClinic
+}
+
+func earliestTime(a time.Time, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func offerExpiredError(err error) *structs.IAppError {
+	return utils.ReturnAppError(err, http.StatusConflict, "Offer Expired", "Confirmation Window Is Over")
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	scheduling "AlShifa/Scheduling"
+	utils "AlShifa/Utils"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// tomorrowsSlot returns start of slot with index of dummy doctor tomorrow
+func tomorrowsSlot(index int8) time.Time {
+	loc := scheduling.ClinicLocation(models.Clinic{})
+	now := time.Now().In(loc)
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, loc).Add(time.Duration(index) * utils.DefaultSlotMinutes * time.Minute)
+}
+
+// returnFreeDay stubs reservations and leaves so every slot of dummy doctor is free
+func returnFreeDay(mockRepo *MockAppointmentRepo) *MockAppointmentRepo {
+	mockRepo.GetReservationsFn = func(ctx context.Context, filter bson.M) ([]models.SlotReservation, error) {
+		return nil, nil
+	}
+	mockRepo.GetLeavesFn = func(ctx context.Context, filter bson.M) ([]models.Leave, error) {
+		return nil, nil
+	}
+	return mockRepo
+}
+
+func TestCancelOffersSlotToWaitlist(t *testing.T) {
+	userID := primitive.NewObjectID()
+	holdID := primitive.NewObjectID()
+	slotStart := tomorrowsSlot(10)
+
+	testCases := []struct {
+		Name            string
+		Waiting         bool
+		ExpectedRelease bool
+	}{
+		{Name: "Freed slot is offered to first waiting patient", Waiting: true, ExpectedRelease: false},
+		{Name: "Hold is released when nobody is waiting", Waiting: false, ExpectedRelease: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var offered *models.WaitlistOffer
+			released := false
+			mockRepo := returnFreeDay(&MockAppointmentRepo{
+				GetAppointmentFn: func(ctx context.Context, filter bson.M) (models.Appointment, error) {
+					return models.Appointment{ID: filter["_id"].(primitive.ObjectID), User: userID, Doctor: primitive.NewObjectID(), Clinic: dummyClinicID, AppointmentDate: slotStart, Slot: 10, Status: utils.AppointmentStatusConfirmed}, nil
+				},
+				TransitionAppointmentStatusFn: func(ctx context.Context, appointmentID primitive.ObjectID, from string, transition models.StatusTransition, releaseSlot bool) error {
+					return nil
+				},
+				GetDoctorFn: func(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error) {
+					return ReturnDummyDoctor(doctorID), nil
+				},
+				GetClinicFn: func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+					return models.Clinic{ID: clinicID}, nil
+				},
+				HoldSeatFn: func(ctx context.Context, reservation models.SlotReservation, capacity int, ttl time.Duration) (primitive.ObjectID, error) {
+					if reservation.Slot != 10 || ttl <= 0 || ttl > utils.WaitlistOfferWindow {
+						t.Fatalf("unexpected hold of slot %d for %v", reservation.Slot, ttl)
+					}
+					return holdID, nil
+				},
+				ClaimNextWaitlistEntryFn: func(ctx context.Context, filter bson.M, offer models.WaitlistOffer) (models.WaitlistEntry, error) {
+					if !tc.Waiting {
+						return models.WaitlistEntry{}, mongo.ErrNoDocuments
+					}
+					offered = &offer
+					return models.WaitlistEntry{Status: utils.WaitlistStatusOffered, Offer: &offer}, nil
+				},
+				ReleaseHoldFn: func(ctx context.Context, id primitive.ObjectID) error {
+					released = id == holdID
+					return nil
+				},
+			})
+
+			service := NewAppointmentService(mockRepo, nil, nil)
+			if err := service.CancelAppointment(context.Background(), userID, primitive.NewObjectID()); err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+
+			if released != tc.ExpectedRelease {
+				t.Fatalf("expected hold released %v but got %v", tc.ExpectedRelease, released)
+			}
+			if tc.Waiting && (offered == nil || offered.Hold != holdID || !offered.AppointmentDate.Equal(slotStart) || offered.ExpiresAt.After(slotStart)) {
+				t.Fatalf("slot not offered properly %+v", offered)
+			}
+		})
+	}
+}
+
+func TestAcceptWaitlistOffer(t *testing.T) {
+	userID := primitive.NewObjectID()
+
+	testCases := []struct {
+		Name               string
+		Entry              models.WaitlistEntry
+		ExpectedStatusCode int
+	}{
+		{
+			Name:  "Offer accepted within confirmation window",
+			Entry: models.WaitlistEntry{Status: utils.WaitlistStatusOffered, Offer: &models.WaitlistOffer{ExpiresAt: time.Now().Add(time.Minute), Slot: 3}},
+		},
+		{
+			Name:               "Offer cant be accepted after confirmation window",
+			Entry:              models.WaitlistEntry{Status: utils.WaitlistStatusOffered, Offer: &models.WaitlistOffer{ExpiresAt: time.Now().Add(-time.Minute)}},
+			ExpectedStatusCode: http.StatusConflict,
+		},
+		{
+			Name:               "Waiting entry has nothing to accept",
+			Entry:              models.WaitlistEntry{Status: utils.WaitlistStatusWaiting},
+			ExpectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			mockRepo := &MockAppointmentRepo{
+				GetWaitlistEntryFn: func(ctx context.Context, filter bson.M) (models.WaitlistEntry, error) {
+					entry := tc.Entry
+					entry.ID = filter["_id"].(primitive.ObjectID)
+					entry.User = filter["user"].(primitive.ObjectID)
+					return entry, nil
+				},
+				AcceptWaitlistOfferFn: func(ctx context.Context, entry models.WaitlistEntry, appointment models.Appointment) error {
+					return nil
+				},
+			}
+
+			service := NewAppointmentService(mockRepo, nil, nil)
+			appointment, err := service.AcceptWaitlistOffer(context.Background(), userID, primitive.NewObjectID())
+
+			if tc.ExpectedStatusCode != 0 {
+				if err == nil || err.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status code %d but got %v", tc.ExpectedStatusCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+			if appointment.User != userID || appointment.Slot != tc.Entry.Offer.Slot || appointment.Status != utils.AppointmentStatusRequested {
+				t.Fatalf("appointment not created from offer %+v", appointment)
+			}
+		})
+	}
+}
+
+func TestExpireWaitlistOffersPassesSlotOn(t *testing.T) {
+	expiredHold := primitive.NewObjectID()
+	entry := models.WaitlistEntry{
+		ID:     primitive.NewObjectID(),
+		Doctor: primitive.NewObjectID(),
+		Clinic: dummyClinicID,
+		Status: utils.WaitlistStatusOffered,
+		Offer:  &models.WaitlistOffer{AppointmentDate: tomorrowsSlot(7), ExpiresAt: time.Now().Add(-time.Second), Hold: expiredHold, Slot: 7},
+	}
+
+	var expired, released, offeredAgain bool
+	mockRepo := returnFreeDay(&MockAppointmentRepo{
+		GetWaitlistEntriesFn: func(ctx context.Context, filter bson.M) ([]models.WaitlistEntry, error) {
+			return []models.WaitlistEntry{entry}, nil
+		},
+		SetWaitlistStatusFn: func(ctx context.Context, entryID primitive.ObjectID, from string, to string) error {
+			expired = entryID == entry.ID && to == utils.WaitlistStatusExpired
+			return nil
+		},
+		ReleaseHoldFn: func(ctx context.Context, holdID primitive.ObjectID) error {
+			released = holdID == expiredHold
+			return nil
+		},
+		GetDoctorFn: func(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error) {
+			return ReturnDummyDoctor(doctorID), nil
+		},
+		GetClinicFn: func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+			return models.Clinic{ID: clinicID}, nil
+		},
+		HoldSeatFn: func(ctx context.Context, reservation models.SlotReservation, capacity int, ttl time.Duration) (primitive.ObjectID, error) {
+			return primitive.NewObjectID(), nil
+		},
+		ClaimNextWaitlistEntryFn: func(ctx context.Context, filter bson.M, offer models.WaitlistOffer) (models.WaitlistEntry, error) {
+			offeredAgain = offer.Slot == entry.Offer.Slot
+			return models.WaitlistEntry{}, nil
+		},
+	})
+
+	service := NewAppointmentService(mockRepo, nil, nil)
+	if err := service.ExpireWaitlistOffers(context.Background()); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if !expired || !released || !offeredAgain {
+		t.Fatalf("expected offer expired %v, hold released %v and slot offered again %v", expired, released, offeredAgain)
+	}
+}
+
+// dates come in as midnight UTC, at a clinic west of UTC they should still be the same calendar day and not the one before
+func TestBookingDayAtClinicWestOfUTC(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+	now := time.Now().In(loc)
+	day := time.Date(now.Year(), now.Month(), now.Day()+2, 0, 0, 0, 0, loc)
+	date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+
+	clinic := models.Clinic{ID: dummyClinicID, TimeZone: "America/New_York"}
+	doctorID := primitive.NewObjectID()
+	doctor := models.Doctor{
+		ID: doctorID,
+		Clinics: []models.ClinicDetails{{
+			Clinic:      dummyClinicID,
+			StartTime:   day,
+			EndTime:     day.Add(24*time.Hour - utils.DefaultSlotMinutes*time.Minute),
+			WorkingDays: []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"},
+		}},
+	}
+	mockRepo := func() *MockAppointmentRepo {
+		return returnFreeDay(&MockAppointmentRepo{
+			GetDoctorFn: func(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error) {
+				return doctor, nil
+			},
+			GetClinicFn: func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+				return clinic, nil
+			},
+		})
+	}
+
+	t.Run("Booked slot is on the requested day", func(t *testing.T) {
+		repo := mockRepo()
+		repo.BookAppointmentFn = func(ctx context.Context, appointment models.Appointment, reservation models.SlotReservation, capacity int) error {
+			if !reservation.Date.Equal(date) {
+				t.Fatalf("expected reservation on %s but got %s", date, reservation.Date)
+			}
+			return nil
+		}
+
+		service := NewAppointmentService(repo, nil, nil)
+		appointment, appErr := service.BookAppointment(context.Background(), primitive.NewObjectID(), models.Appointment{AppointmentDate: date, Clinic: dummyClinicID, Doctor: doctorID, Slot: 40})
+		if appErr != nil {
+			t.Fatalf("expected no error but got %v", appErr)
+		}
+		if expected := day.Add(10 * time.Hour); !appointment.AppointmentDate.Equal(expected) {
+			t.Fatalf("expected appointment at %s but got %s", expected, appointment.AppointmentDate.In(loc))
+		}
+	})
+
+	t.Run("Waitlist is joined for the requested day", func(t *testing.T) {
+		repo := mockRepo()
+		//every slot of the requested day is full
+		repo.GetReservationsFn = func(ctx context.Context, filter bson.M) ([]models.SlotReservation, error) {
+			reservations := []models.SlotReservation{}
+			for slot := int8(0); slot < 96; slot++ {
+				reservations = append(reservations, models.SlotReservation{Date: date, Slot: slot})
+			}
+			return reservations, nil
+		}
+		repo.GetWaitlistEntryFn = func(ctx context.Context, filter bson.M) (models.WaitlistEntry, error) {
+			return models.WaitlistEntry{}, mongo.ErrNoDocuments
+		}
+		repo.InsertWaitlistEntryFn = func(ctx context.Context, entry models.WaitlistEntry) error {
+			return nil
+		}
+
+		service := NewAppointmentService(repo, nil, nil)
+		entry, appErr := service.JoinWaitlist(context.Background(), primitive.NewObjectID(), doctorID, dummyClinicID, date)
+		if appErr != nil {
+			t.Fatalf("expected no error but got %v", appErr)
+		}
+		if !entry.Date.Equal(date) {
+			t.Fatalf("expected waitlist entry for %s but got %s", date, entry.Date)
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package structs
+
+import (
+	scheduling "AlShifa/Scheduling"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AvailableSlot is a free slot of a doctor at one of their clinics
+type AvailableSlot struct {
+	scheduling.Slot
+	Clinic     primitive.ObjectID `json:"clinic"`
+	ClinicName string             `json:"clinicName"`
+}
+
+// NextAvailable is the earliest free slots of a doctor across all their clinics, Slots is empty when doctor has none
+// within the horizon
+type NextAvailable struct {
+	Doctor primitive.ObjectID `json:"doctor"`
+	Slots  []AvailableSlot    `json:"slots"`
+}
@@ -0,0 +1,27 @@
+// Package structs contains response structures of the appointment module
+package structs
+
+import (
+	"AlShifa/Clinic/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NoShowOffender is a patient with the number of appointments they missed at a clinic
+type NoShowOffender struct {
+	User       primitive.ObjectID `json:"user" bson:"user"`
+	Name       string             `json:"name" bson:"name"`
+	Mobile     int                `json:"mobile" bson:"mobile"`
+	NoShows    int32              `json:"noShows" bson:"noShows"`
+	LastNoShow time.Time          `json:"lastNoShow" bson:"lastNoShow"`
+}
+
+// NoShowReport lists patients with most no shows at a clinic since Since
+type NoShowReport struct {
+	Clinic    primitive.ObjectID  `json:"clinic"`
+	Since     time.Time           `json:"since"`
+	Total     int64               `json:"total"`
+	Policy    models.NoShowPolicy `json:"policy"`
+	Offenders []NoShowOffender    `json:"offenders"`
+}
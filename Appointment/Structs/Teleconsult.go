@@ -0,0 +1,21 @@
+package structs
+
+import (
+	"AlShifa/Clinic/models"
+	"time"
+)
+
+// SessionMode is sent by a doctor to set how their session at a clinic is consulted
+type SessionMode struct {
+	Mode        string              `json:"mode"`
+	OnlineHours []models.TimeWindow `json:"onlineHours"`
+}
+
+// JoinLink is a signed link for one participant to join the video room of an appointment
+type JoinLink struct {
+	URL         string    `json:"url"`
+	Room        string    `json:"room"`
+	Participant string    `json:"participant"`
+	ValidFrom   time.Time `json:"validFrom"`
+	ValidTill   time.Time `json:"validTill"`
+}
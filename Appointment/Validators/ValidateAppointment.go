@@ -0,0 +1,46 @@
+// Package validators contains validation functions for appointment module
+package validators
+
+import (
+	"AlShifa/Clinic/models"
+	utils "AlShifa/Utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ValidateAppointment validates booking details sent by a user and returns a map of field errors
+func ValidateAppointment(appointment *models.Appointment) map[string]string {
+	errors := make(map[string]string)
+
+	if appointment == nil {
+		errors["appointment"] = "appointment details are required"
+		return errors
+	}
+
+	if appointment.Doctor == primitive.NilObjectID {
+		errors["doctor"] = "doctor is required"
+	}
+
+	if appointment.Clinic == primitive.NilObjectID {
+		errors["clinic"] = "clinic is required"
+	}
+
+	if appointment.Slot < 0 {
+		errors["slot"] = "slot must be a valid slot number"
+	}
+
+	//only the day of appointmentDate matters, exact time comes from the slot
+	if appointment.AppointmentDate.IsZero() {
+		errors["appointmentDate"] = "appointment date is required"
+	}
+
+	//mode is optional and defaults to in clinic
+	if appointment.Mode != "" && appointment.Mode != utils.ConsultationModeInClinic && appointment.Mode != utils.ConsultationModeVideo {
+		errors["mode"] = "mode must be InClinic or Video"
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
@@ -0,0 +1,36 @@
+package validators
+
+import (
+	"AlShifa/Clinic/models"
+	utils "AlShifa/Utils"
+	"fmt"
+)
+
+// ValidateNoShowPolicy validates no show policy sent by a clinic owner, a zero threshold turns the policy off
+func ValidateNoShowPolicy(policy *models.NoShowPolicy) map[string]string {
+	errors := make(map[string]string)
+
+	if policy.Threshold < 0 {
+		errors["threshold"] = "threshold cannot be negative"
+	}
+	if policy.Threshold == 0 {
+		return nil
+	}
+
+	if policy.WindowDays < 1 || policy.WindowDays > utils.MaxNoShowWindowDays {
+		errors["windowDays"] = fmt.Sprintf("window must be between 1 and %d days", utils.MaxNoShowWindowDays)
+	}
+
+	if policy.Action != utils.NoShowActionBlock && policy.Action != utils.NoShowActionPrepay {
+		errors["action"] = "action must be block or prepay"
+	}
+
+	if policy.RestrictionDays < 0 || policy.RestrictionDays > utils.MaxNoShowRestrictionDays {
+		errors["restrictionDays"] = fmt.Sprintf("restriction must be between 0 and %d days", utils.MaxNoShowRestrictionDays)
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
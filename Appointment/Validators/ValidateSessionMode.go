@@ -0,0 +1,32 @@
+package validators
+
+import (
+	appointmentStructs "AlShifa/Appointment/Structs"
+	utils "AlShifa/Utils"
+	"fmt"
+)
+
+// ValidateSessionMode validates consultation mode and online hours of a doctor session and returns a map of field errors
+func ValidateSessionMode(sessionMode *appointmentStructs.SessionMode) map[string]string {
+	errors := make(map[string]string)
+
+	switch sessionMode.Mode {
+	case utils.ConsultationModeInClinic, utils.ConsultationModeVideo, utils.ConsultationModeBoth:
+	default:
+		errors["mode"] = "mode must be InClinic, Video or Both"
+	}
+
+	if len(sessionMode.OnlineHours) > utils.MaxOnlineHours {
+		errors["onlineHours"] = fmt.Sprintf("at most %d online hours can be set", utils.MaxOnlineHours)
+	}
+	for i, window := range sessionMode.OnlineHours {
+		if !window.EndTime.After(window.StartTime) {
+			errors[fmt.Sprintf("onlineHours[%d]", i)] = "end time must be after start time"
+		}
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
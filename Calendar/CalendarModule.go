@@ -0,0 +1,34 @@
+// Package calendar provides iCalendar feeds of appointments for patients and doctors.
+package calendar
+
+import (
+	appointmentInterfaces "AlShifa/Appointment/Interfaces"
+	controller "AlShifa/Calendar/Controller"
+	repository "AlShifa/Calendar/Repository"
+	service "AlShifa/Calendar/Service"
+	internals "AlShifa/Internals"
+	middleware "AlShifa/Middleware"
+	utils "AlShifa/Utils"
+	"context"
+	"log"
+)
+
+// InitialiseCalendarModule registers calendar routes, access to single appointments is checked by appointments service
+func InitialiseCalendarModule(app *internals.App, appointments appointmentInterfaces.IService) {
+	repository := repository.NewRepository(app.DB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout*5)
+	defer cancel()
+	if err := repository.CreateIndexes(ctx); err != nil {
+		log.Fatal("Failed to create calendar indexes", err)
+	}
+
+	service := service.NewCalendarService(repository, appointments)
+	controller := controller.NewController(service)
+	app.Server.HandleFunc(utils.MakeURL("POST", "/calendar/feed"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.CreateFeed, utils.RoleUser, utils.RoleDoctor)))
+	app.Server.HandleFunc(utils.MakeURL("GET", "/calendar/feed"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.GetFeed, utils.RoleUser, utils.RoleDoctor)))
+	app.Server.HandleFunc(utils.MakeURL("DELETE", "/calendar/feed"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.RevokeFeed, utils.RoleUser, utils.RoleDoctor)))
+	//calendar apps cannot send a jwt, the secret token in the link authenticates the feed
+	app.Server.HandleFunc(utils.MakeURL("GET", "/calendar/feed/{token}"), controller.FeedCalendar)
+	app.Server.HandleFunc(utils.MakeURL("GET", "/appointment/{id}/ics"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.AppointmentCalendar, utils.RoleUser, utils.RoleDoctor, utils.RoleClinicOwner)))
+}
@@ -0,0 +1,133 @@
+// Package controller provides HTTP handlers for calendar feeds and appointment calendar files.
+package controller
+
+import (
+	interfaces "AlShifa/Calendar/Interfaces"
+	middleware "AlShifa/Middleware"
+	utils "AlShifa/Utils"
+	"context"
+	"net/http"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Controller struct {
+	Service interfaces.IService
+}
+
+func NewController(service interfaces.IService) *Controller {
+	return &Controller{
+		Service: service,
+	}
+}
+
+// CreateFeed creates calendar feed of logged in user, calling it again rotates the secret link
+func (controller *Controller) CreateFeed(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	ownerID, role, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	feed, appErr := controller.Service.CreateFeed(ctx, ownerID, role)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusCreated, utils.ReturnAppSuccess(201, "Calendar Feed Created Successfully", feed))
+}
+
+func (controller *Controller) GetFeed(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	ownerID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	feed, appErr := controller.Service.GetFeed(ctx, ownerID)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Calendar Feed Fetched Successfully", feed))
+}
+
+func (controller *Controller) RevokeFeed(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	ownerID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	if appErr := controller.Service.RevokeFeed(ctx, ownerID); appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Calendar Feed Revoked Successfully", nil))
+}
+
+// FeedCalendar serves the feed to calendar apps, the secret token in the path is the only authentication
+func (controller *Controller) FeedCalendar(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	token := strings.TrimSuffix(req.PathValue("token"), ".ics")
+
+	ics, appErr := controller.Service.FeedCalendar(ctx, token)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	writeCalendar(res, ics, "")
+}
+
+// AppointmentCalendar downloads a single appointment as an ics file
+func (controller *Controller) AppointmentCalendar(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	actorID, actorRole, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	appointmentID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Appointment ID", "Invalid ID"))
+		return
+	}
+
+	ics, appErr := controller.Service.AppointmentCalendar(ctx, actorID, actorRole, appointmentID)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	writeCalendar(res, ics, "appointment-"+appointmentID.Hex()+".ics")
+}
+
+// writeCalendar writes ics body, a non empty filename makes browsers download it
+func writeCalendar(res http.ResponseWriter, ics []byte, filename string) {
+	res.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	res.Header().Set("Cache-Control", "no-store")
+	if filename != "" {
+		res.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	}
+	res.WriteHeader(http.StatusOK)
+	_, _ = res.Write(ics)
+}
@@ -0,0 +1,22 @@
+// Package interfaces contains interfaces for Calendar module
+package interfaces
+
+import (
+	"AlShifa/Clinic/models"
+	userModels "AlShifa/Users/Models"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IRepository defines the methods for loose coupling between the calendar repository and its implementation.
+type IRepository interface {
+	SaveFeed(ctx context.Context, feed models.CalendarFeed) error
+	GetFeed(ctx context.Context, filter bson.M) (models.CalendarFeed, error)
+	DeleteFeed(ctx context.Context, ownerID primitive.ObjectID) error
+	GetAppointments(ctx context.Context, filter bson.M) ([]models.Appointment, error)
+	GetDoctors(ctx context.Context, doctorIDs []primitive.ObjectID) ([]models.Doctor, error)
+	GetClinics(ctx context.Context, clinicIDs []primitive.ObjectID) ([]models.Clinic, error)
+	GetUsers(ctx context.Context, userIDs []primitive.ObjectID) ([]userModels.User, error)
+}
@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"AlShifa/Clinic/models"
+	structs "AlShifa/Structs"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IService interface contains functions that calendar service layer must implement( to be used by handlers)
+type IService interface {
+	CreateFeed(ctx context.Context, ownerID primitive.ObjectID, role string) (*models.CalendarFeed, *structs.IAppError)
+	GetFeed(ctx context.Context, ownerID primitive.ObjectID) (*models.CalendarFeed, *structs.IAppError)
+	RevokeFeed(ctx context.Context, ownerID primitive.ObjectID) *structs.IAppError
+	FeedCalendar(ctx context.Context, token string) ([]byte, *structs.IAppError)
+	AppointmentCalendar(ctx context.Context, actorID primitive.ObjectID, actorRole string, appointmentID primitive.ObjectID) ([]byte, *structs.IAppError)
+}
@@ -0,0 +1,133 @@
+// Package repository provides the implementation of the repository layer for calendar feeds in MongoDB.
+package repository
+
+import (
+	interfaces "AlShifa/Calendar/Interfaces"
+	"AlShifa/Clinic/models"
+	userModels "AlShifa/Users/Models"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Repo is the MongoDB implementation of the calendar IRepository interface.
+type Repo struct {
+	DB *mongo.Database
+}
+
+// this ensures this repo implements all methods of repository interface
+var _ interfaces.IRepository = (*Repo)(nil)
+
+// NewRepository creates a new calendar repository with the specified database.
+func NewRepository(db *mongo.Database) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+// CreateIndexes creates indexes calendar module relies on, every owner has at most one feed
+func (r *Repo) CreateIndexes(ctx context.Context) error {
+	_, err := r.DB.Collection("CalendarFeed").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "owner", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	return err
+}
+
+// SaveFeed replaces token of feed of owner of feed, the old token stops working right away. id of an existing feed is
+// kept since _id cant be changed
+func (r *Repo) SaveFeed(ctx context.Context, feed models.CalendarFeed) error {
+	update := bson.M{
+		"$set":         bson.M{"role": feed.Role, "token": feed.Token, "createdAt": feed.CreatedAt},
+		"$setOnInsert": bson.M{"_id": feed.ID},
+	}
+	_, err := r.DB.Collection("CalendarFeed").UpdateOne(ctx, bson.M{"owner": feed.Owner}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *Repo) GetFeed(ctx context.Context, filter bson.M) (models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	err := r.DB.Collection("CalendarFeed").FindOne(ctx, filter).Decode(&feed)
+	return feed, err
+}
+
+func (r *Repo) DeleteFeed(ctx context.Context, ownerID primitive.ObjectID) error {
+	res, err := r.DB.Collection("CalendarFeed").DeleteOne(ctx, bson.M{"owner": ownerID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *Repo) GetAppointments(ctx context.Context, filter bson.M) ([]models.Appointment, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "appointmentDate", Value: 1}})
+	cursor, err := r.DB.Collection("Appointment").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var appointments []models.Appointment
+	if err := cursor.All(ctx, &appointments); err != nil {
+		return nil, err
+	}
+	return appointments, nil
+}
+
+// GetDoctors returns name and slot length of doctors, nothing else is needed for calendar entries
+func (r *Repo) GetDoctors(ctx context.Context, doctorIDs []primitive.ObjectID) ([]models.Doctor, error) {
+	opts := options.Find().SetProjection(bson.M{"name": 1, "slotDuration": 1})
+	cursor, err := r.DB.Collection("Doctor").Find(ctx, bson.M{"_id": bson.M{"$in": doctorIDs}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var doctors []models.Doctor
+	if err := cursor.All(ctx, &doctors); err != nil {
+		return nil, err
+	}
+	return doctors, nil
+}
+
+func (r *Repo) GetClinics(ctx context.Context, clinicIDs []primitive.ObjectID) ([]models.Clinic, error) {
+	cursor, err := r.DB.Collection("Clinic").Find(ctx, bson.M{"_id": bson.M{"$in": clinicIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var clinics []models.Clinic
+	if err := cursor.All(ctx, &clinics); err != nil {
+		return nil, err
+	}
+	return clinics, nil
+}
+
+// GetUsers returns names of patients, contact details are left out as feeds are readable by anyone holding the link
+func (r *Repo) GetUsers(ctx context.Context, userIDs []primitive.ObjectID) ([]userModels.User, error) {
+	opts := options.Find().SetProjection(bson.M{"name": 1})
+	cursor, err := r.DB.Collection("User").Find(ctx, bson.M{"_id": bson.M{"$in": userIDs}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []userModels.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
@@ -0,0 +1,231 @@
+// Package service contains service layer implementation for calendar module
+package service
+
+import (
+	appointmentInterfaces "AlShifa/Appointment/Interfaces"
+	interfaces "AlShifa/Calendar/Interfaces"
+	"AlShifa/Clinic/models"
+	scheduling "AlShifa/Scheduling"
+	structs "AlShifa/Structs"
+	userModels "AlShifa/Users/Models"
+	utils "AlShifa/Utils"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type CalendarService struct {
+	Repo         interfaces.IRepository
+	Appointments appointmentInterfaces.IService
+}
+
+// NewCalendarService creates calendar service, appointments is used to check access to single appointments
+func NewCalendarService(repo interfaces.IRepository, appointments appointmentInterfaces.IService) *CalendarService {
+	return &CalendarService{
+		Repo:         repo,
+		Appointments: appointments,
+	}
+}
+
+// this ensures this service layer implements all methods of service layer interface
+var _ interfaces.IService = (*CalendarService)(nil)
+
+// CreateFeed gives owner a new secret feed link, an existing link stops working
+func (service *CalendarService) CreateFeed(ctx context.Context, ownerID primitive.ObjectID, role string) (*models.CalendarFeed, *structs.IAppError) {
+	if role != utils.RoleUser && role != utils.RoleDoctor {
+		return nil, utils.ReturnAppError(errors.New("feed not available for role"), http.StatusForbidden, "Calendar Feed Not Available", "Only Patients And Doctors Have Calendar Feeds")
+	}
+
+	token, err := utils.GenerateSecretToken(utils.CalendarFeedTokenBytes)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Failed To Create Calendar Feed", "Server Error")
+	}
+
+	feed := models.CalendarFeed{
+		ID:        primitive.NewObjectID(),
+		Owner:     ownerID,
+		Role:      role,
+		Token:     token,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := service.Repo.SaveFeed(ctx, feed); err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Failed To Create Calendar Feed", "Server Error")
+	}
+
+	feed.URL = feedURL(token)
+	return &feed, nil
+}
+
+func (service *CalendarService) GetFeed(ctx context.Context, ownerID primitive.ObjectID) (*models.CalendarFeed, *structs.IAppError) {
+	feed, err := service.Repo.GetFeed(ctx, bson.M{"owner": ownerID})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.ReturnAppError(err, http.StatusNotFound, "Calendar Feed Not Found", "Create A Calendar Feed First")
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Calendar Feed", "Server Error")
+	}
+
+	feed.URL = feedURL(feed.Token)
+	return &feed, nil
+}
+
+// RevokeFeed deletes feed of owner so its link stops working
+func (service *CalendarService) RevokeFeed(ctx context.Context, ownerID primitive.ObjectID) *structs.IAppError {
+	if err := service.Repo.DeleteFeed(ctx, ownerID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return utils.ReturnAppError(err, http.StatusNotFound, "Calendar Feed Not Found", "No Calendar Feed To Revoke")
+		}
+		return utils.ReturnAppError(err, http.StatusInternalServerError, "Failed To Revoke Calendar Feed", "Server Error")
+	}
+	return nil
+}
+
+// FeedCalendar renders appointments of the owner of token from a month back to half a year ahead. cancelled and moved
+// appointments stay in the feed as cancelled events so subscribed calendars remove them
+func (service *CalendarService) FeedCalendar(ctx context.Context, token string) ([]byte, *structs.IAppError) {
+	feed, err := service.Repo.GetFeed(ctx, bson.M{"token": token})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.ReturnAppError(err, http.StatusNotFound, "Calendar Feed Not Found", "Invalid Or Revoked Feed Link")
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Calendar Feed", "Server Error")
+	}
+
+	now := time.Now()
+	filter := bson.M{
+		"appointmentDate": bson.M{
+			"$gte": now.AddDate(0, 0, -utils.CalendarFeedPastDays).UTC(),
+			"$lte": now.AddDate(0, 0, utils.CalendarFeedFutureDays).UTC(),
+		},
+	}
+	if feed.Role == utils.RoleDoctor {
+		filter["doctor"] = feed.Owner
+	} else {
+		filter["user"] = feed.Owner
+	}
+
+	appointments, err := service.Repo.GetAppointments(ctx, filter)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Appointments", "Server Error")
+	}
+
+	events, err := service.events(ctx, appointments, feed.Role)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Appointments", "Server Error")
+	}
+	return renderCalendar("AlShifa Appointments", events, now), nil
+}
+
+// AppointmentCalendar renders a single appointment for any party of the appointment
+func (service *CalendarService) AppointmentCalendar(ctx context.Context, actorID primitive.ObjectID, actorRole string, appointmentID primitive.ObjectID) ([]byte, *structs.IAppError) {
+	appointment, appErr := service.Appointments.GetAppointment(ctx, actorID, actorRole, appointmentID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	events, err := service.events(ctx, []models.Appointment{*appointment}, actorRole)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Appointment", "Server Error")
+	}
+	return renderCalendar("AlShifa Appointment", events, time.Now()), nil
+}
+
+// events turns appointments into calendar events, patients see the doctor in the title and doctors see the patient
+func (service *CalendarService) events(ctx context.Context, appointments []models.Appointment, viewerRole string) ([]icsEvent, error) {
+	if len(appointments) == 0 {
+		return nil, nil
+	}
+
+	var doctorIDs, clinicIDs, userIDs []primitive.ObjectID
+	for _, appointment := range appointments {
+		doctorIDs = append(doctorIDs, appointment.Doctor)
+		clinicIDs = append(clinicIDs, appointment.Clinic)
+		userIDs = append(userIDs, appointment.User)
+	}
+
+	doctorList, err := service.Repo.GetDoctors(ctx, doctorIDs)
+	if err != nil {
+		return nil, err
+	}
+	doctors := make(map[primitive.ObjectID]models.Doctor, len(doctorList))
+	for _, doctor := range doctorList {
+		doctors[doctor.ID] = doctor
+	}
+
+	clinicList, err := service.Repo.GetClinics(ctx, clinicIDs)
+	if err != nil {
+		return nil, err
+	}
+	clinics := make(map[primitive.ObjectID]models.Clinic, len(clinicList))
+	for _, clinic := range clinicList {
+		clinics[clinic.ID] = clinic
+	}
+
+	users := map[primitive.ObjectID]userModels.User{}
+	if viewerRole == utils.RoleDoctor {
+		userList, err := service.Repo.GetUsers(ctx, userIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range userList {
+			users[user.ID] = user
+		}
+	}
+
+	events := make([]icsEvent, 0, len(appointments))
+	for _, appointment := range appointments {
+		doctor := doctors[appointment.Doctor]
+		clinic := clinics[appointment.Clinic]
+
+		summary := "Appointment with Dr. " + doctor.Name
+		if viewerRole == utils.RoleDoctor {
+			summary = "Appointment with " + users[appointment.User].Name
+		}
+
+		place := clinic.Name
+		if clinic.Address != "" {
+			place += ", " + clinic.Address
+		}
+
+		modified := appointment.RegistrationDate
+		if len(appointment.StatusHistory) > 0 {
+			modified = appointment.StatusHistory[len(appointment.StatusHistory)-1].At
+		}
+
+		events = append(events, icsEvent{
+			UID:         appointment.ID.Hex() + "@alshifa",
+			Summary:     summary,
+			Description: fmt.Sprintf("Status: %s\nSlot: %d", appointment.Status, appointment.Slot),
+			Place:       place,
+			Status:      eventStatus(appointment.Status),
+			Start:       appointment.AppointmentDate,
+			End:         appointment.AppointmentDate.Add(scheduling.SlotDuration(doctor)),
+			Modified:    modified,
+			//every status change is a new revision of the event
+			Sequence: len(appointment.StatusHistory),
+			Location: scheduling.ClinicLocation(clinic),
+		})
+	}
+	return events, nil
+}
+
+// eventStatus maps appointment status to VEVENT status
+func eventStatus(status string) string {
+	switch status {
+	case utils.AppointmentStatusRequested:
+		return "TENTATIVE"
+	case utils.AppointmentStatusCancelled, utils.AppointmentStatusRescheduled, utils.AppointmentStatusNoShow, utils.AppointmentStatusNeedsReschedule:
+		return "CANCELLED"
+	}
+	return "CONFIRMED"
+}
+
+func feedURL(token string) string {
+	return utils.MakeURL("", "/calendar/feed/"+token+".ics")
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	userModels "AlShifa/Users/Models"
+	utils "AlShifa/Utils"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ReturnDummyCalendarRepo returns a repo whose feed belongs to a patient with the given appointments
+func ReturnDummyCalendarRepo(appointments []models.Appointment) *MockCalendarRepo {
+	return &MockCalendarRepo{
+		GetFeedFn: func(ctx context.Context, filter bson.M) (models.CalendarFeed, error) {
+			if filter["token"] != "secret" {
+				return models.CalendarFeed{}, mongo.ErrNoDocuments
+			}
+			return models.CalendarFeed{Owner: primitive.NewObjectID(), Role: utils.RoleUser, Token: "secret"}, nil
+		},
+		GetAppointmentsFn: func(ctx context.Context, filter bson.M) ([]models.Appointment, error) {
+			return appointments, nil
+		},
+		GetDoctorsFn: func(ctx context.Context, doctorIDs []primitive.ObjectID) ([]models.Doctor, error) {
+			return []models.Doctor{{ID: doctorIDs[0], Name: "Ayesha Khan"}}, nil
+		},
+		GetClinicsFn: func(ctx context.Context, clinicIDs []primitive.ObjectID) ([]models.Clinic, error) {
+			return []models.Clinic{{ID: clinicIDs[0], Name: "AlShifa Clinic", Address: "12, MG Road; Bengaluru"}}, nil
+		},
+		GetUsersFn: func(ctx context.Context, userIDs []primitive.ObjectID) ([]userModels.User, error) {
+			return nil, nil
+		},
+	}
+}
+
+func TestFeedCalendar(t *testing.T) {
+	doctorID := primitive.NewObjectID()
+	clinicID := primitive.NewObjectID()
+	start := time.Date(2030, 3, 4, 4, 30, 0, 0, time.UTC)
+
+	mockRepo := ReturnDummyCalendarRepo([]models.Appointment{
+		{ID: primitive.NewObjectID(), Doctor: doctorID, Clinic: clinicID, AppointmentDate: start, Status: utils.AppointmentStatusConfirmed},
+		{
+			ID: primitive.NewObjectID(), Doctor: doctorID, Clinic: clinicID, AppointmentDate: start.Add(time.Hour), Status: utils.AppointmentStatusCancelled,
+			StatusHistory: []models.StatusTransition{{At: start.Add(-time.Hour), From: utils.AppointmentStatusRequested, To: utils.AppointmentStatusCancelled}},
+		},
+	})
+	calendarService := NewCalendarService(mockRepo, nil)
+
+	ics, appErr := calendarService.FeedCalendar(context.Background(), "secret")
+	if appErr != nil {
+		t.Fatalf("expected feed got %v", appErr)
+	}
+	calendar := string(ics)
+
+	for _, want := range []string{
+		"BEGIN:VTIMEZONE\r\nTZID:Asia/Kolkata\r\n",
+		"TZOFFSETTO:+0530\r\n",
+		"DTSTART;TZID=Asia/Kolkata:20300304T100000\r\n",
+		"DTEND;TZID=Asia/Kolkata:20300304T101500\r\n",
+		"SUMMARY:Appointment with Dr. Ayesha Khan\r\n",
+		`LOCATION:AlShifa Clinic\, 12\, MG Road\; Bengaluru` + "\r\n",
+		"STATUS:CONFIRMED\r\n",
+		"STATUS:CANCELLED\r\nSEQUENCE:1\r\n",
+	} {
+		if !strings.Contains(calendar, want) {
+			t.Errorf("expected calendar to contain %q got\n%s", want, calendar)
+		}
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(calendar, "\r\n"), "\r\n") {
+		if len(line) > icsLineLimit {
+			t.Errorf("line longer than %d octets %q", icsLineLimit, line)
+		}
+	}
+
+	if _, appErr := calendarService.FeedCalendar(context.Background(), "revoked"); appErr == nil || appErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown token got %v", appErr)
+	}
+}
+
+func TestFoldLine(t *testing.T) {
+	line := "DESCRIPTION:" + strings.Repeat("क्लिनिक ", 20)
+
+	folded := foldLine(line)
+	if strings.ReplaceAll(folded, "\r\n ", "") != line {
+		t.Fatalf("unfolding did not give back the line")
+	}
+	for _, part := range strings.Split(folded, "\r\n") {
+		if len(part) > icsLineLimit {
+			t.Errorf("folded part longer than %d octets %q", icsLineLimit, part)
+		}
+		if !strings.HasPrefix(part, " ") && part != folded[:len(part)] {
+			t.Errorf("continuation line does not start with space %q", part)
+		}
+	}
+}
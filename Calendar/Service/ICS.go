@@ -0,0 +1,184 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	icsLocalLayout = "20060102T150405"
+	icsUTCLayout   = "20060102T150405Z"
+	icsLineLimit   = 75
+)
+
+// icsEvent is a VEVENT, Start and End are written in Location so calendars show clinic time whatever zone the phone is in
+type icsEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Place       string
+	Status      string // TENTATIVE, CONFIRMED or CANCELLED
+	Start       time.Time
+	End         time.Time
+	Modified    time.Time
+	Sequence    int
+	Location    *time.Location
+}
+
+// renderCalendar writes events as an RFC 5545 calendar with a VTIMEZONE for every zone used by the events
+func renderCalendar(name string, events []icsEvent, stamp time.Time) []byte {
+	var lines []string
+	lines = append(lines,
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//AlShifa//Appointments//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:"+escapeText(name),
+	)
+
+	//zones are described for the whole span of the events so offsets are right on both sides of a DST change
+	type span struct{ from, to time.Time }
+	zones := map[string]*span{}
+	var zoneOrder []*time.Location
+	for _, event := range events {
+		if event.Location == nil || event.Location == time.UTC {
+			continue
+		}
+		zone, ok := zones[event.Location.String()]
+		if !ok {
+			zones[event.Location.String()] = &span{from: event.Start, to: event.End}
+			zoneOrder = append(zoneOrder, event.Location)
+			continue
+		}
+		if event.Start.Before(zone.from) {
+			zone.from = event.Start
+		}
+		if event.End.After(zone.to) {
+			zone.to = event.End
+		}
+	}
+	for _, loc := range zoneOrder {
+		zone := zones[loc.String()]
+		lines = append(lines, vtimezone(loc, zone.from, zone.to)...)
+	}
+
+	for _, event := range events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+event.UID,
+			"DTSTAMP:"+stamp.UTC().Format(icsUTCLayout),
+			icsTime("DTSTART", event.Start, event.Location),
+			icsTime("DTEND", event.End, event.Location),
+			"SUMMARY:"+escapeText(event.Summary),
+		)
+		if event.Description != "" {
+			lines = append(lines, "DESCRIPTION:"+escapeText(event.Description))
+		}
+		if event.Place != "" {
+			lines = append(lines, "LOCATION:"+escapeText(event.Place))
+		}
+		if !event.Modified.IsZero() {
+			lines = append(lines, "LAST-MODIFIED:"+event.Modified.UTC().Format(icsUTCLayout))
+		}
+		lines = append(lines,
+			"STATUS:"+event.Status,
+			fmt.Sprintf("SEQUENCE:%d", event.Sequence),
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	var builder strings.Builder
+	for _, line := range lines {
+		builder.WriteString(foldLine(line))
+		builder.WriteString("\r\n")
+	}
+	return []byte(builder.String())
+}
+
+// vtimezone describes every observance of loc which is in effect between from and to
+func vtimezone(loc *time.Location, from time.Time, to time.Time) []string {
+	lines := []string{"BEGIN:VTIMEZONE", "TZID:" + loc.String()}
+
+	t := from.In(loc)
+	for {
+		start, end := t.ZoneBounds()
+		name, offset := t.Zone()
+
+		//DTSTART of an observance is the local time just before it begins
+		previous := offset
+		dtStart := "19700101T000000"
+		if !start.IsZero() {
+			_, previous = start.Add(-time.Second).In(loc).Zone()
+			dtStart = start.In(time.FixedZone("", previous)).Format(icsLocalLayout)
+		}
+
+		kind := "STANDARD"
+		if t.IsDST() {
+			kind = "DAYLIGHT"
+		}
+		lines = append(lines,
+			"BEGIN:"+kind,
+			"DTSTART:"+dtStart,
+			"TZOFFSETFROM:"+icsOffset(previous),
+			"TZOFFSETTO:"+icsOffset(offset),
+			"TZNAME:"+name,
+			"END:"+kind,
+		)
+
+		if end.IsZero() || !end.Before(to) {
+			break
+		}
+		t = end.In(loc)
+	}
+
+	return append(lines, "END:VTIMEZONE")
+}
+
+func icsTime(property string, t time.Time, loc *time.Location) string {
+	if loc == nil || loc == time.UTC {
+		return property + ":" + t.UTC().Format(icsUTCLayout)
+	}
+	return property + ";TZID=" + loc.String() + ":" + t.In(loc).Format(icsLocalLayout)
+}
+
+// icsOffset formats a zone offset in seconds as +hhmm
+func icsOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// escapeText escapes characters which have a meaning in TEXT values
+func escapeText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(text)
+}
+
+// foldLine splits line into chunks of at most 75 octets without breaking a utf-8 character, continuation lines start with a space
+func foldLine(line string) string {
+	if len(line) <= icsLineLimit {
+		return line
+	}
+
+	var builder strings.Builder
+	limit := icsLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		builder.WriteString(line[:cut])
+		builder.WriteString("\r\n ")
+		line = line[cut:]
+		//the leading space counts towards the limit of continuation lines
+		limit = icsLineLimit - 1
+	}
+	builder.WriteString(line)
+	return builder.String()
+}
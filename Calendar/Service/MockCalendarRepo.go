@@ -0,0 +1,72 @@
+package service
+
+import (
+	interfaces "AlShifa/Calendar/Interfaces"
+	"AlShifa/Clinic/models"
+	userModels "AlShifa/Users/Models"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockCalendarRepo struct {
+	SaveFeedFn        func(ctx context.Context, feed models.CalendarFeed) error
+	GetFeedFn         func(ctx context.Context, filter bson.M) (models.CalendarFeed, error)
+	DeleteFeedFn      func(ctx context.Context, ownerID primitive.ObjectID) error
+	GetAppointmentsFn func(ctx context.Context, filter bson.M) ([]models.Appointment, error)
+	GetDoctorsFn      func(ctx context.Context, doctorIDs []primitive.ObjectID) ([]models.Doctor, error)
+	GetClinicsFn      func(ctx context.Context, clinicIDs []primitive.ObjectID) ([]models.Clinic, error)
+	GetUsersFn        func(ctx context.Context, userIDs []primitive.ObjectID) ([]userModels.User, error)
+}
+
+var _ interfaces.IRepository = (*MockCalendarRepo)(nil)
+
+func (m *MockCalendarRepo) SaveFeed(ctx context.Context, feed models.CalendarFeed) error {
+	if m.SaveFeedFn == nil {
+		panic("SaveFeedFn not implemented inside mock")
+	}
+	return m.SaveFeedFn(ctx, feed)
+}
+
+func (m *MockCalendarRepo) GetFeed(ctx context.Context, filter bson.M) (models.CalendarFeed, error) {
+	if m.GetFeedFn == nil {
+		panic("GetFeedFn not implemented inside mock")
+	}
+	return m.GetFeedFn(ctx, filter)
+}
+
+func (m *MockCalendarRepo) DeleteFeed(ctx context.Context, ownerID primitive.ObjectID) error {
+	if m.DeleteFeedFn == nil {
+		panic("DeleteFeedFn not implemented inside mock")
+	}
+	return m.DeleteFeedFn(ctx, ownerID)
+}
+
+func (m *MockCalendarRepo) GetAppointments(ctx context.Context, filter bson.M) ([]models.Appointment, error) {
+	if m.GetAppointmentsFn == nil {
+		panic("GetAppointmentsFn not implemented inside mock")
+	}
+	return m.GetAppointmentsFn(ctx, filter)
+}
+
+func (m *MockCalendarRepo) GetDoctors(ctx context.Context, doctorIDs []primitive.ObjectID) ([]models.Doctor, error) {
+	if m.GetDoctorsFn == nil {
+		panic("GetDoctorsFn not implemented inside mock")
+	}
+	return m.GetDoctorsFn(ctx, doctorIDs)
+}
+
+func (m *MockCalendarRepo) GetClinics(ctx context.Context, clinicIDs []primitive.ObjectID) ([]models.Clinic, error) {
+	if m.GetClinicsFn == nil {
+		panic("GetClinicsFn not implemented inside mock")
+	}
+	return m.GetClinicsFn(ctx, clinicIDs)
+}
+
+func (m *MockCalendarRepo) GetUsers(ctx context.Context, userIDs []primitive.ObjectID) ([]userModels.User, error) {
+	if m.GetUsersFn == nil {
+		panic("GetUsersFn not implemented inside mock")
+	}
+	return m.GetUsersFn(ctx, userIDs)
+}
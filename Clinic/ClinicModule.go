@@ -4,28 +4,63 @@ package clinic
 
 import (
 	controller "AlShifa/Clinic/Controller"
+	interfaces "AlShifa/Clinic/Interfaces"
 	repository "AlShifa/Clinic/Repository"
 	service "AlShifa/Clinic/Service"
+	geo "AlShifa/Geo"
 	internals "AlShifa/Internals"
 	middleware "AlShifa/Middleware"
+	specialtyInterfaces "AlShifa/Specialty/Interfaces"
 	utils "AlShifa/Utils"
+	"context"
 	"fmt"
+	"log"
 	"net/http"
 )
 
-func InitialiseClinicModule(app *internals.App) {
+// InitialiseClinicModule registers clinic, owner and doctor routes, specialties are used to search doctors by specialty.
+// it returns the service so other modules can wind down what they keep for clinics which stop being active
+func InitialiseClinicModule(app *internals.App, specialties specialtyInterfaces.IService) interfaces.IService {
 	repository := repository.NewRepository(app.DB)
-	service := service.NewClinicService(repository)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout*5)
+	defer cancel()
+	if err := repository.CreateIndexes(ctx); err != nil {
+		log.Fatal("Failed to create clinic indexes", err)
+	}
+
+	if err := geo.LoadPincodes(); err != nil {
+		log.Fatal("Failed to load pincode dataset", err)
+	}
+
+	service := service.NewClinicService(repository, specialties)
+	if _, err := service.BackfillLocations(ctx); err != nil {
+		log.Println("Failed to locate clinics without coordinates", err)
+	}
+	if _, err := service.MigrateOwnerClinics(ctx); err != nil {
+		log.Println("Failed to migrate owners to many clinics", err)
+	}
 	controller := controller.NewController(service)
 	app.Server.HandleFunc(utils.MakeURL("POST", "/owner/register"), controller.RegisterOwner)
 	app.Server.HandleFunc(utils.MakeURL("POST", "/clinic/register"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.RegisterClinic, utils.RoleClinicOwner)))
 	app.Server.HandleFunc(utils.MakeURL("GET", "/clinic/details"), middleware.JwtAuthMiddleware(controller.SearchClinic))
+	app.Server.HandleFunc(utils.MakeURL("GET", "/clinic/nearby"), middleware.JwtAuthMiddleware(controller.NearbyClinics))
 	app.Server.HandleFunc(utils.MakeURL("GET", "/owner/details"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.SearchOwner, utils.RoleAdmin, utils.RoleClinicOwner)))
+	app.Server.HandleFunc(utils.MakeURL("GET", "/owner/clinic"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.GetActiveClinic, utils.RoleClinicOwner)))
 	app.Server.HandleFunc(utils.MakeURL("POST", "/doctor/register"), middleware.JwtAuthMiddleware(controller.RegisterDoctor))
 	app.Server.HandleFunc(utils.MakeURL("GET", "/doctor/details"), middleware.JwtAuthMiddleware(controller.SearchDoctor))
 	app.Server.HandleFunc(utils.MakeURL("POST", "/owner/login"), controller.LoginClinicOwner)
 	app.Server.HandleFunc(utils.MakeURL("POST", "/doctor/login"), controller.LoginDoctor)
+	app.Server.HandleFunc(utils.MakeURL("PATCH", "/doctor/{id}"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.PatchDoctor, utils.RoleDoctor, utils.RoleAdmin)))
+	app.Server.HandleFunc(utils.MakeURL("PATCH", "/clinic/{id}"), middleware.JwtAuthMiddleware(middleware.PermissionGuardMiddleware(controller.PatchClinic, utils.PermissionEditClinic, utils.RoleClinicOwner, utils.RoleAdmin)))
+	//closing a clinic cancels its appointments and ends its affiliations so staff cant do it whatever their permissions
+	app.Server.HandleFunc(utils.MakeURL("PUT", "/clinic/{id}/status"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.SetClinicStatus, utils.RoleClinicOwner, utils.RoleAdmin)))
+	app.Server.HandleFunc(utils.MakeURL("DELETE", "/clinic/{id}"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.DeleteClinic, utils.RoleClinicOwner, utils.RoleAdmin)))
+	app.Server.HandleFunc(utils.MakeURL("POST", "/clinic/{id}/restore"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.RestoreClinic, utils.RoleAdmin)))
+	app.Server.HandleFunc(utils.MakeURL("GET", "/clinic/{id}/wallet"), middleware.JwtAuthMiddleware(middleware.PermissionGuardMiddleware(controller.GetClinicWallet, utils.PermissionViewWallet, utils.RoleClinicOwner, utils.RoleAdmin)))
+	app.Server.HandleFunc(utils.MakeURL("PATCH", "/owner/{id}"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.PatchOwner, utils.RoleClinicOwner, utils.RoleAdmin)))
 	app.Server.HandleFunc(utils.MakeURL("GET", "/healthcheck"), func(w http.ResponseWriter, r *http.Request) {
 		fmt.Println(w, "Hey buddy server is working for client module")
 	})
+	return service
 }
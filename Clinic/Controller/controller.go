@@ -5,13 +5,20 @@ import (
 	service "AlShifa/Clinic/Service"
 	validators "AlShifa/Clinic/Validators"
 	"AlShifa/Clinic/models"
+	geo "AlShifa/Geo"
 	middleware "AlShifa/Middleware"
+	scheduling "AlShifa/Scheduling"
 	structs "AlShifa/Structs"
 	utils "AlShifa/Utils"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"net/http"
+	"strconv"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -21,9 +28,15 @@ type Controller struct {
 	Service *service.ClinicService
 }
 
+// ClinicRegistration carries clinic being registered, its owner is the caller
 type ClinicRegistration struct {
-	OwnerID string        `json:"ownerId"`
-	Clinic  models.Clinic `json:"clinicDetails"`
+	Clinic models.Clinic `json:"clinicDetails"`
+}
+
+// ClinicStatusRequest carries the new status of clinic, reason is optional and status isnt read when deleting or restoring
+type ClinicStatusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
 }
 
 func NewController(svr *service.ClinicService) *Controller {
@@ -41,6 +54,13 @@ func (controller *Controller) RegisterClinic(res http.ResponseWriter, req *http.
 	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
 	defer cancel()
 
+	//clinic is always registered for the owner calling, never for an owner named in body
+	ownerID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
 	var clinicRegistrationDetails ClinicRegistration
 	if err := json.NewDecoder(req.Body).Decode(&clinicRegistrationDetails); err != nil {
 		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 500, "Invalid Details Provided", "Json Error"))
@@ -55,9 +75,9 @@ func (controller *Controller) RegisterClinic(res http.ResponseWriter, req *http.
 		return
 	}
 
-	registrationErr := controller.Service.RegisterClinic(ctx, clinicRegistrationDetails.OwnerID, clinicRegistrationDetails.Clinic)
+	registrationErr := controller.Service.RegisterClinic(ctx, ownerID, clinicRegistrationDetails.Clinic)
 	if registrationErr != nil {
-		_ = utils.WriteResponse(res, http.StatusInternalServerError, registrationErr)
+		_ = utils.WriteResponse(res, registrationErr.StatusCode, registrationErr)
 		return
 	}
 
@@ -112,6 +132,27 @@ func (controller *Controller) SearchClinic(res http.ResponseWriter, req *http.Re
 	// Parse query parameters
 	params := req.URL.Query()
 
+	//inactive and deleted clinics are hidden unless admin asks for them
+	role, _ := req.Context().Value(middleware.ContextUserRoleKey).(string)
+	includeInactive := role == utils.RoleAdmin && params.Get("includeInactive") == "true"
+	params.Del("includeInactive")
+
+	//opening hours arent fields of clinic, openNow asks for clinics open at this moment
+	var openAt *time.Time
+	if value := params.Get("openAt"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Unable To Fetch Clinic Details", "openAt Must Be An RFC3339 Time"))
+			return
+		}
+		openAt = &parsed
+	} else if params.Get("openNow") == "true" {
+		now := time.Now().UTC()
+		openAt = &now
+	}
+	params.Del("openAt")
+	params.Del("openNow")
+
 	// Initialize empty filter
 	filters := bson.M{}
 
@@ -136,10 +177,14 @@ func (controller *Controller) SearchClinic(res http.ResponseWriter, req *http.Re
 		}
 	}
 
+	if !includeInactive {
+		maps.Copy(filters, scheduling.ActiveClinicFilter(""))
+	}
+
 	// Call your service with filters
-	clinics, err := controller.Service.SearchClinic(ctx, filters)
+	clinics, err := controller.Service.SearchClinic(ctx, filters, openAt)
 	if err != nil {
-		_ = utils.WriteResponse(res, http.StatusInternalServerError, err)
+		_ = utils.WriteResponse(res, err.StatusCode, err)
 		return
 	}
 
@@ -239,6 +284,10 @@ func (controller *Controller) SearchDoctor(res http.ResponseWriter, req *http.Re
 	// Initialize empty filter
 	filters := bson.M{}
 
+	//specialty and pincode arent fields of doctor, service resolves them
+	var specialty string
+	var pincode int32
+
 	// Iterate over query params
 	for key, values := range params {
 		if len(values) == 0 {
@@ -254,15 +303,24 @@ func (controller *Controller) SearchDoctor(res http.ResponseWriter, req *http.Re
 				return
 			}
 			filters["_id"] = objID
+		} else if key == "specialty" {
+			specialty = value
+		} else if key == "pincode" {
+			parsed, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Unable To Fetch Doctors Details", "Invalid Pincode"))
+				return
+			}
+			pincode = int32(parsed)
 		} else {
 			// Treat all other fields as string match
 			filters[key] = value
 		}
 	}
 
-	doctors, err := controller.Service.SearchDoctor(ctx, filters)
+	doctors, err := controller.Service.SearchDoctor(ctx, filters, specialty, pincode)
 	if err != nil {
-		_ = utils.WriteResponse(res, http.StatusInternalServerError, err)
+		_ = utils.WriteResponse(res, err.StatusCode, err)
 		return
 	}
 
@@ -316,3 +374,253 @@ func (controller *Controller) LoginDoctor(res http.ResponseWriter, req *http.Req
 		StatusCode: 200,
 	})
 }
+
+func (controller *Controller) PatchDoctor(res http.ResponseWriter, req *http.Request) {
+	controller.patch(res, req, "Doctor Updated Successfully", func(ctx context.Context, actorID primitive.ObjectID, actorRole string, id primitive.ObjectID, patch []byte) (any, *structs.IAppError) {
+		return controller.Service.PatchDoctor(ctx, actorID, actorRole, id, patch)
+	})
+}
+
+func (controller *Controller) PatchClinic(res http.ResponseWriter, req *http.Request) {
+	controller.patch(res, req, "Clinic Updated Successfully", func(ctx context.Context, actorID primitive.ObjectID, actorRole string, id primitive.ObjectID, patch []byte) (any, *structs.IAppError) {
+		return controller.Service.PatchClinic(ctx, actorID, actorRole, id, patch)
+	})
+}
+
+func (controller *Controller) PatchOwner(res http.ResponseWriter, req *http.Request) {
+	controller.patch(res, req, "Owner Updated Successfully", func(ctx context.Context, actorID primitive.ObjectID, actorRole string, id primitive.ObjectID, patch []byte) (any, *structs.IAppError) {
+		return controller.Service.PatchOwner(ctx, actorID, actorRole, id, patch)
+	})
+}
+
+// patch reads merge patch of the record in path and hands it to apply
+func (controller *Controller) patch(res http.ResponseWriter, req *http.Request, message string, apply func(ctx context.Context, actorID primitive.ObjectID, actorRole string, id primitive.ObjectID, patch []byte) (any, *structs.IAppError)) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	actorID, actorRole, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid ID", "Invalid ID"))
+		return
+	}
+
+	patch, err := io.ReadAll(http.MaxBytesReader(res, req.Body, utils.MaxPatchSize))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Update Failed", "Invalid Body"))
+		return
+	}
+
+	updated, appErr := apply(ctx, actorID, actorRole, id, patch)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, message, updated))
+}
+
+// NearbyClinics lists clinics around lat and lng or around centre of pincode, nearest first with distance in kilometres
+func (controller *Controller) NearbyClinics(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	params := req.URL.Query()
+	fail := func(err error, reason string) {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, http.StatusBadRequest, "Unable To Fetch Clinics", reason))
+	}
+
+	var origin *models.GeoPoint
+	var pincode int32
+	if params.Has("lat") || params.Has("lng") {
+		lat, latErr := strconv.ParseFloat(params.Get("lat"), 64)
+		lng, lngErr := strconv.ParseFloat(params.Get("lng"), 64)
+		if latErr != nil || lngErr != nil {
+			fail(fmt.Errorf("lat and lng are both required"), "Invalid Coordinates")
+			return
+		}
+		point := geo.NewPoint(lat, lng)
+		if !geo.ValidPoint(point) {
+			fail(fmt.Errorf("lat %v lng %v out of range", lat, lng), "Invalid Coordinates")
+			return
+		}
+		origin = &point
+	} else {
+		parsed, err := strconv.ParseInt(params.Get("pincode"), 10, 32)
+		if err != nil {
+			fail(fmt.Errorf("lat and lng or pincode is required"), "Location Required")
+			return
+		}
+		pincode = int32(parsed)
+	}
+
+	radius := utils.DefaultNearbyRadius
+	if value := params.Get("radius"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 || parsed > utils.MaxNearbyRadius {
+			fail(fmt.Errorf("radius must be between 0 and %v km", utils.MaxNearbyRadius), "Invalid Radius")
+			return
+		}
+		radius = parsed
+	}
+
+	limit := int64(utils.DefaultNearbyClinics)
+	if value := params.Get("limit"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < 1 || parsed > utils.MaxNearbyClinics {
+			fail(fmt.Errorf("limit must be between 1 and %d", utils.MaxNearbyClinics), "Invalid Limit")
+			return
+		}
+		limit = parsed
+	}
+
+	clinics, appErr := controller.Service.NearbyClinics(ctx, origin, pincode, radius, limit)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Successfully Fetched Clinics", clinics))
+}
+
+// GetActiveClinic returns the clinic owner is working on, picked by the active clinic header
+func (controller *Controller) GetActiveClinic(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	ownerID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+	clinicID, appErr := middleware.ActiveClinic(req)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	clinic, appErr := controller.Service.GetActiveClinic(ctx, ownerID, clinicID)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Fetched Successfully", clinic))
+}
+
+func (controller *Controller) GetClinicWallet(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	actorID, actorRole, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	clinicID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Clinic ID", "Invalid ID"))
+		return
+	}
+
+	wallet, appErr := controller.Service.GetClinicWallet(ctx, actorID, actorRole, clinicID)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Fetched Successfully", wallet))
+}
+
+// SetClinicStatus suspends, closes or reopens clinic, future appointments of a clinic which stops being active are
+// cancelled and its doctors unlinked so it is given longer than other requests
+func (controller *Controller) SetClinicStatus(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.BulkRequestTimeout)
+	defer cancel()
+
+	actorID, actorRole, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	clinicID, request, ok := readStatusRequest(res, req, "Unable To Change Clinic Status")
+	if !ok {
+		return
+	}
+
+	clinic, appErr := controller.Service.SetClinicStatus(ctx, actorID, actorRole, clinicID, request.Status, request.Reason)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Clinic Status Changed Successfully", clinic))
+}
+
+// DeleteClinic soft deletes clinic, it is wound down the same way as a closed clinic
+func (controller *Controller) DeleteClinic(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.BulkRequestTimeout)
+	defer cancel()
+
+	actorID, actorRole, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	clinicID, request, ok := readStatusRequest(res, req, "Unable To Delete Clinic")
+	if !ok {
+		return
+	}
+
+	if appErr := controller.Service.DeleteClinic(ctx, actorID, actorRole, clinicID, request.Reason); appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Clinic Deleted Successfully", nil))
+}
+
+// RestoreClinic lets admin restore a soft deleted clinic
+func (controller *Controller) RestoreClinic(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	actorID, actorRole, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	clinicID, request, ok := readStatusRequest(res, req, "Unable To Restore Clinic")
+	if !ok {
+		return
+	}
+
+	clinic, appErr := controller.Service.RestoreClinic(ctx, actorID, actorRole, clinicID, request.Reason)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Clinic Restored Successfully", clinic))
+}
+
+// readStatusRequest reads clinic id from path and the status request from body, body is optional when deleting or
+// restoring. writes the error response itself
+func readStatusRequest(res http.ResponseWriter, req *http.Request, failure string) (primitive.ObjectID, ClinicStatusRequest, bool) {
+	var request ClinicStatusRequest
+
+	clinicID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Clinic ID", "Invalid ID"))
+		return clinicID, request, false
+	}
+
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, failure, "Invalid Json"))
+		return clinicID, request, false
+	}
+	return clinicID, request, true
+}
@@ -4,6 +4,7 @@ package interfaces
 import (
 	"AlShifa/Clinic/models"
 	"context"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -16,6 +17,19 @@ type IRepository interface {
 	GetOwnerDetails(ctx context.Context, filter bson.M) ([]models.Owner, error)
 	SearchClinic(ctx context.Context, filter bson.M) ([]models.Clinic, error)
 	RegisterDoctor(ctx context.Context, doctorDetails models.Doctor) error
-	SearchDoctors(ctx context.Context, filter bson.M) ([]models.DoctorPublicDetails, error)
+	SearchDoctors(ctx context.Context, filter bson.M, clinicFilter bson.M) ([]models.DoctorPublicDetails, error)
 	SearchDoctor(ctx context.Context, filter bson.M) (models.Doctor, error)
+	GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error)
+	UpdateDoctor(ctx context.Context, doctorID primitive.ObjectID, set bson.M) error
+	UpdateClinic(ctx context.Context, clinicID primitive.ObjectID, set bson.M) error
+	UpdateOwner(ctx context.Context, ownerID primitive.ObjectID, set bson.M) error
+	CreateIndexes(ctx context.Context) error
+	NearbyClinics(ctx context.Context, origin models.GeoPoint, maxDistance float64, limit int64) ([]models.NearbyClinic, error)
+	ClinicsWithoutLocation(ctx context.Context) ([]models.Clinic, error)
+	OwnersWithLegacyClinic(ctx context.Context) ([]models.Owner, error)
+	ClinicsOfOwners(ctx context.Context, ownerIDs []primitive.ObjectID) ([]models.Clinic, error)
+	SetOwnerClinics(ctx context.Context, ownerID primitive.ObjectID, clinicIDs []primitive.ObjectID) error
+	GetWallet(ctx context.Context, clinicID primitive.ObjectID) (models.WalletDetails, error)
+	ChangeClinicStatus(ctx context.Context, clinic models.Clinic, change models.ClinicStatusChange) error
+	GetClosures(ctx context.Context, clinicIDs []primitive.ObjectID, from time.Time, to time.Time) ([]models.Leave, error)
 }
@@ -4,15 +4,33 @@ import (
 	"AlShifa/Clinic/models"
 	structs "AlShifa/Structs"
 	"context"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // IService interface contains functions that clinic service layer must implement( to beused by handlers)
 type IService interface {
-	RegisterClinic(ctx context.Context, ownerID string, clinic models.Clinic) *structs.IAppError
+	RegisterClinic(ctx context.Context, ownerID primitive.ObjectID, clinic models.Clinic) *structs.IAppError
 	RegisterClinicOwner(ctx context.Context, ownerDetails models.Owner) *structs.IAppError
-	SearchClinic(ctx context.Context, filter bson.M) ([]models.Clinic, *structs.IAppError)
+	SearchClinic(ctx context.Context, filter bson.M, openAt *time.Time) ([]models.Clinic, *structs.IAppError)
 	LoginClinicOwner(ctx context.Context, email string, password string) (string, *structs.IAppError)
 	LoginDoctor(ctx context.Context, email string, password string) (string, *structs.IAppError)
+	PatchDoctor(ctx context.Context, actorID primitive.ObjectID, actorRole string, doctorID primitive.ObjectID, patch []byte) (*models.Doctor, *structs.IAppError)
+	PatchClinic(ctx context.Context, actorID primitive.ObjectID, actorRole string, clinicID primitive.ObjectID, patch []byte) (*models.Clinic, *structs.IAppError)
+	NearbyClinics(ctx context.Context, origin *models.GeoPoint, pincode int32, radius float64, limit int64) ([]models.NearbyClinic, *structs.IAppError)
+	GetActiveClinic(ctx context.Context, ownerID primitive.ObjectID, clinicID primitive.ObjectID) (*models.Clinic, *structs.IAppError)
+	GetClinicWallet(ctx context.Context, actorID primitive.ObjectID, actorRole string, clinicID primitive.ObjectID) (*models.WalletDetails, *structs.IAppError)
+	PatchOwner(ctx context.Context, actorID primitive.ObjectID, actorRole string, ownerID primitive.ObjectID, patch []byte) (*models.Owner, *structs.IAppError)
+	SetClinicStatus(ctx context.Context, actorID primitive.ObjectID, actorRole string, clinicID primitive.ObjectID, status string, reason string) (*models.Clinic, *structs.IAppError)
+	DeleteClinic(ctx context.Context, actorID primitive.ObjectID, actorRole string, clinicID primitive.ObjectID, reason string) *structs.IAppError
+	RestoreClinic(ctx context.Context, actorID primitive.ObjectID, actorRole string, clinicID primitive.ObjectID, reason string) (*models.Clinic, *structs.IAppError)
+	AddDeactivationListener(listener DeactivationListener)
+}
+
+// DeactivationListener is notified after a clinic stops being active (suspended, closed or deleted), other modules use it
+// to wind down what is booked or linked at the clinic. it is called again when a change is repeated so it must be idempotent
+type DeactivationListener interface {
+	ClinicDeactivated(ctx context.Context, clinic models.Clinic, change models.ClinicStatusChange) error
 }
@@ -5,12 +5,17 @@ import (
 	"AlShifa/Clinic/models"
 	"context"
 	"fmt"
+	"slices"
+	"time"
 
 	interfaces "AlShifa/Clinic/Interfaces"
+	scheduling "AlShifa/Scheduling"
+	utils "AlShifa/Utils"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // Repo is the MongoDB implementation of the IRepository interface.
@@ -57,7 +62,7 @@ func (r *Repo) RegisterClinic(
 
 		clinicID := res.InsertedID.(primitive.ObjectID)
 
-		// 2️⃣ Update owner with clinic ID
+		// 2️⃣ Add clinic ID to clinics of owner, owners keep their other clinics
 		_, err = r.DB.Collection("Owner").UpdateOne(
 			sessCtx,
 			bson.M{
@@ -65,8 +70,8 @@ func (r *Repo) RegisterClinic(
 			},
 
 			bson.D{
-				{Key: "$set", Value: bson.D{
-					{Key: "clinic", Value: clinicID},
+				{Key: "$addToSet", Value: bson.D{
+					{Key: "clinics", Value: clinicID},
 				}},
 			},
 		)
@@ -88,15 +93,10 @@ func (r *Repo) GetOwnerDetails(ctx context.Context, filter bson.M) ([]models.Own
 		bson.D{
 			{Key: "$lookup", Value: bson.D{
 				{Key: "from", Value: "Clinic"},
-				{Key: "localField", Value: "clinic"},
+				{Key: "localField", Value: "clinics"},
 				{Key: "foreignField", Value: "_id"},
 				{Key: "as", Value: "clinicDetails"},
 			}}},
-		bson.D{{Key: "$unwind", Value: bson.M{
-			"path":                       "$clinicDetails",
-			"preserveNullAndEmptyArrays": true,
-		}},
-		},
 	}
 	cursor, err := r.DB.Collection("Owner").Aggregate(ctx, pipeline)
 	if err != nil {
@@ -116,6 +116,7 @@ func (r *Repo) GetOwnerDetails(ctx context.Context, filter bson.M) ([]models.Own
 func (r *Repo) SearchClinic(ctx context.Context, filter bson.M) ([]models.Clinic, error) {
 	pipeline := mongo.Pipeline{
 		bson.D{{Key: "$match", Value: filter}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "rating.average", Value: -1}, {Key: "rating.count", Value: -1}, {Key: "_id", Value: 1}}}},
 		bson.D{{Key: "$lookup", Value: bson.D{
 			{Key: "from", Value: "Doctor"},
 			{Key: "localField", Value: "doctors"},
@@ -128,6 +129,7 @@ func (r *Repo) SearchClinic(ctx context.Context, filter bson.M) ([]models.Clinic
 				{Key: "ownerDetails", Value: 0},
 				{Key: "wallet", Value: 0},
 				{Key: "owner", Value: 0},
+				{Key: "statusHistory", Value: 0},
 			}},
 		},
 	}
@@ -151,7 +153,10 @@ func (r *Repo) RegisterDoctor(ctx context.Context, doctorDetails models.Doctor)
 	return err
 }
 
-func (r *Repo) SearchDoctors(ctx context.Context, filter bson.M) ([]models.DoctorPublicDetails, error) {
+// SearchDoctors returns public details of doctors matching filter, clinicFilter on looked up clinic fields (like
+// clinics.information.pincode) keeps only clinics matching it and doctors with at least one such clinic. clinics which
+// arent active are left out of doctors but the doctors are still returned
+func (r *Repo) SearchDoctors(ctx context.Context, filter bson.M, clinicFilter bson.M) ([]models.DoctorPublicDetails, error) {
 	pipeline := mongo.Pipeline{
 		// 1️⃣ Match doctors by filter
 		bson.D{{Key: "$match", Value: filter}},
@@ -183,12 +188,18 @@ func (r *Repo) SearchDoctors(ctx context.Context, filter bson.M) ([]models.Docto
 			{Key: "name", Value: bson.D{{Key: "$first", Value: "$name"}}},
 			{Key: "qualifications", Value: bson.D{{Key: "$first", Value: "$qualifications"}}},
 			{Key: "workingAt", Value: bson.D{{Key: "$first", Value: "$workingAt"}}},
+			{Key: "specialties", Value: bson.D{{Key: "$first", Value: "$specialties"}}},
+			{Key: "rating", Value: bson.D{{Key: "$first", Value: "$rating"}}},
 
-			// ⭐ CRITICAL FIX: Only push to array if clinics.clinic exists
+			// ⭐ CRITICAL FIX: Only push to array if clinics.clinic exists, clinics which arent active are left out
 			{Key: "clinics", Value: bson.D{
 				{Key: "$push", Value: bson.D{
 					{Key: "$cond", Value: bson.A{
-						bson.D{{Key: "$gt", Value: bson.A{"$clinics.clinic", nil}}},
+						bson.D{{Key: "$and", Value: bson.A{
+							bson.D{{Key: "$gt", Value: bson.A{"$clinics.clinic", nil}}},
+							bson.D{{Key: "$eq", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$clinics.information.status", utils.ClinicStatusActive}}}, utils.ClinicStatusActive}}},
+							bson.D{{Key: "$eq", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$clinics.information.deletedAt", nil}}}, nil}}},
+						}}},
 						"$clinics",
 						"$$REMOVE",
 					}},
@@ -206,6 +217,22 @@ func (r *Repo) SearchDoctors(ctx context.Context, filter bson.M) ([]models.Docto
 				}},
 			}},
 		}}},
+
+		// 7️⃣ Replace specialty ids with their catalogue entries
+		bson.D{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: "Specialty"},
+			{Key: "localField", Value: "specialties"},
+			{Key: "foreignField", Value: "_id"},
+			{Key: "as", Value: "specialties"},
+		}}},
+
+		// 8️⃣ Best rated doctors first
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "rating.average", Value: -1}, {Key: "rating.count", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+
+	//clinics are filtered once their information is looked up and before they are grouped back
+	if len(clinicFilter) > 0 {
+		pipeline = slices.Insert(pipeline, 4, bson.D{{Key: "$match", Value: clinicFilter}})
 	}
 
 	cursor, err := r.DB.Collection("Doctor").Aggregate(ctx, pipeline)
@@ -229,3 +256,194 @@ func (r *Repo) SearchDoctor(ctx context.Context, filter bson.M) (models.Doctor,
 	err := result.Decode(&doctor)
 	return doctor, err
 }
+
+// GetClinic returns clinic as stored, unlike SearchClinic it keeps owner so ownership can be checked
+func (r *Repo) GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+	var clinic models.Clinic
+	err := r.DB.Collection("Clinic").FindOne(ctx, bson.M{"_id": clinicID}).Decode(&clinic)
+	return clinic, err
+}
+
+func (r *Repo) UpdateDoctor(ctx context.Context, doctorID primitive.ObjectID, set bson.M) error {
+	return r.updateFields(ctx, "Doctor", doctorID, set)
+}
+
+func (r *Repo) UpdateClinic(ctx context.Context, clinicID primitive.ObjectID, set bson.M) error {
+	return r.updateFields(ctx, "Clinic", clinicID, set)
+}
+
+func (r *Repo) UpdateOwner(ctx context.Context, ownerID primitive.ObjectID, set bson.M) error {
+	return r.updateFields(ctx, "Owner", ownerID, set)
+}
+
+// updateFields sets fields of document with id, returns mongo.ErrNoDocuments if there is no such document
+func (r *Repo) updateFields(ctx context.Context, collection string, id primitive.ObjectID, set bson.M) error {
+	res, err := r.DB.Collection(collection).UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// CreateIndexes creates indexes clinic module relies on, clinics are searched by distance from their location
+func (r *Repo) CreateIndexes(ctx context.Context) error {
+	_, err := r.DB.Collection("Clinic").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "location", Value: "2dsphere"}},
+	})
+	return err
+}
+
+// NearbyClinics returns up to limit active clinics within maxDistance metres of origin, nearest first with distance in
+// kilometres
+func (r *Repo) NearbyClinics(ctx context.Context, origin models.GeoPoint, maxDistance float64, limit int64) ([]models.NearbyClinic, error) {
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$geoNear", Value: bson.D{
+			{Key: "near", Value: origin},
+			{Key: "distanceField", Value: "distance"},
+			{Key: "maxDistance", Value: maxDistance},
+			{Key: "distanceMultiplier", Value: 0.001},
+			{Key: "spherical", Value: true},
+			{Key: "query", Value: scheduling.ActiveClinicFilter("")},
+		}}},
+		bson.D{{Key: "$limit", Value: limit}},
+		bson.D{{Key: "$project", Value: bson.D{
+			{Key: "registrationDate", Value: 0},
+			{Key: "ownerDetails", Value: 0},
+			{Key: "wallet", Value: 0},
+			{Key: "owner", Value: 0},
+			{Key: "statusHistory", Value: 0},
+		}}},
+	}
+
+	cursor, err := r.DB.Collection("Clinic").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	clinics := []models.NearbyClinic{}
+	if err := cursor.All(ctx, &clinics); err != nil {
+		return nil, err
+	}
+	return clinics, nil
+}
+
+// ClinicsWithoutLocation returns id and pincode of clinics registered before they had a location
+func (r *Repo) ClinicsWithoutLocation(ctx context.Context) ([]models.Clinic, error) {
+	opts := options.Find().SetProjection(bson.M{"pincode": 1})
+	cursor, err := r.DB.Collection("Clinic").Find(ctx, bson.M{"location": bson.M{"$exists": false}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var clinics []models.Clinic
+	if err := cursor.All(ctx, &clinics); err != nil {
+		return nil, err
+	}
+	return clinics, nil
+}
+
+// OwnersWithLegacyClinic returns id and clinics of owners still having the single clinic field or no clinics array
+func (r *Repo) OwnersWithLegacyClinic(ctx context.Context) ([]models.Owner, error) {
+	legacy := bson.M{"$or": bson.A{
+		bson.M{"clinic": bson.M{"$exists": true}},
+		bson.M{"clinics": bson.M{"$exists": false}},
+	}}
+	opts := options.Find().SetProjection(bson.M{"clinics": 1})
+	cursor, err := r.DB.Collection("Owner").Find(ctx, legacy, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var owners []models.Owner
+	if err := cursor.All(ctx, &owners); err != nil {
+		return nil, err
+	}
+	return owners, nil
+}
+
+// ClinicsOfOwners returns id and owner of every clinic owned by any of ownerIDs
+func (r *Repo) ClinicsOfOwners(ctx context.Context, ownerIDs []primitive.ObjectID) ([]models.Clinic, error) {
+	opts := options.Find().SetProjection(bson.M{"owner": 1})
+	cursor, err := r.DB.Collection("Clinic").Find(ctx, bson.M{"owner": bson.M{"$in": ownerIDs}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var clinics []models.Clinic
+	if err := cursor.All(ctx, &clinics); err != nil {
+		return nil, err
+	}
+	return clinics, nil
+}
+
+// SetOwnerClinics saves clinics of owner and drops the single clinic field it had before
+func (r *Repo) SetOwnerClinics(ctx context.Context, ownerID primitive.ObjectID, clinicIDs []primitive.ObjectID) error {
+	_, err := r.DB.Collection("Owner").UpdateOne(ctx, bson.M{"_id": ownerID}, bson.M{
+		"$set":   bson.M{"clinics": clinicIDs},
+		"$unset": bson.M{"clinic": ""},
+	})
+	return err
+}
+
+// GetWallet returns wallet of clinic, mongo.ErrNoDocuments if clinic has none yet
+func (r *Repo) GetWallet(ctx context.Context, clinicID primitive.ObjectID) (models.WalletDetails, error) {
+	var wallet models.WalletDetails
+	err := r.DB.Collection("Wallet").FindOne(ctx, bson.M{"clinic": clinicID}).Decode(&wallet)
+	return wallet, err
+}
+
+// ChangeClinicStatus records change on clinic if it is still in change.From and deleted or not as it was read, deletedAt
+// is set when change deletes clinic and unset otherwise. returns mongo.ErrNoDocuments if clinic was changed meanwhile
+func (r *Repo) ChangeClinicStatus(ctx context.Context, clinic models.Clinic, change models.ClinicStatusChange) error {
+	filter := bson.M{"_id": clinic.ID, "status": change.From, "deletedAt": nil}
+	if change.From == utils.ClinicStatusActive {
+		filter["status"] = bson.M{"$in": bson.A{nil, utils.ClinicStatusActive}}
+	}
+	if clinic.DeletedAt != nil {
+		filter["deletedAt"] = bson.M{"$ne": nil}
+	}
+
+	set := bson.M{"status": change.To}
+	update := bson.M{"$set": set, "$push": bson.M{"statusHistory": change}}
+	if change.Deleted {
+		set["deletedAt"] = change.At
+	} else {
+		update["$unset"] = bson.M{"deletedAt": ""}
+	}
+
+	res, err := r.DB.Collection("Clinic").UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// GetClosures returns closures (holidays) of clinics overlapping from till to, leaves of their doctors are left out
+func (r *Repo) GetClosures(ctx context.Context, clinicIDs []primitive.ObjectID, from time.Time, to time.Time) ([]models.Leave, error) {
+	cursor, err := r.DB.Collection("Leave").Find(ctx, bson.M{
+		"clinic": bson.M{"$in": clinicIDs},
+		"doctor": bson.M{"$exists": false},
+		"start":  bson.M{"$lt": to},
+		"end":    bson.M{"$gt": from},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	closures := []models.Leave{}
+	if err := cursor.All(ctx, &closures); err != nil {
+		return nil, err
+	}
+	return closures, nil
+}
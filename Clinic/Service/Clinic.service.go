@@ -5,11 +5,14 @@ import (
 	interfaces "AlShifa/Clinic/Interfaces"
 	validators "AlShifa/Clinic/Validators"
 	"AlShifa/Clinic/models"
+	scheduling "AlShifa/Scheduling"
+	specialtyInterfaces "AlShifa/Specialty/Interfaces"
 	structs "AlShifa/Structs"
 	utils "AlShifa/Utils"
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -18,31 +21,32 @@ import (
 )
 
 type ClinicService struct {
-	Repo interfaces.IRepository
+	Repo        interfaces.IRepository
+	Specialties specialtyInterfaces.IService
+	Listeners   []interfaces.DeactivationListener
 }
 
-func NewClinicService(repo interfaces.IRepository) *ClinicService {
+// NewClinicService creates clinic service, specialties resolve what patients search doctors by
+func NewClinicService(repo interfaces.IRepository, specialties specialtyInterfaces.IService) *ClinicService {
 	return &ClinicService{
-		Repo: repo,
+		Repo:        repo,
+		Specialties: specialties,
 	}
 }
 
 ///this ensures this service layer implements all methods of service layer interface
 var _ interfaces.IService = (*ClinicService)(nil)
 
-func (service *ClinicService) RegisterClinic(ctx context.Context, ownerID string, clinicDetails models.Clinic) *structs.IAppError {
-	//first convert ownerID to proper mongodb id
-	ownerMongoDBID, err := primitive.ObjectIDFromHex(string(ownerID))
-	if err != nil {
-		fmt.Print(err)
-		return utils.ReturnAppError(err, 500, "Unable TO Register CLinic", "Server Error")
-	}
-
+// RegisterClinic registers clinic for owner, ownerID is the authenticated caller and is never taken from the request
+func (service *ClinicService) RegisterClinic(ctx context.Context, ownerID primitive.ObjectID, clinicDetails models.Clinic) *structs.IAppError {
 	//now first check if against this ownerId owner exists or not
-	_, ownerExistingErr := service.Repo.GetOwnerDetails(ctx, bson.M{"_id": ownerMongoDBID})
+	owners, ownerExistingErr := service.Repo.GetOwnerDetails(ctx, bson.M{"_id": ownerID})
 	if ownerExistingErr != nil {
 		return utils.ReturnAppError(ownerExistingErr, 500, "Failed to register clinic", "Server error")
 	}
+	if len(owners) == 0 {
+		return utils.ReturnAppError(errors.New("owner not found"), 404, "Failed to register clinic", "Owner Doesnt Exist")
+	}
 
 	//first do validation
 	validationErr := validators.ValidateClinicDetails(&clinicDetails)
@@ -55,7 +59,13 @@ func (service *ClinicService) RegisterClinic(ctx context.Context, ownerID string
 	clinicDetails.Wallet = primitive.NilObjectID
 	clinicDetails.ID = primitive.NewObjectID()
 	clinicDetails.Doctors = nil
-	registrationErr := service.Repo.RegisterClinic(ctx, ownerMongoDBID, clinicDetails)
+	clinicDetails.Rating = models.Rating{}
+	clinicDetails.LocationApprox = false
+	clinicDetails.Status = utils.ClinicStatusActive
+	clinicDetails.StatusHistory = nil
+	clinicDetails.DeletedAt = nil
+	locate(&clinicDetails)
+	registrationErr := service.Repo.RegisterClinic(ctx, ownerID, clinicDetails)
 	if registrationErr != nil {
 		fmt.Print(registrationErr)
 		return utils.ReturnAppError(registrationErr, 500, "Registration Failed", "Unknown reason")
@@ -87,7 +97,7 @@ func (service *ClinicService) RegisterClinicOwner(ctx context.Context, ownerDeta
 	ownerDetails.Password = hashedPassword
 
 	ownerDetails.RegistrationDate = time.Now().UTC()
-	ownerDetails.Clinic = primitive.NilObjectID
+	ownerDetails.Clinics = []primitive.ObjectID{}
 	ownerDetails.ID = primitive.NewObjectID()
 	ownerDetails.Role = utils.RoleClinicOwner
 
@@ -104,13 +114,44 @@ func (service *ClinicService) RegisterClinicOwner(ctx context.Context, ownerDeta
 
 }
 
-func (service *ClinicService) SearchClinic(ctx context.Context, filter bson.M) ([]models.Clinic, *structs.IAppError) {
+// SearchClinic searches clinics by filter and tells whether each of them is open now, when openAt is given only clinics
+// open at that moment are kept
+func (service *ClinicService) SearchClinic(ctx context.Context, filter bson.M, openAt *time.Time) ([]models.Clinic, *structs.IAppError) {
+	now := time.Now().UTC()
+	if appErr := validateOpenAt(openAt, now); appErr != nil {
+		return nil, appErr
+	}
+
 	clinics, err := service.Repo.SearchClinic(ctx, filter)
 	if err != nil {
 		return nil, utils.ReturnAppError(err, 500, "Unable To Fetch Clinic details", "Server Error")
 	}
 
-	return clinics, nil
+	from, to := now, now
+	if openAt != nil && openAt.Before(now) {
+		from = *openAt
+	} else if openAt != nil {
+		to = *openAt
+	}
+	clinicIDs := make([]primitive.ObjectID, 0, len(clinics))
+	for _, clinic := range clinics {
+		clinicIDs = append(clinicIDs, clinic.ID)
+	}
+	closures, appErr := service.closuresOf(ctx, clinicIDs, from, to)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	open := clinics[:0]
+	for _, clinic := range clinics {
+		status, isOpen := openingStatus(clinic, closures[clinic.ID], now, openAt)
+		if !isOpen {
+			continue
+		}
+		clinic.OpeningStatus = &status
+		open = append(open, clinic)
+	}
+	return open, nil
 }
 
 func (service *ClinicService) SearchOwner(ctx context.Context, filter bson.M) ([]models.Owner, *structs.IAppError) {
@@ -131,7 +172,7 @@ func (service *ClinicService) RegisterDoctor(ctx context.Context, doctor models.
 			{"email": doctor.Email},
 			{"mobile": doctor.Mobile},
 		},
-	})
+	}, nil)
 
 	///if error is nill check if it is of other type  and return error
 	if err != nil {
@@ -147,6 +188,8 @@ func (service *ClinicService) RegisterDoctor(ctx context.Context, doctor models.
 	//here set the default values
 	doctor.Clinics = nil
 	doctor.Appointments = nil
+	doctor.Specialties = nil
+	doctor.Rating = models.Rating{}
 	doctor.RegistrationDate = time.Now()
 	doctor.ID = primitive.NewObjectID()
 	doctor.Role = utils.RoleDoctor
@@ -164,13 +207,36 @@ func (service *ClinicService) RegisterDoctor(ctx context.Context, doctor models.
 	return nil
 }
 
-func (service *ClinicService) SearchDoctor(ctx context.Context, filter bson.M) ([]models.DoctorPublicDetails, error) {
+// SearchDoctor searches doctors by filter, specialty is resolved through the catalogue (so skin finds dermatologists) and
+// pincode keeps only clinics in it. both are ignored when empty
+func (service *ClinicService) SearchDoctor(ctx context.Context, filter bson.M, specialty string, pincode int32) ([]models.DoctorPublicDetails, *structs.IAppError) {
 	// //here validate filters
 	// allowedFilters := []string{"_id", "name", "mobile", "email"}
 	// for keys := range filter {
 
 	// }
-	return service.Repo.SearchDoctors(ctx, filter)
+	if strings.TrimSpace(specialty) != "" {
+		specialtyIDs, appErr := service.Specialties.MatchSpecialties(ctx, specialty)
+		if appErr != nil {
+			return nil, appErr
+		}
+		if len(specialtyIDs) == 0 {
+			return []models.DoctorPublicDetails{}, nil
+		}
+		filter["specialties"] = bson.M{"$in": specialtyIDs}
+	}
+
+	var clinicFilter bson.M
+	if pincode != 0 {
+		clinicFilter = scheduling.ActiveClinicFilter("clinics.information.")
+		clinicFilter["clinics.information.pincode"] = pincode
+	}
+
+	doctors, err := service.Repo.SearchDoctors(ctx, filter, clinicFilter)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, 500, "Unable To Fetch Doctors Details", "Server Error")
+	}
+	return doctors, nil
 }
 
 func (service *ClinicService) LoginClinicOwner(ctx context.Context, email string, password string) (string, *structs.IAppError) {
@@ -0,0 +1,159 @@
+package service
+
+import (
+	interfaces "AlShifa/Clinic/Interfaces"
+	"AlShifa/Clinic/models"
+	middleware "AlShifa/Middleware"
+	scheduling "AlShifa/Scheduling"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AddDeactivationListener registers listener to be notified whenever a clinic stops being active
+func (service *ClinicService) AddDeactivationListener(listener interfaces.DeactivationListener) {
+	service.Listeners = append(service.Listeners, listener)
+}
+
+// SetClinicStatus moves clinic between active, suspended and closed. owners close and reopen their clinic while only
+// admin suspends a clinic or lifts a suspension. repeating the current status of an inactive clinic
+// winds it down again in case an earlier attempt failed midway
+func (service *ClinicService) SetClinicStatus(ctx context.Context, actorID primitive.ObjectID, actorRole string, clinicID primitive.ObjectID, status string, reason string) (*models.Clinic, *structs.IAppError) {
+	if !slices.Contains([]string{utils.ClinicStatusActive, utils.ClinicStatusSuspended, utils.ClinicStatusClosed}, status) {
+		return nil, utils.ReturnAppError(errors.New("invalid status"), http.StatusBadRequest, "Invalid Status", "Status Must Be Active, Suspended Or Closed")
+	}
+	if appErr := validateStatusReason(reason); appErr != nil {
+		return nil, appErr
+	}
+
+	clinic, appErr := service.clinicOfActor(ctx, actorID, actorRole, clinicID)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if clinic.DeletedAt != nil {
+		return nil, utils.ReturnAppError(errors.New("clinic deleted"), http.StatusConflict, "Unable To Change Clinic Status", "Clinic Is Deleted, Restore It First")
+	}
+
+	from := clinicStatus(clinic)
+	if actorRole != utils.RoleAdmin && (status == utils.ClinicStatusSuspended || from == utils.ClinicStatusSuspended) {
+		return nil, utils.ReturnAppError(errors.New("suspension by admin"), http.StatusForbidden, "Unable To Change Clinic Status", "Only Admin Can Suspend A Clinic Or Lift Its Suspension")
+	}
+
+	return service.changeStatus(ctx, clinic, models.ClinicStatusChange{From: from, To: status, Actor: actorID, ActorRole: actorRole, Reason: reason})
+}
+
+// DeleteClinic soft deletes clinic, it is hidden everywhere and cant be booked until admin restores it. a suspended
+// clinic stays suspended so deleting and restoring it doesnt lift the suspension
+func (service *ClinicService) DeleteClinic(ctx context.Context, actorID primitive.ObjectID, actorRole string, clinicID primitive.ObjectID, reason string) *structs.IAppError {
+	if appErr := validateStatusReason(reason); appErr != nil {
+		return appErr
+	}
+
+	clinic, appErr := service.clinicOfActor(ctx, actorID, actorRole, clinicID)
+	if appErr != nil {
+		return appErr
+	}
+
+	from := clinicStatus(clinic)
+	to := utils.ClinicStatusClosed
+	if from == utils.ClinicStatusSuspended {
+		to = from
+	}
+	_, appErr = service.changeStatus(ctx, clinic, models.ClinicStatusChange{From: from, To: to, Deleted: true, Actor: actorID, ActorRole: actorRole, Reason: reason})
+	return appErr
+}
+
+// RestoreClinic reverses soft delete of clinic by admin, it is active again unless it was suspended. appointments
+// cancelled and affiliations ended when it was deleted stay so, doctors have to be invited again
+func (service *ClinicService) RestoreClinic(ctx context.Context, actorID primitive.ObjectID, actorRole string, clinicID primitive.ObjectID, reason string) (*models.Clinic, *structs.IAppError) {
+	if actorRole != utils.RoleAdmin {
+		return nil, utils.ReturnAppError(errors.New("restore by admin"), http.StatusForbidden, "Unable To Restore Clinic", "Only Admin Can Restore A Clinic")
+	}
+	if appErr := validateStatusReason(reason); appErr != nil {
+		return nil, appErr
+	}
+
+	clinic, appErr := service.clinicOfActor(ctx, actorID, actorRole, clinicID)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if clinic.DeletedAt == nil {
+		return nil, utils.ReturnAppError(errors.New("clinic not deleted"), http.StatusConflict, "Unable To Restore Clinic", "Clinic Is Not Deleted")
+	}
+
+	from := clinicStatus(clinic)
+	to := utils.ClinicStatusActive
+	if from == utils.ClinicStatusSuspended {
+		to = from
+	}
+	return service.changeStatus(ctx, clinic, models.ClinicStatusChange{From: from, To: to, Actor: actorID, ActorRole: utils.RoleAdmin, Reason: reason})
+}
+
+// changeStatus saves change unless clinic is already in it and then lets listeners wind down the clinic if it isnt
+// active afterwards. the change stays saved when a listener fails, repeating it runs the listeners again
+func (service *ClinicService) changeStatus(ctx context.Context, clinic models.Clinic, change models.ClinicStatusChange) (*models.Clinic, *structs.IAppError) {
+	change.At = time.Now().UTC()
+	if change.From != change.To || change.Deleted != (clinic.DeletedAt != nil) {
+		if err := service.Repo.ChangeClinicStatus(ctx, clinic, change); err != nil {
+			if err == mongo.ErrNoDocuments {
+				return nil, utils.ReturnAppError(err, http.StatusConflict, "Clinic Was Updated Meanwhile", "Status Changed, Please Retry")
+			}
+			return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Change Clinic Status", "Server Error")
+		}
+
+		clinic.Status = change.To
+		clinic.StatusHistory = append(clinic.StatusHistory, change)
+		clinic.DeletedAt = nil
+		if change.Deleted {
+			clinic.DeletedAt = &change.At
+		}
+	}
+
+	if scheduling.ClinicActive(clinic) {
+		return &clinic, nil
+	}
+	for _, listener := range service.Listeners {
+		if err := listener.ClinicDeactivated(ctx, clinic, change); err != nil {
+			log.Printf("failed to wind down clinic %s: %v", clinic.ID.Hex(), err)
+			return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Clinic Status Changed But Winding It Down Failed", "Repeat The Change To Finish It")
+		}
+	}
+	return &clinic, nil
+}
+
+// clinicOfActor returns clinic which actor acts for, admin acts for every clinic. clinics of others are reported as
+// not found so ids cant be probed
+func (service *ClinicService) clinicOfActor(ctx context.Context, actorID primitive.ObjectID, actorRole string, clinicID primitive.ObjectID) (models.Clinic, *structs.IAppError) {
+	clinic, err := service.Repo.GetClinic(ctx, clinicID)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return models.Clinic{}, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Clinic", "Server Error")
+	}
+	if err == mongo.ErrNoDocuments || (actorRole != utils.RoleAdmin && !middleware.ActsForClinic(ctx, actorID, clinic.Owner, clinic.ID)) {
+		return models.Clinic{}, utils.ReturnAppError(errors.New("clinic of someone else"), http.StatusNotFound, "Clinic Not Found", "Invalid Clinic")
+	}
+	return clinic, nil
+}
+
+// clinicStatus returns status of clinic, clinics registered before statuses are active
+func clinicStatus(clinic models.Clinic) string {
+	if clinic.Status == "" {
+		return utils.ClinicStatusActive
+	}
+	return clinic.Status
+}
+
+func validateStatusReason(reason string) *structs.IAppError {
+	if len(reason) > utils.MaxClinicStatusReasonLength {
+		return utils.ReturnAppError(errors.New("reason too long"), http.StatusBadRequest, "Invalid Reason", fmt.Sprintf("Reason Cant Be Longer Than %d Characters", utils.MaxClinicStatusReasonLength))
+	}
+	return nil
+}
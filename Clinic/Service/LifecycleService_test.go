@@ -0,0 +1,195 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	utils "AlShifa/Utils"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// recordingListener remembers clinics it was asked to wind down
+type recordingListener struct {
+	deactivated []models.ClinicStatusChange
+}
+
+func (l *recordingListener) ClinicDeactivated(ctx context.Context, clinic models.Clinic, change models.ClinicStatusChange) error {
+	l.deactivated = append(l.deactivated, change)
+	return nil
+}
+
+// lifecycleRepo returns clinic of owner and records the status change saved for it
+func lifecycleRepo(clinic models.Clinic, saved *models.ClinicStatusChange) *MockClinicRepo {
+	return &MockClinicRepo{
+		GetClinicFn: func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+			if clinicID != clinic.ID {
+				return models.Clinic{}, mongo.ErrNoDocuments
+			}
+			return clinic, nil
+		},
+		ChangeClinicStatusFn: func(ctx context.Context, clinic models.Clinic, change models.ClinicStatusChange) error {
+			*saved = change
+			return nil
+		},
+	}
+}
+
+func TestSetClinicStatus(t *testing.T) {
+	ownerID := primitive.NewObjectID()
+	adminID := primitive.NewObjectID()
+	deletedAt := time.Now().Add(-time.Hour)
+
+	testCases := []struct {
+		Name               string
+		Current            string
+		DeletedAt          *time.Time
+		Status             string
+		ActorID            primitive.ObjectID
+		ActorRole          string
+		ExpectedStatusCode int
+		ExpectedWindDown   bool
+	}{
+		{Name: "Owner closes clinic", Current: utils.ClinicStatusActive, Status: utils.ClinicStatusClosed, ActorID: ownerID, ActorRole: utils.RoleClinicOwner, ExpectedWindDown: true},
+		{Name: "Owner reopens closed clinic", Current: utils.ClinicStatusClosed, Status: utils.ClinicStatusActive, ActorID: ownerID, ActorRole: utils.RoleClinicOwner},
+		{Name: "Clinic registered before statuses counts as active", Current: "", Status: utils.ClinicStatusClosed, ActorID: ownerID, ActorRole: utils.RoleClinicOwner, ExpectedWindDown: true},
+		{Name: "Owner cant suspend clinic", Current: utils.ClinicStatusActive, Status: utils.ClinicStatusSuspended, ActorID: ownerID, ActorRole: utils.RoleClinicOwner, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Owner cant lift suspension", Current: utils.ClinicStatusSuspended, Status: utils.ClinicStatusActive, ActorID: ownerID, ActorRole: utils.RoleClinicOwner, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Owner cant close suspended clinic either", Current: utils.ClinicStatusSuspended, Status: utils.ClinicStatusClosed, ActorID: ownerID, ActorRole: utils.RoleClinicOwner, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Admin suspends clinic", Current: utils.ClinicStatusActive, Status: utils.ClinicStatusSuspended, ActorID: adminID, ActorRole: utils.RoleAdmin, ExpectedWindDown: true},
+		{Name: "Admin lifts suspension", Current: utils.ClinicStatusSuspended, Status: utils.ClinicStatusActive, ActorID: adminID, ActorRole: utils.RoleAdmin},
+		{Name: "Someone else cant change status of clinic", Current: utils.ClinicStatusActive, Status: utils.ClinicStatusClosed, ActorID: primitive.NewObjectID(), ActorRole: utils.RoleClinicOwner, ExpectedStatusCode: http.StatusNotFound},
+		{Name: "Status of deleted clinic cant change", Current: utils.ClinicStatusClosed, DeletedAt: &deletedAt, Status: utils.ClinicStatusActive, ActorID: ownerID, ActorRole: utils.RoleClinicOwner, ExpectedStatusCode: http.StatusConflict},
+		{Name: "Status of deleted clinic cant change even by admin", Current: utils.ClinicStatusClosed, DeletedAt: &deletedAt, Status: utils.ClinicStatusSuspended, ActorID: adminID, ActorRole: utils.RoleAdmin, ExpectedStatusCode: http.StatusConflict},
+		{Name: "Unknown status is rejected", Current: utils.ClinicStatusActive, Status: "Paused", ActorID: ownerID, ActorRole: utils.RoleClinicOwner, ExpectedStatusCode: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			clinic := models.Clinic{ID: primitive.NewObjectID(), Owner: ownerID, Status: tc.Current, DeletedAt: tc.DeletedAt}
+			var saved models.ClinicStatusChange
+			listener := &recordingListener{}
+			service := NewClinicService(lifecycleRepo(clinic, &saved), nil)
+			service.AddDeactivationListener(listener)
+
+			updated, appErr := service.SetClinicStatus(context.Background(), tc.ActorID, tc.ActorRole, clinic.ID, tc.Status, "")
+
+			if tc.ExpectedStatusCode != 0 {
+				if appErr == nil || appErr.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status code %d but got %v", tc.ExpectedStatusCode, appErr)
+				}
+				if saved.To != "" {
+					t.Fatalf("expected no change to be saved but got %+v", saved)
+				}
+				return
+			}
+
+			if appErr != nil {
+				t.Fatalf("expected no error but got %v", appErr)
+			}
+			if updated.Status != tc.Status || saved.To != tc.Status || saved.Actor != tc.ActorID || saved.ActorRole != tc.ActorRole || saved.Deleted {
+				t.Fatalf("change not saved properly %+v", saved)
+			}
+			if (len(listener.deactivated) == 1) != tc.ExpectedWindDown {
+				t.Fatalf("expected clinic to be wound down %v but listener got %+v", tc.ExpectedWindDown, listener.deactivated)
+			}
+		})
+	}
+}
+
+func TestDeleteClinic(t *testing.T) {
+	ownerID := primitive.NewObjectID()
+
+	testCases := []struct {
+		Name               string
+		Current            string
+		ActorID            primitive.ObjectID
+		ActorRole          string
+		ExpectedStatus     string
+		ExpectedStatusCode int
+	}{
+		{Name: "Owner deletes active clinic which closes it", Current: utils.ClinicStatusActive, ActorID: ownerID, ActorRole: utils.RoleClinicOwner, ExpectedStatus: utils.ClinicStatusClosed},
+		{Name: "Deleting suspended clinic keeps the suspension", Current: utils.ClinicStatusSuspended, ActorID: ownerID, ActorRole: utils.RoleClinicOwner, ExpectedStatus: utils.ClinicStatusSuspended},
+		{Name: "Admin deletes clinic of anyone", Current: utils.ClinicStatusActive, ActorID: primitive.NewObjectID(), ActorRole: utils.RoleAdmin, ExpectedStatus: utils.ClinicStatusClosed},
+		{Name: "Someone else cant delete clinic", Current: utils.ClinicStatusActive, ActorID: primitive.NewObjectID(), ActorRole: utils.RoleClinicOwner, ExpectedStatusCode: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			clinic := models.Clinic{ID: primitive.NewObjectID(), Owner: ownerID, Status: tc.Current}
+			var saved models.ClinicStatusChange
+			listener := &recordingListener{}
+			service := NewClinicService(lifecycleRepo(clinic, &saved), nil)
+			service.AddDeactivationListener(listener)
+
+			appErr := service.DeleteClinic(context.Background(), tc.ActorID, tc.ActorRole, clinic.ID, "")
+
+			if tc.ExpectedStatusCode != 0 {
+				if appErr == nil || appErr.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status code %d but got %v", tc.ExpectedStatusCode, appErr)
+				}
+				return
+			}
+
+			if appErr != nil {
+				t.Fatalf("expected no error but got %v", appErr)
+			}
+			if !saved.Deleted || saved.From != tc.Current || saved.To != tc.ExpectedStatus {
+				t.Fatalf("expected clinic deleted in status %s but got %+v", tc.ExpectedStatus, saved)
+			}
+			if len(listener.deactivated) != 1 {
+				t.Fatalf("expected deleted clinic to be wound down but listener got %+v", listener.deactivated)
+			}
+		})
+	}
+}
+
+func TestRestoreClinic(t *testing.T) {
+	ownerID := primitive.NewObjectID()
+	adminID := primitive.NewObjectID()
+	deletedAt := time.Now().Add(-time.Hour)
+
+	testCases := []struct {
+		Name               string
+		Current            string
+		DeletedAt          *time.Time
+		ActorID            primitive.ObjectID
+		ActorRole          string
+		ExpectedStatus     string
+		ExpectedStatusCode int
+	}{
+		{Name: "Admin restores deleted clinic", Current: utils.ClinicStatusClosed, DeletedAt: &deletedAt, ActorID: adminID, ActorRole: utils.RoleAdmin, ExpectedStatus: utils.ClinicStatusActive},
+		{Name: "Restored clinic stays suspended", Current: utils.ClinicStatusSuspended, DeletedAt: &deletedAt, ActorID: adminID, ActorRole: utils.RoleAdmin, ExpectedStatus: utils.ClinicStatusSuspended},
+		{Name: "Owner cant restore own clinic", Current: utils.ClinicStatusClosed, DeletedAt: &deletedAt, ActorID: ownerID, ActorRole: utils.RoleClinicOwner, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Staff cant restore clinic", Current: utils.ClinicStatusClosed, DeletedAt: &deletedAt, ActorID: primitive.NewObjectID(), ActorRole: utils.RoleStaff, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Clinic which isnt deleted cant be restored", Current: utils.ClinicStatusClosed, ActorID: adminID, ActorRole: utils.RoleAdmin, ExpectedStatusCode: http.StatusConflict},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			clinic := models.Clinic{ID: primitive.NewObjectID(), Owner: ownerID, Status: tc.Current, DeletedAt: tc.DeletedAt}
+			var saved models.ClinicStatusChange
+			service := NewClinicService(lifecycleRepo(clinic, &saved), nil)
+			service.AddDeactivationListener(&recordingListener{})
+
+			restored, appErr := service.RestoreClinic(context.Background(), tc.ActorID, tc.ActorRole, clinic.ID, "")
+
+			if tc.ExpectedStatusCode != 0 {
+				if appErr == nil || appErr.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status code %d but got %v", tc.ExpectedStatusCode, appErr)
+				}
+				return
+			}
+
+			if appErr != nil {
+				t.Fatalf("expected no error but got %v", appErr)
+			}
+			if saved.Deleted || saved.To != tc.ExpectedStatus || restored.DeletedAt != nil || restored.Status != tc.ExpectedStatus {
+				t.Fatalf("expected clinic restored in status %s but got %+v", tc.ExpectedStatus, saved)
+			}
+		})
+	}
+}
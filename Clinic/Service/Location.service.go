@@ -0,0 +1,87 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	geo "AlShifa/Geo"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NearbyClinics returns clinics within radius kilometres of origin nearest first with whether they are open now, centre
+// of pincode is the origin when coordinates arent given
+func (service *ClinicService) NearbyClinics(ctx context.Context, origin *models.GeoPoint, pincode int32, radius float64, limit int64) ([]models.NearbyClinic, *structs.IAppError) {
+	if origin == nil {
+		centroid, ok := geo.PincodeCentroid(pincode)
+		if !ok {
+			return nil, utils.ReturnAppError(errors.New("pincode not in dataset"), http.StatusNotFound, "Unable To Locate Pincode", "Search With Coordinates Instead")
+		}
+		origin = &centroid
+	}
+
+	clinics, err := service.Repo.NearbyClinics(ctx, *origin, radius*1000, limit)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Clinics", "Server Error")
+	}
+
+	now := time.Now().UTC()
+	clinicIDs := make([]primitive.ObjectID, 0, len(clinics))
+	for _, clinic := range clinics {
+		clinicIDs = append(clinicIDs, clinic.ID)
+	}
+	closures, appErr := service.closuresOf(ctx, clinicIDs, now, now)
+	if appErr != nil {
+		return nil, appErr
+	}
+	for i := range clinics {
+		status, _ := openingStatus(clinics[i].Clinic, closures[clinics[i].ID], now, nil)
+		clinics[i].OpeningStatus = &status
+	}
+	return clinics, nil
+}
+
+// BackfillLocations gives clinics registered without coordinates the centre of their pincode so they show up in nearby
+// searches, returns how many were located
+func (service *ClinicService) BackfillLocations(ctx context.Context) (int, error) {
+	clinics, err := service.Repo.ClinicsWithoutLocation(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	located := 0
+	for i := range clinics {
+		if !locate(&clinics[i]) {
+			continue
+		}
+		set := bson.M{"location": clinics[i].Location, "locationApprox": clinics[i].LocationApprox}
+		if err := service.Repo.UpdateClinic(ctx, clinics[i].ID, set); err != nil {
+			return located, err
+		}
+		located++
+	}
+	if located < len(clinics) {
+		log.Printf("%d clinics have pincodes missing from pincode dataset and cant be found by distance", len(clinics)-located)
+	}
+	return located, nil
+}
+
+// locate sets location of clinic without one to centre of its pincode, tells if location was set
+func locate(clinic *models.Clinic) bool {
+	if clinic.Location != nil {
+		return false
+	}
+	centroid, ok := geo.PincodeCentroid(clinic.Pincode)
+	if !ok {
+		return false
+	}
+	clinic.Location = &centroid
+	clinic.LocationApprox = true
+	return true
+}
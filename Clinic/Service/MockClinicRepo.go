@@ -0,0 +1,176 @@
+package service
+
+import (
+	interfaces "AlShifa/Clinic/Interfaces"
+	"AlShifa/Clinic/models"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockClinicRepo struct {
+	RegisterClinicFn         func(ctx context.Context, ownerID primitive.ObjectID, clinic models.Clinic) error
+	RegisterClinicOwnerFn    func(ctx context.Context, owner models.Owner) error
+	GetOwnerDetailsFn        func(ctx context.Context, filter bson.M) ([]models.Owner, error)
+	SearchClinicFn           func(ctx context.Context, filter bson.M) ([]models.Clinic, error)
+	RegisterDoctorFn         func(ctx context.Context, doctorDetails models.Doctor) error
+	SearchDoctorsFn          func(ctx context.Context, filter bson.M, clinicFilter bson.M) ([]models.DoctorPublicDetails, error)
+	SearchDoctorFn           func(ctx context.Context, filter bson.M) (models.Doctor, error)
+	GetClinicFn              func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error)
+	UpdateDoctorFn           func(ctx context.Context, doctorID primitive.ObjectID, set bson.M) error
+	UpdateClinicFn           func(ctx context.Context, clinicID primitive.ObjectID, set bson.M) error
+	UpdateOwnerFn            func(ctx context.Context, ownerID primitive.ObjectID, set bson.M) error
+	CreateIndexesFn          func(ctx context.Context) error
+	NearbyClinicsFn          func(ctx context.Context, origin models.GeoPoint, maxDistance float64, limit int64) ([]models.NearbyClinic, error)
+	ClinicsWithoutLocationFn func(ctx context.Context) ([]models.Clinic, error)
+	OwnersWithLegacyClinicFn func(ctx context.Context) ([]models.Owner, error)
+	ClinicsOfOwnersFn        func(ctx context.Context, ownerIDs []primitive.ObjectID) ([]models.Clinic, error)
+	SetOwnerClinicsFn        func(ctx context.Context, ownerID primitive.ObjectID, clinicIDs []primitive.ObjectID) error
+	GetWalletFn              func(ctx context.Context, clinicID primitive.ObjectID) (models.WalletDetails, error)
+	ChangeClinicStatusFn     func(ctx context.Context, clinic models.Clinic, change models.ClinicStatusChange) error
+	GetClosuresFn            func(ctx context.Context, clinicIDs []primitive.ObjectID, from time.Time, to time.Time) ([]models.Leave, error)
+}
+
+var _ interfaces.IRepository = (*MockClinicRepo)(nil)
+
+func (m *MockClinicRepo) RegisterClinic(ctx context.Context, ownerID primitive.ObjectID, clinic models.Clinic) error {
+	if m.RegisterClinicFn == nil {
+		panic("RegisterClinicFn not implemented inside mock")
+	}
+	return m.RegisterClinicFn(ctx, ownerID, clinic)
+}
+
+func (m *MockClinicRepo) RegisterClinicOwner(ctx context.Context, owner models.Owner) error {
+	if m.RegisterClinicOwnerFn == nil {
+		panic("RegisterClinicOwnerFn not implemented inside mock")
+	}
+	return m.RegisterClinicOwnerFn(ctx, owner)
+}
+
+func (m *MockClinicRepo) GetOwnerDetails(ctx context.Context, filter bson.M) ([]models.Owner, error) {
+	if m.GetOwnerDetailsFn == nil {
+		panic("GetOwnerDetailsFn not implemented inside mock")
+	}
+	return m.GetOwnerDetailsFn(ctx, filter)
+}
+
+func (m *MockClinicRepo) SearchClinic(ctx context.Context, filter bson.M) ([]models.Clinic, error) {
+	if m.SearchClinicFn == nil {
+		panic("SearchClinicFn not implemented inside mock")
+	}
+	return m.SearchClinicFn(ctx, filter)
+}
+
+func (m *MockClinicRepo) RegisterDoctor(ctx context.Context, doctorDetails models.Doctor) error {
+	if m.RegisterDoctorFn == nil {
+		panic("RegisterDoctorFn not implemented inside mock")
+	}
+	return m.RegisterDoctorFn(ctx, doctorDetails)
+}
+
+func (m *MockClinicRepo) SearchDoctors(ctx context.Context, filter bson.M, clinicFilter bson.M) ([]models.DoctorPublicDetails, error) {
+	if m.SearchDoctorsFn == nil {
+		panic("SearchDoctorsFn not implemented inside mock")
+	}
+	return m.SearchDoctorsFn(ctx, filter, clinicFilter)
+}
+
+func (m *MockClinicRepo) SearchDoctor(ctx context.Context, filter bson.M) (models.Doctor, error) {
+	if m.SearchDoctorFn == nil {
+		panic("SearchDoctorFn not implemented inside mock")
+	}
+	return m.SearchDoctorFn(ctx, filter)
+}
+
+func (m *MockClinicRepo) GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+	if m.GetClinicFn == nil {
+		panic("GetClinicFn not implemented inside mock")
+	}
+	return m.GetClinicFn(ctx, clinicID)
+}
+
+func (m *MockClinicRepo) UpdateDoctor(ctx context.Context, doctorID primitive.ObjectID, set bson.M) error {
+	if m.UpdateDoctorFn == nil {
+		panic("UpdateDoctorFn not implemented inside mock")
+	}
+	return m.UpdateDoctorFn(ctx, doctorID, set)
+}
+
+func (m *MockClinicRepo) UpdateClinic(ctx context.Context, clinicID primitive.ObjectID, set bson.M) error {
+	if m.UpdateClinicFn == nil {
+		panic("UpdateClinicFn not implemented inside mock")
+	}
+	return m.UpdateClinicFn(ctx, clinicID, set)
+}
+
+func (m *MockClinicRepo) UpdateOwner(ctx context.Context, ownerID primitive.ObjectID, set bson.M) error {
+	if m.UpdateOwnerFn == nil {
+		panic("UpdateOwnerFn not implemented inside mock")
+	}
+	return m.UpdateOwnerFn(ctx, ownerID, set)
+}
+
+func (m *MockClinicRepo) CreateIndexes(ctx context.Context) error {
+	if m.CreateIndexesFn == nil {
+		panic("CreateIndexesFn not implemented inside mock")
+	}
+	return m.CreateIndexesFn(ctx)
+}
+
+func (m *MockClinicRepo) NearbyClinics(ctx context.Context, origin models.GeoPoint, maxDistance float64, limit int64) ([]models.NearbyClinic, error) {
+	if m.NearbyClinicsFn == nil {
+		panic("NearbyClinicsFn not implemented inside mock")
+	}
+	return m.NearbyClinicsFn(ctx, origin, maxDistance, limit)
+}
+
+func (m *MockClinicRepo) ClinicsWithoutLocation(ctx context.Context) ([]models.Clinic, error) {
+	if m.ClinicsWithoutLocationFn == nil {
+		panic("ClinicsWithoutLocationFn not implemented inside mock")
+	}
+	return m.ClinicsWithoutLocationFn(ctx)
+}
+
+func (m *MockClinicRepo) OwnersWithLegacyClinic(ctx context.Context) ([]models.Owner, error) {
+	if m.OwnersWithLegacyClinicFn == nil {
+		panic("OwnersWithLegacyClinicFn not implemented inside mock")
+	}
+	return m.OwnersWithLegacyClinicFn(ctx)
+}
+
+func (m *MockClinicRepo) ClinicsOfOwners(ctx context.Context, ownerIDs []primitive.ObjectID) ([]models.Clinic, error) {
+	if m.ClinicsOfOwnersFn == nil {
+		panic("ClinicsOfOwnersFn not implemented inside mock")
+	}
+	return m.ClinicsOfOwnersFn(ctx, ownerIDs)
+}
+
+func (m *MockClinicRepo) SetOwnerClinics(ctx context.Context, ownerID primitive.ObjectID, clinicIDs []primitive.ObjectID) error {
+	if m.SetOwnerClinicsFn == nil {
+		panic("SetOwnerClinicsFn not implemented inside mock")
+	}
+	return m.SetOwnerClinicsFn(ctx, ownerID, clinicIDs)
+}
+
+func (m *MockClinicRepo) GetWallet(ctx context.Context, clinicID primitive.ObjectID) (models.WalletDetails, error) {
+	if m.GetWalletFn == nil {
+		panic("GetWalletFn not implemented inside mock")
+	}
+	return m.GetWalletFn(ctx, clinicID)
+}
+
+func (m *MockClinicRepo) ChangeClinicStatus(ctx context.Context, clinic models.Clinic, change models.ClinicStatusChange) error {
+	if m.ChangeClinicStatusFn == nil {
+		panic("ChangeClinicStatusFn not implemented inside mock")
+	}
+	return m.ChangeClinicStatusFn(ctx, clinic, change)
+}
+
+func (m *MockClinicRepo) GetClosures(ctx context.Context, clinicIDs []primitive.ObjectID, from time.Time, to time.Time) ([]models.Leave, error) {
+	if m.GetClosuresFn == nil {
+		panic("GetClosuresFn not implemented inside mock")
+	}
+	return m.GetClosuresFn(ctx, clinicIDs, from, to)
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	scheduling "AlShifa/Scheduling"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// closuresOf returns closures of clinics which can affect their opening status between from and to, grouped by clinic
+func (service *ClinicService) closuresOf(ctx context.Context, clinicIDs []primitive.ObjectID, from time.Time, to time.Time) (map[primitive.ObjectID][]models.Leave, *structs.IAppError) {
+	byClinic := map[primitive.ObjectID][]models.Leave{}
+	if len(clinicIDs) == 0 {
+		return byClinic, nil
+	}
+
+	//opening status looks from the start of the day in clinic time zone till next opening
+	closures, err := service.Repo.GetClosures(ctx, clinicIDs, from.AddDate(0, 0, -1), to.AddDate(0, 0, utils.OpeningLookaheadDays+1))
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Clinic details", "Server Error")
+	}
+	for _, closure := range closures {
+		if closure.Clinic != nil {
+			byClinic[*closure.Clinic] = append(byClinic[*closure.Clinic], closure)
+		}
+	}
+	return byClinic, nil
+}
+
+// validateOpenAt makes sure clinics arent asked to be open too far from now
+func validateOpenAt(openAt *time.Time, now time.Time) *structs.IAppError {
+	if openAt == nil {
+		return nil
+	}
+	if gap := openAt.Sub(now); gap > utils.MaxOpenAtDays*24*time.Hour || gap < -utils.MaxOpenAtDays*24*time.Hour {
+		return utils.ReturnAppError(errors.New("openAt out of range"), http.StatusBadRequest, "Invalid Opening Time", "openAt Must Be Within A Year From Now")
+	}
+	return nil
+}
+
+// openingStatus returns status of clinic at now and tells if clinic is open at openAt, every clinic qualifies when openAt is nil
+func openingStatus(clinic models.Clinic, closures []models.Leave, now time.Time, openAt *time.Time) (models.OpeningStatus, bool) {
+	status := scheduling.OpeningStatusAt(clinic, closures, now)
+	if openAt == nil {
+		return status, true
+	}
+	if openAt.Equal(now) {
+		return status, status.Status == utils.OpeningStatusOpen
+	}
+	return status, scheduling.OpeningStatusAt(clinic, closures, *openAt).Status == utils.OpeningStatusOpen
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetActiveClinic returns the clinic owner has switched to, clinicID is nil when owner didnt pick one and then the only
+// clinic of owner is used. owners of many clinics have to pick one and clinics of others are reported as not found
+func (service *ClinicService) GetActiveClinic(ctx context.Context, ownerID primitive.ObjectID, clinicID primitive.ObjectID) (*models.Clinic, *structs.IAppError) {
+	if clinicID.IsZero() {
+		owners, err := service.Repo.GetOwnerDetails(ctx, bson.M{"_id": ownerID})
+		if err != nil {
+			return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Clinic", "Server Error")
+		}
+		if len(owners) == 0 || len(owners[0].Clinics) == 0 {
+			return nil, utils.ReturnAppError(errors.New("owner has no clinic"), http.StatusNotFound, "Clinic Not Found", "Owner Has No Clinic")
+		}
+		if len(owners[0].Clinics) > 1 {
+			return nil, utils.ReturnAppError(errors.New("owner has many clinics"), http.StatusBadRequest, "Active Clinic Required", "Pick A Clinic Using "+utils.ActiveClinicHeader+" Header")
+		}
+		clinicID = owners[0].Clinics[0]
+	}
+
+	clinic, err := service.Repo.GetClinic(ctx, clinicID)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Clinic", "Server Error")
+	}
+	if err == mongo.ErrNoDocuments || clinic.Owner != ownerID {
+		return nil, utils.ReturnAppError(errors.New("clinic of someone else"), http.StatusNotFound, "Clinic Not Found", "Invalid Clinic")
+	}
+	return &clinic, nil
+}
+
+// MigrateOwnerClinics moves owners from the single clinic field to the clinics array. registering a second clinic used
+// to overwrite the first so clinics are collected back from the owner field of every clinic, returns how many owners
+// were migrated
+func (service *ClinicService) MigrateOwnerClinics(ctx context.Context) (int, error) {
+	owners, err := service.Repo.OwnersWithLegacyClinic(ctx)
+	if err != nil || len(owners) == 0 {
+		return 0, err
+	}
+
+	ownerIDs := make([]primitive.ObjectID, 0, len(owners))
+	for _, owner := range owners {
+		ownerIDs = append(ownerIDs, owner.ID)
+	}
+	clinics, err := service.Repo.ClinicsOfOwners(ctx, ownerIDs)
+	if err != nil {
+		return 0, err
+	}
+	owned := make(map[primitive.ObjectID][]primitive.ObjectID, len(owners))
+	for _, clinic := range clinics {
+		owned[clinic.Owner] = append(owned[clinic.Owner], clinic.ID)
+	}
+
+	migrated := 0
+	for _, owner := range owners {
+		clinicIDs := append([]primitive.ObjectID{}, owner.Clinics...)
+		for _, clinicID := range owned[owner.ID] {
+			if !slices.Contains(clinicIDs, clinicID) {
+				clinicIDs = append(clinicIDs, clinicID)
+			}
+		}
+		if err := service.Repo.SetOwnerClinics(ctx, owner.ID, clinicIDs); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+	return migrated, nil
+}
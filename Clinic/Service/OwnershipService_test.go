@@ -0,0 +1,161 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestGetActiveClinic(t *testing.T) {
+	ownerID := primitive.NewObjectID()
+	firstClinic := primitive.NewObjectID()
+	secondClinic := primitive.NewObjectID()
+	clinicOfOther := primitive.NewObjectID()
+	clinics := map[primitive.ObjectID]models.Clinic{
+		firstClinic:   {ID: firstClinic, Owner: ownerID},
+		secondClinic:  {ID: secondClinic, Owner: ownerID},
+		clinicOfOther: {ID: clinicOfOther, Owner: primitive.NewObjectID()},
+	}
+
+	testCases := []struct {
+		Name               string
+		OwnedClinics       []primitive.ObjectID
+		Header             primitive.ObjectID
+		ExpectedClinic     primitive.ObjectID
+		ExpectedStatusCode int
+	}{
+		{Name: "Only clinic of owner is used without header", OwnedClinics: []primitive.ObjectID{firstClinic}, ExpectedClinic: firstClinic},
+		{Name: "Owner of many clinics has to pick one", OwnedClinics: []primitive.ObjectID{firstClinic, secondClinic}, ExpectedStatusCode: http.StatusBadRequest},
+		{Name: "Owner of many clinics switches to the one in header", OwnedClinics: []primitive.ObjectID{firstClinic, secondClinic}, Header: secondClinic, ExpectedClinic: secondClinic},
+		{Name: "Owner without clinic gets not found", OwnedClinics: nil, ExpectedStatusCode: http.StatusNotFound},
+		{Name: "Header naming clinic of someone else is not found", OwnedClinics: []primitive.ObjectID{firstClinic}, Header: clinicOfOther, ExpectedStatusCode: http.StatusNotFound},
+		{Name: "Header naming clinic which doesnt exist is not found", OwnedClinics: []primitive.ObjectID{firstClinic}, Header: primitive.NewObjectID(), ExpectedStatusCode: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			mockRepo := &MockClinicRepo{
+				GetOwnerDetailsFn: func(ctx context.Context, filter bson.M) ([]models.Owner, error) {
+					if filter["_id"] != ownerID {
+						t.Fatalf("expected details of owner %s but got filter %v", ownerID.Hex(), filter)
+					}
+					return []models.Owner{{ID: ownerID, Clinics: tc.OwnedClinics}}, nil
+				},
+				GetClinicFn: func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+					clinic, ok := clinics[clinicID]
+					if !ok {
+						return models.Clinic{}, mongo.ErrNoDocuments
+					}
+					return clinic, nil
+				},
+			}
+
+			service := NewClinicService(mockRepo, nil)
+			clinic, appErr := service.GetActiveClinic(context.Background(), ownerID, tc.Header)
+
+			if tc.ExpectedStatusCode != 0 {
+				if appErr == nil || appErr.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status code %d but got %v", tc.ExpectedStatusCode, appErr)
+				}
+				return
+			}
+
+			if appErr != nil {
+				t.Fatalf("expected no error but got %v", appErr)
+			}
+			if clinic.ID != tc.ExpectedClinic {
+				t.Fatalf("expected clinic %s but got %s", tc.ExpectedClinic.Hex(), clinic.ID.Hex())
+			}
+		})
+	}
+}
+
+func TestMigrateOwnerClinics(t *testing.T) {
+	overwritten := primitive.NewObjectID()
+	partlyMigrated := primitive.NewObjectID()
+	withoutClinic := primitive.NewObjectID()
+	first, second, third, fourth := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+
+	saved := map[primitive.ObjectID][]primitive.ObjectID{}
+	mockRepo := &MockClinicRepo{
+		//owner field of the old document only kept the clinic registered last
+		OwnersWithLegacyClinicFn: func(ctx context.Context) ([]models.Owner, error) {
+			return []models.Owner{
+				{ID: overwritten},
+				{ID: partlyMigrated, Clinics: []primitive.ObjectID{third}},
+				{ID: withoutClinic},
+			}, nil
+		},
+		ClinicsOfOwnersFn: func(ctx context.Context, ownerIDs []primitive.ObjectID) ([]models.Clinic, error) {
+			if len(ownerIDs) != 3 {
+				t.Fatalf("expected clinics of 3 owners to be looked up but got %v", ownerIDs)
+			}
+			return []models.Clinic{
+				{ID: first, Owner: overwritten},
+				{ID: second, Owner: overwritten},
+				{ID: third, Owner: partlyMigrated},
+				{ID: fourth, Owner: partlyMigrated},
+			}, nil
+		},
+		SetOwnerClinicsFn: func(ctx context.Context, ownerID primitive.ObjectID, clinicIDs []primitive.ObjectID) error {
+			saved[ownerID] = clinicIDs
+			return nil
+		},
+	}
+
+	service := NewClinicService(mockRepo, nil)
+	migrated, err := service.MigrateOwnerClinics(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if migrated != 3 {
+		t.Fatalf("expected 3 owners to be migrated but got %d", migrated)
+	}
+
+	expected := map[primitive.ObjectID][]primitive.ObjectID{
+		overwritten:    {first, second},
+		partlyMigrated: {third, fourth},
+		withoutClinic:  {},
+	}
+	for ownerID, clinicIDs := range expected {
+		got, ok := saved[ownerID]
+		if !ok || got == nil || !slices.Equal(got, clinicIDs) {
+			t.Fatalf("expected owner %s to get clinics %v but got %v", ownerID.Hex(), clinicIDs, got)
+		}
+	}
+}
+
+func TestMigrateOwnerClinicsWhenNothingToMigrate(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		Owners      []models.Owner
+		Err         error
+		ExpectedErr bool
+	}{
+		{Name: "Nothing happens once every owner is migrated", Owners: nil},
+		{Name: "Failure to find owners is returned", Err: errors.New("error from mock repo"), ExpectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			mockRepo := &MockClinicRepo{
+				OwnersWithLegacyClinicFn: func(ctx context.Context) ([]models.Owner, error) {
+					return tc.Owners, tc.Err
+				},
+			}
+
+			service := NewClinicService(mockRepo, nil)
+			migrated, err := service.MigrateOwnerClinics(context.Background())
+			if migrated != 0 || (err != nil) != tc.ExpectedErr {
+				t.Fatalf("expected nothing to be migrated with error %v but got %d and %v", tc.ExpectedErr, migrated, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	validators "AlShifa/Clinic/Validators"
+	"AlShifa/Clinic/models"
+	middleware "AlShifa/Middleware"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// PatchDoctor applies json merge patch on profile of doctor, doctors can patch only themselves and admin anyone
+func (service *ClinicService) PatchDoctor(ctx context.Context, actorID primitive.ObjectID, actorRole string, doctorID primitive.ObjectID, patch []byte) (*models.Doctor, *structs.IAppError) {
+	if actorRole != utils.RoleAdmin && actorID != doctorID {
+		return nil, utils.ReturnAppError(errors.New("profile of someone else"), http.StatusForbidden, "Update Failed", "Cannot Update Someone Else")
+	}
+
+	doctor, err := service.Repo.SearchDoctor(ctx, bson.M{"_id": doctorID})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.ReturnAppError(err, http.StatusNotFound, "Doctor Not Found", "Invalid Doctor")
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Update Failed", "Server Error")
+	}
+
+	fields, appErr := applyPatch(&doctor, patch, validators.DoctorPatchFields[actorRole])
+	if appErr != nil {
+		return nil, appErr
+	}
+	if appErr := validatePatched(validators.ValidateDoctor(doctor)); appErr != nil {
+		return nil, appErr
+	}
+
+	if identityChanged(fields) {
+		_, err := service.Repo.SearchDoctor(ctx, bson.M{"_id": bson.M{"$ne": doctorID}, "$or": []bson.M{{"email": doctor.Email}, {"mobile": doctor.Mobile}}})
+		if err == nil {
+			return nil, utils.ReturnAppError(errors.New("doctor already exists"), http.StatusConflict, "Email or Mobile Already Exists", "Duplicate Email or Mobile")
+		}
+		if err != mongo.ErrNoDocuments {
+			return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Update Failed", "Server Error")
+		}
+	}
+
+	if appErr := saveFields(&doctor, fields, func(set bson.M) error { return service.Repo.UpdateDoctor(ctx, doctorID, set) }); appErr != nil {
+		return nil, appErr
+	}
+
+	doctor.Password = ""
+	return &doctor, nil
+}
+
+// PatchClinic applies json merge patch on clinic, owners and their staff can patch only their clinic and admin any clinic
+func (service *ClinicService) PatchClinic(ctx context.Context, actorID primitive.ObjectID, actorRole string, clinicID primitive.ObjectID, patch []byte) (*models.Clinic, *structs.IAppError) {
+	clinic, err := service.Repo.GetClinic(ctx, clinicID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.ReturnAppError(err, http.StatusNotFound, "Clinic Not Found", "Invalid Clinic")
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Update Failed", "Server Error")
+	}
+	if actorRole != utils.RoleAdmin && !middleware.ActsForClinic(ctx, actorID, clinic.Owner, clinic.ID) {
+		return nil, utils.ReturnAppError(errors.New("clinic of someone else"), http.StatusForbidden, "Update Failed", "Cannot Update Clinic Of Someone Else")
+	}
+
+	fields, appErr := applyPatch(&clinic, patch, validators.ClinicPatchFields[actorRole])
+	if appErr != nil {
+		return nil, appErr
+	}
+	if appErr := validatePatched(validators.ValidateClinicDetails(&clinic)); appErr != nil {
+		return nil, appErr
+	}
+
+	//location given by owner is exact, a removed one or one taken from the old pincode is taken from the pincode again
+	if slices.Contains(fields, "location") || slices.Contains(fields, "pincode") {
+		if slices.Contains(fields, "location") {
+			clinic.LocationApprox = false
+		}
+		if clinic.LocationApprox {
+			clinic.Location = nil
+		}
+		locate(&clinic)
+		fields = append(slices.DeleteFunc(fields, func(field string) bool { return field == "location" }), "location", "locationApprox")
+	}
+
+	if appErr := saveFields(&clinic, fields, func(set bson.M) error { return service.Repo.UpdateClinic(ctx, clinicID, set) }); appErr != nil {
+		return nil, appErr
+	}
+	return &clinic, nil
+}
+
+// PatchOwner applies json merge patch on profile of clinic owner, owners can patch only themselves and admin anyone
+func (service *ClinicService) PatchOwner(ctx context.Context, actorID primitive.ObjectID, actorRole string, ownerID primitive.ObjectID, patch []byte) (*models.Owner, *structs.IAppError) {
+	if actorRole != utils.RoleAdmin && actorID != ownerID {
+		return nil, utils.ReturnAppError(errors.New("profile of someone else"), http.StatusForbidden, "Update Failed", "Cannot Update Someone Else")
+	}
+
+	owners, err := service.Repo.GetOwnerDetails(ctx, bson.M{"_id": ownerID})
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Update Failed", "Server Error")
+	}
+	if len(owners) == 0 {
+		return nil, utils.ReturnAppError(mongo.ErrNoDocuments, http.StatusNotFound, "Owner Not Found", "Invalid Owner")
+	}
+	owner := owners[0]
+
+	fields, appErr := applyPatch(&owner, patch, validators.OwnerPatchFields[actorRole])
+	if appErr != nil {
+		return nil, appErr
+	}
+	if appErr := validatePatched(validators.ValidateOwnerDetails(&owner)); appErr != nil {
+		return nil, appErr
+	}
+
+	if identityChanged(fields) {
+		existing, err := service.Repo.GetOwnerDetails(ctx, bson.M{"_id": bson.M{"$ne": ownerID}, "$or": []bson.M{{"email": owner.Email}, {"mobile": owner.Mobile}}})
+		if err != nil {
+			return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Update Failed", "Server Error")
+		}
+		if len(existing) > 0 {
+			return nil, utils.ReturnAppError(errors.New("owner already exists"), http.StatusConflict, "Email or Mobile Already Exists", "Duplicate Email or Mobile")
+		}
+	}
+
+	if appErr := saveFields(&owner, fields, func(set bson.M) error { return service.Repo.UpdateOwner(ctx, ownerID, set) }); appErr != nil {
+		return nil, appErr
+	}
+
+	owner.Password = ""
+	return &owner, nil
+}
+
+// applyPatch merges patch into target allowing only fields of the role
+func applyPatch(target any, patch []byte, allowed []string) ([]string, *structs.IAppError) {
+	fields, forbidden, err := utils.MergePatch(target, patch, allowed)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusBadRequest, "Update Failed", "Invalid Json")
+	}
+	if forbidden != nil {
+		return nil, utils.ReturnAppError(forbidden, http.StatusForbidden, "Update Failed", "Fields Cannot Be Changed")
+	}
+	return fields, nil
+}
+
+// validatePatched turns validation errors of merged result into app error. password is stored hashed and is not patched
+// here so it would never pass the registration rules
+func validatePatched(validationErrors map[string]string) *structs.IAppError {
+	delete(validationErrors, "password")
+	if len(validationErrors) == 0 {
+		return nil
+	}
+	return utils.ReturnAppError(validationErrors, http.StatusBadRequest, "Update Failed", "Invalid Details")
+}
+
+// identityChanged tells if patch touched fields which have to stay unique among accounts
+func identityChanged(fields []string) bool {
+	return slices.Contains(fields, "email") || slices.Contains(fields, "mobile")
+}
+
+// saveFields writes patched fields of target using update, nothing is written for an empty patch
+func saveFields(target any, fields []string, update func(set bson.M) error) *structs.IAppError {
+	if len(fields) == 0 {
+		return nil
+	}
+	set, err := utils.PatchSet(target, fields)
+	if err != nil {
+		return utils.ReturnAppError(err, http.StatusInternalServerError, "Update Failed", "Server Error")
+	}
+	if err := update(set); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return utils.ReturnAppError(err, http.StatusNotFound, "Update Failed", "Record No Longer Exists")
+		}
+		return utils.ReturnAppError(err, http.StatusInternalServerError, "Update Failed", "Server Error")
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	middleware "AlShifa/Middleware"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// expectSaved checks patch failed with expected status without anything saved, or saved only expected fields
+func expectSaved(t *testing.T, saved bson.M, appErr *structs.IAppError, expectedStatusCode int, expectedFields []string) {
+	t.Helper()
+	if expectedStatusCode != 0 {
+		if appErr == nil || appErr.StatusCode != expectedStatusCode {
+			t.Fatalf("expected status %d got %v", expectedStatusCode, appErr)
+		}
+		if saved != nil {
+			t.Fatalf("expected nothing to be saved got %v", saved)
+		}
+		return
+	}
+	if appErr != nil {
+		t.Fatalf("expected no error but got %v", appErr)
+	}
+	//only patched fields are written back
+	if len(saved) != len(expectedFields) {
+		t.Fatalf("expected only %v to be saved got %v", expectedFields, saved)
+	}
+	for _, field := range expectedFields {
+		if _, ok := saved[field]; !ok {
+			t.Fatalf("expected %s to be saved got %v", field, saved)
+		}
+	}
+}
+
+func TestPatchDoctor(t *testing.T) {
+	doctorID := primitive.NewObjectID()
+
+	testCases := []struct {
+		Name               string
+		ActorID            primitive.ObjectID
+		ActorRole          string
+		Patch              string
+		ExpectedFields     []string
+		ExpectedStatusCode int
+	}{
+		{Name: "Doctor changes qualifications", ActorID: doctorID, ActorRole: utils.RoleDoctor, Patch: `{"qualifications":"MBBS MD"}`, ExpectedFields: []string{"qualifications"}},
+		{Name: "Doctor cannot change email", ActorID: doctorID, ActorRole: utils.RoleDoctor, Patch: `{"email":"new@gmail.com"}`, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Doctor cannot change mobile", ActorID: doctorID, ActorRole: utils.RoleDoctor, Patch: `{"mobile":9123456780}`, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Doctor cannot change someone else", ActorID: primitive.NewObjectID(), ActorRole: utils.RoleDoctor, Patch: `{"address":"Rajbagh Srinagar"}`, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Merged result is validated", ActorID: doctorID, ActorRole: utils.RoleDoctor, Patch: `{"name":null}`, ExpectedStatusCode: http.StatusBadRequest},
+		{Name: "Admin changes email", ActorID: primitive.NewObjectID(), ActorRole: utils.RoleAdmin, Patch: `{"email":"new@gmail.com"}`, ExpectedFields: []string{"email"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var saved bson.M
+			mockRepo := &MockClinicRepo{
+				SearchDoctorFn: func(ctx context.Context, filter bson.M) (models.Doctor, error) {
+					if filter["_id"] != doctorID {
+						return models.Doctor{}, mongo.ErrNoDocuments
+					}
+					return models.Doctor{
+						ID:             doctorID,
+						Name:           "Saqlain",
+						Qualifications: "MBBS",
+						Address:        "Soura Srinagar",
+						Email:          "Saqlain@gmail.com",
+						Password:       "$argon2id$v=19$m=65536,t=1,p=4$c2FsdA$aGFzaA",
+						WorkingAt:      "Skims",
+						Mobile:         9797798243,
+					}, nil
+				},
+				UpdateDoctorFn: func(ctx context.Context, doctorID primitive.ObjectID, set bson.M) error {
+					saved = set
+					return nil
+				},
+			}
+
+			doctor, appErr := NewClinicService(mockRepo, nil).PatchDoctor(context.Background(), tc.ActorID, tc.ActorRole, doctorID, []byte(tc.Patch))
+			expectSaved(t, saved, appErr, tc.ExpectedStatusCode, tc.ExpectedFields)
+			if appErr == nil && doctor.Password != "" {
+				t.Fatalf("expected password to be hidden")
+			}
+		})
+	}
+}
+
+func TestPatchClinic(t *testing.T) {
+	ownerID := primitive.NewObjectID()
+	clinicID := primitive.NewObjectID()
+	otherClinicID := primitive.NewObjectID()
+
+	testCases := []struct {
+		Name               string
+		ActorID            primitive.ObjectID
+		ActorRole          string
+		StaffAt            primitive.ObjectID
+		ClinicID           primitive.ObjectID
+		Patch              string
+		ExpectedFields     []string
+		ExpectedStatusCode int
+	}{
+		{Name: "Owner changes name", ActorID: ownerID, ActorRole: utils.RoleClinicOwner, ClinicID: clinicID, Patch: `{"name":"Al Shifa Rajbagh"}`, ExpectedFields: []string{"name"}},
+		{Name: "Owner changes working days", ActorID: ownerID, ActorRole: utils.RoleClinicOwner, ClinicID: clinicID, Patch: `{"workingDays":["Mon","Tue"]}`, ExpectedFields: []string{"workingDays"}},
+		{Name: "Owner cannot change plan type", ActorID: ownerID, ActorRole: utils.RoleClinicOwner, ClinicID: clinicID, Patch: `{"planType":"premium"}`, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Owner cannot change time zone", ActorID: ownerID, ActorRole: utils.RoleClinicOwner, ClinicID: clinicID, Patch: `{"timeZone":"Asia/Dubai"}`, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Owner cannot change clinic of someone else", ActorID: ownerID, ActorRole: utils.RoleClinicOwner, ClinicID: otherClinicID, Patch: `{"name":"Al Shifa Rajbagh"}`, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Staff changes name of their clinic", ActorID: primitive.NewObjectID(), ActorRole: utils.RoleStaff, StaffAt: clinicID, ClinicID: clinicID, Patch: `{"name":"Al Shifa Rajbagh"}`, ExpectedFields: []string{"name"}},
+		{Name: "Staff cannot change another clinic", ActorID: primitive.NewObjectID(), ActorRole: utils.RoleStaff, StaffAt: otherClinicID, ClinicID: clinicID, Patch: `{"name":"Al Shifa Rajbagh"}`, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Merged result is validated", ActorID: ownerID, ActorRole: utils.RoleClinicOwner, ClinicID: clinicID, Patch: `{"workingDays":["Someday"]}`, ExpectedStatusCode: http.StatusBadRequest},
+		{Name: "Admin changes plan type", ActorID: primitive.NewObjectID(), ActorRole: utils.RoleAdmin, ClinicID: otherClinicID, Patch: `{"planType":"premium"}`, ExpectedFields: []string{"planType"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var saved bson.M
+			mockRepo := &MockClinicRepo{
+				GetClinicFn: func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+					owner := primitive.NewObjectID()
+					if clinicID != otherClinicID {
+						owner = ownerID
+					}
+					location := models.GeoPoint{Type: "Point", Coordinates: []float64{74.8, 34.08}}
+					return models.Clinic{
+						ID:            clinicID,
+						Owner:         owner,
+						Name:          "Al Shifa",
+						Address:       "Soura Srinagar",
+						Mobile:        9797798243,
+						Pincode:       190011,
+						Location:      &location,
+						SeasonTimings: []models.SeasonTimingDetails{{Name: "Summer", Start: time.Date(2000, 1, 1, 3, 30, 0, 0, time.UTC), End: time.Date(2000, 1, 1, 12, 30, 0, 0, time.UTC)}},
+					}, nil
+				},
+				UpdateClinicFn: func(ctx context.Context, clinicID primitive.ObjectID, set bson.M) error {
+					saved = set
+					return nil
+				},
+			}
+
+			ctx := context.Background()
+			if !tc.StaffAt.IsZero() {
+				ctx = context.WithValue(ctx, middleware.ContextStaffClinicKey, tc.StaffAt)
+			}
+			_, appErr := NewClinicService(mockRepo, nil).PatchClinic(ctx, tc.ActorID, tc.ActorRole, tc.ClinicID, []byte(tc.Patch))
+			expectSaved(t, saved, appErr, tc.ExpectedStatusCode, tc.ExpectedFields)
+		})
+	}
+}
+
+func TestPatchOwner(t *testing.T) {
+	ownerID := primitive.NewObjectID()
+
+	testCases := []struct {
+		Name               string
+		ActorID            primitive.ObjectID
+		ActorRole          string
+		Patch              string
+		Duplicate          bool
+		ExpectedFields     []string
+		ExpectedStatusCode int
+	}{
+		{Name: "Owner changes address", ActorID: ownerID, ActorRole: utils.RoleClinicOwner, Patch: `{"address":"Rajbagh Srinagar"}`, ExpectedFields: []string{"address"}},
+		{Name: "Owner cannot change email", ActorID: ownerID, ActorRole: utils.RoleClinicOwner, Patch: `{"email":"new@gmail.com"}`, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Owner cannot change own clinics", ActorID: ownerID, ActorRole: utils.RoleClinicOwner, Patch: `{"clinics":[]}`, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Owner cannot change someone else", ActorID: primitive.NewObjectID(), ActorRole: utils.RoleClinicOwner, Patch: `{"address":"Rajbagh Srinagar"}`, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Admin changes mobile", ActorID: primitive.NewObjectID(), ActorRole: utils.RoleAdmin, Patch: `{"mobile":9123456780}`, ExpectedFields: []string{"mobile"}},
+		{Name: "Admin cannot reuse mobile of another owner", ActorID: primitive.NewObjectID(), ActorRole: utils.RoleAdmin, Patch: `{"mobile":9123456780}`, Duplicate: true, ExpectedStatusCode: http.StatusConflict},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var saved bson.M
+			mockRepo := &MockClinicRepo{
+				GetOwnerDetailsFn: func(ctx context.Context, filter bson.M) ([]models.Owner, error) {
+					if filter["_id"] != ownerID {
+						if tc.Duplicate {
+							return []models.Owner{{ID: primitive.NewObjectID()}}, nil
+						}
+						return nil, nil
+					}
+					return []models.Owner{{
+						ID:       ownerID,
+						Name:     "Saqlain",
+						Email:    "Saqlain@gmail.com",
+						Password: "$argon2id$v=19$m=65536,t=1,p=4$c2FsdA$aGFzaA",
+						Mobile:   9797798243,
+						Address:  "Soura Srinagar",
+						Gender:   "Male",
+					}}, nil
+				},
+				UpdateOwnerFn: func(ctx context.Context, ownerID primitive.ObjectID, set bson.M) error {
+					saved = set
+					return nil
+				},
+			}
+
+			owner, appErr := NewClinicService(mockRepo, nil).PatchOwner(context.Background(), tc.ActorID, tc.ActorRole, ownerID, []byte(tc.Patch))
+			expectSaved(t, saved, appErr, tc.ExpectedStatusCode, tc.ExpectedFields)
+			if appErr == nil && owner.Password != "" {
+				t.Fatalf("expected password to be hidden")
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetClinicWallet returns wallet of clinic to its owner, staff allowed to view it and admin. clinics which are yet to
+// be paid have no wallet and get an empty one
+func (service *ClinicService) GetClinicWallet(ctx context.Context, actorID primitive.ObjectID, actorRole string, clinicID primitive.ObjectID) (*models.WalletDetails, *structs.IAppError) {
+	clinic, appErr := service.clinicOfActor(ctx, actorID, actorRole, clinicID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	wallet, err := service.Repo.GetWallet(ctx, clinicID)
+	if err == mongo.ErrNoDocuments {
+		return &models.WalletDetails{ID: clinic.Wallet, Clinic: clinicID}, nil
+	}
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Wallet", "Server Error")
+	}
+	return &wallet, nil
+}
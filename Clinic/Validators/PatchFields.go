@@ -0,0 +1,22 @@
+package validators
+
+import utils "AlShifa/Utils"
+
+// fields each role can patch, names are json names which are the same as the stored bson names. email and mobile
+// identify the account so only admin can change them, time zone of clinic moves its booked slots so only admin changes
+// it too. password, role, ids, links and slot duration (it renumbers booked slots) are not patchable
+var (
+	DoctorPatchFields = map[string][]string{
+		utils.RoleDoctor: {"name", "qualifications", "address", "workingAt"},
+		utils.RoleAdmin:  {"name", "qualifications", "address", "workingAt", "email", "mobile"},
+	}
+	ClinicPatchFields = map[string][]string{
+		utils.RoleClinicOwner: {"name", "address", "mobile", "pincode", "location", "seasonTimings", "workingDays"},
+		utils.RoleStaff:       {"name", "address", "mobile", "pincode", "location", "seasonTimings", "workingDays"},
+		utils.RoleAdmin:       {"name", "address", "mobile", "pincode", "location", "seasonTimings", "workingDays", "planType", "timeZone"},
+	}
+	OwnerPatchFields = map[string][]string{
+		utils.RoleClinicOwner: {"name", "address", "gender"},
+		utils.RoleAdmin:       {"name", "address", "gender", "email", "mobile"},
+	}
+)
@@ -2,7 +2,10 @@ package validators
 
 import (
 	"AlShifa/Clinic/models"
+	scheduling "AlShifa/Scheduling"
+	utils "AlShifa/Utils"
 	"net/mail"
+	"strconv"
 	"strings"
 	"unicode/utf8"
 )
@@ -127,6 +130,19 @@ func ValidateDoctor(d models.Doctor) map[string]string {
 		}
 	}
 
+	// ---------- Slot Duration ----------
+	// 0 falls back to default, slots are numbered by int8 so a session cant be cut into too many of them
+	if d.SlotDuration != 0 && (d.SlotDuration < utils.MinSlotMinutes || d.SlotDuration > utils.MaxSlotMinutes) {
+		errors["slotDuration"] = "Slot duration must be between " + strconv.Itoa(utils.MinSlotMinutes) + " and " + strconv.Itoa(utils.MaxSlotMinutes) + " minutes"
+	} else {
+		for _, session := range d.Clinics {
+			if scheduling.SessionSlots(session, scheduling.SlotDuration(d)) > utils.MaxSlotsPerSession {
+				errors["clinics"] = "A session cannot have more than " + strconv.Itoa(utils.MaxSlotsPerSession) + " slots, use longer slots or shorter sessions"
+				break
+			}
+		}
+	}
+
 	if len(errors) == 0 {
 		return nil
 	}
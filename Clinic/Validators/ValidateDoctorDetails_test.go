@@ -0,0 +1,53 @@
+package validators
+
+import (
+	"AlShifa/Clinic/models"
+	"testing"
+	"time"
+)
+
+func TestValidateDoctorSlotDuration(t *testing.T) {
+	session := models.ClinicDetails{
+		StartTime: time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2025, 1, 1, 21, 0, 0, 0, time.UTC),
+	}
+
+	testCases := []struct {
+		Name          string
+		SlotDuration  int32
+		Clinics       []models.ClinicDetails
+		ExpectedField string
+	}{
+		{Name: "Default slot duration is allowed", SlotDuration: 0, Clinics: []models.ClinicDetails{session}},
+		{Name: "Slot duration within bounds is allowed", SlotDuration: 10, Clinics: []models.ClinicDetails{session}},
+		{Name: "Slot duration too short", SlotDuration: 1, ExpectedField: "slotDuration"},
+		{Name: "Slot duration too long", SlotDuration: 600, ExpectedField: "slotDuration"},
+		{Name: "Session cut into more slots than can be numbered", SlotDuration: 5, Clinics: []models.ClinicDetails{session}, ExpectedField: "clinics"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			errs := ValidateDoctor(models.Doctor{
+				Name:           "Saqlain",
+				Qualifications: "MBBS",
+				Address:        "Soura Srinagar",
+				Email:          "Saqlain@gmail.com",
+				Password:       "Saqlain@123",
+				WorkingAt:      "Skims",
+				Mobile:         9797798243,
+				SlotDuration:   tc.SlotDuration,
+				Clinics:        tc.Clinics,
+			})
+
+			if tc.ExpectedField == "" {
+				if errs != nil {
+					t.Fatalf("expected no errors got %v", errs)
+				}
+				return
+			}
+			if _, ok := errs[tc.ExpectedField]; !ok || len(errs) != 1 {
+				t.Fatalf("expected only %s to be invalid got %v", tc.ExpectedField, errs)
+			}
+		})
+	}
+}
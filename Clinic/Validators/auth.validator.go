@@ -3,9 +3,12 @@ package validators
 
 import (
 	"AlShifa/Clinic/models"
+	geo "AlShifa/Geo"
+	scheduling "AlShifa/Scheduling"
 	utils "AlShifa/Utils"
 	"regexp"
 	"strings"
+	"time"
 	"unicode"
 )
 
@@ -151,6 +154,26 @@ func ValidateClinicDetails(clinic *models.Clinic) map[string]string {
 		errors["pincode"] = "invalid pincode"
 	}
 
+	// Location, clinics without one are placed at centre of their pincode
+	if clinic.Location != nil && !geo.ValidPoint(*clinic.Location) {
+		errors["location"] = "location must be a GeoJSON Point of longitude and latitude"
+	}
+
+	// Time zone, clinics without one use the default time zone
+	if clinic.TimeZone != "" {
+		if _, err := time.LoadLocation(clinic.TimeZone); err != nil || clinic.TimeZone == "Local" {
+			errors["timeZone"] = "invalid time zone"
+		}
+	}
+
+	// Working days, clinics without them are open every day of their season
+	for _, day := range clinic.WorkingDays {
+		if !validWeekday(day) {
+			errors["workingDays"] = "invalid working day " + day
+			break
+		}
+	}
+
 	// Season timings
 	if len(clinic.SeasonTimings) == 0 {
 		errors["seasonTimings"] = "season timing details required"
@@ -188,3 +211,13 @@ func ValidateClinicDetails(clinic *models.Clinic) map[string]string {
 
 	return errors
 }
+
+// validWeekday checks if day names a weekday the way working days are matched
+func validWeekday(day string) bool {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if scheduling.WorksOn([]string{day}, weekday) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Affiliation links a doctor to a clinic. clinic owner invites the doctor with a proposed session, once the doctor accepts
+// the session is added to clinics of the doctor and the doctor to doctors of the clinic
+type Affiliation struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	Clinic      primitive.ObjectID `json:"clinic" bson:"clinic"`
+	Doctor      primitive.ObjectID `json:"doctor" bson:"doctor"`
+	InvitedBy   primitive.ObjectID `json:"invitedBy" bson:"invitedBy"`
+	Session     ClinicDetails      `json:"session" bson:"session"`
+	Status      string             `json:"status" bson:"status"`
+	Active      bool               `json:"-" bson:"active,omitempty"` // set while pending or accepted, one per clinic and doctor
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+	RespondedAt *time.Time         `json:"respondedAt,omitempty" bson:"respondedAt,omitempty"`
+	EndedAt     *time.Time         `json:"endedAt,omitempty" bson:"endedAt,omitempty"`
+	EndedBy     string             `json:"endedBy,omitempty" bson:"endedBy,omitempty"` // role which declined, withdrew or ended it
+	Reason      string             `json:"reason,omitempty" bson:"reason,omitempty"`
+}
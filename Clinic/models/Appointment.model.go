@@ -6,13 +6,28 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// StatusTransition is an audit entry of an appointment status change
+type StatusTransition struct {
+	At        time.Time          `json:"at" bson:"at"`
+	From      string             `json:"from" bson:"from"`
+	To        string             `json:"to" bson:"to"`
+	ActorRole string             `json:"actorRole" bson:"actorRole"`
+	Reason    string             `json:"reason,omitempty" bson:"reason,omitempty"`
+	Actor     primitive.ObjectID `json:"actor" bson:"actor"`
+}
+
 type Appointment struct {
-	AppointmentDate  time.Time          `json:"appointmentDate" bson:"appointmentDate"`
-	RegistrationDate time.Time          `json:"registrationDate" bson:"registrationDate"`
-	Status           string             `json:"status" bson:"status"`
-	ID               primitive.ObjectID `json:"id" bson:"_id"`
-	Clinic           primitive.ObjectID `json:"clinic" bson:"clinic"`
-	User             primitive.ObjectID `json:"user" bson:"user"`
-	Doctor           primitive.ObjectID `json:"doctor" bson:"doctor"`
-	Slot             int8               `json:"slot" bson:"slot"`
+	AppointmentDate  time.Time           `json:"appointmentDate" bson:"appointmentDate"`
+	RegistrationDate time.Time           `json:"registrationDate" bson:"registrationDate"`
+	Status           string              `json:"status" bson:"status"`
+	ID               primitive.ObjectID  `json:"id" bson:"_id"`
+	Clinic           primitive.ObjectID  `json:"clinic" bson:"clinic"`
+	User             primitive.ObjectID  `json:"user" bson:"user"`
+	Doctor           primitive.ObjectID  `json:"doctor" bson:"doctor"`
+	StatusHistory    []StatusTransition  `json:"statusHistory" bson:"statusHistory"`
+	RescheduledFrom  *primitive.ObjectID `json:"rescheduledFrom,omitempty" bson:"rescheduledFrom,omitempty"`
+	RescheduleCount  int32               `json:"rescheduleCount" bson:"rescheduleCount"`
+	Slot             int8                `json:"slot" bson:"slot"`
+	Mode             string              `json:"mode" bson:"mode,omitempty"`                     // InClinic or Video, empty means InClinic
+	VideoRoom        string              `json:"videoRoom,omitempty" bson:"videoRoom,omitempty"` // room of video appointments at the room provider
 }
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CalendarFeed is the secret iCalendar subscription of a patient or doctor, anyone holding Token can read the feed
+// so it is replaced on every rotation
+type CalendarFeed struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	Owner     primitive.ObjectID `json:"owner" bson:"owner"`
+	Role      string             `json:"role" bson:"role"`
+	Token     string             `json:"token" bson:"token"`
+	URL       string             `json:"url" bson:"-"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Job is a unit of work due at RunAt. Key is unique so a job is scheduled once however many instances plan it, and a
+// worker owns a running job until LockedUntil so a crashed worker's job is picked up again after the lease
+type Job struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	Key         string             `json:"key" bson:"key"`
+	Kind        string             `json:"kind" bson:"kind"`
+	Ref         primitive.ObjectID `json:"ref" bson:"ref"` // document the job acts on, e.g. the appointment of a reminder
+	RunAt       time.Time          `json:"runAt" bson:"runAt"`
+	Status      string             `json:"status" bson:"status"`
+	Attempts    int32              `json:"attempts" bson:"attempts"`
+	LockedBy    string             `json:"lockedBy,omitempty" bson:"lockedBy,omitempty"`
+	LockedUntil *time.Time         `json:"lockedUntil,omitempty" bson:"lockedUntil,omitempty"`
+	LastError   string             `json:"lastError,omitempty" bson:"lastError,omitempty"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+	FinishedAt  *time.Time         `json:"finishedAt,omitempty" bson:"finishedAt,omitempty"`
+}
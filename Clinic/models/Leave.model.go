@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Leave blocks bookings between Start and End. a doctor leave has Doctor set and applies at Clinic only or at every
+// clinic of the doctor when Clinic is nil, a clinic closure (holiday) has only Clinic set and applies to every doctor there
+type Leave struct {
+	ID        primitive.ObjectID  `json:"id" bson:"_id"`
+	Doctor    *primitive.ObjectID `json:"doctor,omitempty" bson:"doctor,omitempty"`
+	Clinic    *primitive.ObjectID `json:"clinic,omitempty" bson:"clinic,omitempty"`
+	Start     time.Time           `json:"start" bson:"start"`
+	End       time.Time           `json:"end" bson:"end"`
+	Reason    string              `json:"reason" bson:"reason"`
+	CreatedBy primitive.ObjectID  `json:"createdBy" bson:"createdBy"`
+	CreatedAt time.Time           `json:"createdAt" bson:"createdAt"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NoShowRecord is written against the user when an appointment is marked NoShow, counts over these records drive
+// no show policies of clinics
+type NoShowRecord struct {
+	ID              primitive.ObjectID `json:"id" bson:"_id"`
+	User            primitive.ObjectID `json:"user" bson:"user"`
+	Clinic          primitive.ObjectID `json:"clinic" bson:"clinic"`
+	Doctor          primitive.ObjectID `json:"doctor" bson:"doctor"`
+	Appointment     primitive.ObjectID `json:"appointment" bson:"appointment"`
+	AppointmentDate time.Time          `json:"appointmentDate" bson:"appointmentDate"`
+	RecordedBy      primitive.ObjectID `json:"recordedBy" bson:"recordedBy"`
+	RecordedAt      time.Time          `json:"recordedAt" bson:"recordedAt"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// QueueDay is the token counter of a doctor at a clinic for one day, tokens are numbered by incrementing LastToken
+type QueueDay struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	Doctor    primitive.ObjectID `json:"doctor" bson:"doctor"`
+	Clinic    primitive.ObjectID `json:"clinic" bson:"clinic"`
+	Date      time.Time          `json:"date" bson:"date"` // midnight UTC of the clinic local date
+	LastToken int32              `json:"lastToken" bson:"lastToken"`
+}
+
+// QueueToken is a place in the walk-in queue of a doctor at a clinic, checked in appointments get a token as well
+type QueueToken struct {
+	ID          primitive.ObjectID  `json:"id" bson:"_id"`
+	Doctor      primitive.ObjectID  `json:"doctor" bson:"doctor"`
+	Clinic      primitive.ObjectID  `json:"clinic" bson:"clinic"`
+	Date        time.Time           `json:"date" bson:"date"` // midnight UTC of the clinic local date
+	User        *primitive.ObjectID `json:"user,omitempty" bson:"user,omitempty"`
+	Appointment *primitive.ObjectID `json:"appointment,omitempty" bson:"appointment,omitempty"`
+	PatientName string              `json:"patientName,omitempty" bson:"patientName,omitempty"`
+	Status      string              `json:"status" bson:"status"`
+	IssuedAt    time.Time           `json:"issuedAt" bson:"issuedAt"`
+	CalledAt    *time.Time          `json:"calledAt,omitempty" bson:"calledAt,omitempty"`
+	Number      int32               `json:"number" bson:"number"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Rating is the aggregate of visible reviews kept on doctors and clinics so they can be sorted by it
+type Rating struct {
+	Average float64 `json:"average" bson:"average"` // rounded to two decimals
+	Count   int64   `json:"count" bson:"count"`
+	Total   int64   `json:"-" bson:"total"` // sum of ratings, average is worked out of it
+}
+
+// ReviewReply is the answer of clinic owner to a review
+type ReviewReply struct {
+	Text      string             `json:"text" bson:"text"`
+	Owner     primitive.ObjectID `json:"owner" bson:"owner"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// Review is rating and comment of a patient about the doctor and clinic of a completed appointment, one per appointment.
+// hidden reviews are left out of listings and ratings
+type Review struct {
+	ID           primitive.ObjectID  `json:"id" bson:"_id"`
+	Appointment  primitive.ObjectID  `json:"appointment" bson:"appointment"`
+	User         primitive.ObjectID  `json:"user" bson:"user"`
+	Doctor       primitive.ObjectID  `json:"doctor" bson:"doctor"`
+	Clinic       primitive.ObjectID  `json:"clinic" bson:"clinic"`
+	Rating       int32               `json:"rating" bson:"rating"`
+	Comment      string              `json:"comment" bson:"comment"`
+	Reply        *ReviewReply        `json:"reply,omitempty" bson:"reply,omitempty"`
+	Hidden       bool                `json:"hidden" bson:"hidden"`
+	HiddenBy     *primitive.ObjectID `json:"hiddenBy,omitempty" bson:"hiddenBy,omitempty"`
+	HiddenReason string              `json:"hiddenReason,omitempty" bson:"hiddenReason,omitempty"`
+	CreatedAt    time.Time           `json:"createdAt" bson:"createdAt"`
+	ModeratedAt  *time.Time          `json:"moderatedAt,omitempty" bson:"moderatedAt,omitempty"`
+}
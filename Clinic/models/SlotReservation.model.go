@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SlotReservation is one seat of a slot, a slot with capacity N can have N reservations (unit 0..N-1).
+// unique index on doctor, clinic, date, slot and unit makes sure a seat is never given twice.
+// a reservation with ExpiresAt is a temporary hold which is removed by TTL index unless it gets confirmed
+type SlotReservation struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	Doctor      primitive.ObjectID `json:"doctor" bson:"doctor"`
+	Clinic      primitive.ObjectID `json:"clinic" bson:"clinic"`
+	Date        time.Time          `json:"date" bson:"date"` // midnight UTC of the clinic local date
+	Appointment primitive.ObjectID `json:"appointment" bson:"appointment"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+	ExpiresAt   *time.Time         `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"`
+	Unit        int32              `json:"unit" bson:"unit"`
+	Slot        int8               `json:"slot" bson:"slot"`
+}
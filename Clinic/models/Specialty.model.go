@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Specialty is an entry of the admin managed catalogue doctors pick their specialties from. synonyms are what patients
+// may search instead of the name, like skin for Dermatology
+type Specialty struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	Name      string             `json:"name" bson:"name"`
+	Synonyms  []string           `json:"synonyms" bson:"synonyms"`
+	Terms     []string           `json:"-" bson:"terms"` // normalised name and synonyms, searches match these
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Staff is a member of staff of a clinic like a receptionist. owner invites them with the permissions they get at that
+// clinic, anyone holding InviteToken can join with it so it is cleared once used
+type Staff struct {
+	ID              primitive.ObjectID `json:"id" bson:"_id"`
+	Clinic          primitive.ObjectID `json:"clinic" bson:"clinic"`
+	InvitedBy       primitive.ObjectID `json:"invitedBy" bson:"invitedBy"`
+	Name            string             `json:"name" bson:"name"`
+	Email           string             `json:"email" bson:"email"`
+	Mobile          int64              `json:"mobile" bson:"mobile"`
+	Password        string             `json:"-" bson:"password,omitempty"`
+	Role            string             `json:"role" bson:"role"`
+	Permissions     []string           `json:"permissions" bson:"permissions"`
+	Status          string             `json:"status" bson:"status"`
+	Active          bool               `json:"-" bson:"active,omitempty"` // set while invited or active, one per email
+	InviteToken     string             `json:"inviteToken,omitempty" bson:"inviteToken,omitempty"`
+	InviteExpiresAt *time.Time         `json:"inviteExpiresAt,omitempty" bson:"inviteExpiresAt,omitempty"`
+	CreatedAt       time.Time          `json:"createdAt" bson:"createdAt"`
+	JoinedAt        *time.Time         `json:"joinedAt,omitempty" bson:"joinedAt,omitempty"`
+	RevokedAt       *time.Time         `json:"revokedAt,omitempty" bson:"revokedAt,omitempty"`
+}
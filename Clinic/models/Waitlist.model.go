@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WaitlistOffer is a freed slot offered to a waitlisted patient, the seat is held until ExpiresAt
+type WaitlistOffer struct {
+	AppointmentDate time.Time          `json:"appointmentDate" bson:"appointmentDate"`
+	ExpiresAt       time.Time          `json:"expiresAt" bson:"expiresAt"`
+	Hold            primitive.ObjectID `json:"hold" bson:"hold"`
+	Slot            int8               `json:"slot" bson:"slot"`
+	Mode            string             `json:"mode" bson:"mode,omitempty"` // consultation mode the slot is offered in
+}
+
+// WaitlistEntry is a patient waiting for a slot of a doctor at a clinic on a date, entries are served in CreatedAt order
+type WaitlistEntry struct {
+	ID          primitive.ObjectID  `json:"id" bson:"_id"`
+	Doctor      primitive.ObjectID  `json:"doctor" bson:"doctor"`
+	Clinic      primitive.ObjectID  `json:"clinic" bson:"clinic"`
+	User        primitive.ObjectID  `json:"user" bson:"user"`
+	Date        time.Time           `json:"date" bson:"date"` // midnight UTC of the clinic local date
+	CreatedAt   time.Time           `json:"createdAt" bson:"createdAt"`
+	Status      string              `json:"status" bson:"status"`
+	Offer       *WaitlistOffer      `json:"offer,omitempty" bson:"offer,omitempty"`
+	Appointment *primitive.ObjectID `json:"appointment,omitempty" bson:"appointment,omitempty"`
+}
@@ -13,6 +13,29 @@ type SeasonTimingDetails struct {
 	Name  string    `json:"name" bson:"name"`   // 16 bytes
 }
 
+// NoShowPolicy restricts online booking of patients who missed Threshold appointments within WindowDays. the restriction
+// lasts RestrictionDays from the latest no show, or while the no shows stay in the window when RestrictionDays is 0
+type NoShowPolicy struct {
+	Threshold       int32  `json:"threshold" bson:"threshold"` // 0 disables the policy
+	WindowDays      int32  `json:"windowDays" bson:"windowDays"`
+	Action          string `json:"action" bson:"action"` // block or prepay
+	RestrictionDays int32  `json:"restrictionDays" bson:"restrictionDays"`
+	AcrossClinics   bool   `json:"acrossClinics" bson:"acrossClinics"` // count no shows at every clinic, not only this one
+}
+
+// BookingRules are clinic defined rules which are applied on appointments of the clinic, zero values mean no restriction
+type BookingRules struct {
+	RescheduleCutoffHours int32        `json:"rescheduleCutoffHours" bson:"rescheduleCutoffHours"` // no reschedules within these many hours of appointment
+	MaxReschedules        int32        `json:"maxReschedules" bson:"maxReschedules"`
+	NoShowPolicy          NoShowPolicy `json:"noShowPolicy" bson:"noShowPolicy"`
+}
+
+// GeoPoint is a GeoJSON point, coordinates are longitude then latitude
+type GeoPoint struct {
+	Type        string    `json:"type" bson:"type"`
+	Coordinates []float64 `json:"coordinates" bson:"coordinates"`
+}
+
 // Clinic represents the details of a clinic, reordered for alignment.
 type Clinic struct {
 	ID               primitive.ObjectID    `json:"id" bson:"_id"`
@@ -28,4 +51,39 @@ type Clinic struct {
 	OwnerDetails     *Owner                `bson:"ownerDetails,omitempty"`
 	DoctorDetails    []Doctor              `bson:"doctorDetails,omitempty"`
 	PlanType         string                `json:"planType" bson:"planType"`
+	BookingRules     BookingRules          `json:"bookingRules" bson:"bookingRules"`
+	Location         *GeoPoint             `json:"location,omitempty" bson:"location,omitempty"`
+	LocationApprox   bool                  `json:"locationApprox" bson:"locationApprox"` // location is centre of pincode, not of clinic
+	Rating           Rating                `json:"rating" bson:"rating"`
+	Status           string                `json:"status" bson:"status,omitempty"` // empty for clinics registered before statuses, they are active
+	StatusHistory    []ClinicStatusChange  `json:"statusHistory,omitempty" bson:"statusHistory,omitempty"`
+	DeletedAt        *time.Time            `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"` // soft deleted, admin can restore it
+	TimeZone         string                `json:"timeZone" bson:"timeZone,omitempty"`             // IANA name, default time zone when empty
+	WorkingDays      []string              `json:"workingDays" bson:"workingDays,omitempty"`       // open every day when empty
+	OpeningStatus    *OpeningStatus        `json:"openingStatus,omitempty" bson:"-"`               // computed for search results, never stored
+}
+
+// OpeningStatus tells if clinic is open at a moment and when that changes, Label reads like Open till 08:00 PM
+type OpeningStatus struct {
+	Status   string     `json:"status"` // Open or Closed
+	Label    string     `json:"label"`
+	ClosesAt *time.Time `json:"closesAt,omitempty"`
+	OpensAt  *time.Time `json:"opensAt,omitempty"` // nil when a closed clinic doesnt open again soon
+}
+
+// ClinicStatusChange records a change in lifecycle of clinic, Deleted tells if clinic is deleted after the change
+type ClinicStatusChange struct {
+	At        time.Time          `json:"at" bson:"at"`
+	From      string             `json:"from" bson:"from"`
+	To        string             `json:"to" bson:"to"`
+	Deleted   bool               `json:"deleted" bson:"deleted"`
+	Actor     primitive.ObjectID `json:"actor" bson:"actor"`
+	ActorRole string             `json:"actorRole" bson:"actorRole"`
+	Reason    string             `json:"reason,omitempty" bson:"reason,omitempty"`
+}
+
+// NearbyClinic is a clinic found around a point with its distance from the point in kilometres
+type NearbyClinic struct {
+	Clinic   `bson:",inline"`
+	Distance float64 `json:"distance" bson:"distance"`
 }
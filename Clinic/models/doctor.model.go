@@ -6,13 +6,22 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// TimeWindow is a daily window of clock times, dates of StartTime and EndTime are ignored
+type TimeWindow struct {
+	StartTime time.Time `json:"startTime" bson:"startTime"`
+	EndTime   time.Time `json:"endTime" bson:"endTime"`
+}
+
 type ClinicDetails struct {
-	ID          primitive.ObjectID `json:"id" bson:"_id"`
-	StartTime   time.Time          `json:"startTiming" bson:"startTiming"`
-	EndTime     time.Time          `json:"endTime" bson:"endTime"`
-	Clinic      primitive.ObjectID `json:"clinic" bson:"clinic"`
-	Information []Clinic           `json:"information" bson:"-"`
-	WorkingDays []string           `json:"workingDays" bson:"workingDays"`
+	ID           primitive.ObjectID `json:"id" bson:"_id"`
+	StartTime    time.Time          `json:"startTiming" bson:"startTiming"`
+	EndTime      time.Time          `json:"endTime" bson:"endTime"`
+	Clinic       primitive.ObjectID `json:"clinic" bson:"clinic"`
+	Information  []Clinic           `json:"information" bson:"-"`
+	WorkingDays  []string           `json:"workingDays" bson:"workingDays"`
+	SlotCapacity int32              `json:"slotCapacity" bson:"slotCapacity"`         // patients per slot, 0 means 1
+	Mode         string             `json:"mode" bson:"mode,omitempty"`               // InClinic, Video or Both, empty means InClinic
+	OnlineHours  []TimeWindow       `json:"onlineHours" bson:"onlineHours,omitempty"` // parts of the session which are video only
 }
 
 type Doctor struct {
@@ -28,6 +37,9 @@ type Doctor struct {
 	Appointments     []primitive.ObjectID `json:"appointments" bson:"appointments"`
 	Clinics          []ClinicDetails      `json:"clinics" bson:"clinics"`
 	Role             string               `json:"role" bson:"role"`
+	SlotDuration     int32                `json:"slotDuration" bson:"slotDuration"` // minutes per appointment slot, 0 means default
+	Specialties      []primitive.ObjectID `json:"specialties" bson:"specialties"`   // ids from the specialty catalogue
+	Rating           Rating               `json:"rating" bson:"rating"`
 }
 
 type DoctorPublicDetails struct {
@@ -36,4 +48,6 @@ type DoctorPublicDetails struct {
 	Qualifications string             `json:"qualifications" bson:"qualifications"`
 	WorkingAt      string             `json:"workingAt" bson:"workingAt"`
 	Clinics        []ClinicDetails    `json:"clinics" bson:"clinics"`
+	Specialties    []Specialty        `json:"specialties" bson:"specialties"`
+	Rating         Rating             `json:"rating" bson:"rating"`
 }
@@ -0,0 +1,119 @@
+// Package geo turns pincodes and coordinates into GeoJSON points clinics are searched by.
+package geo
+
+import (
+	"AlShifa/Clinic/models"
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// PointType is the GeoJSON type of a single location
+const PointType = "Point"
+
+//go:embed pincodes.csv
+var bundledPincodes []byte
+
+var (
+	centroids     map[int32]models.GeoPoint
+	centroidsErr  error
+	centroidsOnce sync.Once
+)
+
+// NewPoint returns GeoJSON point of latitude and longitude, GeoJSON keeps longitude first
+func NewPoint(latitude float64, longitude float64) models.GeoPoint {
+	return models.GeoPoint{Type: PointType, Coordinates: []float64{longitude, latitude}}
+}
+
+// ValidPoint tells if point is a GeoJSON point with longitude and latitude in range
+func ValidPoint(point models.GeoPoint) bool {
+	if point.Type != PointType || len(point.Coordinates) != 2 {
+		return false
+	}
+	longitude, latitude := point.Coordinates[0], point.Coordinates[1]
+	return longitude >= -180 && longitude <= 180 && latitude >= -90 && latitude <= 90
+}
+
+// LoadPincodes loads the pincode dataset, the bundled one or the csv PINCODE_DATASET points to when it is set. it is
+// meant for startup so a broken override fails there instead of turning geocoding off, PincodeCentroid loads it too
+func LoadPincodes() error {
+	_, err := loadCentroids()
+	return err
+}
+
+// PincodeCentroid returns the centre of pincode from the pincode dataset
+func PincodeCentroid(pincode int32) (models.GeoPoint, bool) {
+	loaded, err := loadCentroids()
+	if err != nil {
+		return models.GeoPoint{}, false
+	}
+	point, ok := loaded[pincode]
+	return point, ok
+}
+
+// loadCentroids reads the pincode dataset once, every later call gets the same centroids or error
+func loadCentroids() (map[int32]models.GeoPoint, error) {
+	centroidsOnce.Do(func() {
+		centroids, centroidsErr = readCentroids(os.Getenv("PINCODE_DATASET"))
+		if centroidsErr != nil {
+			log.Printf("pincode geocoding is off: %v", centroidsErr)
+		}
+	})
+	return centroids, centroidsErr
+}
+
+// readCentroids parses csv at path, or the bundled dataset when path is empty
+func readCentroids(path string) (map[int32]models.GeoPoint, error) {
+	data, source := bundledPincodes, "bundled pincode dataset"
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read pincode dataset %s: %w", path, err)
+		}
+		source = "pincode dataset " + path
+	}
+
+	parsed, err := parseCentroids(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", source, err)
+	}
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("%s has no pincodes", source)
+	}
+	return parsed, nil
+}
+
+// parseCentroids reads csv of pincode,latitude,longitude with a header row
+func parseCentroids(data []byte) (map[int32]models.GeoPoint, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = 3
+
+	parsed := make(map[int32]models.GeoPoint)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return parsed, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == 1 {
+			continue
+		}
+
+		pincode, pincodeErr := strconv.ParseInt(record[0], 10, 32)
+		latitude, latitudeErr := strconv.ParseFloat(record[1], 64)
+		longitude, longitudeErr := strconv.ParseFloat(record[2], 64)
+		point := NewPoint(latitude, longitude)
+		if pincodeErr != nil || latitudeErr != nil || longitudeErr != nil || !ValidPoint(point) {
+			return nil, fmt.Errorf("invalid row %d: %v", line, record)
+		}
+		parsed[int32(pincode)] = point
+	}
+}
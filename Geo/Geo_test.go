@@ -0,0 +1,78 @@
+package geo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPincodeCentroid(t *testing.T) {
+	point, ok := PincodeCentroid(190001)
+	if !ok || !ValidPoint(point) {
+		t.Fatalf("expected centroid of bundled pincode got %+v %v", point, ok)
+	}
+	//longitude comes first in GeoJSON
+	if point.Coordinates[0] < 74 || point.Coordinates[1] > 35 {
+		t.Fatalf("coordinates out of order %v", point.Coordinates)
+	}
+
+	if _, ok := PincodeCentroid(999999); ok {
+		t.Fatalf("expected no centroid for unknown pincode")
+	}
+}
+
+func TestParseCentroids(t *testing.T) {
+	if _, err := parseCentroids([]byte("pincode,latitude,longitude\n190001,134.08,74.79\n")); err == nil {
+		t.Fatalf("expected latitude out of range to fail")
+	}
+
+	parsed, err := parseCentroids([]byte("pincode,latitude,longitude\n190001,34.08,74.79\n"))
+	if err != nil || len(parsed) != 1 {
+		t.Fatalf("expected one centroid got %v %v", parsed, err)
+	}
+}
+
+func TestReadCentroids(t *testing.T) {
+	bundled, err := readCentroids("")
+	if err != nil || len(bundled) == 0 {
+		t.Fatalf("expected bundled dataset without override got %v", err)
+	}
+
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.csv")
+	if err := os.WriteFile(empty, []byte("pincode,latitude,longitude\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	full := filepath.Join(dir, "pincodes.csv")
+	if err := os.WriteFile(full, []byte("pincode,latitude,longitude\n182101,32.92,75.14\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name        string
+		Path        string
+		ExpectedErr bool
+	}{
+		{Name: "Unreadable override fails", Path: filepath.Join(dir, "missing.csv"), ExpectedErr: true},
+		{Name: "Override without pincodes fails", Path: empty, ExpectedErr: true},
+		{Name: "Override replaces bundled dataset", Path: full},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			parsed, err := readCentroids(tc.Path)
+			if tc.ExpectedErr {
+				if err == nil {
+					t.Fatalf("expected dataset to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected dataset to load got %v", err)
+			}
+			if _, ok := parsed[182101]; !ok || len(parsed) != 1 {
+				t.Fatalf("expected only centroids of override got %v", parsed)
+			}
+		})
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"os"
 	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type contextKey string
@@ -16,6 +18,8 @@ const (
 	//context related values
 	ContextUserIDKey   contextKey = "userID"
 	ContextUserRoleKey contextKey = "role"
+	//set by PermissionGuardMiddleware to the clinic staff member works at
+	ContextStaffClinicKey contextKey = "staffClinic"
 )
 
 func JwtAuthMiddleware(handler func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
@@ -64,3 +68,41 @@ func JwtAuthMiddleware(handler func(http.ResponseWriter, *http.Request)) func(ht
 		handler(w, r.WithContext(ctx))
 	})
 }
+
+// AuthenticatedUser reads the userID and role injected by JwtAuthMiddleware and converts the userID to a mongodb id
+func AuthenticatedUser(req *http.Request) (primitive.ObjectID, string, *structs.IAppError) {
+	userID, ok := req.Context().Value(ContextUserIDKey).(string)
+	if !ok || userID == "" {
+		return primitive.NilObjectID, "", utils.ReturnAppError(nil, http.StatusBadRequest, "Invalid or missing Userid", "Invalid or missing Userid")
+	}
+
+	userRole, ok := req.Context().Value(ContextUserRoleKey).(string)
+	if !ok || userRole == "" {
+		return primitive.NilObjectID, "", utils.ReturnAppError(nil, http.StatusBadRequest, "Missing Role", "Missing Role")
+	}
+
+	objectUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil || objectUserID == primitive.NilObjectID {
+		return primitive.NilObjectID, "", utils.ReturnAppError(err, http.StatusBadRequest, "Invalid UserID", "Invalid UserID")
+	}
+
+	return objectUserID, userRole, nil
+}
+
+// ActiveClinic reads the clinic owner has switched to for this call from ActiveClinicHeader, it is nil id when the
+// header is absent. it only parses the id, ownership is checked by whoever uses it. staff always work at their own clinic
+func ActiveClinic(req *http.Request) (primitive.ObjectID, *structs.IAppError) {
+	if staffClinic, ok := req.Context().Value(ContextStaffClinicKey).(primitive.ObjectID); ok {
+		return staffClinic, nil
+	}
+	header := strings.TrimSpace(req.Header.Get(utils.ActiveClinicHeader))
+	if header == "" {
+		return primitive.NilObjectID, nil
+	}
+
+	clinicID, err := primitive.ObjectIDFromHex(header)
+	if err != nil {
+		return primitive.NilObjectID, utils.ReturnAppError(err, http.StatusBadRequest, "Invalid Active Clinic", "Invalid "+utils.ActiveClinicHeader+" Header")
+	}
+	return clinicID, nil
+}
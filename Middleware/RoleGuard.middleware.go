@@ -2,8 +2,11 @@ package middleware
 
 import (
 	utils "AlShifa/Utils"
+	"context"
 	"net/http"
 	"slices"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func RoleGuardMiddleware(handler http.HandlerFunc, allowedRoles ...string) http.HandlerFunc {
@@ -35,3 +38,60 @@ func RoleGuardMiddleware(handler http.HandlerFunc, allowedRoles ...string) http.
 		_ = utils.WriteResponse(w, http.StatusForbidden, utils.ReturnAppError(nil, 403, "Forbidden To Access This Api", "Forbidden"))
 	}
 }
+
+// StaffAccessFunc returns the clinic staff member works at and their permissions there, ok is false for staff who are
+// yet to join or were revoked
+type StaffAccessFunc func(ctx context.Context, staffID primitive.ObjectID) (clinicID primitive.ObjectID, permissions []string, ok bool, err error)
+
+var staffAccess StaffAccessFunc
+
+// SetStaffAccess sets how PermissionGuardMiddleware looks up staff, staff module sets it when it is initialised
+func SetStaffAccess(access StaffAccessFunc) {
+	staffAccess = access
+}
+
+// PermissionGuardMiddleware extends RoleGuardMiddleware to clinic staff. allowedRoles pass as they do there and staff pass
+// only with permission at their clinic, that clinic is put in context so services let staff act on it and nothing else
+func PermissionGuardMiddleware(handler http.HandlerFunc, permission string, allowedRoles ...string) http.HandlerFunc {
+	guarded := RoleGuardMiddleware(handler, allowedRoles...)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if role, _ := r.Context().Value(ContextUserRoleKey).(string); role != utils.RoleStaff {
+			guarded(w, r)
+			return
+		}
+
+		staffID, _, authErr := AuthenticatedUser(r)
+		if authErr != nil {
+			_ = utils.WriteResponse(w, authErr.StatusCode, authErr)
+			return
+		}
+		if staffAccess == nil {
+			_ = utils.WriteResponse(w, http.StatusForbidden, utils.ReturnAppError(nil, 403, "Forbidden To Access This Api", "Forbidden"))
+			return
+		}
+
+		clinicID, permissions, ok, err := staffAccess(r.Context(), staffID)
+		if err != nil {
+			_ = utils.WriteResponse(w, http.StatusInternalServerError, utils.ReturnAppError(err, 500, "Unable To Check Permissions", "Server Error"))
+			return
+		}
+		if !ok || !slices.Contains(permissions, permission) {
+			_ = utils.WriteResponse(w, http.StatusForbidden, utils.ReturnAppError(nil, 403, "Forbidden To Access This Api", "Missing Permission "+permission))
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ContextStaffClinicKey, clinicID)
+		handler(w, r.WithContext(ctx))
+	}
+}
+
+// ActsForClinic tells if actor can act for clinic with ownerID, that is actor owns it or is staff who passed
+// PermissionGuardMiddleware at it. ctx has to come from the request for staff to be recognised
+func ActsForClinic(ctx context.Context, actorID primitive.ObjectID, ownerID primitive.ObjectID, clinicID primitive.ObjectID) bool {
+	if actorID == ownerID {
+		return true
+	}
+	staffClinic, ok := ctx.Value(ContextStaffClinicKey).(primitive.ObjectID)
+	return ok && staffClinic == clinicID
+}
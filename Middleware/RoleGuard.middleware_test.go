@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	utils "AlShifa/Utils"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// requestOf returns request made by user with role the way JwtAuthMiddleware leaves it
+func requestOf(userID primitive.ObjectID, role string) *http.Request {
+	req := httptest.NewRequest(http.MethodPut, "/v1/clinic", nil)
+	ctx := context.WithValue(req.Context(), ContextUserIDKey, userID.Hex())
+	if role != "" {
+		ctx = context.WithValue(ctx, ContextUserRoleKey, role)
+	}
+	return req.WithContext(ctx)
+}
+
+func TestPermissionGuardMiddleware(t *testing.T) {
+	clinicA := primitive.NewObjectID()
+	clinicB := primitive.NewObjectID()
+	ownerID := primitive.NewObjectID()
+
+	testCases := []struct {
+		Name               string
+		Role               string
+		Permissions        []string
+		Joined             bool
+		AccessErr          error
+		ExpectedStatusCode int
+		ActsForClinicA     bool
+		ActsForClinicB     bool
+	}{
+		{Name: "Owner passes like role guard", Role: utils.RoleClinicOwner, ExpectedStatusCode: http.StatusOK},
+		{Name: "Admin passes like role guard", Role: utils.RoleAdmin, ExpectedStatusCode: http.StatusOK},
+		{Name: "Role not allowed is forbidden", Role: utils.RoleUser, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Doctor not allowed is forbidden even with staff access around", Role: utils.RoleDoctor, Permissions: []string{utils.PermissionEditClinic}, Joined: true, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Request without role is rejected", Role: "", ExpectedStatusCode: http.StatusBadRequest},
+		{Name: "Staff with permission acts for their clinic only", Role: utils.RoleStaff, Permissions: []string{utils.PermissionViewWallet, utils.PermissionEditClinic}, Joined: true, ExpectedStatusCode: http.StatusOK, ActsForClinicA: true},
+		{Name: "Staff missing permission is forbidden", Role: utils.RoleStaff, Permissions: []string{utils.PermissionViewWallet}, Joined: true, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Revoked or invited staff is forbidden", Role: utils.RoleStaff, Permissions: []string{utils.PermissionEditClinic}, Joined: false, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Staff lookup failing is server error", Role: utils.RoleStaff, AccessErr: errors.New("error from mock"), ExpectedStatusCode: http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			actorID := primitive.NewObjectID()
+			SetStaffAccess(func(ctx context.Context, staffID primitive.ObjectID) (primitive.ObjectID, []string, bool, error) {
+				if staffID != actorID {
+					t.Fatalf("expected access of %s to be looked up but got %s", actorID.Hex(), staffID.Hex())
+				}
+				return clinicA, tc.Permissions, tc.Joined, tc.AccessErr
+			})
+			defer SetStaffAccess(nil)
+
+			called := false
+			actsForA, actsForB := false, false
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				actsForA = ActsForClinic(r.Context(), actorID, ownerID, clinicA)
+				actsForB = ActsForClinic(r.Context(), actorID, ownerID, clinicB)
+				w.WriteHeader(http.StatusOK)
+			}
+
+			res := httptest.NewRecorder()
+			PermissionGuardMiddleware(handler, utils.PermissionEditClinic, utils.RoleClinicOwner, utils.RoleAdmin)(res, requestOf(actorID, tc.Role))
+
+			if res.Code != tc.ExpectedStatusCode {
+				t.Fatalf("expected status code %d but got %d", tc.ExpectedStatusCode, res.Code)
+			}
+			if called != (tc.ExpectedStatusCode == http.StatusOK) {
+				t.Fatalf("expected handler to be called only when access is granted")
+			}
+			if actsForA != tc.ActsForClinicA || actsForB != tc.ActsForClinicB {
+				t.Fatalf("expected to act for clinic A %v and B %v but got %v and %v", tc.ActsForClinicA, tc.ActsForClinicB, actsForA, actsForB)
+			}
+		})
+	}
+}
+
+func TestPermissionGuardWithoutStaffModule(t *testing.T) {
+	SetStaffAccess(nil)
+
+	res := httptest.NewRecorder()
+	PermissionGuardMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("staff shouldnt pass when their access cant be looked up")
+	}, utils.PermissionEditClinic, utils.RoleClinicOwner)(res, requestOf(primitive.NewObjectID(), utils.RoleStaff))
+
+	if res.Code != http.StatusForbidden {
+		t.Fatalf("expected status code %d but got %d", http.StatusForbidden, res.Code)
+	}
+}
+
+func TestActsForClinic(t *testing.T) {
+	ownerID := primitive.NewObjectID()
+	staffID := primitive.NewObjectID()
+	clinicA := primitive.NewObjectID()
+	clinicB := primitive.NewObjectID()
+	staffAtA := context.WithValue(context.Background(), ContextStaffClinicKey, clinicA)
+
+	testCases := []struct {
+		Name     string
+		Ctx      context.Context
+		ActorID  primitive.ObjectID
+		ClinicID primitive.ObjectID
+		Expected bool
+	}{
+		{Name: "Owner acts for own clinic", Ctx: context.Background(), ActorID: ownerID, ClinicID: clinicA, Expected: true},
+		{Name: "Someone else doesnt act for clinic", Ctx: context.Background(), ActorID: primitive.NewObjectID(), ClinicID: clinicA, Expected: false},
+		{Name: "Staff acts for clinic they passed the guard at", Ctx: staffAtA, ActorID: staffID, ClinicID: clinicA, Expected: true},
+		{Name: "Staff at clinic A doesnt act for clinic B", Ctx: staffAtA, ActorID: staffID, ClinicID: clinicB, Expected: false},
+		{Name: "Staff who didnt pass the guard doesnt act for any clinic", Ctx: context.Background(), ActorID: staffID, ClinicID: clinicA, Expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if got := ActsForClinic(tc.Ctx, tc.ActorID, ownerID, tc.ClinicID); got != tc.Expected {
+				t.Fatalf("expected %v but got %v", tc.Expected, got)
+			}
+		})
+	}
+}
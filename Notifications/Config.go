@@ -0,0 +1,103 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// MultiNotifier sends every message on all of its channels. a message reaching the recipient on one channel counts as
+// delivered, failures on the others are only logged so a retry does not deliver it twice
+type MultiNotifier struct {
+	Notifiers []Notifier
+}
+
+// this ensures multi notifier implements notifier interface
+var _ Notifier = (*MultiNotifier)(nil)
+
+func (notifier *MultiNotifier) Notify(ctx context.Context, message Message) error {
+	delivered := false
+	var errs []error
+	for _, channel := range notifier.Notifiers {
+		err := channel.Notify(ctx, message)
+		switch {
+		case err == nil:
+			delivered = true
+		case !errors.Is(err, ErrNoContact):
+			errs = append(errs, err)
+		}
+	}
+
+	if delivered {
+		for _, err := range errs {
+			log.Printf("notification to %s failed on a channel: %v", message.Recipient.Hex(), err)
+		}
+		return nil
+	}
+	if len(errs) == 0 {
+		return ErrNoContact
+	}
+	return errors.Join(errs...)
+}
+
+// NewNotifierFromEnv builds the notifier named by NOTIFIER_CHANNELS, a comma separated list of smtp, sms, file and log.
+// it defaults to log so development needs no configuration
+func NewNotifierFromEnv(contacts ContactBook) (Notifier, error) {
+	channels := os.Getenv("NOTIFIER_CHANNELS")
+	if channels == "" {
+		channels = "log"
+	}
+
+	var notifiers []Notifier
+	for _, channel := range strings.Split(channels, ",") {
+		switch strings.ToLower(strings.TrimSpace(channel)) {
+		case "smtp":
+			config := SMTPConfig{
+				Host:     os.Getenv("SMTP_HOST"),
+				Port:     os.Getenv("SMTP_PORT"),
+				Username: os.Getenv("SMTP_USERNAME"),
+				Password: os.Getenv("SMTP_PASSWORD"),
+				From:     os.Getenv("SMTP_FROM"),
+			}
+			if config.Host == "" || config.From == "" {
+				return nil, errors.New("SMTP_HOST and SMTP_FROM are required for smtp notifier")
+			}
+			if config.Port == "" {
+				config.Port = "587"
+			}
+			notifiers = append(notifiers, NewSMTPNotifier(config, contacts))
+		case "sms":
+			config := SMSConfig{
+				GatewayURL:  os.Getenv("SMS_GATEWAY_URL"),
+				APIKey:      os.Getenv("SMS_API_KEY"),
+				Sender:      os.Getenv("SMS_SENDER"),
+				CountryCode: os.Getenv("SMS_COUNTRY_CODE"),
+			}
+			if config.GatewayURL == "" {
+				return nil, errors.New("SMS_GATEWAY_URL is required for sms notifier")
+			}
+			if config.CountryCode == "" {
+				config.CountryCode = "91"
+			}
+			notifiers = append(notifiers, NewSMSNotifier(config, contacts))
+		case "file":
+			path := os.Getenv("NOTIFIER_FILE")
+			if path == "" {
+				path = "notifications.log"
+			}
+			notifiers = append(notifiers, NewFileNotifier(path))
+		case "log":
+			notifiers = append(notifiers, NewLogNotifier())
+		default:
+			return nil, fmt.Errorf("unknown notifier channel %q", channel)
+		}
+	}
+
+	if len(notifiers) == 1 {
+		return notifiers[0], nil
+	}
+	return &MultiNotifier{Notifiers: notifiers}, nil
+}
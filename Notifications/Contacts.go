@@ -0,0 +1,52 @@
+package notifications
+
+import (
+	"context"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// contactCollections are searched in order, an id belongs to only one of them
+var contactCollections = []string{"User", "Doctor", "Owner"}
+
+// MongoContactBook reads email and mobile of patients, doctors and clinic owners from their collections
+type MongoContactBook struct {
+	DB *mongo.Database
+}
+
+// this ensures mongo contact book implements contact book interface
+var _ ContactBook = (*MongoContactBook)(nil)
+
+func NewMongoContactBook(db *mongo.Database) *MongoContactBook {
+	return &MongoContactBook{
+		DB: db,
+	}
+}
+
+func (book *MongoContactBook) Contact(ctx context.Context, recipient primitive.ObjectID) (Contact, error) {
+	opts := options.FindOne().SetProjection(bson.M{"email": 1, "mobile": 1})
+	for _, collection := range contactCollections {
+		var person struct {
+			Email  string `bson:"email"`
+			Mobile int64  `bson:"mobile"`
+		}
+		err := book.DB.Collection(collection).FindOne(ctx, bson.M{"_id": recipient}, opts).Decode(&person)
+		if err == mongo.ErrNoDocuments {
+			continue
+		}
+		if err != nil {
+			return Contact{}, err
+		}
+
+		contact := Contact{Email: person.Email}
+		if person.Mobile != 0 {
+			contact.Mobile = strconv.FormatInt(person.Mobile, 10)
+		}
+		return contact, nil
+	}
+	return Contact{}, mongo.ErrNoDocuments
+}
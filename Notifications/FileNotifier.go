@@ -0,0 +1,49 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileNotifier appends messages as json lines to a file, it stands in for real channels in development and tests
+type FileNotifier struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// this ensures file notifier implements notifier interface
+var _ Notifier = (*FileNotifier)(nil)
+
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{
+		Path: path,
+	}
+}
+
+type fileEntry struct {
+	Message
+	SentAt time.Time `json:"sentAt"`
+}
+
+func (notifier *FileNotifier) Notify(ctx context.Context, message Message) error {
+	line, err := json.Marshal(fileEntry{Message: message, SentAt: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+
+	file, err := os.OpenFile(notifier.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
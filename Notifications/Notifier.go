@@ -0,0 +1,51 @@
+// Package notifications delivers messages to patients and doctors through pluggable channels.
+package notifications
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Message is a notification for a single recipient
+type Message struct {
+	Recipient primitive.ObjectID `json:"recipient" bson:"recipient"`
+	Subject   string             `json:"subject" bson:"subject"`
+	Body      string             `json:"body" bson:"body"`
+}
+
+// Notifier delivers a message to its recipient
+type Notifier interface {
+	Notify(ctx context.Context, message Message) error
+}
+
+// Contact is where a recipient can be reached, empty fields are unknown
+type Contact struct {
+	Email  string
+	Mobile string
+}
+
+// ContactBook finds contact details of a recipient for channels that need an address
+type ContactBook interface {
+	Contact(ctx context.Context, recipient primitive.ObjectID) (Contact, error)
+}
+
+// ErrNoContact is returned when a recipient has no address on the notifier's channel
+var ErrNoContact = errors.New("recipient has no contact for this channel")
+
+// LogNotifier writes messages to the log instead of delivering them, it is meant for development
+type LogNotifier struct{}
+
+// this ensures log notifier implements notifier interface
+var _ Notifier = (*LogNotifier)(nil)
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (notifier *LogNotifier) Notify(ctx context.Context, message Message) error {
+	log.Printf("notification to %s: %s - %s", message.Recipient.Hex(), message.Subject, message.Body)
+	return nil
+}
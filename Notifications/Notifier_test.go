@@ -0,0 +1,72 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type staticContacts map[primitive.ObjectID]Contact
+
+func (contacts staticContacts) Contact(ctx context.Context, recipient primitive.ObjectID) (Contact, error) {
+	return contacts[recipient], nil
+}
+
+type failingNotifier struct {
+	err error
+}
+
+func (notifier failingNotifier) Notify(ctx context.Context, message Message) error {
+	return notifier.err
+}
+
+func TestSMSNotifier(t *testing.T) {
+	patient := primitive.NewObjectID()
+	var received smsRequest
+	gateway := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer key" {
+			res.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewDecoder(req.Body).Decode(&received)
+		res.WriteHeader(http.StatusAccepted)
+	}))
+	defer gateway.Close()
+
+	notifier := NewSMSNotifier(SMSConfig{GatewayURL: gateway.URL, APIKey: "key", Sender: "ALSHFA", CountryCode: "91"}, staticContacts{patient: {Mobile: "9876543210"}})
+
+	if err := notifier.Notify(context.Background(), Message{Recipient: patient, Subject: "Appointment Reminder", Body: "Tomorrow"}); err != nil {
+		t.Fatalf("expected sms to be sent got %v", err)
+	}
+	if received.To != "+919876543210" || received.Message != "Appointment Reminder: Tomorrow" {
+		t.Errorf("unexpected sms %+v", received)
+	}
+
+	if err := notifier.Notify(context.Background(), Message{Recipient: primitive.NewObjectID()}); !errors.Is(err, ErrNoContact) {
+		t.Errorf("expected ErrNoContact for recipient without mobile got %v", err)
+	}
+}
+
+func TestMultiNotifier(t *testing.T) {
+	down := errors.New("gateway down")
+
+	delivered := &MultiNotifier{Notifiers: []Notifier{failingNotifier{down}, failingNotifier{nil}}}
+	if err := delivered.Notify(context.Background(), Message{}); err != nil {
+		t.Errorf("expected delivery on one channel to succeed got %v", err)
+	}
+
+	unreachable := &MultiNotifier{Notifiers: []Notifier{failingNotifier{ErrNoContact}, failingNotifier{ErrNoContact}}}
+	if err := unreachable.Notify(context.Background(), Message{}); !errors.Is(err, ErrNoContact) {
+		t.Errorf("expected ErrNoContact got %v", err)
+	}
+
+	failed := &MultiNotifier{Notifiers: []Notifier{failingNotifier{ErrNoContact}, failingNotifier{down}}}
+	if err := failed.Notify(context.Background(), Message{}); !errors.Is(err, down) || errors.Is(err, ErrNoContact) {
+		t.Errorf("expected only the delivery failure got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SMSConfig is the http sms gateway used by sms notifier
+type SMSConfig struct {
+	GatewayURL  string
+	APIKey      string
+	Sender      string
+	CountryCode string // prefixed to mobiles stored without one, e.g. 91
+}
+
+// SMSNotifier texts messages through an http gateway which accepts {"to", "from", "message"} as json
+type SMSNotifier struct {
+	Config   SMSConfig
+	Contacts ContactBook
+	Client   *http.Client
+}
+
+// this ensures sms notifier implements notifier interface
+var _ Notifier = (*SMSNotifier)(nil)
+
+func NewSMSNotifier(config SMSConfig, contacts ContactBook) *SMSNotifier {
+	return &SMSNotifier{
+		Config:   config,
+		Contacts: contacts,
+		Client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type smsRequest struct {
+	To      string `json:"to"`
+	From    string `json:"from"`
+	Message string `json:"message"`
+}
+
+func (notifier *SMSNotifier) Notify(ctx context.Context, message Message) error {
+	contact, err := notifier.Contacts.Contact(ctx, message.Recipient)
+	if err != nil {
+		return err
+	}
+	if contact.Mobile == "" {
+		return ErrNoContact
+	}
+
+	to := contact.Mobile
+	if !strings.HasPrefix(to, "+") {
+		to = "+" + notifier.Config.CountryCode + to
+	}
+
+	body, err := json.Marshal(smsRequest{
+		To:      to,
+		From:    notifier.Config.Sender,
+		Message: message.Subject + ": " + message.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, notifier.Config.GatewayURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if notifier.Config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+notifier.Config.APIKey)
+	}
+
+	res, err := notifier.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("sms gateway returned %s", res.Status)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPConfig is the mail server used by smtp notifier, Username empty means the server needs no auth
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPNotifier emails messages to the address found in its contact book
+type SMTPNotifier struct {
+	Config   SMTPConfig
+	Contacts ContactBook
+	// send is smtp.SendMail, replaced in tests
+	send func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// this ensures smtp notifier implements notifier interface
+var _ Notifier = (*SMTPNotifier)(nil)
+
+func NewSMTPNotifier(config SMTPConfig, contacts ContactBook) *SMTPNotifier {
+	return &SMTPNotifier{
+		Config:   config,
+		Contacts: contacts,
+		send:     smtp.SendMail,
+	}
+}
+
+func (notifier *SMTPNotifier) Notify(ctx context.Context, message Message) error {
+	contact, err := notifier.Contacts.Contact(ctx, message.Recipient)
+	if err != nil {
+		return err
+	}
+	if contact.Email == "" {
+		return ErrNoContact
+	}
+
+	var auth smtp.Auth
+	if notifier.Config.Username != "" {
+		auth = smtp.PlainAuth("", notifier.Config.Username, notifier.Config.Password, notifier.Config.Host)
+	}
+
+	addr := net.JoinHostPort(notifier.Config.Host, notifier.Config.Port)
+	return notifier.send(addr, auth, notifier.Config.From, []string{contact.Email}, mailBody(notifier.Config.From, contact.Email, message, time.Now()))
+}
+
+// mailBody builds a plain text mail, subject is encoded so names in any script survive
+func mailBody(from string, to string, message Message, date time.Time) []byte {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "From: %s\r\n", from)
+	fmt.Fprintf(&builder, "To: %s\r\n", to)
+	fmt.Fprintf(&builder, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&builder, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	builder.WriteString("MIME-Version: 1.0\r\n")
+	builder.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	builder.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	builder.WriteString("\r\n")
+	builder.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+	builder.WriteString("\r\n")
+	return []byte(builder.String())
+}
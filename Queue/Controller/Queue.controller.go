@@ -0,0 +1,270 @@
+// Package controller provides HTTP handlers for token queues of doctors.
+package controller
+
+import (
+	middleware "AlShifa/Middleware"
+	interfaces "AlShifa/Queue/Interfaces"
+	queueStructs "AlShifa/Queue/Structs"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Controller struct {
+	Service interfaces.IService
+}
+
+type TokenRequest struct {
+	Clinic      primitive.ObjectID `json:"clinic"`
+	PatientName string             `json:"patientName"`
+}
+
+type RecallRequest struct {
+	Number int32 `json:"number"`
+}
+
+func NewController(service interfaces.IService) *Controller {
+	return &Controller{
+		Service: service,
+	}
+}
+
+func (controller *Controller) IssueToken(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	actorID, actorRole, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	doctorID, err := primitive.ObjectIDFromHex(req.PathValue("doctor"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Doctor ID", "Invalid ID"))
+		return
+	}
+
+	var tokenRequest TokenRequest
+	if err := json.NewDecoder(req.Body).Decode(&tokenRequest); err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Failed To Issue Token", "Invalid Json"))
+		return
+	}
+	//owners and staff can leave clinic out of the body and issue at the clinic they switched to or work at
+	if tokenRequest.Clinic.IsZero() && actorRole != utils.RoleDoctor {
+		activeClinic, appErr := middleware.ActiveClinic(req)
+		if appErr != nil {
+			_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+			return
+		}
+		tokenRequest.Clinic = activeClinic
+	}
+	tokenRequest.PatientName = strings.TrimSpace(tokenRequest.PatientName)
+	if tokenRequest.Clinic.IsZero() || len(tokenRequest.PatientName) < utils.MinNameLength || len(tokenRequest.PatientName) > utils.MaxNameLength {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(map[string]string{"patientName": "clinic and patient name are required"}, 400, "Failed To Issue Token", "Invalid Details"))
+		return
+	}
+
+	token, appErr := controller.Service.IssueToken(ctx, actorID, actorRole, doctorID, tokenRequest.Clinic, nil, tokenRequest.PatientName)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusCreated, utils.ReturnAppSuccess(201, "Token Issued Successfully", token))
+}
+
+func (controller *Controller) CallNext(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	doctorID, clinicID, appErr := ownQueue(req)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	snapshot, appErr := controller.Service.CallNext(ctx, doctorID, clinicID)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Next Token Called", snapshot))
+}
+
+func (controller *Controller) Skip(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	doctorID, clinicID, appErr := ownQueue(req)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	snapshot, appErr := controller.Service.Skip(ctx, doctorID, clinicID)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Token Skipped", snapshot))
+}
+
+func (controller *Controller) Recall(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	doctorID, clinicID, appErr := ownQueue(req)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	//empty body recalls the current token
+	var recallRequest RecallRequest
+	if req.ContentLength != 0 {
+		if err := json.NewDecoder(req.Body).Decode(&recallRequest); err != nil {
+			_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Recall Failed", "Invalid Json"))
+			return
+		}
+	}
+	if recallRequest.Number < 0 {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(map[string]string{"number": "invalid token number"}, 400, "Recall Failed", "Invalid Details"))
+		return
+	}
+
+	snapshot, appErr := controller.Service.Recall(ctx, doctorID, clinicID, recallRequest.Number)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Token Recalled", snapshot))
+}
+
+func (controller *Controller) GetQueue(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	doctorID, clinicID, appErr := queueOfRequest(req)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	snapshot, appErr := controller.Service.GetQueue(ctx, doctorID, clinicID)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Fetched Successfully", snapshot))
+}
+
+// StreamQueue streams snapshots of the queue as Server-Sent Events, current state is sent first and then every change
+func (controller *Controller) StreamQueue(res http.ResponseWriter, req *http.Request) {
+	doctorID, clinicID, appErr := queueOfRequest(req)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	flusher, ok := res.(http.Flusher)
+	if !ok {
+		_ = utils.WriteResponse(res, http.StatusInternalServerError, utils.ReturnAppError(errors.New("streaming unsupported"), 500, "Streaming Not Supported", "Server Error"))
+		return
+	}
+
+	//subscribe before reading current state so no change in between is missed
+	updates, unsubscribe := controller.Service.Subscribe(doctorID, clinicID)
+	defer unsubscribe()
+
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	snapshot, appErr := controller.Service.GetQueue(ctx, doctorID, clinicID)
+	cancel()
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	res.Header().Set("Content-Type", "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	if err := writeEvent(res, *snapshot); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	//comments keep proxies from closing an idle stream
+	heartbeat := time.NewTicker(utils.QueueHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case snapshot := <-updates:
+			if err := writeEvent(res, snapshot); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+func writeEvent(res http.ResponseWriter, snapshot queueStructs.QueueSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(res, "event: queue\ndata: %s\n\n", data)
+	return err
+}
+
+// queueOfRequest reads doctor from path and clinic from query params
+func queueOfRequest(req *http.Request) (primitive.ObjectID, primitive.ObjectID, *structs.IAppError) {
+	doctorID, err := primitive.ObjectIDFromHex(req.PathValue("doctor"))
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, utils.ReturnAppError(err, 400, "Invalid Doctor ID", "Invalid ID")
+	}
+
+	clinicID, err := primitive.ObjectIDFromHex(req.URL.Query().Get("clinic"))
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, utils.ReturnAppError(err, 400, "Invalid Clinic ID", "Invalid ID")
+	}
+	return doctorID, clinicID, nil
+}
+
+// ownQueue is queueOfRequest for queue actions, only the doctor of the queue can advance it
+func ownQueue(req *http.Request) (primitive.ObjectID, primitive.ObjectID, *structs.IAppError) {
+	actorID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, authErr
+	}
+
+	doctorID, clinicID, appErr := queueOfRequest(req)
+	if appErr != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, appErr
+	}
+	if doctorID != actorID {
+		return primitive.NilObjectID, primitive.NilObjectID, utils.ReturnAppError(errors.New("queue of other doctor"), http.StatusForbidden, "Forbidden To Advance Queue", "Doctors Can Only Advance Their Own Queue")
+	}
+	return doctorID, clinicID, nil
+}
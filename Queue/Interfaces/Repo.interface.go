@@ -0,0 +1,21 @@
+// Package interfaces contains interfaces for Queue module
+package interfaces
+
+import (
+	"AlShifa/Clinic/models"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IRepository defines the methods for loose coupling between the queue repository and its implementation.
+type IRepository interface {
+	IssueToken(ctx context.Context, token models.QueueToken) (models.QueueToken, error)
+	GetTokens(ctx context.Context, filter bson.M) ([]models.QueueToken, error)
+	GetToken(ctx context.Context, filter bson.M) (models.QueueToken, error)
+	AdvanceQueue(ctx context.Context, queue bson.M, currentTo string, next bson.M) ([]models.QueueToken, *models.QueueToken, error)
+	UpdateTokenStatus(ctx context.Context, filter bson.M, status string) error
+	GetDoctor(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error)
+	GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error)
+}
@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"AlShifa/Clinic/models"
+	queueStructs "AlShifa/Queue/Structs"
+	structs "AlShifa/Structs"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IService interface contains functions that queue service layer must implement( to be used by handlers)
+type IService interface {
+	IssueToken(ctx context.Context, actorID primitive.ObjectID, actorRole string, doctorID primitive.ObjectID, clinicID primitive.ObjectID, userID *primitive.ObjectID, patientName string) (*models.QueueToken, *structs.IAppError)
+	CallNext(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID) (*queueStructs.QueueSnapshot, *structs.IAppError)
+	Skip(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID) (*queueStructs.QueueSnapshot, *structs.IAppError)
+	Recall(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID, number int32) (*queueStructs.QueueSnapshot, *structs.IAppError)
+	GetQueue(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID) (*queueStructs.QueueSnapshot, *structs.IAppError)
+	Subscribe(doctorID primitive.ObjectID, clinicID primitive.ObjectID) (<-chan queueStructs.QueueSnapshot, func())
+}
@@ -0,0 +1,39 @@
+// Package queue provides walk-in token queues of doctors with a live stream for patient apps and waiting room screens.
+package queue
+
+import (
+	appointmentInterfaces "AlShifa/Appointment/Interfaces"
+	internals "AlShifa/Internals"
+	middleware "AlShifa/Middleware"
+	controller "AlShifa/Queue/Controller"
+	interfaces "AlShifa/Queue/Interfaces"
+	repository "AlShifa/Queue/Repository"
+	service "AlShifa/Queue/Service"
+	utils "AlShifa/Utils"
+	"context"
+	"log"
+)
+
+// InitialiseQueueModule registers queue routes and returns the service, checked in appointments of appointments service get tokens automatically
+func InitialiseQueueModule(app *internals.App, appointments appointmentInterfaces.IService) interfaces.IService {
+	repository := repository.NewRepository(app.DB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout*5)
+	defer cancel()
+	if err := repository.CreateIndexes(ctx); err != nil {
+		log.Fatal("Failed to create queue indexes", err)
+	}
+
+	service := service.NewQueueService(repository, appointments)
+	appointments.AddStatusListener(service)
+
+	controller := controller.NewController(service)
+	app.Server.HandleFunc(utils.MakeURL("POST", "/queue/{doctor}/token"), middleware.JwtAuthMiddleware(middleware.PermissionGuardMiddleware(controller.IssueToken, utils.PermissionManageQueue, utils.RoleDoctor, utils.RoleClinicOwner)))
+	app.Server.HandleFunc(utils.MakeURL("POST", "/queue/{doctor}/next"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.CallNext, utils.RoleDoctor)))
+	app.Server.HandleFunc(utils.MakeURL("POST", "/queue/{doctor}/skip"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.Skip, utils.RoleDoctor)))
+	app.Server.HandleFunc(utils.MakeURL("POST", "/queue/{doctor}/recall"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.Recall, utils.RoleDoctor)))
+	app.Server.HandleFunc(utils.MakeURL("GET", "/queue/{doctor}"), middleware.JwtAuthMiddleware(controller.GetQueue))
+	app.Server.HandleFunc(utils.MakeURL("GET", "/queue/{doctor}/stream"), middleware.JwtAuthMiddleware(controller.StreamQueue))
+
+	return service
+}
@@ -0,0 +1,186 @@
+// Package repository provides the implementation of the repository layer for managing queue tokens in MongoDB.
+package repository
+
+import (
+	"AlShifa/Clinic/models"
+	interfaces "AlShifa/Queue/Interfaces"
+	utils "AlShifa/Utils"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Repo is the MongoDB implementation of the queue IRepository interface.
+type Repo struct {
+	DB *mongo.Database
+}
+
+// this ensures this repo implements all methods of repository interface
+var _ interfaces.IRepository = (*Repo)(nil)
+
+// NewRepository creates a new queue repository with the specified database.
+func NewRepository(db *mongo.Database) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+// CreateIndexes creates indexes queue module relies on, unique indexes make sure a token number is never issued twice
+// and an appointment never gets two tokens
+func (r *Repo) CreateIndexes(ctx context.Context) error {
+	_, err := r.DB.Collection("QueueDay").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "doctor", Value: 1},
+			{Key: "clinic", Value: 1},
+			{Key: "date", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.DB.Collection("QueueToken").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "doctor", Value: 1},
+				{Key: "clinic", Value: 1},
+				{Key: "date", Value: 1},
+				{Key: "number", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "appointment", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"appointment": bson.M{"$exists": true}}),
+		},
+	})
+	return err
+}
+
+// IssueToken gives token the next number of its queue day, the counter is incremented atomically so concurrent
+// check ins never get the same number
+func (r *Repo) IssueToken(ctx context.Context, token models.QueueToken) (models.QueueToken, error) {
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var day models.QueueDay
+	err := r.DB.Collection("QueueDay").FindOneAndUpdate(ctx,
+		bson.M{"doctor": token.Doctor, "clinic": token.Clinic, "date": token.Date},
+		bson.M{"$inc": bson.M{"lastToken": 1}, "$setOnInsert": bson.M{"_id": primitive.NewObjectID()}},
+		opts,
+	).Decode(&day)
+	if err != nil {
+		return models.QueueToken{}, err
+	}
+
+	token.Number = day.LastToken
+	if _, err := r.DB.Collection("QueueToken").InsertOne(ctx, token); err != nil {
+		return models.QueueToken{}, err
+	}
+	return token, nil
+}
+
+// GetTokens returns tokens matching filter in token number order
+func (r *Repo) GetTokens(ctx context.Context, filter bson.M) ([]models.QueueToken, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "number", Value: 1}})
+	cursor, err := r.DB.Collection("QueueToken").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var tokens []models.QueueToken
+	if err := cursor.All(ctx, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *Repo) GetToken(ctx context.Context, filter bson.M) (models.QueueToken, error) {
+	var token models.QueueToken
+	err := r.DB.Collection("QueueToken").FindOne(ctx, filter).Decode(&token)
+	return token, err
+}
+
+// AdvanceQueue calls the lowest numbered token of queue matching next and moves the token called before it to currentTo,
+// both in one transaction. when next is nil only the current token is moved. returns the tokens moved to currentTo and
+// the called token, mongo.ErrNoDocuments is returned without any change when no token matches next
+func (r *Repo) AdvanceQueue(ctx context.Context, queue bson.M, currentTo string, next bson.M) ([]models.QueueToken, *models.QueueToken, error) {
+	session, err := r.DB.Client().StartSession()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer session.EndSession(ctx)
+
+	var finished []models.QueueToken
+	var called *models.QueueToken
+	callback := func(sessCtx mongo.SessionContext) (any, error) {
+		finished, called = nil, nil
+
+		var excludeID any = primitive.NilObjectID
+		if next != nil {
+			filter := bson.M{}
+			for key, value := range queue {
+				filter[key] = value
+			}
+			for key, value := range next {
+				filter[key] = value
+			}
+
+			var token models.QueueToken
+			err := r.DB.Collection("QueueToken").FindOneAndUpdate(sessCtx, filter,
+				bson.M{"$set": bson.M{"status": utils.QueueTokenStatusCalled, "calledAt": time.Now().UTC()}},
+				options.FindOneAndUpdate().SetSort(bson.D{{Key: "number", Value: 1}}).SetReturnDocument(options.After),
+			).Decode(&token)
+			if err != nil {
+				return nil, err
+			}
+			called = &token
+			excludeID = token.ID
+		}
+
+		currentFilter := bson.M{"status": utils.QueueTokenStatusCalled, "_id": bson.M{"$ne": excludeID}}
+		for key, value := range queue {
+			currentFilter[key] = value
+		}
+
+		cursor, err := r.DB.Collection("QueueToken").Find(sessCtx, currentFilter)
+		if err != nil {
+			return nil, err
+		}
+		if err := cursor.All(sessCtx, &finished); err != nil {
+			return nil, err
+		}
+
+		_, err = r.DB.Collection("QueueToken").UpdateMany(sessCtx, currentFilter, bson.M{"$set": bson.M{"status": currentTo}})
+		return nil, err
+	}
+
+	if _, err := session.WithTransaction(ctx, callback); err != nil {
+		return nil, nil, err
+	}
+	return finished, called, nil
+}
+
+// UpdateTokenStatus moves tokens matching filter to status
+func (r *Repo) UpdateTokenStatus(ctx context.Context, filter bson.M, status string) error {
+	_, err := r.DB.Collection("QueueToken").UpdateMany(ctx, filter, bson.M{"$set": bson.M{"status": status}})
+	return err
+}
+
+func (r *Repo) GetDoctor(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error) {
+	var doctor models.Doctor
+	opts := options.FindOne().SetProjection(bson.M{"password": 0})
+	err := r.DB.Collection("Doctor").FindOne(ctx, bson.M{"_id": doctorID}, opts).Decode(&doctor)
+	return doctor, err
+}
+
+func (r *Repo) GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+	var clinic models.Clinic
+	err := r.DB.Collection("Clinic").FindOne(ctx, bson.M{"_id": clinicID}).Decode(&clinic)
+	return clinic, err
+}
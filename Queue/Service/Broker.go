@@ -0,0 +1,62 @@
+package service
+
+import (
+	queueStructs "AlShifa/Queue/Structs"
+	"sync"
+)
+
+// Broker fans queue snapshots out to the streams subscribed to a queue. it lives in process so a stream only sees
+// changes made through the same instance, subscribers always get the latest snapshot and slow ones skip older ones
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan queueStructs.QueueSnapshot]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[string]map[chan queueStructs.QueueSnapshot]struct{}),
+	}
+}
+
+// Subscribe returns a channel receiving snapshots published for key and a function to stop receiving them
+func (broker *Broker) Subscribe(key string) (<-chan queueStructs.QueueSnapshot, func()) {
+	ch := make(chan queueStructs.QueueSnapshot, 1)
+
+	broker.mu.Lock()
+	if broker.subscribers[key] == nil {
+		broker.subscribers[key] = make(map[chan queueStructs.QueueSnapshot]struct{})
+	}
+	broker.subscribers[key][ch] = struct{}{}
+	broker.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			broker.mu.Lock()
+			defer broker.mu.Unlock()
+			delete(broker.subscribers[key], ch)
+			if len(broker.subscribers[key]) == 0 {
+				delete(broker.subscribers, key)
+			}
+		})
+	}
+	return ch, unsubscribe
+}
+
+// Publish sends snapshot to every subscriber of key without blocking
+func (broker *Broker) Publish(key string, snapshot queueStructs.QueueSnapshot) {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	for ch := range broker.subscribers[key] {
+		//drop the snapshot subscriber hasnt read yet, it is stale now
+		select {
+		case <-ch:
+		default:
+		}
+		select {
+		case ch <- snapshot:
+		default:
+		}
+	}
+}
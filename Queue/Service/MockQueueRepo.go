@@ -0,0 +1,71 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	interfaces "AlShifa/Queue/Interfaces"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockQueueRepo struct {
+	IssueTokenFn        func(ctx context.Context, token models.QueueToken) (models.QueueToken, error)
+	GetTokensFn         func(ctx context.Context, filter bson.M) ([]models.QueueToken, error)
+	GetTokenFn          func(ctx context.Context, filter bson.M) (models.QueueToken, error)
+	AdvanceQueueFn      func(ctx context.Context, queue bson.M, currentTo string, next bson.M) ([]models.QueueToken, *models.QueueToken, error)
+	UpdateTokenStatusFn func(ctx context.Context, filter bson.M, status string) error
+	GetDoctorFn         func(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error)
+	GetClinicFn         func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error)
+}
+
+var _ interfaces.IRepository = (*MockQueueRepo)(nil)
+
+func (m *MockQueueRepo) IssueToken(ctx context.Context, token models.QueueToken) (models.QueueToken, error) {
+	if m.IssueTokenFn == nil {
+		panic("IssueTokenFn not implemented inside mock")
+	}
+	return m.IssueTokenFn(ctx, token)
+}
+
+func (m *MockQueueRepo) GetTokens(ctx context.Context, filter bson.M) ([]models.QueueToken, error) {
+	if m.GetTokensFn == nil {
+		panic("GetTokensFn not implemented inside mock")
+	}
+	return m.GetTokensFn(ctx, filter)
+}
+
+func (m *MockQueueRepo) GetToken(ctx context.Context, filter bson.M) (models.QueueToken, error) {
+	if m.GetTokenFn == nil {
+		panic("GetTokenFn not implemented inside mock")
+	}
+	return m.GetTokenFn(ctx, filter)
+}
+
+func (m *MockQueueRepo) AdvanceQueue(ctx context.Context, queue bson.M, currentTo string, next bson.M) ([]models.QueueToken, *models.QueueToken, error) {
+	if m.AdvanceQueueFn == nil {
+		panic("AdvanceQueueFn not implemented inside mock")
+	}
+	return m.AdvanceQueueFn(ctx, queue, currentTo, next)
+}
+
+func (m *MockQueueRepo) UpdateTokenStatus(ctx context.Context, filter bson.M, status string) error {
+	if m.UpdateTokenStatusFn == nil {
+		panic("UpdateTokenStatusFn not implemented inside mock")
+	}
+	return m.UpdateTokenStatusFn(ctx, filter, status)
+}
+
+func (m *MockQueueRepo) GetDoctor(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error) {
+	if m.GetDoctorFn == nil {
+		panic("GetDoctorFn not implemented inside mock")
+	}
+	return m.GetDoctorFn(ctx, doctorID)
+}
+
+func (m *MockQueueRepo) GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+	if m.GetClinicFn == nil {
+		panic("GetClinicFn not implemented inside mock")
+	}
+	return m.GetClinicFn(ctx, clinicID)
+}
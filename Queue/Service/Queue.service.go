@@ -0,0 +1,285 @@
+// Package service contains service layer implementation for queue module
+package service
+
+import (
+	appointmentInterfaces "AlShifa/Appointment/Interfaces"
+	"AlShifa/Clinic/models"
+	middleware "AlShifa/Middleware"
+	interfaces "AlShifa/Queue/Interfaces"
+	queueStructs "AlShifa/Queue/Structs"
+	scheduling "AlShifa/Scheduling"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type QueueService struct {
+	Repo         interfaces.IRepository
+	Appointments appointmentInterfaces.IService
+	Broker       *Broker
+}
+
+// NewQueueService creates queue service, appointments is used to keep checked in appointments in step with their tokens
+func NewQueueService(repo interfaces.IRepository, appointments appointmentInterfaces.IService) *QueueService {
+	return &QueueService{
+		Repo:         repo,
+		Appointments: appointments,
+		Broker:       NewBroker(),
+	}
+}
+
+// this ensures this service layer implements all methods of service layer interface
+var _ interfaces.IService = (*QueueService)(nil)
+
+// this ensures queue service can listen to appointment status changes
+var _ appointmentInterfaces.StatusListener = (*QueueService)(nil)
+
+// queueDay identifies the queue of a doctor at a clinic on a day
+type queueDay struct {
+	doctor primitive.ObjectID
+	clinic primitive.ObjectID
+	date   time.Time
+}
+
+func (queue queueDay) filter() bson.M {
+	return bson.M{"doctor": queue.doctor, "clinic": queue.clinic, "date": queue.date}
+}
+
+func (queue queueDay) key() string {
+	return queueKey(queue.doctor, queue.clinic)
+}
+
+func queueKey(doctorID primitive.ObjectID, clinicID primitive.ObjectID) string {
+	return doctorID.Hex() + ":" + clinicID.Hex()
+}
+
+// IssueToken issues a walk-in token in todays queue of doctor, doctor can issue tokens of own queue and owner of clinic of any doctor sitting there.
+// userID links the token to a patient record, it is nil for patients who have none
+func (service *QueueService) IssueToken(ctx context.Context, actorID primitive.ObjectID, actorRole string, doctorID primitive.ObjectID, clinicID primitive.ObjectID, userID *primitive.ObjectID, patientName string) (*models.QueueToken, *structs.IAppError) {
+	queue, clinic, appErr := service.todaysQueue(ctx, doctorID, clinicID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	allowed := (actorRole == utils.RoleDoctor && actorID == doctorID) || (actorRole != utils.RoleDoctor && middleware.ActsForClinic(ctx, actorID, clinic.Owner, clinic.ID))
+	if !allowed {
+		return nil, utils.ReturnAppError(errors.New("not allowed to issue token"), http.StatusForbidden, "Forbidden To Issue Token", "Only Doctor Or Staff Of Clinic Can Issue Tokens")
+	}
+
+	token, err := service.issueToken(ctx, queue, models.QueueToken{User: userID, PatientName: patientName})
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Failed To Issue Token", "Server Error")
+	}
+	return &token, nil
+}
+
+// CallNext finishes the patient in consultation and calls the next waiting token
+func (service *QueueService) CallNext(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID) (*queueStructs.QueueSnapshot, *structs.IAppError) {
+	return service.advance(ctx, doctorID, clinicID, utils.QueueTokenStatusServed, bson.M{"status": utils.QueueTokenStatusWaiting}, false)
+}
+
+// Skip sets the called token aside as not present and calls the next waiting token, skipped tokens can be recalled later
+func (service *QueueService) Skip(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID) (*queueStructs.QueueSnapshot, *structs.IAppError) {
+	return service.advance(ctx, doctorID, clinicID, utils.QueueTokenStatusSkipped, bson.M{"status": utils.QueueTokenStatusWaiting}, false)
+}
+
+// Recall calls a skipped token again finishing the patient in consultation, number 0 announces the current token again
+func (service *QueueService) Recall(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID, number int32) (*queueStructs.QueueSnapshot, *structs.IAppError) {
+	if number == 0 {
+		queue, _, appErr := service.todaysQueue(ctx, doctorID, clinicID)
+		if appErr != nil {
+			return nil, appErr
+		}
+		return service.publish(ctx, queue)
+	}
+	return service.advance(ctx, doctorID, clinicID, utils.QueueTokenStatusServed, bson.M{"status": utils.QueueTokenStatusSkipped, "number": number}, true)
+}
+
+func (service *QueueService) GetQueue(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID) (*queueStructs.QueueSnapshot, *structs.IAppError) {
+	queue, _, appErr := service.todaysQueue(ctx, doctorID, clinicID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	snapshot, err := service.snapshot(ctx, queue)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Queue", "Server Error")
+	}
+	return &snapshot, nil
+}
+
+// Subscribe streams snapshots of queue of doctor at clinic, call the returned function once done
+func (service *QueueService) Subscribe(doctorID primitive.ObjectID, clinicID primitive.ObjectID) (<-chan queueStructs.QueueSnapshot, func()) {
+	return service.Broker.Subscribe(queueKey(doctorID, clinicID))
+}
+
+// AppointmentStatusChanged issues a token when patient of an appointment checks in and drops the token when appointment is cancelled
+func (service *QueueService) AppointmentStatusChanged(ctx context.Context, appointment models.Appointment, transition models.StatusTransition) {
+	switch {
+	//moving back from consultation is a skip in the queue, token already exists
+	case transition.To == utils.AppointmentStatusCheckedIn && transition.From != utils.AppointmentStatusInConsultation:
+		queue, _, appErr := service.todaysQueue(ctx, appointment.Doctor, appointment.Clinic)
+		if appErr != nil {
+			log.Println("failed to issue token for checked in appointment:", appErr.ErrorObj)
+			return
+		}
+
+		appointmentID, userID := appointment.ID, appointment.User
+		if _, err := service.issueToken(ctx, queue, models.QueueToken{Appointment: &appointmentID, User: &userID}); err != nil && !mongo.IsDuplicateKeyError(err) {
+			log.Println("failed to issue token for checked in appointment:", err)
+		}
+
+	case transition.To == utils.AppointmentStatusCancelled || transition.To == utils.AppointmentStatusNoShow:
+		token, err := service.Repo.GetToken(ctx, bson.M{"appointment": appointment.ID})
+		if err != nil {
+			if err != mongo.ErrNoDocuments {
+				log.Println("failed to fetch token of cancelled appointment:", err)
+			}
+			return
+		}
+		if err := service.Repo.UpdateTokenStatus(ctx, bson.M{"_id": token.ID, "status": bson.M{"$ne": utils.QueueTokenStatusServed}}, utils.QueueTokenStatusCancelled); err != nil {
+			log.Println("failed to cancel token of cancelled appointment:", err)
+			return
+		}
+		_, _ = service.publish(ctx, queueDay{doctor: token.Doctor, clinic: token.Clinic, date: token.Date})
+	}
+}
+
+// advance calls the first token matching next and moves the token called before it to currentTo, appointments of both
+// tokens follow. when nothing matches next the current token is still finished unless next is required
+func (service *QueueService) advance(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID, currentTo string, next bson.M, nextRequired bool) (*queueStructs.QueueSnapshot, *structs.IAppError) {
+	queue, _, appErr := service.todaysQueue(ctx, doctorID, clinicID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	finished, called, err := service.Repo.AdvanceQueue(ctx, queue.filter(), currentTo, next)
+	if err == mongo.ErrNoDocuments {
+		if nextRequired {
+			return nil, utils.ReturnAppError(err, http.StatusNotFound, "Token Not Found", "No Skipped Token With This Number Today")
+		}
+		finished, called, err = service.Repo.AdvanceQueue(ctx, queue.filter(), currentTo, nil)
+	}
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Failed To Update Queue", "Server Error")
+	}
+
+	//served patients are done with consultation and skipped ones go back to waiting
+	finishedStatus := utils.AppointmentStatusCompleted
+	if currentTo == utils.QueueTokenStatusSkipped {
+		finishedStatus = utils.AppointmentStatusCheckedIn
+	}
+	for _, token := range finished {
+		service.moveAppointment(ctx, doctorID, token, finishedStatus)
+	}
+	if called != nil {
+		service.moveAppointment(ctx, doctorID, *called, utils.AppointmentStatusInConsultation)
+	}
+
+	return service.publish(ctx, queue)
+}
+
+// moveAppointment moves appointment of token to status on behalf of doctor, the queue has already moved so failures are only logged
+func (service *QueueService) moveAppointment(ctx context.Context, doctorID primitive.ObjectID, token models.QueueToken, status string) {
+	if token.Appointment == nil || service.Appointments == nil {
+		return
+	}
+	reason := fmt.Sprintf("Queue token %d", token.Number)
+	if _, appErr := service.Appointments.UpdateAppointmentStatus(ctx, doctorID, utils.RoleDoctor, *token.Appointment, status, reason); appErr != nil {
+		log.Println("failed to move appointment of queue token:", appErr.Message, appErr.Reason)
+	}
+}
+
+func (service *QueueService) issueToken(ctx context.Context, queue queueDay, token models.QueueToken) (models.QueueToken, error) {
+	token.ID = primitive.NewObjectID()
+	token.Doctor = queue.doctor
+	token.Clinic = queue.clinic
+	token.Date = queue.date
+	token.Status = utils.QueueTokenStatusWaiting
+	token.IssuedAt = time.Now().UTC()
+
+	token, err := service.Repo.IssueToken(ctx, token)
+	if err != nil {
+		return models.QueueToken{}, err
+	}
+
+	_, _ = service.publish(ctx, queue)
+	return token, nil
+}
+
+// publish sends current snapshot of queue to its subscribers and returns it
+func (service *QueueService) publish(ctx context.Context, queue queueDay) (*queueStructs.QueueSnapshot, *structs.IAppError) {
+	snapshot, err := service.snapshot(ctx, queue)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Queue", "Server Error")
+	}
+	service.Broker.Publish(queue.key(), snapshot)
+	return &snapshot, nil
+}
+
+func (service *QueueService) snapshot(ctx context.Context, queue queueDay) (queueStructs.QueueSnapshot, error) {
+	tokens, err := service.Repo.GetTokens(ctx, queue.filter())
+	if err != nil {
+		return queueStructs.QueueSnapshot{}, err
+	}
+
+	snapshot := queueStructs.QueueSnapshot{
+		Doctor:    queue.doctor,
+		Clinic:    queue.clinic,
+		Date:      queue.date.Format(utils.DateLayout),
+		Waiting:   []int32{},
+		Skipped:   []int32{},
+		UpdatedAt: time.Now().UTC(),
+	}
+	for _, token := range tokens {
+		switch token.Status {
+		case utils.QueueTokenStatusCalled:
+			number := token.Number
+			snapshot.Current = &number
+		case utils.QueueTokenStatusWaiting:
+			snapshot.Waiting = append(snapshot.Waiting, token.Number)
+		case utils.QueueTokenStatusSkipped:
+			snapshot.Skipped = append(snapshot.Skipped, token.Number)
+		}
+		snapshot.LastIssued = max(snapshot.LastIssued, token.Number)
+	}
+	return snapshot, nil
+}
+
+// todaysQueue makes sure doctor sits at clinic and returns queue of today in time zone of clinic
+func (service *QueueService) todaysQueue(ctx context.Context, doctorID primitive.ObjectID, clinicID primitive.ObjectID) (queueDay, models.Clinic, *structs.IAppError) {
+	doctor, err := service.Repo.GetDoctor(ctx, doctorID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return queueDay{}, models.Clinic{}, utils.ReturnAppError(err, http.StatusNotFound, "Doctor Not Found", "Invalid Doctor")
+		}
+		return queueDay{}, models.Clinic{}, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Doctor", "Server Error")
+	}
+
+	if _, found := scheduling.DoctorSession(doctor, clinicID.Hex()); !found {
+		return queueDay{}, models.Clinic{}, utils.ReturnAppError(errors.New("doctor not available at clinic"), http.StatusBadRequest, "Doctor Not Available At This Clinic", "Invalid Clinic")
+	}
+
+	clinic, err := service.Repo.GetClinic(ctx, clinicID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return queueDay{}, models.Clinic{}, utils.ReturnAppError(err, http.StatusNotFound, "Clinic Not Found", "Invalid Clinic")
+		}
+		return queueDay{}, models.Clinic{}, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Clinic", "Server Error")
+	}
+
+	return queueDay{
+		doctor: doctorID,
+		clinic: clinicID,
+		date:   scheduling.DayKey(time.Now(), scheduling.ClinicLocation(clinic)),
+	}, clinic, nil
+}
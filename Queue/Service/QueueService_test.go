@@ -0,0 +1,158 @@
+package service
+
+import (
+	appointmentInterfaces "AlShifa/Appointment/Interfaces"
+	"AlShifa/Clinic/models"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"net/http"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var dummyClinicID = primitive.NewObjectID()
+
+// recordingAppointments stands in for appointment service and records status moves made by the queue
+type recordingAppointments struct {
+	appointmentInterfaces.IService
+	moves map[primitive.ObjectID]string
+}
+
+func (r *recordingAppointments) UpdateAppointmentStatus(ctx context.Context, actorID primitive.ObjectID, actorRole string, appointmentID primitive.ObjectID, status string, reason string) (*models.Appointment, *structs.IAppError) {
+	r.moves[appointmentID] = status
+	return &models.Appointment{ID: appointmentID, Status: status}, nil
+}
+
+// ReturnDummyQueueRepo returns a repo where doctor sits at dummy clinic and queue holds tokens
+func ReturnDummyQueueRepo(tokens []models.QueueToken) *MockQueueRepo {
+	return &MockQueueRepo{
+		GetDoctorFn: func(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error) {
+			return models.Doctor{ID: doctorID, Clinics: []models.ClinicDetails{{Clinic: dummyClinicID}}}, nil
+		},
+		GetClinicFn: func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+			return models.Clinic{ID: clinicID}, nil
+		},
+		GetTokensFn: func(ctx context.Context, filter bson.M) ([]models.QueueToken, error) {
+			return tokens, nil
+		},
+	}
+}
+
+func TestCallNextMovesAppointments(t *testing.T) {
+	doctorID := primitive.NewObjectID()
+	servedAppointment := primitive.NewObjectID()
+	calledAppointment := primitive.NewObjectID()
+
+	mockRepo := ReturnDummyQueueRepo([]models.QueueToken{
+		{Number: 1, Status: utils.QueueTokenStatusServed},
+		{Number: 2, Status: utils.QueueTokenStatusCalled},
+		{Number: 3, Status: utils.QueueTokenStatusWaiting},
+		{Number: 4, Status: utils.QueueTokenStatusSkipped},
+	})
+	mockRepo.AdvanceQueueFn = func(ctx context.Context, queue bson.M, currentTo string, next bson.M) ([]models.QueueToken, *models.QueueToken, error) {
+		if currentTo != utils.QueueTokenStatusServed || next["status"] != utils.QueueTokenStatusWaiting {
+			t.Fatalf("unexpected advance to %s with %v", currentTo, next)
+		}
+		return []models.QueueToken{{Number: 1, Appointment: &servedAppointment}}, &models.QueueToken{Number: 2, Appointment: &calledAppointment}, nil
+	}
+
+	appointments := &recordingAppointments{moves: map[primitive.ObjectID]string{}}
+	service := NewQueueService(mockRepo, appointments)
+	updates, unsubscribe := service.Subscribe(doctorID, dummyClinicID)
+	defer unsubscribe()
+
+	snapshot, err := service.CallNext(context.Background(), doctorID, dummyClinicID)
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+
+	if appointments.moves[servedAppointment] != utils.AppointmentStatusCompleted || appointments.moves[calledAppointment] != utils.AppointmentStatusInConsultation {
+		t.Fatalf("appointments not moved with queue %v", appointments.moves)
+	}
+	if snapshot.Current == nil || *snapshot.Current != 2 || len(snapshot.Waiting) != 1 || len(snapshot.Skipped) != 1 || snapshot.LastIssued != 4 {
+		t.Fatalf("unexpected snapshot %+v", snapshot)
+	}
+
+	select {
+	case published := <-updates:
+		if published.Current == nil || *published.Current != 2 {
+			t.Fatalf("unexpected published snapshot %+v", published)
+		}
+	default:
+		t.Fatalf("expected snapshot to be published to subscribers")
+	}
+}
+
+func TestCallNextOnEmptyQueueFinishesCurrent(t *testing.T) {
+	calls := 0
+	mockRepo := ReturnDummyQueueRepo(nil)
+	mockRepo.AdvanceQueueFn = func(ctx context.Context, queue bson.M, currentTo string, next bson.M) ([]models.QueueToken, *models.QueueToken, error) {
+		calls++
+		if next != nil {
+			return nil, nil, mongo.ErrNoDocuments
+		}
+		return nil, nil, nil
+	}
+
+	service := NewQueueService(mockRepo, nil)
+	snapshot, err := service.CallNext(context.Background(), primitive.NewObjectID(), dummyClinicID)
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if calls != 2 || snapshot.Current != nil {
+		t.Fatalf("expected current token to be finished without calling another, calls %d snapshot %+v", calls, snapshot)
+	}
+}
+
+func TestRecallUnknownToken(t *testing.T) {
+	mockRepo := ReturnDummyQueueRepo(nil)
+	mockRepo.AdvanceQueueFn = func(ctx context.Context, queue bson.M, currentTo string, next bson.M) ([]models.QueueToken, *models.QueueToken, error) {
+		return nil, nil, mongo.ErrNoDocuments
+	}
+
+	service := NewQueueService(mockRepo, nil)
+	_, err := service.Recall(context.Background(), primitive.NewObjectID(), dummyClinicID, 7)
+	if err == nil || err.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected not found error but got %v", err)
+	}
+}
+
+func TestCheckInIssuesToken(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		From          string
+		To            string
+		ExpectedIssue bool
+	}{
+		{Name: "Confirmed appointment checking in gets a token", From: utils.AppointmentStatusConfirmed, To: utils.AppointmentStatusCheckedIn, ExpectedIssue: true},
+		{Name: "Skipped patient going back to waiting keeps the token", From: utils.AppointmentStatusInConsultation, To: utils.AppointmentStatusCheckedIn, ExpectedIssue: false},
+		{Name: "Confirming appointment doesnt issue a token", From: utils.AppointmentStatusRequested, To: utils.AppointmentStatusConfirmed, ExpectedIssue: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			appointment := models.Appointment{ID: primitive.NewObjectID(), User: primitive.NewObjectID(), Doctor: primitive.NewObjectID(), Clinic: dummyClinicID}
+
+			issued := false
+			mockRepo := ReturnDummyQueueRepo(nil)
+			mockRepo.IssueTokenFn = func(ctx context.Context, token models.QueueToken) (models.QueueToken, error) {
+				issued = true
+				if token.Appointment == nil || *token.Appointment != appointment.ID || token.Status != utils.QueueTokenStatusWaiting {
+					t.Fatalf("token not linked to appointment %+v", token)
+				}
+				token.Number = 1
+				return token, nil
+			}
+
+			service := NewQueueService(mockRepo, nil)
+			service.AppointmentStatusChanged(context.Background(), appointment, models.StatusTransition{From: tc.From, To: tc.To})
+			if issued != tc.ExpectedIssue {
+				t.Fatalf("expected token issued %v but got %v", tc.ExpectedIssue, issued)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+// Package structs contains data structures used across the queue module.
+package structs
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// QueueSnapshot is the public state of a doctor's queue for a day, it is what waiting room screens and the patient app show.
+// patient details are left out on purpose as the stream is shown on public screens
+type QueueSnapshot struct {
+	Doctor     primitive.ObjectID `json:"doctor"`
+	Clinic     primitive.ObjectID `json:"clinic"`
+	Date       string             `json:"date"`
+	Current    *int32             `json:"current"`
+	Waiting    []int32            `json:"waiting"`
+	Skipped    []int32            `json:"skipped"`
+	LastIssued int32              `json:"lastIssued"`
+	UpdatedAt  time.Time          `json:"updatedAt"`
+}
@@ -0,0 +1,23 @@
+// Package interfaces contains interfaces for Reminder module
+package interfaces
+
+import (
+	"AlShifa/Clinic/models"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IRepository defines the methods for loose coupling between the reminder repository and its implementation.
+type IRepository interface {
+	ScheduleJob(ctx context.Context, job models.Job) error
+	ClaimJob(ctx context.Context, kind string, worker string, now time.Time, lease time.Duration) (models.Job, error)
+	FinishJob(ctx context.Context, jobID primitive.ObjectID, worker string, status string, lastError string) error
+	RetryJob(ctx context.Context, jobID primitive.ObjectID, worker string, runAt time.Time, lastError string) error
+	GetAppointments(ctx context.Context, filter bson.M) ([]models.Appointment, error)
+	GetAppointment(ctx context.Context, appointmentID primitive.ObjectID) (models.Appointment, error)
+	GetDoctor(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error)
+	GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error)
+}
@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+)
+
+// IService interface contains functions that reminder service layer must implement( to be used by the worker)
+type IService interface {
+	PlanReminders(ctx context.Context, now time.Time) error
+	RunDueReminders(ctx context.Context, now time.Time) error
+	RunReminderWorker(ctx context.Context, period time.Duration)
+}
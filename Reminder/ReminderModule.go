@@ -0,0 +1,48 @@
+// Package reminder sends patients reminders of their upcoming appointments.
+package reminder
+
+import (
+	internals "AlShifa/Internals"
+	notifications "AlShifa/Notifications"
+	repository "AlShifa/Reminder/Repository"
+	service "AlShifa/Reminder/Service"
+	utils "AlShifa/Utils"
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InitialiseReminderModule starts the reminder worker, REMINDER_OFFSETS sets how long before appointments reminders go out
+func InitialiseReminderModule(app *internals.App) {
+	repository := repository.NewRepository(app.DB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout*5)
+	defer cancel()
+	if err := repository.CreateIndexes(ctx); err != nil {
+		log.Fatal("Failed to create reminder indexes", err)
+	}
+
+	offsetList := os.Getenv("REMINDER_OFFSETS")
+	if offsetList == "" {
+		offsetList = utils.DefaultReminders
+	}
+	offsets, err := service.ParseOffsets(offsetList)
+	if err != nil {
+		log.Fatal("Invalid REMINDER_OFFSETS", err)
+	}
+
+	notifier, err := notifications.NewNotifierFromEnv(notifications.NewMongoContactBook(app.DB))
+	if err != nil {
+		log.Fatal("Failed to configure notifier", err)
+	}
+
+	//worker name only has to be unique among running instances
+	hostname, _ := os.Hostname()
+	worker := fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), primitive.NewObjectID().Hex())
+
+	service := service.NewReminderService(repository, notifier, offsets, worker)
+	go service.RunReminderWorker(context.Background(), utils.ReminderSweepPeriod)
+}
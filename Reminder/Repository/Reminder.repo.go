@@ -0,0 +1,153 @@
+// Package repository provides the implementation of the repository layer for reminder jobs in MongoDB.
+package repository
+
+import (
+	"AlShifa/Clinic/models"
+	interfaces "AlShifa/Reminder/Interfaces"
+	utils "AlShifa/Utils"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Repo is the MongoDB implementation of the reminder IRepository interface.
+type Repo struct {
+	DB *mongo.Database
+}
+
+// this ensures this repo implements all methods of repository interface
+var _ interfaces.IRepository = (*Repo)(nil)
+
+// NewRepository creates a new reminder repository with the specified database.
+func NewRepository(db *mongo.Database) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+// CreateIndexes creates indexes of Job collection, unique key is what keeps a reminder from being scheduled twice and
+// finished jobs are removed after retention period
+func (r *Repo) CreateIndexes(ctx context.Context) error {
+	_, err := r.DB.Collection("Job").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "key", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "kind", Value: 1}, {Key: "status", Value: 1}, {Key: "runAt", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "finishedAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(utils.JobRetention / time.Second)),
+		},
+	})
+	return err
+}
+
+// ScheduleJob inserts job unless a job with same key exists, scheduling is idempotent
+func (r *Repo) ScheduleJob(ctx context.Context, job models.Job) error {
+	_, err := r.DB.Collection("Job").UpdateOne(ctx,
+		bson.M{"key": job.Key},
+		bson.M{"$setOnInsert": job},
+		options.Update().SetUpsert(true),
+	)
+	//two instances upserting the same key at once, the other one inserted it
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+// ClaimJob atomically takes the earliest due job of kind for worker. a running job whose lease ran out belongs to a
+// worker which died and is claimed again. returns mongo.ErrNoDocuments when nothing is due
+func (r *Repo) ClaimJob(ctx context.Context, kind string, worker string, now time.Time, lease time.Duration) (models.Job, error) {
+	filter := bson.M{
+		"kind": kind,
+		"$or": bson.A{
+			bson.M{"status": utils.JobStatusPending, "runAt": bson.M{"$lte": now}},
+			bson.M{"status": utils.JobStatusRunning, "lockedUntil": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":      utils.JobStatusRunning,
+			"lockedBy":    worker,
+			"lockedUntil": now.Add(lease),
+		},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().SetSort(bson.M{"runAt": 1}).SetReturnDocument(options.After)
+
+	var job models.Job
+	err := r.DB.Collection("Job").FindOneAndUpdate(ctx, filter, update, opts).Decode(&job)
+	return job, err
+}
+
+// FinishJob closes a job still owned by worker, returns mongo.ErrNoDocuments if the lease was lost to another worker
+func (r *Repo) FinishJob(ctx context.Context, jobID primitive.ObjectID, worker string, status string, lastError string) error {
+	now := time.Now().UTC()
+	set := bson.M{"status": status, "finishedAt": now}
+	if lastError != "" {
+		set["lastError"] = lastError
+	}
+	return r.updateOwnedJob(ctx, jobID, worker, bson.M{
+		"$set":   set,
+		"$unset": bson.M{"lockedBy": "", "lockedUntil": ""},
+	})
+}
+
+// RetryJob hands a job owned by worker back to the queue to run again at runAt
+func (r *Repo) RetryJob(ctx context.Context, jobID primitive.ObjectID, worker string, runAt time.Time, lastError string) error {
+	return r.updateOwnedJob(ctx, jobID, worker, bson.M{
+		"$set":   bson.M{"status": utils.JobStatusPending, "runAt": runAt, "lastError": lastError},
+		"$unset": bson.M{"lockedBy": "", "lockedUntil": ""},
+	})
+}
+
+func (r *Repo) updateOwnedJob(ctx context.Context, jobID primitive.ObjectID, worker string, update bson.M) error {
+	result, err := r.DB.Collection("Job").UpdateOne(ctx, bson.M{"_id": jobID, "status": utils.JobStatusRunning, "lockedBy": worker}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *Repo) GetAppointments(ctx context.Context, filter bson.M) ([]models.Appointment, error) {
+	cursor, err := r.DB.Collection("Appointment").Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var appointments []models.Appointment
+	if err := cursor.All(ctx, &appointments); err != nil {
+		return nil, err
+	}
+	return appointments, nil
+}
+
+func (r *Repo) GetAppointment(ctx context.Context, appointmentID primitive.ObjectID) (models.Appointment, error) {
+	var appointment models.Appointment
+	err := r.DB.Collection("Appointment").FindOne(ctx, bson.M{"_id": appointmentID}).Decode(&appointment)
+	return appointment, err
+}
+
+func (r *Repo) GetDoctor(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error) {
+	var doctor models.Doctor
+	opts := options.FindOne().SetProjection(bson.M{"name": 1, "slotDuration": 1})
+	err := r.DB.Collection("Doctor").FindOne(ctx, bson.M{"_id": doctorID}, opts).Decode(&doctor)
+	return doctor, err
+}
+
+func (r *Repo) GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+	var clinic models.Clinic
+	err := r.DB.Collection("Clinic").FindOne(ctx, bson.M{"_id": clinicID}).Decode(&clinic)
+	return clinic, err
+}
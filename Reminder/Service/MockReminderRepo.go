@@ -0,0 +1,80 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	interfaces "AlShifa/Reminder/Interfaces"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockReminderRepo struct {
+	ScheduleJobFn     func(ctx context.Context, job models.Job) error
+	ClaimJobFn        func(ctx context.Context, kind string, worker string, now time.Time, lease time.Duration) (models.Job, error)
+	FinishJobFn       func(ctx context.Context, jobID primitive.ObjectID, worker string, status string, lastError string) error
+	RetryJobFn        func(ctx context.Context, jobID primitive.ObjectID, worker string, runAt time.Time, lastError string) error
+	GetAppointmentsFn func(ctx context.Context, filter bson.M) ([]models.Appointment, error)
+	GetAppointmentFn  func(ctx context.Context, appointmentID primitive.ObjectID) (models.Appointment, error)
+	GetDoctorFn       func(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error)
+	GetClinicFn       func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error)
+}
+
+var _ interfaces.IRepository = (*MockReminderRepo)(nil)
+
+func (m *MockReminderRepo) ScheduleJob(ctx context.Context, job models.Job) error {
+	if m.ScheduleJobFn == nil {
+		panic("ScheduleJobFn not implemented inside mock")
+	}
+	return m.ScheduleJobFn(ctx, job)
+}
+
+func (m *MockReminderRepo) ClaimJob(ctx context.Context, kind string, worker string, now time.Time, lease time.Duration) (models.Job, error) {
+	if m.ClaimJobFn == nil {
+		panic("ClaimJobFn not implemented inside mock")
+	}
+	return m.ClaimJobFn(ctx, kind, worker, now, lease)
+}
+
+func (m *MockReminderRepo) FinishJob(ctx context.Context, jobID primitive.ObjectID, worker string, status string, lastError string) error {
+	if m.FinishJobFn == nil {
+		panic("FinishJobFn not implemented inside mock")
+	}
+	return m.FinishJobFn(ctx, jobID, worker, status, lastError)
+}
+
+func (m *MockReminderRepo) RetryJob(ctx context.Context, jobID primitive.ObjectID, worker string, runAt time.Time, lastError string) error {
+	if m.RetryJobFn == nil {
+		panic("RetryJobFn not implemented inside mock")
+	}
+	return m.RetryJobFn(ctx, jobID, worker, runAt, lastError)
+}
+
+func (m *MockReminderRepo) GetAppointments(ctx context.Context, filter bson.M) ([]models.Appointment, error) {
+	if m.GetAppointmentsFn == nil {
+		panic("GetAppointmentsFn not implemented inside mock")
+	}
+	return m.GetAppointmentsFn(ctx, filter)
+}
+
+func (m *MockReminderRepo) GetAppointment(ctx context.Context, appointmentID primitive.ObjectID) (models.Appointment, error) {
+	if m.GetAppointmentFn == nil {
+		panic("GetAppointmentFn not implemented inside mock")
+	}
+	return m.GetAppointmentFn(ctx, appointmentID)
+}
+
+func (m *MockReminderRepo) GetDoctor(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error) {
+	if m.GetDoctorFn == nil {
+		panic("GetDoctorFn not implemented inside mock")
+	}
+	return m.GetDoctorFn(ctx, doctorID)
+}
+
+func (m *MockReminderRepo) GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+	if m.GetClinicFn == nil {
+		panic("GetClinicFn not implemented inside mock")
+	}
+	return m.GetClinicFn(ctx, clinicID)
+}
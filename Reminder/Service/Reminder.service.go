@@ -0,0 +1,226 @@
+// Package service contains service layer implementation for reminder module
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	notifications "AlShifa/Notifications"
+	interfaces "AlShifa/Reminder/Interfaces"
+	scheduling "AlShifa/Scheduling"
+	utils "AlShifa/Utils"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ReminderService schedules reminders of upcoming appointments as jobs and sends them when due. every instance of the
+// server runs it, jobs are claimed atomically so each reminder is sent by one of them
+type ReminderService struct {
+	Repo     interfaces.IRepository
+	Notifier notifications.Notifier
+	Offsets  []time.Duration // how long before an appointment reminders go out, largest first
+	Worker   string          // identifies this instance as owner of the jobs it claims
+}
+
+// NewReminderService creates reminder service which reminds patients offsets before their appointments
+func NewReminderService(repo interfaces.IRepository, notifier notifications.Notifier, offsets []time.Duration, worker string) *ReminderService {
+	sorted := append([]time.Duration(nil), offsets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] > sorted[j] })
+
+	return &ReminderService{
+		Repo:     repo,
+		Notifier: notifier,
+		Offsets:  sorted,
+		Worker:   worker,
+	}
+}
+
+// this ensures this service layer implements all methods of service layer interface
+var _ interfaces.IService = (*ReminderService)(nil)
+
+// ParseOffsets reads a comma separated list of durations such as "24h,2h"
+func ParseOffsets(list string) ([]time.Duration, error) {
+	var offsets []time.Duration
+	for _, part := range strings.Split(list, ",") {
+		offset, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		if offset <= 0 {
+			return nil, fmt.Errorf("reminder offset %s must be positive", offset)
+		}
+		offsets = append(offsets, offset)
+	}
+	return offsets, nil
+}
+
+// PlanReminders schedules a job for every reminder of active appointments starting within the largest offset.
+// planning runs again on every tick so appointments booked or moved since the last tick get their reminders
+func (service *ReminderService) PlanReminders(ctx context.Context, now time.Time) error {
+	if len(service.Offsets) == 0 {
+		return nil
+	}
+
+	appointments, err := service.Repo.GetAppointments(ctx, bson.M{
+		"status":          bson.M{"$in": bson.A{utils.AppointmentStatusRequested, utils.AppointmentStatusConfirmed}},
+		"appointmentDate": bson.M{"$gt": now.UTC(), "$lte": now.Add(service.Offsets[0]).UTC()},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, appointment := range appointments {
+		for _, offset := range service.Offsets {
+			runAt := appointment.AppointmentDate.Add(-offset)
+			//booked after this reminder was due, a 24h reminder for an appointment booked 3 hours ahead is noise
+			if runAt.Before(appointment.RegistrationDate) || service.superseded(offset, appointment.AppointmentDate, now) {
+				continue
+			}
+
+			err := service.Repo.ScheduleJob(ctx, models.Job{
+				ID:        primitive.NewObjectID(),
+				Key:       fmt.Sprintf("%s:%s:%d", utils.JobKindReminder, appointment.ID.Hex(), int64(offset/time.Minute)),
+				Kind:      utils.JobKindReminder,
+				Ref:       appointment.ID,
+				RunAt:     runAt.UTC(),
+				Status:    utils.JobStatusPending,
+				CreatedAt: now.UTC(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RunDueReminders sends every reminder which is due, failures are retried with backoff until attempts run out
+func (service *ReminderService) RunDueReminders(ctx context.Context, now time.Time) error {
+	for {
+		job, err := service.Repo.ClaimJob(ctx, utils.JobKindReminder, service.Worker, now.UTC(), utils.JobLease)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return nil
+			}
+			return err
+		}
+
+		status, sendErr := service.sendReminder(ctx, job, now)
+		switch {
+		case sendErr == nil:
+			err = service.Repo.FinishJob(ctx, job.ID, service.Worker, status, "")
+		case job.Attempts >= utils.JobMaxAttempts:
+			err = service.Repo.FinishJob(ctx, job.ID, service.Worker, utils.JobStatusFailed, sendErr.Error())
+		default:
+			backoff := utils.JobRetryBackoff * time.Duration(1<<(job.Attempts-1))
+			err = service.Repo.RetryJob(ctx, job.ID, service.Worker, now.Add(backoff).UTC(), sendErr.Error())
+		}
+		//lease ran out while sending and another worker took the job over
+		if err != nil && err != mongo.ErrNoDocuments {
+			return err
+		}
+	}
+}
+
+// RunReminderWorker plans and sends reminders every period until ctx is done
+func (service *ReminderService) RunReminderWorker(ctx context.Context, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			tickCtx, cancel := context.WithTimeout(ctx, utils.BulkRequestTimeout)
+			now := time.Now()
+			if err := service.PlanReminders(tickCtx, now); err != nil {
+				log.Println("failed to plan reminders:", err)
+			}
+			if err := service.RunDueReminders(tickCtx, now); err != nil {
+				log.Println("failed to send reminders:", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// sendReminder reminds patient of appointment of job, it returns the status the job finishes with. reminders of
+// appointments which are no longer active, have started or are about to get a closer reminder are skipped
+func (service *ReminderService) sendReminder(ctx context.Context, job models.Job, now time.Time) (string, error) {
+	appointment, err := service.Repo.GetAppointment(ctx, job.Ref)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return utils.JobStatusSkipped, nil
+		}
+		return "", err
+	}
+
+	active := appointment.Status == utils.AppointmentStatusRequested || appointment.Status == utils.AppointmentStatusConfirmed
+	offset := appointment.AppointmentDate.Sub(job.RunAt)
+	if !active || !now.Before(appointment.AppointmentDate) || service.superseded(offset, appointment.AppointmentDate, now) {
+		return utils.JobStatusSkipped, nil
+	}
+
+	doctor, err := service.Repo.GetDoctor(ctx, appointment.Doctor)
+	if err != nil {
+		return "", err
+	}
+	clinic, err := service.Repo.GetClinic(ctx, appointment.Clinic)
+	if err != nil {
+		return "", err
+	}
+
+	place := clinic.Name
+	if clinic.Address != "" {
+		place += ", " + clinic.Address
+	}
+	message := notifications.Message{
+		Recipient: appointment.User,
+		Subject:   "Appointment Reminder",
+		Body: fmt.Sprintf("Your appointment with Dr. %s at %s is on %s (in %s).",
+			doctor.Name, place, scheduling.ClinicTime(clinic, appointment.AppointmentDate), humanDuration(appointment.AppointmentDate.Sub(now))),
+	}
+	if err := service.Notifier.Notify(ctx, message); err != nil {
+		//patient cannot be reached on this channel, retrying will not change that
+		if errors.Is(err, notifications.ErrNoContact) {
+			return utils.JobStatusSkipped, nil
+		}
+		return "", err
+	}
+	return utils.JobStatusDone, nil
+}
+
+// superseded reports whether a smaller offset than offset is already due for an appointment starting at start, after
+// downtime only the closest reminder is sent instead of all of them at once
+func (service *ReminderService) superseded(offset time.Duration, start time.Time, now time.Time) bool {
+	for _, other := range service.Offsets {
+		if other < offset && !now.Before(start.Add(-other)) {
+			return true
+		}
+	}
+	return false
+}
+
+// humanDuration rounds d to hours, or minutes below an hour
+func humanDuration(d time.Duration) string {
+	if d >= time.Hour {
+		hours := int((d + 30*time.Minute) / time.Hour)
+		if hours == 1 {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", hours)
+	}
+	minutes := int((d + 30*time.Second) / time.Minute)
+	if minutes == 1 {
+		return "1 minute"
+	}
+	return fmt.Sprintf("%d minutes", minutes)
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	notifications "AlShifa/Notifications"
+	utils "AlShifa/Utils"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var reminderOffsets = []time.Duration{2 * time.Hour, 24 * time.Hour}
+
+// recordingNotifier records messages and fails with err when it is set
+type recordingNotifier struct {
+	messages []notifications.Message
+	err      error
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, message notifications.Message) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+func TestPlanReminders(t *testing.T) {
+	now := time.Date(2030, 3, 4, 6, 0, 0, 0, time.UTC)
+	bookedEarly := models.Appointment{ID: primitive.NewObjectID(), AppointmentDate: now.Add(20 * time.Hour), RegistrationDate: now.Add(-48 * time.Hour)}
+	bookedLate := models.Appointment{ID: primitive.NewObjectID(), AppointmentDate: now.Add(3 * time.Hour), RegistrationDate: now.Add(-time.Hour)}
+	closeBy := models.Appointment{ID: primitive.NewObjectID(), AppointmentDate: now.Add(90 * time.Minute), RegistrationDate: now.Add(-48 * time.Hour)}
+
+	scheduled := map[string]time.Time{}
+	mockRepo := &MockReminderRepo{
+		GetAppointmentsFn: func(ctx context.Context, filter bson.M) ([]models.Appointment, error) {
+			return []models.Appointment{bookedEarly, bookedLate, closeBy}, nil
+		},
+		ScheduleJobFn: func(ctx context.Context, job models.Job) error {
+			scheduled[job.Key] = job.RunAt
+			return nil
+		},
+	}
+	reminderService := NewReminderService(mockRepo, &recordingNotifier{}, reminderOffsets, "test")
+
+	if err := reminderService.PlanReminders(context.Background(), now); err != nil {
+		t.Fatalf("expected planning to succeed got %v", err)
+	}
+
+	expected := map[string]time.Time{
+		//24h reminder is already late but nothing closer is due yet
+		utils.JobKindReminder + ":" + bookedEarly.ID.Hex() + ":1440": bookedEarly.AppointmentDate.Add(-24 * time.Hour),
+		utils.JobKindReminder + ":" + bookedEarly.ID.Hex() + ":120":  bookedEarly.AppointmentDate.Add(-2 * time.Hour),
+		utils.JobKindReminder + ":" + bookedLate.ID.Hex() + ":120":   bookedLate.AppointmentDate.Add(-2 * time.Hour),
+		utils.JobKindReminder + ":" + closeBy.ID.Hex() + ":120":      closeBy.AppointmentDate.Add(-2 * time.Hour),
+	}
+	if len(scheduled) != len(expected) {
+		t.Fatalf("expected %d jobs got %v", len(expected), scheduled)
+	}
+	for key, runAt := range expected {
+		if got, ok := scheduled[key]; !ok || !got.Equal(runAt) {
+			t.Errorf("expected job %s at %v got %v", key, runAt, got)
+		}
+	}
+}
+
+func TestRunDueReminders(t *testing.T) {
+	now := time.Date(2030, 3, 4, 6, 0, 0, 0, time.UTC)
+	start := now.Add(2 * time.Hour)
+
+	active := models.Appointment{ID: primitive.NewObjectID(), User: primitive.NewObjectID(), AppointmentDate: start, Status: utils.AppointmentStatusConfirmed}
+	cancelled := models.Appointment{ID: primitive.NewObjectID(), AppointmentDate: start, Status: utils.AppointmentStatusCancelled}
+	appointments := map[primitive.ObjectID]models.Appointment{active.ID: active, cancelled.ID: cancelled}
+
+	tests := []struct {
+		name       string
+		job        models.Job
+		notifyErr  error
+		wantStatus string
+		wantRetry  time.Duration
+		wantSent   int
+	}{
+		{"sends due reminder", models.Job{Ref: active.ID, Attempts: 1}, nil, utils.JobStatusDone, 0, 1},
+		{"skips cancelled appointment", models.Job{Ref: cancelled.ID, Attempts: 1}, nil, utils.JobStatusSkipped, 0, 0},
+		{"skips deleted appointment", models.Job{Ref: primitive.NewObjectID(), Attempts: 1}, nil, utils.JobStatusSkipped, 0, 0},
+		{"skips unreachable patient", models.Job{Ref: active.ID, Attempts: 1}, notifications.ErrNoContact, utils.JobStatusSkipped, 0, 0},
+		{"retries with backoff", models.Job{Ref: active.ID, Attempts: 2}, errors.New("gateway down"), "", 2 * utils.JobRetryBackoff, 0},
+		{"gives up after max attempts", models.Job{Ref: active.ID, Attempts: utils.JobMaxAttempts}, errors.New("gateway down"), utils.JobStatusFailed, 0, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.job.ID = primitive.NewObjectID()
+			tc.job.RunAt = start.Add(-2 * time.Hour)
+
+			claimed := false
+			var finished string
+			var retryAt time.Time
+			mockRepo := &MockReminderRepo{
+				ClaimJobFn: func(ctx context.Context, kind string, worker string, now time.Time, lease time.Duration) (models.Job, error) {
+					if claimed {
+						return models.Job{}, mongo.ErrNoDocuments
+					}
+					claimed = true
+					return tc.job, nil
+				},
+				GetAppointmentFn: func(ctx context.Context, appointmentID primitive.ObjectID) (models.Appointment, error) {
+					appointment, ok := appointments[appointmentID]
+					if !ok {
+						return models.Appointment{}, mongo.ErrNoDocuments
+					}
+					return appointment, nil
+				},
+				GetDoctorFn: func(ctx context.Context, doctorID primitive.ObjectID) (models.Doctor, error) {
+					return models.Doctor{Name: "Ayesha Khan"}, nil
+				},
+				GetClinicFn: func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+					return models.Clinic{Name: "AlShifa Clinic"}, nil
+				},
+				FinishJobFn: func(ctx context.Context, jobID primitive.ObjectID, worker string, status string, lastError string) error {
+					finished = status
+					return nil
+				},
+				RetryJobFn: func(ctx context.Context, jobID primitive.ObjectID, worker string, runAt time.Time, lastError string) error {
+					retryAt = runAt
+					return nil
+				},
+			}
+			notifier := &recordingNotifier{err: tc.notifyErr}
+			reminderService := NewReminderService(mockRepo, notifier, reminderOffsets, "test")
+
+			if err := reminderService.RunDueReminders(context.Background(), now); err != nil {
+				t.Fatalf("expected run to succeed got %v", err)
+			}
+			if finished != tc.wantStatus {
+				t.Errorf("expected job to finish as %q got %q", tc.wantStatus, finished)
+			}
+			if tc.wantRetry != 0 && !retryAt.Equal(now.Add(tc.wantRetry)) {
+				t.Errorf("expected retry at %v got %v", now.Add(tc.wantRetry), retryAt)
+			}
+			if len(notifier.messages) != tc.wantSent {
+				t.Fatalf("expected %d messages got %d", tc.wantSent, len(notifier.messages))
+			}
+			if tc.wantSent > 0 {
+				message := notifier.messages[0]
+				if message.Recipient != active.User || !strings.Contains(message.Body, "Dr. Ayesha Khan") || !strings.Contains(message.Body, "in 2 hours") {
+					t.Errorf("unexpected reminder %+v", message)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Package controller provides HTTP handlers for reviews of doctors and clinics.
+package controller
+
+import (
+	middleware "AlShifa/Middleware"
+	interfaces "AlShifa/Review/Interfaces"
+	reviewStructs "AlShifa/Review/Structs"
+	validators "AlShifa/Review/Validators"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Controller struct {
+	Service interfaces.IService
+}
+
+func NewController(service interfaces.IService) *Controller {
+	return &Controller{
+		Service: service,
+	}
+}
+
+func (controller *Controller) CreateReview(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	userID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	appointmentID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Appointment ID", "Invalid ID"))
+		return
+	}
+
+	var request reviewStructs.ReviewRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Unable To Add Review", "Invalid Json"))
+		return
+	}
+
+	validationErrors := validators.ValidateReview(&request)
+	if validationErrors != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(validationErrors, 400, "Unable To Add Review", "Invalid Details"))
+		return
+	}
+
+	review, appErr := controller.Service.CreateReview(ctx, userID, appointmentID, request)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusCreated, utils.ReturnAppSuccess(201, "Review Added Successfully", review))
+}
+
+func (controller *Controller) GetDoctorReviews(res http.ResponseWriter, req *http.Request) {
+	controller.getReviews(res, req, "Invalid Doctor ID", controller.Service.GetDoctorReviews)
+}
+
+func (controller *Controller) GetClinicReviews(res http.ResponseWriter, req *http.Request) {
+	controller.getReviews(res, req, "Invalid Clinic ID", controller.Service.GetClinicReviews)
+}
+
+// getReviews lists reviews of the doctor or clinic in path, admins also see hidden reviews
+func (controller *Controller) getReviews(res http.ResponseWriter, req *http.Request, invalidID string, get func(ctx context.Context, id primitive.ObjectID, includeHidden bool, cursor string, limit int64) (*reviewStructs.ReviewPage, *structs.IAppError)) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	_, role, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, invalidID, "Invalid ID"))
+		return
+	}
+
+	params := req.URL.Query()
+	limit := int64(utils.DefaultReviewPageSize)
+	if value := params.Get("limit"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < 1 || parsed > utils.MaxReviewPageSize {
+			_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(fmt.Errorf("limit must be between 1 and %d", utils.MaxReviewPageSize), 400, "Unable To Fetch Reviews", "Invalid Limit"))
+			return
+		}
+		limit = parsed
+	}
+
+	page, appErr := get(ctx, id, role == utils.RoleAdmin, params.Get("cursor"), limit)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Reviews Fetched Successfully", page))
+}
+
+func (controller *Controller) ReplyToReview(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	ownerID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	reviewID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Review ID", "Invalid ID"))
+		return
+	}
+
+	var request reviewStructs.ReplyRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Unable To Reply", "Invalid Json"))
+		return
+	}
+
+	validationErrors := validators.ValidateReply(&request)
+	if validationErrors != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(validationErrors, 400, "Unable To Reply", "Invalid Details"))
+		return
+	}
+
+	review, appErr := controller.Service.ReplyToReview(ctx, ownerID, reviewID, request)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Replied Successfully", review))
+}
+
+func (controller *Controller) SetReviewVisibility(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	adminID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	reviewID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Review ID", "Invalid ID"))
+		return
+	}
+
+	var request reviewStructs.VisibilityRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Unable To Moderate Review", "Invalid Json"))
+		return
+	}
+
+	validationErrors := validators.ValidateVisibility(&request)
+	if validationErrors != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(validationErrors, 400, "Unable To Moderate Review", "Invalid Details"))
+		return
+	}
+
+	review, appErr := controller.Service.SetReviewVisibility(ctx, adminID, reviewID, request)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Review Moderated Successfully", review))
+}
@@ -0,0 +1,6 @@
+package interfaces
+
+import "errors"
+
+// ErrReviewExists is returned when the appointment is already reviewed
+var ErrReviewExists = errors.New("appointment already reviewed")
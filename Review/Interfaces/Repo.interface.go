@@ -0,0 +1,24 @@
+// Package interfaces contains interfaces for Review module
+package interfaces
+
+import (
+	"AlShifa/Clinic/models"
+	reviewStructs "AlShifa/Review/Structs"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IRepository defines the methods for loose coupling between the review repository and its implementation.
+type IRepository interface {
+	CreateIndexes(ctx context.Context) error
+	GetAppointment(ctx context.Context, appointmentID primitive.ObjectID) (models.Appointment, error)
+	GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error)
+	InsertReview(ctx context.Context, review models.Review) error
+	GetReview(ctx context.Context, reviewID primitive.ObjectID) (models.Review, error)
+	GetReviews(ctx context.Context, filter bson.M, after *reviewStructs.ReviewCursor, limit int64) ([]reviewStructs.ReviewItem, error)
+	SetReply(ctx context.Context, reviewID primitive.ObjectID, reply models.ReviewReply) error
+	SetHidden(ctx context.Context, review models.Review, hidden bool, adminID primitive.ObjectID, reason string, at time.Time) error
+}
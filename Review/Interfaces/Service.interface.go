@@ -0,0 +1,19 @@
+package interfaces
+
+import (
+	"AlShifa/Clinic/models"
+	reviewStructs "AlShifa/Review/Structs"
+	structs "AlShifa/Structs"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IService interface contains functions that review service layer must implement( to be used by handlers and other modules)
+type IService interface {
+	CreateReview(ctx context.Context, userID primitive.ObjectID, appointmentID primitive.ObjectID, request reviewStructs.ReviewRequest) (*models.Review, *structs.IAppError)
+	GetDoctorReviews(ctx context.Context, doctorID primitive.ObjectID, includeHidden bool, cursor string, limit int64) (*reviewStructs.ReviewPage, *structs.IAppError)
+	GetClinicReviews(ctx context.Context, clinicID primitive.ObjectID, includeHidden bool, cursor string, limit int64) (*reviewStructs.ReviewPage, *structs.IAppError)
+	ReplyToReview(ctx context.Context, ownerID primitive.ObjectID, reviewID primitive.ObjectID, request reviewStructs.ReplyRequest) (*models.Review, *structs.IAppError)
+	SetReviewVisibility(ctx context.Context, adminID primitive.ObjectID, reviewID primitive.ObjectID, request reviewStructs.VisibilityRequest) (*models.Review, *structs.IAppError)
+}
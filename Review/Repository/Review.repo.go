@@ -0,0 +1,203 @@
+// Package repository provides the implementation of the repository layer for reviews in MongoDB.
+package repository
+
+import (
+	"AlShifa/Clinic/models"
+	interfaces "AlShifa/Review/Interfaces"
+	reviewStructs "AlShifa/Review/Structs"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Repo is the MongoDB implementation of the review IRepository interface.
+type Repo struct {
+	DB *mongo.Database
+}
+
+// this ensures this repo implements all methods of repository interface
+var _ interfaces.IRepository = (*Repo)(nil)
+
+// NewRepository creates a new review repository with the specified database.
+func NewRepository(db *mongo.Database) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+// CreateIndexes creates indexes review module relies on, the unique index allows one review per appointment. doctors and
+// clinics are indexed on their rating so they can be sorted by it
+func (r *Repo) CreateIndexes(ctx context.Context) error {
+	_, err := r.DB.Collection("Review").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "appointment", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "doctor", Value: 1}, {Key: "hidden", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "clinic", Value: 1}, {Key: "hidden", Value: 1}, {Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	byRating := mongo.IndexModel{Keys: bson.D{{Key: "rating.average", Value: -1}, {Key: "rating.count", Value: -1}}}
+	for _, collection := range []string{"Doctor", "Clinic"} {
+		if _, err := r.DB.Collection(collection).Indexes().CreateOne(ctx, byRating); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Repo) GetAppointment(ctx context.Context, appointmentID primitive.ObjectID) (models.Appointment, error) {
+	var appointment models.Appointment
+	err := r.DB.Collection("Appointment").FindOne(ctx, bson.M{"_id": appointmentID}).Decode(&appointment)
+	return appointment, err
+}
+
+func (r *Repo) GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+	var clinic models.Clinic
+	err := r.DB.Collection("Clinic").FindOne(ctx, bson.M{"_id": clinicID}).Decode(&clinic)
+	return clinic, err
+}
+
+// InsertReview saves review and in the same transaction adds its rating to the doctor and clinic. returns
+// ErrReviewExists if the appointment is already reviewed
+func (r *Repo) InsertReview(ctx context.Context, review models.Review) error {
+	session, err := r.DB.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	callback := func(sessCtx mongo.SessionContext) (any, error) {
+		if _, err := r.DB.Collection("Review").InsertOne(sessCtx, review); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return nil, interfaces.ErrReviewExists
+			}
+			return nil, err
+		}
+		return nil, r.rate(sessCtx, review, 1)
+	}
+
+	_, err = session.WithTransaction(ctx, callback)
+	return err
+}
+
+func (r *Repo) GetReview(ctx context.Context, reviewID primitive.ObjectID) (models.Review, error) {
+	var review models.Review
+	err := r.DB.Collection("Review").FindOne(ctx, bson.M{"_id": reviewID}).Decode(&review)
+	return review, err
+}
+
+// GetReviews returns a page of reviews matching filter latest first with names of their authors. paging continues after
+// after using creation time and id so pages stay stable while new reviews come in
+func (r *Repo) GetReviews(ctx context.Context, filter bson.M, after *reviewStructs.ReviewCursor, limit int64) ([]reviewStructs.ReviewItem, error) {
+	if after != nil {
+		filter["$or"] = bson.A{
+			bson.M{"createdAt": bson.M{"$lt": after.CreatedAt}},
+			bson.M{"createdAt": after.CreatedAt, "_id": bson.M{"$lt": after.ID}},
+		}
+	}
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: filter}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}}},
+		bson.D{{Key: "$limit", Value: limit}},
+		bson.D{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: "User"},
+			{Key: "localField", Value: "user"},
+			{Key: "foreignField", Value: "_id"},
+			{Key: "as", Value: "author"},
+		}}},
+		bson.D{{Key: "$addFields", Value: bson.D{
+			{Key: "authorName", Value: bson.D{{Key: "$arrayElemAt", Value: bson.A{"$author.name", 0}}}},
+		}}},
+		bson.D{{Key: "$project", Value: bson.D{{Key: "author", Value: 0}}}},
+	}
+
+	cursor, err := r.DB.Collection("Review").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	reviews := []reviewStructs.ReviewItem{}
+	if err := cursor.All(ctx, &reviews); err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// SetReply sets reply of clinic owner on review, returns mongo.ErrNoDocuments if there is no such review
+func (r *Repo) SetReply(ctx context.Context, reviewID primitive.ObjectID, reply models.ReviewReply) error {
+	res, err := r.DB.Collection("Review").UpdateOne(ctx, bson.M{"_id": reviewID}, bson.M{"$set": bson.M{"reply": reply}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// SetHidden hides review or shows it again and in the same transaction takes its rating out of or back into ratings of
+// the doctor and clinic. returns mongo.ErrNoDocuments if review was moderated meanwhile
+func (r *Repo) SetHidden(ctx context.Context, review models.Review, hidden bool, adminID primitive.ObjectID, reason string, at time.Time) error {
+	update := bson.M{"$set": bson.M{"hidden": true, "moderatedAt": at, "hiddenBy": adminID, "hiddenReason": reason}}
+	sign := int64(-1)
+	if !hidden {
+		update = bson.M{"$set": bson.M{"hidden": false, "moderatedAt": at}, "$unset": bson.M{"hiddenBy": "", "hiddenReason": ""}}
+		sign = 1
+	}
+
+	session, err := r.DB.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	callback := func(sessCtx mongo.SessionContext) (any, error) {
+		res, err := r.DB.Collection("Review").UpdateOne(sessCtx, bson.M{"_id": review.ID, "hidden": review.Hidden}, update)
+		if err != nil {
+			return nil, err
+		}
+		if res.MatchedCount == 0 {
+			return nil, mongo.ErrNoDocuments
+		}
+		return nil, r.rate(sessCtx, review, sign)
+	}
+
+	_, err = session.WithTransaction(ctx, callback)
+	return err
+}
+
+// rate adds rating of review to the doctor and clinic when sign is 1 and takes it out when sign is -1, average is worked
+// out again from the total so it never drifts
+func (r *Repo) rate(ctx context.Context, review models.Review, sign int64) error {
+	update := bson.A{
+		bson.M{"$set": bson.M{
+			"rating.count": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$rating.count", 0}}, sign}},
+			"rating.total": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$rating.total", 0}}, sign * int64(review.Rating)}},
+		}},
+		bson.M{"$set": bson.M{"rating.average": bson.M{"$cond": bson.A{
+			bson.M{"$gt": bson.A{"$rating.count", 0}},
+			bson.M{"$round": bson.A{bson.M{"$divide": bson.A{"$rating.total", "$rating.count"}}, 2}},
+			0,
+		}}}},
+	}
+
+	if _, err := r.DB.Collection("Doctor").UpdateOne(ctx, bson.M{"_id": review.Doctor}, update); err != nil {
+		return err
+	}
+	_, err := r.DB.Collection("Clinic").UpdateOne(ctx, bson.M{"_id": review.Clinic}, update)
+	return err
+}
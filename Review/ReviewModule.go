@@ -0,0 +1,32 @@
+// Package review lets patients rate doctors and clinics of their completed appointments, clinic owners reply to reviews
+// and admins hide abusive ones.
+package review
+
+import (
+	internals "AlShifa/Internals"
+	middleware "AlShifa/Middleware"
+	controller "AlShifa/Review/Controller"
+	repository "AlShifa/Review/Repository"
+	service "AlShifa/Review/Service"
+	utils "AlShifa/Utils"
+	"context"
+	"log"
+)
+
+func InitialiseReviewModule(app *internals.App) {
+	repository := repository.NewRepository(app.DB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout*5)
+	defer cancel()
+	if err := repository.CreateIndexes(ctx); err != nil {
+		log.Fatal("Failed to create review indexes", err)
+	}
+
+	service := service.NewReviewService(repository)
+	controller := controller.NewController(service)
+	app.Server.HandleFunc(utils.MakeURL("POST", "/appointment/{id}/review"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.CreateReview, utils.RoleUser)))
+	app.Server.HandleFunc(utils.MakeURL("GET", "/doctor/{id}/reviews"), middleware.JwtAuthMiddleware(controller.GetDoctorReviews))
+	app.Server.HandleFunc(utils.MakeURL("GET", "/clinic/{id}/reviews"), middleware.JwtAuthMiddleware(controller.GetClinicReviews))
+	app.Server.HandleFunc(utils.MakeURL("PUT", "/review/{id}/reply"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.ReplyToReview, utils.RoleClinicOwner)))
+	app.Server.HandleFunc(utils.MakeURL("PUT", "/review/{id}/visibility"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.SetReviewVisibility, utils.RoleAdmin)))
+}
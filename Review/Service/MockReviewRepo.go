@@ -0,0 +1,81 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	interfaces "AlShifa/Review/Interfaces"
+	reviewStructs "AlShifa/Review/Structs"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockReviewRepo struct {
+	CreateIndexesFn  func(ctx context.Context) error
+	GetAppointmentFn func(ctx context.Context, appointmentID primitive.ObjectID) (models.Appointment, error)
+	GetClinicFn      func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error)
+	InsertReviewFn   func(ctx context.Context, review models.Review) error
+	GetReviewFn      func(ctx context.Context, reviewID primitive.ObjectID) (models.Review, error)
+	GetReviewsFn     func(ctx context.Context, filter bson.M, after *reviewStructs.ReviewCursor, limit int64) ([]reviewStructs.ReviewItem, error)
+	SetReplyFn       func(ctx context.Context, reviewID primitive.ObjectID, reply models.ReviewReply) error
+	SetHiddenFn      func(ctx context.Context, review models.Review, hidden bool, adminID primitive.ObjectID, reason string, at time.Time) error
+}
+
+var _ interfaces.IRepository = (*MockReviewRepo)(nil)
+
+func (m *MockReviewRepo) CreateIndexes(ctx context.Context) error {
+	if m.CreateIndexesFn == nil {
+		panic("CreateIndexesFn not implemented inside mock")
+	}
+	return m.CreateIndexesFn(ctx)
+}
+
+func (m *MockReviewRepo) GetAppointment(ctx context.Context, appointmentID primitive.ObjectID) (models.Appointment, error) {
+	if m.GetAppointmentFn == nil {
+		panic("GetAppointmentFn not implemented inside mock")
+	}
+	return m.GetAppointmentFn(ctx, appointmentID)
+}
+
+func (m *MockReviewRepo) GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+	if m.GetClinicFn == nil {
+		panic("GetClinicFn not implemented inside mock")
+	}
+	return m.GetClinicFn(ctx, clinicID)
+}
+
+func (m *MockReviewRepo) InsertReview(ctx context.Context, review models.Review) error {
+	if m.InsertReviewFn == nil {
+		panic("InsertReviewFn not implemented inside mock")
+	}
+	return m.InsertReviewFn(ctx, review)
+}
+
+func (m *MockReviewRepo) GetReview(ctx context.Context, reviewID primitive.ObjectID) (models.Review, error) {
+	if m.GetReviewFn == nil {
+		panic("GetReviewFn not implemented inside mock")
+	}
+	return m.GetReviewFn(ctx, reviewID)
+}
+
+func (m *MockReviewRepo) GetReviews(ctx context.Context, filter bson.M, after *reviewStructs.ReviewCursor, limit int64) ([]reviewStructs.ReviewItem, error) {
+	if m.GetReviewsFn == nil {
+		panic("GetReviewsFn not implemented inside mock")
+	}
+	return m.GetReviewsFn(ctx, filter, after, limit)
+}
+
+func (m *MockReviewRepo) SetReply(ctx context.Context, reviewID primitive.ObjectID, reply models.ReviewReply) error {
+	if m.SetReplyFn == nil {
+		panic("SetReplyFn not implemented inside mock")
+	}
+	return m.SetReplyFn(ctx, reviewID, reply)
+}
+
+func (m *MockReviewRepo) SetHidden(ctx context.Context, review models.Review, hidden bool, adminID primitive.ObjectID, reason string, at time.Time) error {
+	if m.SetHiddenFn == nil {
+		panic("SetHiddenFn not implemented inside mock")
+	}
+	return m.SetHiddenFn(ctx, review, hidden, adminID, reason, at)
+}
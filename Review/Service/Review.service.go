@@ -0,0 +1,208 @@
+// Package service contains service layer implementation for review module
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	interfaces "AlShifa/Review/Interfaces"
+	reviewStructs "AlShifa/Review/Structs"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ReviewService struct {
+	Repo interfaces.IRepository
+}
+
+func NewReviewService(repo interfaces.IRepository) *ReviewService {
+	return &ReviewService{
+		Repo: repo,
+	}
+}
+
+// this ensures this service layer implements all methods of service layer interface
+var _ interfaces.IService = (*ReviewService)(nil)
+
+// CreateReview rates doctor and clinic of a completed appointment of user, every appointment can be reviewed once
+func (service *ReviewService) CreateReview(ctx context.Context, userID primitive.ObjectID, appointmentID primitive.ObjectID, request reviewStructs.ReviewRequest) (*models.Review, *structs.IAppError) {
+	appointment, err := service.Repo.GetAppointment(ctx, appointmentID)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Add Review", "Server Error")
+	}
+	//appointments of others are reported missing so their ids cant be probed
+	if err == mongo.ErrNoDocuments || appointment.User != userID {
+		return nil, utils.ReturnAppError(mongo.ErrNoDocuments, http.StatusNotFound, "Appointment Not Found", "Invalid Appointment")
+	}
+	if appointment.Status != utils.AppointmentStatusCompleted {
+		return nil, utils.ReturnAppError(errors.New("appointment is "+appointment.Status), http.StatusConflict, "Unable To Add Review", "Only Completed Appointments Can Be Reviewed")
+	}
+
+	review := models.Review{
+		ID:          primitive.NewObjectID(),
+		Appointment: appointment.ID,
+		User:        userID,
+		Doctor:      appointment.Doctor,
+		Clinic:      appointment.Clinic,
+		Rating:      request.Rating,
+		Comment:     request.Comment,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := service.Repo.InsertReview(ctx, review); err != nil {
+		if errors.Is(err, interfaces.ErrReviewExists) {
+			return nil, utils.ReturnAppError(err, http.StatusConflict, "Unable To Add Review", "Appointment Already Reviewed")
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Add Review", "Server Error")
+	}
+	return &review, nil
+}
+
+// GetDoctorReviews returns a page of reviews of doctor, hidden ones only when includeHidden is set
+func (service *ReviewService) GetDoctorReviews(ctx context.Context, doctorID primitive.ObjectID, includeHidden bool, cursor string, limit int64) (*reviewStructs.ReviewPage, *structs.IAppError) {
+	return service.getReviews(ctx, bson.M{"doctor": doctorID}, includeHidden, cursor, limit)
+}
+
+// GetClinicReviews returns a page of reviews of clinic, hidden ones only when includeHidden is set
+func (service *ReviewService) GetClinicReviews(ctx context.Context, clinicID primitive.ObjectID, includeHidden bool, cursor string, limit int64) (*reviewStructs.ReviewPage, *structs.IAppError) {
+	return service.getReviews(ctx, bson.M{"clinic": clinicID}, includeHidden, cursor, limit)
+}
+
+// ReplyToReview sets reply of owner of the reviewed clinic, replying again edits the reply
+func (service *ReviewService) ReplyToReview(ctx context.Context, ownerID primitive.ObjectID, reviewID primitive.ObjectID, request reviewStructs.ReplyRequest) (*models.Review, *structs.IAppError) {
+	review, appErr := service.getReview(ctx, reviewID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	clinic, err := service.Repo.GetClinic(ctx, review.Clinic)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Reply", "Server Error")
+	}
+	if err == mongo.ErrNoDocuments || clinic.Owner != ownerID {
+		return nil, utils.ReturnAppError(errors.New("review of clinic of someone else"), http.StatusForbidden, "Unable To Reply", "Cannot Reply To Reviews Of Clinic Of Someone Else")
+	}
+
+	now := time.Now().UTC()
+	reply := models.ReviewReply{Text: request.Text, Owner: ownerID, CreatedAt: now, UpdatedAt: now}
+	if review.Reply != nil {
+		reply.CreatedAt = review.Reply.CreatedAt
+	}
+	if err := service.Repo.SetReply(ctx, reviewID, reply); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.ReturnAppError(err, http.StatusNotFound, "Review Not Found", "Invalid Review")
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Reply", "Server Error")
+	}
+
+	review.Reply = &reply
+	return &review, nil
+}
+
+// SetReviewVisibility hides review from listings and ratings or shows it again, nothing changes if it already is that way
+func (service *ReviewService) SetReviewVisibility(ctx context.Context, adminID primitive.ObjectID, reviewID primitive.ObjectID, request reviewStructs.VisibilityRequest) (*models.Review, *structs.IAppError) {
+	review, appErr := service.getReview(ctx, reviewID)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if review.Hidden == request.Hidden {
+		return &review, nil
+	}
+
+	now := time.Now().UTC()
+	if err := service.Repo.SetHidden(ctx, review, request.Hidden, adminID, request.Reason, now); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.ReturnAppError(err, http.StatusConflict, "Unable To Moderate Review", "Review Was Moderated Meanwhile")
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Moderate Review", "Server Error")
+	}
+
+	review.Hidden, review.ModeratedAt = request.Hidden, &now
+	review.HiddenBy, review.HiddenReason = nil, ""
+	if request.Hidden {
+		review.HiddenBy, review.HiddenReason = &adminID, request.Reason
+	}
+	return &review, nil
+}
+
+func (service *ReviewService) getReview(ctx context.Context, reviewID primitive.ObjectID) (models.Review, *structs.IAppError) {
+	review, err := service.Repo.GetReview(ctx, reviewID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return review, utils.ReturnAppError(err, http.StatusNotFound, "Review Not Found", "Invalid Review")
+		}
+		return review, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Review", "Server Error")
+	}
+	return review, nil
+}
+
+// getReviews returns a page of reviews matching filter, cursor is the NextCursor of the previous page and empty for the
+// first one
+func (service *ReviewService) getReviews(ctx context.Context, filter bson.M, includeHidden bool, cursor string, limit int64) (*reviewStructs.ReviewPage, *structs.IAppError) {
+	var after *reviewStructs.ReviewCursor
+	if cursor != "" {
+		decoded, err := decodeReviewCursor(cursor)
+		if err != nil {
+			return nil, utils.ReturnAppError(err, http.StatusBadRequest, "Invalid Cursor", "Cursor Is Malformed")
+		}
+		after = &decoded
+	}
+	if !includeHidden {
+		filter["hidden"] = false
+	}
+	if limit <= 0 {
+		limit = utils.DefaultReviewPageSize
+	}
+
+	//one extra review tells if there is a next page
+	reviews, err := service.Repo.GetReviews(ctx, filter, after, limit+1)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Reviews", "Server Error")
+	}
+
+	page := &reviewStructs.ReviewPage{Reviews: reviews}
+	if int64(len(reviews)) > limit {
+		page.Reviews = reviews[:limit]
+		last := page.Reviews[limit-1]
+		page.NextCursor = encodeReviewCursor(reviewStructs.ReviewCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	if page.Reviews == nil {
+		page.Reviews = []reviewStructs.ReviewItem{}
+	}
+	return page, nil
+}
+
+// encodeReviewCursor makes an opaque cursor out of creation time (in milliseconds as stored by mongo) and id of a review
+func encodeReviewCursor(cursor reviewStructs.ReviewCursor) string {
+	raw := strconv.FormatInt(cursor.CreatedAt.UnixMilli(), 10) + ":" + cursor.ID.Hex()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeReviewCursor(cursor string) (reviewStructs.ReviewCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return reviewStructs.ReviewCursor{}, err
+	}
+
+	millis, hex, found := strings.Cut(string(raw), ":")
+	if !found {
+		return reviewStructs.ReviewCursor{}, errors.New("cursor without id")
+	}
+	unixMilli, err := strconv.ParseInt(millis, 10, 64)
+	if err != nil {
+		return reviewStructs.ReviewCursor{}, err
+	}
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return reviewStructs.ReviewCursor{}, err
+	}
+	return reviewStructs.ReviewCursor{CreatedAt: time.UnixMilli(unixMilli).UTC(), ID: id}, nil
+}
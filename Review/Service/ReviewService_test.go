@@ -0,0 +1,213 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	interfaces "AlShifa/Review/Interfaces"
+	reviewStructs "AlShifa/Review/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestCreateReview(t *testing.T) {
+	userID := primitive.NewObjectID()
+	completed := models.Appointment{ID: primitive.NewObjectID(), User: userID, Doctor: primitive.NewObjectID(), Clinic: primitive.NewObjectID(), Status: utils.AppointmentStatusCompleted}
+	confirmed := completed
+	confirmed.ID, confirmed.Status = primitive.NewObjectID(), utils.AppointmentStatusConfirmed
+	ofSomeoneElse := completed
+	ofSomeoneElse.ID, ofSomeoneElse.User = primitive.NewObjectID(), primitive.NewObjectID()
+	reviewed := completed
+	reviewed.ID = primitive.NewObjectID()
+
+	appointments := map[primitive.ObjectID]models.Appointment{
+		completed.ID: completed, confirmed.ID: confirmed, ofSomeoneElse.ID: ofSomeoneElse, reviewed.ID: reviewed,
+	}
+	var inserted models.Review
+	mockRepo := &MockReviewRepo{
+		GetAppointmentFn: func(ctx context.Context, appointmentID primitive.ObjectID) (models.Appointment, error) {
+			appointment, ok := appointments[appointmentID]
+			if !ok {
+				return models.Appointment{}, mongo.ErrNoDocuments
+			}
+			return appointment, nil
+		},
+		InsertReviewFn: func(ctx context.Context, review models.Review) error {
+			if review.Appointment == reviewed.ID {
+				return interfaces.ErrReviewExists
+			}
+			inserted = review
+			return nil
+		},
+	}
+
+	testCases := []struct {
+		Name               string
+		Appointment        primitive.ObjectID
+		ExpectedStatusCode int
+	}{
+		{Name: "Completed appointment is reviewed", Appointment: completed.ID},
+		{Name: "Appointment yet to be completed", Appointment: confirmed.ID, ExpectedStatusCode: http.StatusConflict},
+		{Name: "Appointment of someone else", Appointment: ofSomeoneElse.ID, ExpectedStatusCode: http.StatusNotFound},
+		{Name: "Appointment does not exist", Appointment: primitive.NewObjectID(), ExpectedStatusCode: http.StatusNotFound},
+		{Name: "Appointment reviewed already", Appointment: reviewed.ID, ExpectedStatusCode: http.StatusConflict},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			review, appErr := NewReviewService(mockRepo).CreateReview(context.Background(), userID, tc.Appointment, reviewStructs.ReviewRequest{Rating: 4, Comment: "Listened patiently"})
+			if tc.ExpectedStatusCode != 0 {
+				if appErr == nil || appErr.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status %d got %v", tc.ExpectedStatusCode, appErr)
+				}
+				return
+			}
+			if appErr != nil {
+				t.Fatalf("expected no error but got %v", appErr)
+			}
+			//doctor and clinic come from the appointment, not from the patient
+			if review.Doctor != completed.Doctor || review.Clinic != completed.Clinic || inserted.ID != review.ID {
+				t.Fatalf("expected review of doctor and clinic of appointment got %+v", review)
+			}
+		})
+	}
+}
+
+func TestReplyToReview(t *testing.T) {
+	ownerID := primitive.NewObjectID()
+	repliedAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	review := models.Review{ID: primitive.NewObjectID(), Clinic: primitive.NewObjectID(), Reply: &models.ReviewReply{Text: "Thanks", CreatedAt: repliedAt}}
+
+	var saved models.ReviewReply
+	mockRepo := &MockReviewRepo{
+		GetReviewFn: func(ctx context.Context, reviewID primitive.ObjectID) (models.Review, error) {
+			return review, nil
+		},
+		GetClinicFn: func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+			return models.Clinic{ID: clinicID, Owner: ownerID}, nil
+		},
+		SetReplyFn: func(ctx context.Context, reviewID primitive.ObjectID, reply models.ReviewReply) error {
+			saved = reply
+			return nil
+		},
+	}
+
+	testCases := []struct {
+		Name               string
+		Owner              primitive.ObjectID
+		ExpectedStatusCode int
+	}{
+		{Name: "Owner of clinic edits reply", Owner: ownerID},
+		{Name: "Owner of another clinic", Owner: primitive.NewObjectID(), ExpectedStatusCode: http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			_, appErr := NewReviewService(mockRepo).ReplyToReview(context.Background(), tc.Owner, review.ID, reviewStructs.ReplyRequest{Text: "Sorry for the wait"})
+			if tc.ExpectedStatusCode != 0 {
+				if appErr == nil || appErr.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status %d got %v", tc.ExpectedStatusCode, appErr)
+				}
+				return
+			}
+			if appErr != nil {
+				t.Fatalf("expected no error but got %v", appErr)
+			}
+			if saved.Text != "Sorry for the wait" || !saved.CreatedAt.Equal(repliedAt) || !saved.UpdatedAt.After(repliedAt) {
+				t.Fatalf("expected edited reply keeping its creation time got %+v", saved)
+			}
+		})
+	}
+}
+
+func TestSetReviewVisibility(t *testing.T) {
+	adminID := primitive.NewObjectID()
+	visible := models.Review{ID: primitive.NewObjectID(), Rating: 1}
+
+	hides := 0
+	mockRepo := &MockReviewRepo{
+		GetReviewFn: func(ctx context.Context, reviewID primitive.ObjectID) (models.Review, error) {
+			return visible, nil
+		},
+		SetHiddenFn: func(ctx context.Context, review models.Review, hidden bool, by primitive.ObjectID, reason string, at time.Time) error {
+			hides++
+			return nil
+		},
+	}
+	service := NewReviewService(mockRepo)
+
+	review, appErr := service.SetReviewVisibility(context.Background(), adminID, visible.ID, reviewStructs.VisibilityRequest{Hidden: true, Reason: "abusive language"})
+	if appErr != nil {
+		t.Fatalf("expected no error but got %v", appErr)
+	}
+	if !review.Hidden || review.HiddenBy == nil || *review.HiddenBy != adminID || hides != 1 {
+		t.Fatalf("expected review hidden by admin got %+v", review)
+	}
+
+	//showing a visible review again changes nothing so ratings are not counted twice
+	if _, appErr := service.SetReviewVisibility(context.Background(), adminID, visible.ID, reviewStructs.VisibilityRequest{}); appErr != nil {
+		t.Fatalf("expected no error but got %v", appErr)
+	}
+	if hides != 1 {
+		t.Fatalf("expected no repo call for unchanged visibility got %d", hides)
+	}
+}
+
+func TestGetDoctorReviews(t *testing.T) {
+	doctorID := primitive.NewObjectID()
+	createdAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	reviews := []reviewStructs.ReviewItem{
+		{Review: models.Review{ID: primitive.NewObjectID(), CreatedAt: createdAt}},
+		{Review: models.Review{ID: primitive.NewObjectID(), CreatedAt: createdAt.Add(-time.Hour)}},
+		{Review: models.Review{ID: primitive.NewObjectID(), CreatedAt: createdAt.Add(-2 * time.Hour)}},
+	}
+
+	var filters []bson.M
+	var afters []*reviewStructs.ReviewCursor
+	mockRepo := &MockReviewRepo{
+		GetReviewsFn: func(ctx context.Context, filter bson.M, after *reviewStructs.ReviewCursor, limit int64) ([]reviewStructs.ReviewItem, error) {
+			filters, afters = append(filters, filter), append(afters, after)
+			start := 0
+			if after != nil {
+				for i := range reviews {
+					if reviews[i].ID == after.ID {
+						start = i + 1
+					}
+				}
+			}
+			return reviews[start:min(start+int(limit), len(reviews))], nil
+		},
+	}
+	service := NewReviewService(mockRepo)
+
+	first, appErr := service.GetDoctorReviews(context.Background(), doctorID, false, "", 2)
+	if appErr != nil {
+		t.Fatalf("expected no error but got %v", appErr)
+	}
+	if len(first.Reviews) != 2 || first.NextCursor == "" {
+		t.Fatalf("expected first page of 2 with a cursor got %+v", first)
+	}
+	if hidden, ok := filters[0]["hidden"]; !ok || hidden != false {
+		t.Fatalf("expected hidden reviews to be left out got filter %v", filters[0])
+	}
+
+	second, appErr := service.GetDoctorReviews(context.Background(), doctorID, true, first.NextCursor, 2)
+	if appErr != nil {
+		t.Fatalf("expected no error but got %v", appErr)
+	}
+	if len(second.Reviews) != 1 || second.NextCursor != "" || second.Reviews[0].ID != reviews[2].ID {
+		t.Fatalf("expected last review on second page got %+v", second)
+	}
+	if _, ok := filters[1]["hidden"]; ok || !afters[1].CreatedAt.Equal(reviews[1].CreatedAt) {
+		t.Fatalf("expected admin page after second review got filter %v after %+v", filters[1], afters[1])
+	}
+
+	if _, appErr := service.GetDoctorReviews(context.Background(), doctorID, false, "not a cursor", 2); appErr == nil || appErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected malformed cursor to be rejected got %v", appErr)
+	}
+}
@@ -0,0 +1,44 @@
+// Package structs contains request and response structures of the review module
+package structs
+
+import (
+	"AlShifa/Clinic/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReviewRequest is sent by patient to review a completed appointment
+type ReviewRequest struct {
+	Rating  int32  `json:"rating"`
+	Comment string `json:"comment"`
+}
+
+// ReplyRequest is sent by clinic owner to answer a review, a new reply replaces the old one
+type ReplyRequest struct {
+	Text string `json:"text"`
+}
+
+// VisibilityRequest is sent by admin to hide a review or show it again
+type VisibilityRequest struct {
+	Hidden bool   `json:"hidden"`
+	Reason string `json:"reason"`
+}
+
+// ReviewItem is a review along with name of its author
+type ReviewItem struct {
+	models.Review `bson:",inline"`
+	AuthorName    string `json:"authorName" bson:"authorName"`
+}
+
+// ReviewCursor points at the last review of a page, next page starts right after it
+type ReviewCursor struct {
+	CreatedAt time.Time
+	ID        primitive.ObjectID
+}
+
+// ReviewPage is one page of reviews latest first, NextCursor is empty on the last page
+type ReviewPage struct {
+	Reviews    []ReviewItem `json:"reviews"`
+	NextCursor string       `json:"nextCursor,omitempty"`
+}
@@ -0,0 +1,53 @@
+// Package validators contains validation functions for review module
+package validators
+
+import (
+	reviewStructs "AlShifa/Review/Structs"
+	utils "AlShifa/Utils"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidateReview validates review of patient and returns a map of field errors
+func ValidateReview(request *reviewStructs.ReviewRequest) map[string]string {
+	errors := make(map[string]string)
+
+	if request.Rating < utils.MinReviewRating || request.Rating > utils.MaxReviewRating {
+		errors["rating"] = fmt.Sprintf("rating must be between %d and %d", utils.MinReviewRating, utils.MaxReviewRating)
+	}
+
+	request.Comment = strings.TrimSpace(request.Comment)
+	if utf8.RuneCountInString(request.Comment) > utils.MaxReviewCommentLength {
+		errors["comment"] = "comment is too long"
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
+
+// ValidateReply validates reply of clinic owner
+func ValidateReply(request *reviewStructs.ReplyRequest) map[string]string {
+	request.Text = strings.TrimSpace(request.Text)
+	switch {
+	case request.Text == "":
+		return map[string]string{"text": "reply is required"}
+	case utf8.RuneCountInString(request.Text) > utils.MaxReviewReplyLength:
+		return map[string]string{"text": "reply is too long"}
+	}
+	return nil
+}
+
+// ValidateVisibility validates reason given by admin for hiding a review
+func ValidateVisibility(request *reviewStructs.VisibilityRequest) map[string]string {
+	request.Reason = strings.TrimSpace(request.Reason)
+	if request.Hidden && request.Reason == "" {
+		return map[string]string{"reason": "reason is required to hide a review"}
+	}
+	if utf8.RuneCountInString(request.Reason) > utils.MaxModerationReasonLength {
+		return map[string]string{"reason": "reason is too long"}
+	}
+	return nil
+}
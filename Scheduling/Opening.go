@@ -0,0 +1,76 @@
+package scheduling
+
+import (
+	"AlShifa/Clinic/models"
+	utils "AlShifa/Utils"
+	"time"
+)
+
+// openSpan is a stretch of time clinic stays open
+type openSpan struct {
+	from time.Time
+	till time.Time
+}
+
+// OpeningStatusAt tells if clinic is open at given moment and when that changes, from its season timings, working days
+// and time zone. closures should already be the ones applying to the clinic. a closed clinic reports when it opens next
+// if it does within utils.OpeningLookaheadDays
+func OpeningStatusAt(clinic models.Clinic, closures []models.Leave, at time.Time) models.OpeningStatus {
+	loc := ClinicLocation(clinic)
+	day := dateIn(at.In(loc), loc)
+
+	//hours of consecutive days are joined so a clinic open round the clock doesnt close at midnight
+	var spans []openSpan
+	for i := 0; i <= utils.OpeningLookaheadDays; i++ {
+		from, till := clinicHours(clinic, day.AddDate(0, 0, i), loc)
+		for _, span := range withoutClosures(openSpan{from: from, till: till}, closures) {
+			if last := len(spans) - 1; last >= 0 && spans[last].till.Equal(span.from) {
+				spans[last].till = span.till
+				continue
+			}
+			spans = append(spans, span)
+		}
+	}
+
+	horizon := day.AddDate(0, 0, utils.OpeningLookaheadDays+1)
+	for _, span := range spans {
+		if !span.till.After(at) {
+			continue
+		}
+		if span.from.After(at) {
+			return models.OpeningStatus{Status: utils.OpeningStatusClosed, Label: "Opens at " + ClinicTime(clinic, span.from), OpensAt: &span.from}
+		}
+		//open beyond the days looked at
+		if span.till.Equal(horizon) {
+			return models.OpeningStatus{Status: utils.OpeningStatusOpen, Label: "Open"}
+		}
+		return models.OpeningStatus{Status: utils.OpeningStatusOpen, Label: "Open till " + ClinicTime(clinic, span.till), ClosesAt: &span.till}
+	}
+	return models.OpeningStatus{Status: utils.OpeningStatusClosed, Label: "Closed"}
+}
+
+// withoutClosures cuts closures out of span, empty spans are dropped
+func withoutClosures(span openSpan, closures []models.Leave) []openSpan {
+	spans := []openSpan{}
+	if span.till.After(span.from) {
+		spans = append(spans, span)
+	}
+
+	for _, closure := range closures {
+		kept := spans[:0:0]
+		for _, span := range spans {
+			if !closure.Start.Before(span.till) || !closure.End.After(span.from) {
+				kept = append(kept, span)
+				continue
+			}
+			if closure.Start.After(span.from) {
+				kept = append(kept, openSpan{from: span.from, till: closure.Start})
+			}
+			if closure.End.Before(span.till) {
+				kept = append(kept, openSpan{from: closure.End, till: span.till})
+			}
+		}
+		spans = kept
+	}
+	return spans
+}
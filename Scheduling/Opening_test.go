@@ -0,0 +1,64 @@
+package scheduling
+
+import (
+	"AlShifa/Clinic/models"
+	utils "AlShifa/Utils"
+	"testing"
+	"time"
+)
+
+func TestOpeningStatusAt(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Dubai")
+	clinic := models.Clinic{
+		TimeZone:    "Asia/Dubai",
+		WorkingDays: []string{"Mon", "Tue", "Wed", "Thu", "Fri"},
+		SeasonTimings: []models.SeasonTimingDetails{
+			{Name: "All Year", Start: time.Date(2025, 1, 1, 9, 0, 0, 0, loc), End: time.Date(2025, 12, 31, 17, 0, 0, 0, loc)},
+		},
+	}
+	//2025-06-02 is a monday
+	on := func(day int, hour int, minute int) time.Time {
+		return time.Date(2025, 6, day, hour, minute, 0, 0, loc)
+	}
+
+	testCases := []struct {
+		Name             string
+		At               time.Time
+		Closures         []models.Leave
+		ExpectedStatus   string
+		ExpectedClosesAt time.Time
+		ExpectedOpensAt  time.Time
+	}{
+		{Name: "Open in working hours", At: on(2, 10, 0), ExpectedStatus: utils.OpeningStatusOpen, ExpectedClosesAt: on(2, 17, 0)},
+		{Name: "Closed before opening", At: on(2, 8, 0), ExpectedStatus: utils.OpeningStatusClosed, ExpectedOpensAt: on(2, 9, 0)},
+		{Name: "Closed after hours opens next day", At: on(2, 18, 0), ExpectedStatus: utils.OpeningStatusClosed, ExpectedOpensAt: on(3, 9, 0)},
+		{Name: "Weekend is skipped", At: on(6, 18, 0), ExpectedStatus: utils.OpeningStatusClosed, ExpectedOpensAt: on(9, 9, 0)},
+		{Name: "Holiday is skipped", At: on(2, 18, 0), Closures: []models.Leave{{Start: on(3, 0, 0), End: on(4, 0, 0)}}, ExpectedStatus: utils.OpeningStatusClosed, ExpectedOpensAt: on(4, 9, 0)},
+		{Name: "Closed during a closure", At: on(2, 12, 30), Closures: []models.Leave{{Start: on(2, 12, 0), End: on(2, 14, 0)}}, ExpectedStatus: utils.OpeningStatusClosed, ExpectedOpensAt: on(2, 14, 0)},
+		{Name: "Closes early for a closure", At: on(2, 10, 0), Closures: []models.Leave{{Start: on(2, 12, 0), End: on(2, 14, 0)}}, ExpectedStatus: utils.OpeningStatusOpen, ExpectedClosesAt: on(2, 12, 0)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			status := OpeningStatusAt(clinic, tc.Closures, tc.At)
+			if status.Status != tc.ExpectedStatus {
+				t.Fatalf("expected %s got %+v", tc.ExpectedStatus, status)
+			}
+			if !tc.ExpectedClosesAt.IsZero() && (status.ClosesAt == nil || !status.ClosesAt.Equal(tc.ExpectedClosesAt)) {
+				t.Fatalf("expected to close at %v got %+v", tc.ExpectedClosesAt, status)
+			}
+			if !tc.ExpectedOpensAt.IsZero() && (status.OpensAt == nil || !status.OpensAt.Equal(tc.ExpectedOpensAt)) {
+				t.Fatalf("expected to open at %v got %+v", tc.ExpectedOpensAt, status)
+			}
+		})
+	}
+
+	//a clinic without seasons and working days never closes
+	if status := OpeningStatusAt(models.Clinic{}, nil, on(2, 3, 0)); status.Status != utils.OpeningStatusOpen || status.ClosesAt != nil {
+		t.Fatalf("expected clinic open round the clock got %+v", status)
+	}
+	//a clinic which wont open again soon has no opening time
+	if status := OpeningStatusAt(models.Clinic{WorkingDays: []string{"Sun"}}, []models.Leave{{Start: on(1, 0, 0), End: on(30, 0, 0)}}, on(2, 10, 0)); status.Status != utils.OpeningStatusClosed || status.OpensAt != nil {
+		t.Fatalf("expected clinic closed without opening time got %+v", status)
+	}
+}
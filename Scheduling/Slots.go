@@ -0,0 +1,321 @@
+// Package scheduling turns clinic season timings and doctor sessions into bookable slots.
+//
+// A season applies every year between the month/day of its Start and End (so a Winter season
+// stored as Nov 1 -> Mar 31 wraps over the new year) and the clock part of Start/End is the
+// daily opening window of the clinic in that season. Timings are read in the time zone of the
+// clinic and a clinic with working days is closed on other days. A doctor session (ClinicDetails)
+// sits inside that window on its working days and is cut into slots of the doctor's slot duration.
+// Slot numbers are counted from the start of the doctor session so they stay stable for a day.
+//
+// A session is consulted in clinic, over video or both. Online hours of a session are video only and video slots
+// dont need the clinic to be open, so a doctor can stretch a session past clinic hours to consult online.
+package scheduling
+
+import (
+	"AlShifa/Clinic/models"
+	utils "AlShifa/Utils"
+	"log"
+	"math"
+	"strings"
+	"time"
+	_ "time/tzdata" // clinic time zones must resolve even on hosts without zoneinfo
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Slot is a single bookable slot of a doctor at a clinic
+type Slot struct {
+	Index     int8      `json:"slot"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Available bool      `json:"available"`
+	Remaining int       `json:"remaining"` // seats left in the slot
+	Mode      string    `json:"mode"`      // InClinic, Video or Both
+}
+
+// ClinicLocation returns the time zone in which clinic timings are interpreted, clinics without a time zone of their
+// own use the default one
+func ClinicLocation(clinic models.Clinic) *time.Location {
+	if clinic.TimeZone != "" {
+		if loc, err := time.LoadLocation(clinic.TimeZone); err == nil {
+			return loc
+		}
+	}
+	loc, err := time.LoadLocation(utils.DefaultTimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// ClinicTime formats t in time zone of clinic for messages to patients
+func ClinicTime(clinic models.Clinic, t time.Time) string {
+	return t.In(ClinicLocation(clinic)).Format(utils.DisplayTimeLayout)
+}
+
+// ClinicActive checks if clinic can be found and booked, clinics registered before statuses have none and are active
+func ClinicActive(clinic models.Clinic) bool {
+	return clinic.DeletedAt == nil && (clinic.Status == "" || clinic.Status == utils.ClinicStatusActive)
+}
+
+// ActiveClinicFilter matches clinics which are active, prefix is the path of clinic inside filtered documents (like
+// clinics.information.) and is empty when clinics are filtered themselves
+func ActiveClinicFilter(prefix string) bson.M {
+	return bson.M{
+		prefix + "status":    bson.M{"$in": bson.A{nil, utils.ClinicStatusActive}},
+		prefix + "deletedAt": nil,
+	}
+}
+
+// SlotDuration returns slot length of doctor falling back to default when doctor hasnt set it
+func SlotDuration(doctor models.Doctor) time.Duration {
+	if doctor.SlotDuration <= 0 {
+		return utils.DefaultSlotMinutes * time.Minute
+	}
+	return time.Duration(doctor.SlotDuration) * time.Minute
+}
+
+// DoctorSession returns the session of doctor at given clinic
+func DoctorSession(doctor models.Doctor, clinicID string) (models.ClinicDetails, bool) {
+	for _, session := range doctor.Clinics {
+		if session.Clinic.Hex() == clinicID {
+			return session, true
+		}
+	}
+	return models.ClinicDetails{}, false
+}
+
+// WorksOn checks if weekday is present in working days, both full names (Monday) and short names (mon) are accepted
+func WorksOn(workingDays []string, weekday time.Weekday) bool {
+	dayName := strings.ToLower(weekday.String())
+	for _, day := range workingDays {
+		day = strings.ToLower(strings.TrimSpace(day))
+		if day == dayName || (len(day) == 3 && strings.HasPrefix(dayName, day)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ActiveSeason returns the season which covers the given day, nil if no season covers it
+func ActiveSeason(seasons []models.SeasonTimingDetails, day time.Time, loc *time.Location) *models.SeasonTimingDetails {
+	dayKey := monthDay(day.In(loc))
+	for i := range seasons {
+		start := monthDay(seasons[i].Start.In(loc))
+		end := monthDay(seasons[i].End.In(loc))
+
+		if start <= end && dayKey >= start && dayKey <= end {
+			return &seasons[i]
+		}
+		//season wraps over new year like winter
+		if start > end && (dayKey >= start || dayKey <= end) {
+			return &seasons[i]
+		}
+	}
+	return nil
+}
+
+// clinicHours returns when clinic is open on day (midnight in loc), from equals till when clinic is closed that day.
+// clinic is open in its active season on its working days and a clinic without seasons is treated as open all day
+func clinicHours(clinic models.Clinic, day time.Time, loc *time.Location) (time.Time, time.Time) {
+	if len(clinic.WorkingDays) > 0 && !WorksOn(clinic.WorkingDays, day.Weekday()) {
+		return day, day
+	}
+	if len(clinic.SeasonTimings) == 0 {
+		return day, day.AddDate(0, 0, 1)
+	}
+
+	season := ActiveSeason(clinic.SeasonTimings, day, loc)
+	if season == nil {
+		return day, day
+	}
+	seasonStart := clockOn(day, season.Start, loc)
+	seasonEnd := clockOn(day, season.End, loc)
+	//season saved with dates only means clinic is open whole day
+	if !seasonEnd.After(seasonStart) {
+		return day, day.AddDate(0, 0, 1)
+	}
+	return seasonStart, seasonEnd
+}
+
+// GenerateSlots computes slots of a doctor session at clinic for every calendar day between from and to (both inclusive).
+// only the date of from and to is used so convert them to clinic location first if they are instants
+func GenerateSlots(clinic models.Clinic, session models.ClinicDetails, slotDuration time.Duration, from time.Time, to time.Time) []Slot {
+	loc := ClinicLocation(clinic)
+	if slotDuration <= 0 {
+		return nil
+	}
+
+	var slots []Slot
+	day := dateIn(from, loc)
+	lastDay := dateIn(to, loc)
+
+	for !day.After(lastDay) {
+		slots = append(slots, slotsOfDay(clinic, session, slotDuration, day, loc)...)
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return slots
+}
+
+func slotsOfDay(clinic models.Clinic, session models.ClinicDetails, slotDuration time.Duration, day time.Time, loc *time.Location) []Slot {
+	if !WorksOn(session.WorkingDays, day.Weekday()) {
+		return nil
+	}
+
+	sessionStart := clockOn(day, session.StartTime, loc)
+	sessionEnd := clockOn(day, session.EndTime, loc)
+	if !sessionEnd.After(sessionStart) {
+		return nil
+	}
+
+	//clinic has to be open, while it is closed only video consultations go on
+	openFrom, openTill := clinicHours(clinic, day, loc)
+	openFrom = latest(openFrom, sessionStart)
+	openTill = earliest(openTill, sessionEnd)
+
+	var slots []Slot
+	for index := 0; ; index++ {
+		start := sessionStart.Add(time.Duration(index) * slotDuration)
+		end := start.Add(slotDuration)
+		if end.After(sessionEnd) {
+			break
+		}
+		//sessions are validated against this, one saved before that loses the rest of its day
+		if index > math.MaxInt8 {
+			log.Printf("session %s of clinic %s has more than %d slots of %s, slots after %s are dropped", session.ID.Hex(), clinic.ID.Hex(), utils.MaxSlotsPerSession, slotDuration, start.Format(utils.DisplayTimeLayout))
+			break
+		}
+		mode := slotMode(session, day, start, end, loc)
+		if mode != utils.ConsultationModeVideo && (start.Before(openFrom) || end.After(openTill)) {
+			continue
+		}
+		slots = append(slots, Slot{Index: int8(index), Start: start, End: end, Available: true, Remaining: SlotCapacity(session), Mode: mode})
+	}
+	return slots
+}
+
+// SessionSlots returns how many slots of slotDuration a day of session holds, it cant be more than utils.MaxSlotsPerSession
+func SessionSlots(session models.ClinicDetails, slotDuration time.Duration) int {
+	if slotDuration <= 0 {
+		return 0
+	}
+	length := clockOn(time.Time{}, session.EndTime, time.UTC).Sub(clockOn(time.Time{}, session.StartTime, time.UTC))
+	if length <= 0 {
+		return 0
+	}
+	return int(length / slotDuration)
+}
+
+// slotMode returns how slot is consulted, slots lying wholly inside online hours of the session are video only
+func slotMode(session models.ClinicDetails, day time.Time, start time.Time, end time.Time, loc *time.Location) string {
+	for _, window := range session.OnlineHours {
+		from := clockOn(day, window.StartTime, loc)
+		till := clockOn(day, window.EndTime, loc)
+		if !start.Before(from) && !end.After(till) {
+			return utils.ConsultationModeVideo
+		}
+	}
+	return SessionMode(session)
+}
+
+// SessionMode returns consultation mode of session, sessions saved before modes existed are in clinic
+func SessionMode(session models.ClinicDetails) string {
+	if session.Mode == "" {
+		return utils.ConsultationModeInClinic
+	}
+	return session.Mode
+}
+
+// Supports checks if an appointment in mode can be booked in slot
+func (slot Slot) Supports(mode string) bool {
+	return slot.Mode == mode || slot.Mode == utils.ConsultationModeBoth
+}
+
+// FilterMode keeps the slots which can be booked in mode, empty mode keeps all
+func FilterMode(slots []Slot, mode string) []Slot {
+	if mode == "" {
+		return slots
+	}
+
+	filtered := slots[:0:0]
+	for _, slot := range slots {
+		if slot.Supports(mode) {
+			filtered = append(filtered, slot)
+		}
+	}
+	return filtered
+}
+
+func monthDay(t time.Time) int {
+	return int(t.Month())*100 + t.Day()
+}
+
+// dateIn returns midnight in loc of the calendar date of t
+func dateIn(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// clockOn places the clock of given time on day. timings are usually saved with a dummy date
+// so the offset of the clinic zone on that day is used instead of the (historic) offset of the saved date
+func clockOn(day time.Time, clock time.Time, loc *time.Location) time.Time {
+	base := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+	_, offset := base.Zone()
+
+	clock = clock.UTC()
+	seconds := (clock.Hour()*3600 + clock.Minute()*60 + offset) % 86400
+	if seconds < 0 {
+		seconds += 86400
+	}
+	return base.Add(time.Duration(seconds) * time.Second)
+}
+
+func latest(a time.Time, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func earliest(a time.Time, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// SlotCapacity returns how many patients can book one slot of the session
+func SlotCapacity(session models.ClinicDetails) int {
+	if session.SlotCapacity <= 0 {
+		return utils.DefaultSlotCapacity
+	}
+	return int(session.SlotCapacity)
+}
+
+// DayKey returns midnight UTC of the calendar date of t in loc, it is used to store dates of slots
+func DayKey(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// ExcludeLeaves drops slots which overlap any of leaves, leaves should already be the ones applying to the doctor session
+func ExcludeLeaves(slots []Slot, leaves []models.Leave) []Slot {
+	if len(leaves) == 0 {
+		return slots
+	}
+
+	available := slots[:0:0]
+	for _, slot := range slots {
+		onLeave := false
+		for _, leave := range leaves {
+			if slot.Start.Before(leave.End) && slot.End.After(leave.Start) {
+				onLeave = true
+				break
+			}
+		}
+		if !onLeave {
+			available = append(available, slot)
+		}
+	}
+	return available
+}
@@ -0,0 +1,229 @@
+package scheduling
+
+import (
+	"AlShifa/Clinic/models"
+	utils "AlShifa/Utils"
+	"testing"
+	"time"
+)
+
+func TestWorksOn(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		WorkingDays []string
+		Weekday     time.Weekday
+		Expected    bool
+	}{
+		{Name: "Full day name", WorkingDays: []string{"Monday"}, Weekday: time.Monday, Expected: true},
+		{Name: "Short day name in any case", WorkingDays: []string{" tUe "}, Weekday: time.Tuesday, Expected: true},
+		{Name: "Day not present", WorkingDays: []string{"Mon", "Tue"}, Weekday: time.Friday, Expected: false},
+		{Name: "No working days", WorkingDays: nil, Weekday: time.Friday, Expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if got := WorksOn(tc.WorkingDays, tc.Weekday); got != tc.Expected {
+				t.Fatalf("expected %v got %v", tc.Expected, got)
+			}
+		})
+	}
+}
+
+func TestClinicActive(t *testing.T) {
+	deletedAt := time.Now()
+	testCases := []struct {
+		Name     string
+		Clinic   models.Clinic
+		Expected bool
+	}{
+		{Name: "Clinic registered before statuses", Clinic: models.Clinic{}, Expected: true},
+		{Name: "Active clinic", Clinic: models.Clinic{Status: utils.ClinicStatusActive}, Expected: true},
+		{Name: "Suspended clinic", Clinic: models.Clinic{Status: utils.ClinicStatusSuspended}, Expected: false},
+		{Name: "Closed clinic", Clinic: models.Clinic{Status: utils.ClinicStatusClosed}, Expected: false},
+		{Name: "Deleted clinic", Clinic: models.Clinic{Status: utils.ClinicStatusActive, DeletedAt: &deletedAt}, Expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if got := ClinicActive(tc.Clinic); got != tc.Expected {
+				t.Fatalf("expected %v got %v", tc.Expected, got)
+			}
+		})
+	}
+}
+
+func TestActiveSeason(t *testing.T) {
+	loc, _ := time.LoadLocation(utils.DefaultTimeZone)
+	seasons := []models.SeasonTimingDetails{
+		{Name: "Summer", Start: time.Date(2025, 4, 1, 9, 0, 0, 0, loc), End: time.Date(2025, 10, 31, 17, 0, 0, 0, loc)},
+		{Name: "Winter", Start: time.Date(2025, 11, 1, 10, 0, 0, 0, loc), End: time.Date(2026, 3, 31, 16, 0, 0, 0, loc)},
+	}
+
+	testCases := []struct {
+		Name     string
+		Day      time.Time
+		Expected string
+	}{
+		{Name: "Summer of a later year", Day: time.Date(2030, 6, 15, 0, 0, 0, 0, loc), Expected: "Summer"},
+		{Name: "Winter before new year", Day: time.Date(2025, 12, 20, 0, 0, 0, 0, loc), Expected: "Winter"},
+		{Name: "Winter after new year", Day: time.Date(2026, 1, 5, 0, 0, 0, 0, loc), Expected: "Winter"},
+		{Name: "Last day of summer", Day: time.Date(2026, 10, 31, 0, 0, 0, 0, loc), Expected: "Summer"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			season := ActiveSeason(seasons, tc.Day, loc)
+			if season == nil || season.Name != tc.Expected {
+				t.Fatalf("expected season %s got %v", tc.Expected, season)
+			}
+		})
+	}
+
+	if season := ActiveSeason(seasons[:1], time.Date(2026, 1, 5, 0, 0, 0, 0, loc), loc); season != nil {
+		t.Fatalf("expected no season but got %s", season.Name)
+	}
+}
+
+func TestGenerateSlots(t *testing.T) {
+	loc, _ := time.LoadLocation(utils.DefaultTimeZone)
+	clinic := models.Clinic{
+		SeasonTimings: []models.SeasonTimingDetails{
+			//clinic opens at 10 in summer
+			{Name: "Summer", Start: time.Date(2025, 4, 1, 10, 0, 0, 0, loc), End: time.Date(2025, 10, 31, 17, 0, 0, 0, loc)},
+		},
+	}
+	session := models.ClinicDetails{
+		StartTime:   time.Date(2025, 1, 1, 9, 0, 0, 0, loc),
+		EndTime:     time.Date(2025, 1, 1, 11, 0, 0, 0, loc),
+		WorkingDays: []string{"Mon", "Wed"},
+	}
+
+	//2025-06-02 is a monday, tuesday is not a working day and wednesday is
+	slots := GenerateSlots(clinic, session, 30*time.Minute, time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC), time.Date(2025, 6, 4, 0, 0, 0, 0, time.UTC))
+	if len(slots) != 4 {
+		t.Fatalf("expected 4 slots got %d: %+v", len(slots), slots)
+	}
+
+	//slots before clinic opens are skipped but numbering still starts from doctor session
+	first := slots[0]
+	if first.Index != 2 || !first.Start.Equal(time.Date(2025, 6, 2, 10, 0, 0, 0, loc)) || !first.Available {
+		t.Fatalf("unexpected first slot %+v", first)
+	}
+
+	last := slots[3]
+	if last.Index != 3 || !last.End.Equal(time.Date(2025, 6, 4, 11, 0, 0, 0, loc)) {
+		t.Fatalf("unexpected last slot %+v", last)
+	}
+
+	//no slots outside the season
+	if slots := GenerateSlots(clinic, session, 30*time.Minute, time.Date(2025, 12, 1, 0, 0, 0, 0, loc), time.Date(2025, 12, 31, 0, 0, 0, 0, loc)); len(slots) != 0 {
+		t.Fatalf("expected no slots outside season got %d", len(slots))
+	}
+
+	//no slots on days clinic doesnt work even when doctor does
+	clinic.WorkingDays = []string{"Wed"}
+	if slots := GenerateSlots(clinic, session, 30*time.Minute, time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC), time.Date(2025, 6, 4, 0, 0, 0, 0, time.UTC)); len(slots) != 2 || slots[0].Start.Day() != 4 {
+		t.Fatalf("expected slots only on wednesday got %+v", slots)
+	}
+}
+
+func TestSessionSlots(t *testing.T) {
+	clinic := models.Clinic{
+		TimeZone:      "UTC",
+		SeasonTimings: []models.SeasonTimingDetails{{Name: "Whole Year", Start: time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC), End: time.Date(2025, 12, 31, 22, 0, 0, 0, time.UTC)}},
+	}
+	session := models.ClinicDetails{
+		StartTime:   time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC),
+		EndTime:     time.Date(2025, 1, 1, 21, 0, 0, 0, time.UTC),
+		WorkingDays: []string{"Mon"},
+	}
+
+	testCases := []struct {
+		Name          string
+		SlotDuration  time.Duration
+		ExpectedSlots int
+	}{
+		{Name: "Session is cut into whole slots", SlotDuration: 25 * time.Minute, ExpectedSlots: 28},
+		{Name: "Short slots over a long session are too many to number", SlotDuration: 5 * time.Minute, ExpectedSlots: 144},
+		{Name: "Slot without duration holds nothing", SlotDuration: 0, ExpectedSlots: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if got := SessionSlots(session, tc.SlotDuration); got != tc.ExpectedSlots {
+				t.Fatalf("expected %d slots got %d", tc.ExpectedSlots, got)
+			}
+			//slots generated never go past what can be numbered, 2025-06-02 is a monday
+			slots := GenerateSlots(clinic, session, tc.SlotDuration, time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC), time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC))
+			if len(slots) != min(tc.ExpectedSlots, utils.MaxSlotsPerSession) {
+				t.Fatalf("expected %d generated slots got %d", min(tc.ExpectedSlots, utils.MaxSlotsPerSession), len(slots))
+			}
+		})
+	}
+}
+
+func TestGenerateSlotsOnlineHours(t *testing.T) {
+	loc, _ := time.LoadLocation(utils.DefaultTimeZone)
+	clinic := models.Clinic{
+		SeasonTimings: []models.SeasonTimingDetails{
+			{Name: "Summer", Start: time.Date(2025, 4, 1, 10, 0, 0, 0, loc), End: time.Date(2025, 10, 31, 17, 0, 0, 0, loc)},
+		},
+	}
+	//doctor consults in clinic till it closes at 5 and online for an hour after
+	session := models.ClinicDetails{
+		StartTime:   time.Date(2025, 1, 1, 16, 0, 0, 0, loc),
+		EndTime:     time.Date(2025, 1, 1, 18, 0, 0, 0, loc),
+		WorkingDays: []string{"Mon"},
+		Mode:        utils.ConsultationModeBoth,
+		OnlineHours: []models.TimeWindow{{StartTime: time.Date(2025, 1, 1, 17, 0, 0, 0, loc), EndTime: time.Date(2025, 1, 1, 18, 0, 0, 0, loc)}},
+	}
+
+	day := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	slots := GenerateSlots(clinic, session, 30*time.Minute, day, day)
+	modes := []string{utils.ConsultationModeBoth, utils.ConsultationModeBoth, utils.ConsultationModeVideo, utils.ConsultationModeVideo}
+	if len(slots) != len(modes) {
+		t.Fatalf("expected %d slots got %+v", len(modes), slots)
+	}
+	for i, slot := range slots {
+		if slot.Mode != modes[i] {
+			t.Fatalf("expected slot %d to be %s got %s", slot.Index, modes[i], slot.Mode)
+		}
+	}
+
+	if inClinic := FilterMode(slots, utils.ConsultationModeInClinic); len(inClinic) != 2 {
+		t.Fatalf("expected 2 in clinic slots got %+v", inClinic)
+	}
+
+	//video consultations go on while clinic is closed for the season
+	winter := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	if slots := GenerateSlots(clinic, session, 30*time.Minute, winter, winter); len(slots) != 2 || slots[0].Mode != utils.ConsultationModeVideo {
+		t.Fatalf("expected only online hours outside season got %+v", slots)
+	}
+}
+
+func TestExcludeLeaves(t *testing.T) {
+	loc, _ := time.LoadLocation(utils.DefaultTimeZone)
+	session := models.ClinicDetails{
+		StartTime:   time.Date(2025, 1, 1, 9, 0, 0, 0, loc),
+		EndTime:     time.Date(2025, 1, 1, 11, 0, 0, 0, loc),
+		WorkingDays: []string{"Mon", "Tue", "Wed"},
+	}
+	slots := GenerateSlots(models.Clinic{}, session, 30*time.Minute, time.Date(2025, 6, 2, 0, 0, 0, 0, loc), time.Date(2025, 6, 4, 0, 0, 0, 0, loc))
+
+	leaves := []models.Leave{
+		//whole tuesday off
+		{Start: time.Date(2025, 6, 3, 0, 0, 0, 0, loc), End: time.Date(2025, 6, 4, 0, 0, 0, 0, loc)},
+		//late on wednesday, the slot running into the leave is blocked as well
+		{Start: time.Date(2025, 6, 4, 10, 15, 0, 0, loc), End: time.Date(2025, 6, 4, 12, 0, 0, 0, loc)},
+	}
+
+	available := ExcludeLeaves(slots, leaves)
+	if len(available) != 6 {
+		t.Fatalf("expected 6 slots got %d: %+v", len(available), available)
+	}
+	for _, slot := range available {
+		if slot.Start.Day() == 3 || (slot.Start.Day() == 4 && slot.Index > 1) {
+			t.Fatalf("slot %+v falls in a leave", slot)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Package controller provides HTTP handlers for unified search.
+package controller
+
+import (
+	interfaces "AlShifa/Search/Interfaces"
+	utils "AlShifa/Utils"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+type Controller struct {
+	Service interfaces.IService
+}
+
+func NewController(service interfaces.IService) *Controller {
+	return &Controller{
+		Service: service,
+	}
+}
+
+// Search ranks doctors, clinics and specialties matching q, type narrows it to one of them
+func (controller *Controller) Search(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	params := req.URL.Query()
+	query, ok := readQuery(res, params.Get("q"))
+	if !ok {
+		return
+	}
+
+	kind := params.Get("type")
+	switch kind {
+	case "", utils.SearchTypeDoctor, utils.SearchTypeClinic, utils.SearchTypeSpecialty:
+	default:
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(fmt.Errorf("unknown type %q", kind), 400, "Unable To Search", "Type Must Be doctor, clinic or specialty"))
+		return
+	}
+
+	limit, ok := readLimit(res, params.Get("limit"), utils.DefaultSearchResults, utils.MaxSearchResults)
+	if !ok {
+		return
+	}
+
+	results, appErr := controller.Service.Search(ctx, query, kind, limit)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Search Results Fetched Successfully", results))
+}
+
+// Suggest returns autocomplete entries for what is typed so far in the search box
+func (controller *Controller) Suggest(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	params := req.URL.Query()
+	query, ok := readQuery(res, params.Get("q"))
+	if !ok {
+		return
+	}
+	limit, ok := readLimit(res, params.Get("limit"), utils.DefaultSuggestions, utils.MaxSuggestions)
+	if !ok {
+		return
+	}
+
+	suggestions, appErr := controller.Service.Suggest(ctx, query, limit)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Suggestions Fetched Successfully", suggestions))
+}
+
+func readQuery(res http.ResponseWriter, query string) (string, bool) {
+	if len(query) > utils.MaxSearchQueryLength {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(fmt.Errorf("query longer than %d characters", utils.MaxSearchQueryLength), 400, "Unable To Search", "Search Query Too Long"))
+		return "", false
+	}
+	return query, true
+}
+
+func readLimit(res http.ResponseWriter, value string, fallback int, most int) (int, bool) {
+	if value == "" {
+		return fallback, true
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > most {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(fmt.Errorf("limit must be between 1 and %d", most), 400, "Unable To Search", "Invalid Limit"))
+		return 0, false
+	}
+	return limit, true
+}
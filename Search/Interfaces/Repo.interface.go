@@ -0,0 +1,19 @@
+// Package interfaces contains interfaces for Search module
+package interfaces
+
+import (
+	"AlShifa/Clinic/models"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IRepository defines the methods for loose coupling between the search repository and its implementation.
+type IRepository interface {
+	CreateIndexes(ctx context.Context) error
+	Vocabulary(ctx context.Context) ([]string, error)
+	TextSearchDoctors(ctx context.Context, search string, limit int64) ([]models.DoctorPublicDetails, error)
+	DoctorsWithSpecialties(ctx context.Context, specialtyIDs []primitive.ObjectID, limit int64) ([]models.DoctorPublicDetails, error)
+	TextSearchClinics(ctx context.Context, search string, limit int64) ([]models.Clinic, error)
+	TextSearchSpecialties(ctx context.Context, search string, limit int64) ([]models.Specialty, error)
+}
@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	searchStructs "AlShifa/Search/Structs"
+	structs "AlShifa/Structs"
+	"context"
+)
+
+// IService interface contains functions that search service layer must implement( to be used by handlers and other modules)
+type IService interface {
+	Search(ctx context.Context, query string, kind string, limit int) ([]searchStructs.SearchResult, *structs.IAppError)
+	Suggest(ctx context.Context, query string, limit int) ([]searchStructs.Suggestion, *structs.IAppError)
+}
@@ -0,0 +1,172 @@
+// Package repository provides the implementation of the repository layer for unified search in MongoDB.
+package repository
+
+import (
+	"AlShifa/Clinic/models"
+	scheduling "AlShifa/Scheduling"
+	interfaces "AlShifa/Search/Interfaces"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Repo is the MongoDB implementation of the search IRepository interface.
+type Repo struct {
+	DB *mongo.Database
+}
+
+// this ensures this repo implements all methods of repository interface
+var _ interfaces.IRepository = (*Repo)(nil)
+
+// NewRepository creates a new search repository with the specified database.
+func NewRepository(db *mongo.Database) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+// CreateIndexes creates text indexes searched by unified search. words are indexed as they are without stemming because
+// service corrects typos against the same words
+func (r *Repo) CreateIndexes(ctx context.Context) error {
+	indexes := map[string]mongo.IndexModel{
+		"Doctor": {
+			Keys:    bson.D{{Key: "name", Value: "text"}},
+			Options: options.Index().SetName("search").SetDefaultLanguage("none"),
+		},
+		"Clinic": {
+			Keys:    bson.D{{Key: "name", Value: "text"}, {Key: "address", Value: "text"}},
+			Options: options.Index().SetName("search").SetDefaultLanguage("none").SetWeights(bson.D{{Key: "name", Value: 3}, {Key: "address", Value: 1}}),
+		},
+		"Specialty": {
+			Keys:    bson.D{{Key: "name", Value: "text"}, {Key: "synonyms", Value: "text"}},
+			Options: options.Index().SetName("search").SetDefaultLanguage("none").SetWeights(bson.D{{Key: "name", Value: 3}, {Key: "synonyms", Value: 2}}),
+		},
+	}
+	for collection, index := range indexes {
+		if _, err := r.DB.Collection(collection).Indexes().CreateOne(ctx, index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Vocabulary returns names and addresses whose words can be searched, typos are corrected to these words
+func (r *Repo) Vocabulary(ctx context.Context) ([]string, error) {
+	fields := []struct {
+		collection string
+		field      string
+	}{
+		{"Doctor", "name"},
+		{"Clinic", "name"},
+		{"Clinic", "address"},
+		{"Specialty", "name"},
+		{"Specialty", "synonyms"},
+	}
+
+	vocabulary := []string{}
+	for _, f := range fields {
+		values, err := r.DB.Collection(f.collection).Distinct(ctx, f.field, bson.M{})
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range values {
+			if text, ok := value.(string); ok {
+				vocabulary = append(vocabulary, text)
+			}
+		}
+	}
+	return vocabulary, nil
+}
+
+// TextSearchDoctors returns public details of doctors whose name matches any word of search, best matches first
+func (r *Repo) TextSearchDoctors(ctx context.Context, search string, limit int64) ([]models.DoctorPublicDetails, error) {
+	return r.findDoctors(ctx, mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.M{"$text": bson.M{"$search": search}}}},
+		bson.D{{Key: "$sort", Value: bson.M{"score": bson.M{"$meta": "textScore"}}}},
+		bson.D{{Key: "$limit", Value: limit}},
+	})
+}
+
+// DoctorsWithSpecialties returns public details of doctors practising any of the specialties
+func (r *Repo) DoctorsWithSpecialties(ctx context.Context, specialtyIDs []primitive.ObjectID, limit int64) ([]models.DoctorPublicDetails, error) {
+	return r.findDoctors(ctx, mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.M{"specialties": bson.M{"$in": specialtyIDs}}}},
+		bson.D{{Key: "$limit", Value: limit}},
+	})
+}
+
+// findDoctors runs match stages followed by replacing specialty ids with their catalogue entries, private fields of
+// doctor are never returned
+func (r *Repo) findDoctors(ctx context.Context, pipeline mongo.Pipeline) ([]models.DoctorPublicDetails, error) {
+	pipeline = append(pipeline,
+		bson.D{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: "Specialty"},
+			{Key: "localField", Value: "specialties"},
+			{Key: "foreignField", Value: "_id"},
+			{Key: "as", Value: "specialties"},
+		}}},
+		bson.D{{Key: "$project", Value: bson.D{
+			{Key: "name", Value: 1},
+			{Key: "qualifications", Value: 1},
+			{Key: "workingAt", Value: 1},
+			{Key: "clinics", Value: 1},
+			{Key: "specialties", Value: 1},
+			{Key: "rating", Value: 1},
+		}}},
+	)
+
+	cursor, err := r.DB.Collection("Doctor").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	doctors := []models.DoctorPublicDetails{}
+	if err := cursor.All(ctx, &doctors); err != nil {
+		return nil, err
+	}
+	return doctors, nil
+}
+
+// TextSearchClinics returns active clinics whose name or address matches any word of search without details private to
+// owner
+func (r *Repo) TextSearchClinics(ctx context.Context, search string, limit int64) ([]models.Clinic, error) {
+	opts := options.Find().
+		SetSort(bson.M{"score": bson.M{"$meta": "textScore"}}).
+		SetLimit(limit).
+		SetProjection(bson.M{"registrationDate": 0, "ownerDetails": 0, "wallet": 0, "owner": 0, "statusHistory": 0})
+	filter := scheduling.ActiveClinicFilter("")
+	filter["$text"] = bson.M{"$search": search}
+	cursor, err := r.DB.Collection("Clinic").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	clinics := []models.Clinic{}
+	if err := cursor.All(ctx, &clinics); err != nil {
+		return nil, err
+	}
+	return clinics, nil
+}
+
+// TextSearchSpecialties returns catalogue entries whose name or synonyms match any word of search
+func (r *Repo) TextSearchSpecialties(ctx context.Context, search string, limit int64) ([]models.Specialty, error) {
+	opts := options.Find().
+		SetSort(bson.M{"score": bson.M{"$meta": "textScore"}}).
+		SetLimit(limit)
+	cursor, err := r.DB.Collection("Specialty").Find(ctx, bson.M{"$text": bson.M{"$search": search}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	specialties := []models.Specialty{}
+	if err := cursor.All(ctx, &specialties); err != nil {
+		return nil, err
+	}
+	return specialties, nil
+}
@@ -0,0 +1,28 @@
+// Package search provides one search box over doctors, clinics and specialties.
+package search
+
+import (
+	internals "AlShifa/Internals"
+	middleware "AlShifa/Middleware"
+	controller "AlShifa/Search/Controller"
+	repository "AlShifa/Search/Repository"
+	service "AlShifa/Search/Service"
+	utils "AlShifa/Utils"
+	"context"
+	"log"
+)
+
+func InitialiseSearchModule(app *internals.App) {
+	repository := repository.NewRepository(app.DB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout*5)
+	defer cancel()
+	if err := repository.CreateIndexes(ctx); err != nil {
+		log.Fatal("Failed to create search indexes", err)
+	}
+
+	service := service.NewSearchService(repository)
+	controller := controller.NewController(service)
+	app.Server.HandleFunc(utils.MakeURL("GET", "/search"), middleware.JwtAuthMiddleware(controller.Search))
+	app.Server.HandleFunc(utils.MakeURL("GET", "/search/suggest"), middleware.JwtAuthMiddleware(controller.Suggest))
+}
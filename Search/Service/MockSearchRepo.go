@@ -0,0 +1,62 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	interfaces "AlShifa/Search/Interfaces"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockSearchRepo struct {
+	CreateIndexesFn          func(ctx context.Context) error
+	VocabularyFn             func(ctx context.Context) ([]string, error)
+	TextSearchDoctorsFn      func(ctx context.Context, search string, limit int64) ([]models.DoctorPublicDetails, error)
+	DoctorsWithSpecialtiesFn func(ctx context.Context, specialtyIDs []primitive.ObjectID, limit int64) ([]models.DoctorPublicDetails, error)
+	TextSearchClinicsFn      func(ctx context.Context, search string, limit int64) ([]models.Clinic, error)
+	TextSearchSpecialtiesFn  func(ctx context.Context, search string, limit int64) ([]models.Specialty, error)
+}
+
+var _ interfaces.IRepository = (*MockSearchRepo)(nil)
+
+func (m *MockSearchRepo) CreateIndexes(ctx context.Context) error {
+	if m.CreateIndexesFn == nil {
+		panic("CreateIndexesFn not implemented inside mock")
+	}
+	return m.CreateIndexesFn(ctx)
+}
+
+func (m *MockSearchRepo) Vocabulary(ctx context.Context) ([]string, error) {
+	if m.VocabularyFn == nil {
+		panic("VocabularyFn not implemented inside mock")
+	}
+	return m.VocabularyFn(ctx)
+}
+
+func (m *MockSearchRepo) TextSearchDoctors(ctx context.Context, search string, limit int64) ([]models.DoctorPublicDetails, error) {
+	if m.TextSearchDoctorsFn == nil {
+		panic("TextSearchDoctorsFn not implemented inside mock")
+	}
+	return m.TextSearchDoctorsFn(ctx, search, limit)
+}
+
+func (m *MockSearchRepo) DoctorsWithSpecialties(ctx context.Context, specialtyIDs []primitive.ObjectID, limit int64) ([]models.DoctorPublicDetails, error) {
+	if m.DoctorsWithSpecialtiesFn == nil {
+		panic("DoctorsWithSpecialtiesFn not implemented inside mock")
+	}
+	return m.DoctorsWithSpecialtiesFn(ctx, specialtyIDs, limit)
+}
+
+func (m *MockSearchRepo) TextSearchClinics(ctx context.Context, search string, limit int64) ([]models.Clinic, error) {
+	if m.TextSearchClinicsFn == nil {
+		panic("TextSearchClinicsFn not implemented inside mock")
+	}
+	return m.TextSearchClinicsFn(ctx, search, limit)
+}
+
+func (m *MockSearchRepo) TextSearchSpecialties(ctx context.Context, search string, limit int64) ([]models.Specialty, error) {
+	if m.TextSearchSpecialtiesFn == nil {
+		panic("TextSearchSpecialtiesFn not implemented inside mock")
+	}
+	return m.TextSearchSpecialtiesFn(ctx, search, limit)
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	utils "AlShifa/Utils"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// field is text of a hit and how much a match in it counts
+type field struct {
+	text   string
+	weight float64
+}
+
+// tokenize splits text into lowercase words the way text index does
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// vocabularyWords turns indexed texts into sorted unique words
+func vocabularyWords(texts []string) []string {
+	words := []string{}
+	for _, text := range texts {
+		words = append(words, tokenize(text)...)
+	}
+	slices.Sort(words)
+	return slices.Compact(words)
+}
+
+// maxEdits is how many typos a word may have, short words have to be spelt right
+func maxEdits(word string) int {
+	switch length := len([]rune(word)); {
+	case length < 4:
+		return 0
+	case length < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance counts insertions, deletions, substitutions and swaps of neighbouring letters turning a into b
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	rows := make([][]int, len(ra)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(rb)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(ra)][len(rb)]
+}
+
+// typos returns edits between token and word if word is within the typos allowed for token
+func typos(token string, word string) (int, bool) {
+	allowed := maxEdits(token)
+	if allowed == 0 {
+		return 0, false
+	}
+	if diff := len([]rune(token)) - len([]rune(word)); diff > allowed || -diff > allowed {
+		return 0, false
+	}
+	edits := editDistance(token, word)
+	return edits, edits <= allowed
+}
+
+// expand returns words of query to search for. every token is kept, misspelt ones also search the closest words of
+// vocabulary and the last token also searches words it starts when prefix is set
+func expand(tokens []string, words []string, prefix bool) []string {
+	terms := []string{}
+	for i, token := range tokens {
+		terms = append(terms, token)
+
+		if _, found := slices.BinarySearch(words, token); !found {
+			closest, corrections := -1, []string{}
+			for _, word := range words {
+				edits, ok := typos(token, word)
+				if !ok {
+					continue
+				}
+				if closest == -1 || edits < closest {
+					closest, corrections = edits, corrections[:0]
+				}
+				if edits == closest {
+					corrections = append(corrections, word)
+				}
+			}
+			terms = append(terms, corrections...)
+		}
+
+		if prefix && i == len(tokens)-1 && len([]rune(token)) >= utils.MinPrefixLength {
+			start, _ := slices.BinarySearch(words, token)
+			for _, word := range words[start:min(start+utils.MaxPrefixExpansions, len(words))] {
+				if !strings.HasPrefix(word, token) {
+					break
+				}
+				terms = append(terms, word)
+			}
+		}
+	}
+
+	slices.Sort(terms)
+	return slices.Compact(terms)
+}
+
+// matchScore tells how well token matches word of a hit, exact words count most then completions then typos
+func matchScore(token string, word string, prefix bool) float64 {
+	if token == word {
+		return 1
+	}
+	if prefix && strings.HasPrefix(word, token) {
+		return 0.8
+	}
+	if edits, ok := typos(token, word); ok {
+		return 0.8 - 0.2*float64(edits)
+	}
+	return 0
+}
+
+// relevance ranks a hit for query tokens. every token adds its best match over fields, hits matching only some tokens
+// are scaled down and a primary field starting with the whole query gets a bonus so "city cl" puts City Clinic first
+func relevance(tokens []string, prefix bool, fields ...field) float64 {
+	if len(tokens) == 0 || len(fields) == 0 {
+		return 0
+	}
+
+	total, matched := 0.0, 0
+	for i, token := range tokens {
+		best := 0.0
+		for _, f := range fields {
+			for _, word := range tokenize(f.text) {
+				best = max(best, matchScore(token, word, prefix && i == len(tokens)-1)*f.weight)
+			}
+		}
+		if best > 0 {
+			matched++
+		}
+		total += best
+	}
+	if matched == 0 {
+		return 0
+	}
+
+	if strings.HasPrefix(strings.Join(tokenize(fields[0].text), " "), strings.Join(tokens, " ")) {
+		total += fields[0].weight
+	}
+	return total * float64(matched) / float64(len(tokens))
+}
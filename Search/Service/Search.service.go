@@ -0,0 +1,205 @@
+// Package service contains service layer implementation for search module
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	interfaces "AlShifa/Search/Interfaces"
+	searchStructs "AlShifa/Search/Structs"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	"cmp"
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SearchService struct {
+	Repo interfaces.IRepository
+
+	mu       sync.Mutex
+	words    []string // sorted words of names and addresses, typos are corrected to these
+	loadedAt time.Time
+}
+
+func NewSearchService(repo interfaces.IRepository) *SearchService {
+	return &SearchService{
+		Repo: repo,
+	}
+}
+
+// this ensures this service layer implements all methods of service layer interface
+var _ interfaces.IService = (*SearchService)(nil)
+
+// order of types when hits score the same
+var typeOrder = map[string]int{utils.SearchTypeSpecialty: 0, utils.SearchTypeDoctor: 1, utils.SearchTypeClinic: 2}
+
+// Search finds doctors, clinics and specialties matching query, misspelt words and the unfinished last word are matched
+// too. kind limits hits to one type, empty kind searches all of them
+func (service *SearchService) Search(ctx context.Context, query string, kind string, limit int) ([]searchStructs.SearchResult, *structs.IAppError) {
+	tokens := tokenize(query)
+	if len(tokens) == 0 {
+		return nil, utils.ReturnAppError(errors.New("empty search query"), http.StatusBadRequest, "Unable To Search", "Search Query Required")
+	}
+
+	words, err := service.vocabulary(ctx)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Search", "Server Error")
+	}
+	search := strings.Join(expand(tokens, words, true), " ")
+
+	results := []searchStructs.SearchResult{}
+	add := func(result searchStructs.SearchResult) {
+		if result.Score > 0 {
+			results = append(results, result)
+		}
+	}
+
+	if kind != utils.SearchTypeClinic {
+		specialties, err := service.Repo.TextSearchSpecialties(ctx, search, utils.SearchCandidates)
+		if err != nil {
+			return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Search", "Server Error")
+		}
+
+		//doctors are found by their name and by specialties matching the query
+		specialtyIDs := []primitive.ObjectID{}
+		for i := range specialties {
+			score := relevance(tokens, true, specialtyFields(specialties[i], 3)...)
+			if score == 0 {
+				continue
+			}
+			specialtyIDs = append(specialtyIDs, specialties[i].ID)
+			if kind == "" || kind == utils.SearchTypeSpecialty {
+				add(searchStructs.SearchResult{Type: utils.SearchTypeSpecialty, Score: score, Specialty: &specialties[i]})
+			}
+		}
+
+		if kind == "" || kind == utils.SearchTypeDoctor {
+			doctors, appErr := service.doctors(ctx, search, specialtyIDs)
+			if appErr != nil {
+				return nil, appErr
+			}
+			for i := range doctors {
+				fields := []field{{doctors[i].Name, 3}}
+				for _, specialty := range doctors[i].Specialties {
+					fields = append(fields, specialtyFields(specialty, 2)...)
+				}
+				add(searchStructs.SearchResult{Type: utils.SearchTypeDoctor, Score: relevance(tokens, true, fields...), Doctor: &doctors[i]})
+			}
+		}
+	}
+
+	if kind == "" || kind == utils.SearchTypeClinic {
+		clinics, err := service.Repo.TextSearchClinics(ctx, search, utils.SearchCandidates)
+		if err != nil {
+			return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Search", "Server Error")
+		}
+		for i := range clinics {
+			score := relevance(tokens, true, field{clinics[i].Name, 3}, field{clinics[i].Address, 1})
+			add(searchStructs.SearchResult{Type: utils.SearchTypeClinic, Score: score, Clinic: &clinics[i]})
+		}
+	}
+
+	slices.SortStableFunc(results, func(a, b searchStructs.SearchResult) int {
+		return cmp.Or(
+			cmp.Compare(b.Score, a.Score),
+			cmp.Compare(typeOrder[a.Type], typeOrder[b.Type]),
+			strings.Compare(resultName(a), resultName(b)),
+		)
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// Suggest returns best matches of what is typed so far in the search box
+func (service *SearchService) Suggest(ctx context.Context, query string, limit int) ([]searchStructs.Suggestion, *structs.IAppError) {
+	results, appErr := service.Search(ctx, query, "", limit)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	suggestions := make([]searchStructs.Suggestion, 0, len(results))
+	for _, result := range results {
+		suggestion := searchStructs.Suggestion{Type: result.Type, Label: resultName(result)}
+		switch result.Type {
+		case utils.SearchTypeDoctor:
+			suggestion.ID, suggestion.Detail = result.Doctor.ID, result.Doctor.Qualifications
+		case utils.SearchTypeClinic:
+			suggestion.ID, suggestion.Detail = result.Clinic.ID, result.Clinic.Address
+		case utils.SearchTypeSpecialty:
+			suggestion.ID = result.Specialty.ID
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, nil
+}
+
+// doctors returns doctors matching search by name or practising one of specialties, each doctor once
+func (service *SearchService) doctors(ctx context.Context, search string, specialtyIDs []primitive.ObjectID) ([]models.DoctorPublicDetails, *structs.IAppError) {
+	doctors, err := service.Repo.TextSearchDoctors(ctx, search, utils.SearchCandidates)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Search", "Server Error")
+	}
+	if len(specialtyIDs) == 0 {
+		return doctors, nil
+	}
+
+	bySpecialty, err := service.Repo.DoctorsWithSpecialties(ctx, specialtyIDs, utils.SearchCandidates)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Search", "Server Error")
+	}
+	for _, doctor := range bySpecialty {
+		if !slices.ContainsFunc(doctors, func(found models.DoctorPublicDetails) bool { return found.ID == doctor.ID }) {
+			doctors = append(doctors, doctor)
+		}
+	}
+	return doctors, nil
+}
+
+// vocabulary returns searchable words, they are reloaded every SearchVocabularyRefresh and old ones are kept if reload
+// fails. words added meanwhile are still found when spelt right
+func (service *SearchService) vocabulary(ctx context.Context) ([]string, error) {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if service.words != nil && time.Since(service.loadedAt) < utils.SearchVocabularyRefresh {
+		return service.words, nil
+	}
+	texts, err := service.Repo.Vocabulary(ctx)
+	if err != nil {
+		if service.words != nil {
+			return service.words, nil
+		}
+		return nil, err
+	}
+	service.words, service.loadedAt = vocabularyWords(texts), time.Now()
+	return service.words, nil
+}
+
+// specialtyFields weighs name of specialty over its synonyms
+func specialtyFields(specialty models.Specialty, weight float64) []field {
+	fields := []field{{specialty.Name, weight}}
+	for _, synonym := range specialty.Synonyms {
+		fields = append(fields, field{synonym, weight * 2 / 3})
+	}
+	return fields
+}
+
+func resultName(result searchStructs.SearchResult) string {
+	switch {
+	case result.Doctor != nil:
+		return result.Doctor.Name
+	case result.Clinic != nil:
+		return result.Clinic.Name
+	case result.Specialty != nil:
+		return result.Specialty.Name
+	}
+	return ""
+}
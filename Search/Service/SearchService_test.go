@@ -0,0 +1,139 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	utils "AlShifa/Utils"
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestExpand(t *testing.T) {
+	words := vocabularyWords([]string{"Cardiology", "Heart", "City Clinic", "Dermatology", "Dr Aamir Khan", "Khanyar"})
+
+	testCases := []struct {
+		Name     string
+		Query    string
+		Expected []string
+	}{
+		{Name: "Known words stay as they are", Query: "heart", Expected: []string{"heart"}},
+		{Name: "Typo is corrected", Query: "cardiolgy", Expected: []string{"cardiolgy", "cardiology"}},
+		{Name: "Swapped letters are one typo", Query: "haert", Expected: []string{"haert", "heart"}},
+		{Name: "Short words are not corrected", Query: "cty", Expected: []string{"cty"}},
+		{Name: "Last word is completed", Query: "city kha", Expected: []string{"city", "kha", "khan", "khanyar"}},
+		{Name: "Single letter is not completed", Query: "k", Expected: []string{"k"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if terms := expand(tokenize(tc.Query), words, true); !slices.Equal(terms, tc.Expected) {
+				t.Fatalf("expected %v got %v", tc.Expected, terms)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	cardiology := models.Specialty{ID: primitive.NewObjectID(), Name: "Cardiology", Synonyms: []string{"Heart"}}
+	cardiologist := models.DoctorPublicDetails{ID: primitive.NewObjectID(), Name: "Dr Aamir Khan", Specialties: []models.Specialty{cardiology}}
+	namesake := models.DoctorPublicDetails{ID: primitive.NewObjectID(), Name: "Dr Heart Lone"}
+	clinic := models.Clinic{ID: primitive.NewObjectID(), Name: "Heart Care Clinic", Address: "Khanyar Srinagar"}
+
+	var searched []string
+	mockRepo := &MockSearchRepo{
+		VocabularyFn: func(ctx context.Context) ([]string, error) {
+			return []string{cardiology.Name, "Heart", cardiologist.Name, namesake.Name, clinic.Name, clinic.Address}, nil
+		},
+		TextSearchSpecialtiesFn: func(ctx context.Context, search string, limit int64) ([]models.Specialty, error) {
+			searched = append(searched, search)
+			return []models.Specialty{cardiology}, nil
+		},
+		TextSearchDoctorsFn: func(ctx context.Context, search string, limit int64) ([]models.DoctorPublicDetails, error) {
+			return []models.DoctorPublicDetails{namesake}, nil
+		},
+		DoctorsWithSpecialtiesFn: func(ctx context.Context, specialtyIDs []primitive.ObjectID, limit int64) ([]models.DoctorPublicDetails, error) {
+			return []models.DoctorPublicDetails{cardiologist, namesake}, nil
+		},
+		TextSearchClinicsFn: func(ctx context.Context, search string, limit int64) ([]models.Clinic, error) {
+			return []models.Clinic{clinic}, nil
+		},
+	}
+	service := NewSearchService(mockRepo)
+
+	testCases := []struct {
+		Name               string
+		Query              string
+		Kind               string
+		Limit              int
+		Expected           []string
+		ExpectedStatusCode int
+	}{
+		{Name: "Specialty matches rank above doctors practising it", Query: "cardiology", Limit: 10, Expected: []string{"Cardiology", "Dr Aamir Khan"}},
+		{Name: "Misspelt query finds same hits", Query: "cardiolgy", Limit: 10, Expected: []string{"Cardiology", "Dr Aamir Khan"}},
+		{Name: "Name starting with query ranks first", Query: "heart c", Limit: 10, Expected: []string{"Heart Care Clinic", "Cardiology", "Dr Aamir Khan", "Dr Heart Lone"}},
+		{Name: "Type narrows hits", Query: "heart", Kind: utils.SearchTypeClinic, Limit: 10, Expected: []string{"Heart Care Clinic"}},
+		{Name: "Hits are cut at limit", Query: "heart", Limit: 1, Expected: []string{"Heart Care Clinic"}},
+		{Name: "Empty query is rejected", Query: " ,. ", Limit: 10, ExpectedStatusCode: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			results, appErr := service.Search(context.Background(), tc.Query, tc.Kind, tc.Limit)
+			if tc.ExpectedStatusCode != 0 {
+				if appErr == nil || appErr.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status %d got %v", tc.ExpectedStatusCode, appErr)
+				}
+				return
+			}
+			if appErr != nil {
+				t.Fatalf("expected no error but got %v", appErr)
+			}
+
+			names := []string{}
+			for _, result := range results {
+				names = append(names, resultName(result))
+			}
+			if !slices.Equal(names, tc.Expected) {
+				t.Fatalf("expected %v got %v", tc.Expected, names)
+			}
+		})
+	}
+
+	if !slices.ContainsFunc(searched, func(search string) bool {
+		return strings.Contains(search, "cardiology") && strings.Contains(search, "cardiolgy")
+	}) {
+		t.Fatalf("expected corrected word to be searched, searched %v", searched)
+	}
+}
+
+func TestSearchKeepsVocabularyWhenReloadFails(t *testing.T) {
+	loads := 0
+	mockRepo := &MockSearchRepo{
+		VocabularyFn: func(ctx context.Context) ([]string, error) {
+			loads++
+			if loads > 1 {
+				return nil, errors.New("connection reset")
+			}
+			return []string{"Dermatology"}, nil
+		},
+	}
+	service := NewSearchService(mockRepo)
+
+	if _, err := service.vocabulary(context.Background()); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	service.loadedAt = service.loadedAt.Add(-utils.SearchVocabularyRefresh)
+
+	words, err := service.vocabulary(context.Background())
+	if err != nil || !slices.Equal(words, []string{"dermatology"}) {
+		t.Fatalf("expected old words to be kept got %v %v", words, err)
+	}
+	if loads != 2 {
+		t.Fatalf("expected vocabulary to be reloaded once got %d loads", loads)
+	}
+}
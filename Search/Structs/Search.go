@@ -0,0 +1,25 @@
+// Package structs contains response structures of the search module
+package structs
+
+import (
+	"AlShifa/Clinic/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SearchResult is one ranked hit of unified search, only the entity of its type is set
+type SearchResult struct {
+	Type      string                      `json:"type"`
+	Score     float64                     `json:"score"`
+	Doctor    *models.DoctorPublicDetails `json:"doctor,omitempty"`
+	Clinic    *models.Clinic              `json:"clinic,omitempty"`
+	Specialty *models.Specialty           `json:"specialty,omitempty"`
+}
+
+// Suggestion is an autocomplete entry of the search box
+type Suggestion struct {
+	Type   string             `json:"type"`
+	ID     primitive.ObjectID `json:"id"`
+	Label  string             `json:"label"`
+	Detail string             `json:"detail,omitempty"` // address of clinic or qualifications of doctor
+}
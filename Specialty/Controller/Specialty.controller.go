@@ -0,0 +1,142 @@
+// Package controller provides HTTP handlers for the specialty catalogue.
+package controller
+
+import (
+	middleware "AlShifa/Middleware"
+	interfaces "AlShifa/Specialty/Interfaces"
+	specialtyStructs "AlShifa/Specialty/Structs"
+	validators "AlShifa/Specialty/Validators"
+	utils "AlShifa/Utils"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Controller struct {
+	Service interfaces.IService
+}
+
+func NewController(service interfaces.IService) *Controller {
+	return &Controller{
+		Service: service,
+	}
+}
+
+func (controller *Controller) GetSpecialties(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	specialties, appErr := controller.Service.GetSpecialties(ctx, req.URL.Query().Get("q"))
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Specialties Fetched Successfully", specialties))
+}
+
+func (controller *Controller) CreateSpecialty(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	var request specialtyStructs.SpecialtyRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Unable To Add Specialty", "Invalid Json"))
+		return
+	}
+
+	validationErrors := validators.ValidateSpecialty(&request)
+	if validationErrors != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(validationErrors, 400, "Unable To Add Specialty", "Invalid Details"))
+		return
+	}
+
+	specialty, appErr := controller.Service.CreateSpecialty(ctx, request)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusCreated, utils.ReturnAppSuccess(201, "Specialty Added Successfully", specialty))
+}
+
+func (controller *Controller) UpdateSpecialty(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	specialtyID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Specialty ID", "Invalid ID"))
+		return
+	}
+
+	var request specialtyStructs.SpecialtyRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Unable To Update Specialty", "Invalid Json"))
+		return
+	}
+
+	validationErrors := validators.ValidateSpecialty(&request)
+	if validationErrors != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(validationErrors, 400, "Unable To Update Specialty", "Invalid Details"))
+		return
+	}
+
+	specialty, appErr := controller.Service.UpdateSpecialty(ctx, specialtyID, request)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Specialty Updated Successfully", specialty))
+}
+
+func (controller *Controller) DeleteSpecialty(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	specialtyID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Specialty ID", "Invalid ID"))
+		return
+	}
+
+	if appErr := controller.Service.DeleteSpecialty(ctx, specialtyID); appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Specialty Deleted Successfully", nil))
+}
+
+func (controller *Controller) SetDoctorSpecialties(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	doctorID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	var request specialtyStructs.DoctorSpecialties
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Unable To Update Specialties", "Invalid Json"))
+		return
+	}
+
+	validationErrors := validators.ValidateDoctorSpecialties(&request)
+	if validationErrors != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(validationErrors, 400, "Unable To Update Specialties", "Invalid Details"))
+		return
+	}
+
+	if appErr := controller.Service.SetDoctorSpecialties(ctx, doctorID, request.Specialties); appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Specialties Updated Successfully", nil))
+}
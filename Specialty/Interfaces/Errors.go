@@ -0,0 +1,6 @@
+package interfaces
+
+import "errors"
+
+// ErrTermTaken is returned when name or a synonym of a specialty is already used by another specialty
+var ErrTermTaken = errors.New("specialty name or synonym already used")
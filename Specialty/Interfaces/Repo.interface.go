@@ -0,0 +1,20 @@
+// Package interfaces contains interfaces for Specialty module
+package interfaces
+
+import (
+	"AlShifa/Clinic/models"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IRepository defines the methods for loose coupling between the specialty repository and its implementation.
+type IRepository interface {
+	InsertSpecialty(ctx context.Context, specialty models.Specialty) error
+	UpdateSpecialty(ctx context.Context, specialty models.Specialty) error
+	DeleteSpecialty(ctx context.Context, specialtyID primitive.ObjectID) error
+	GetSpecialties(ctx context.Context, filter bson.M) ([]models.Specialty, error)
+	CountSpecialties(ctx context.Context, specialtyIDs []primitive.ObjectID) (int64, error)
+	SetDoctorSpecialties(ctx context.Context, doctorID primitive.ObjectID, specialtyIDs []primitive.ObjectID) error
+}
@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"AlShifa/Clinic/models"
+	specialtyStructs "AlShifa/Specialty/Structs"
+	structs "AlShifa/Structs"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IService interface contains functions that specialty service layer must implement( to be used by handlers and other modules)
+type IService interface {
+	CreateSpecialty(ctx context.Context, request specialtyStructs.SpecialtyRequest) (*models.Specialty, *structs.IAppError)
+	UpdateSpecialty(ctx context.Context, specialtyID primitive.ObjectID, request specialtyStructs.SpecialtyRequest) (*models.Specialty, *structs.IAppError)
+	DeleteSpecialty(ctx context.Context, specialtyID primitive.ObjectID) *structs.IAppError
+	GetSpecialties(ctx context.Context, term string) ([]models.Specialty, *structs.IAppError)
+	MatchSpecialties(ctx context.Context, term string) ([]primitive.ObjectID, *structs.IAppError)
+	SetDoctorSpecialties(ctx context.Context, doctorID primitive.ObjectID, specialtyIDs []primitive.ObjectID) *structs.IAppError
+}
@@ -0,0 +1,129 @@
+// Package repository provides the implementation of the repository layer for the specialty catalogue in MongoDB.
+package repository
+
+import (
+	"AlShifa/Clinic/models"
+	interfaces "AlShifa/Specialty/Interfaces"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Repo is the MongoDB implementation of the specialty IRepository interface.
+type Repo struct {
+	DB *mongo.Database
+}
+
+// this ensures this repo implements all methods of repository interface
+var _ interfaces.IRepository = (*Repo)(nil)
+
+// NewRepository creates a new specialty repository with the specified database.
+func NewRepository(db *mongo.Database) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+// CreateIndexes creates indexes specialty module relies on, terms are unique across the catalogue so a search term
+// resolves to a single specialty
+func (r *Repo) CreateIndexes(ctx context.Context) error {
+	_, err := r.DB.Collection("Specialty").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "terms", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.DB.Collection("Doctor").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "specialties", Value: 1}},
+	})
+	return err
+}
+
+func (r *Repo) InsertSpecialty(ctx context.Context, specialty models.Specialty) error {
+	_, err := r.DB.Collection("Specialty").InsertOne(ctx, specialty)
+	if mongo.IsDuplicateKeyError(err) {
+		return interfaces.ErrTermTaken
+	}
+	return err
+}
+
+// UpdateSpecialty replaces name and synonyms of specialty, returns mongo.ErrNoDocuments if there is no such specialty
+func (r *Repo) UpdateSpecialty(ctx context.Context, specialty models.Specialty) error {
+	res, err := r.DB.Collection("Specialty").UpdateOne(ctx, bson.M{"_id": specialty.ID}, bson.M{"$set": bson.M{
+		"name":      specialty.Name,
+		"synonyms":  specialty.Synonyms,
+		"terms":     specialty.Terms,
+		"updatedAt": specialty.UpdatedAt,
+	}})
+	if mongo.IsDuplicateKeyError(err) {
+		return interfaces.ErrTermTaken
+	}
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// DeleteSpecialty removes specialty from the catalogue and in the same transaction from every doctor who picked it
+func (r *Repo) DeleteSpecialty(ctx context.Context, specialtyID primitive.ObjectID) error {
+	session, err := r.DB.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	callback := func(sessCtx mongo.SessionContext) (any, error) {
+		res, err := r.DB.Collection("Specialty").DeleteOne(sessCtx, bson.M{"_id": specialtyID})
+		if err != nil {
+			return nil, err
+		}
+		if res.DeletedCount == 0 {
+			return nil, mongo.ErrNoDocuments
+		}
+		_, err = r.DB.Collection("Doctor").UpdateMany(sessCtx, bson.M{"specialties": specialtyID}, bson.M{"$pull": bson.M{"specialties": specialtyID}})
+		return nil, err
+	}
+
+	_, err = session.WithTransaction(ctx, callback)
+	return err
+}
+
+// GetSpecialties returns specialties matching filter sorted by name
+func (r *Repo) GetSpecialties(ctx context.Context, filter bson.M) ([]models.Specialty, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := r.DB.Collection("Specialty").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	specialties := []models.Specialty{}
+	if err := cursor.All(ctx, &specialties); err != nil {
+		return nil, err
+	}
+	return specialties, nil
+}
+
+func (r *Repo) CountSpecialties(ctx context.Context, specialtyIDs []primitive.ObjectID) (int64, error) {
+	return r.DB.Collection("Specialty").CountDocuments(ctx, bson.M{"_id": bson.M{"$in": specialtyIDs}})
+}
+
+// SetDoctorSpecialties replaces specialties of doctor, returns mongo.ErrNoDocuments if there is no such doctor
+func (r *Repo) SetDoctorSpecialties(ctx context.Context, doctorID primitive.ObjectID, specialtyIDs []primitive.ObjectID) error {
+	res, err := r.DB.Collection("Doctor").UpdateOne(ctx, bson.M{"_id": doctorID}, bson.M{"$set": bson.M{"specialties": specialtyIDs}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	interfaces "AlShifa/Specialty/Interfaces"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockSpecialtyRepo struct {
+	InsertSpecialtyFn      func(ctx context.Context, specialty models.Specialty) error
+	UpdateSpecialtyFn      func(ctx context.Context, specialty models.Specialty) error
+	DeleteSpecialtyFn      func(ctx context.Context, specialtyID primitive.ObjectID) error
+	GetSpecialtiesFn       func(ctx context.Context, filter bson.M) ([]models.Specialty, error)
+	CountSpecialtiesFn     func(ctx context.Context, specialtyIDs []primitive.ObjectID) (int64, error)
+	SetDoctorSpecialtiesFn func(ctx context.Context, doctorID primitive.ObjectID, specialtyIDs []primitive.ObjectID) error
+}
+
+var _ interfaces.IRepository = (*MockSpecialtyRepo)(nil)
+
+func (m *MockSpecialtyRepo) InsertSpecialty(ctx context.Context, specialty models.Specialty) error {
+	if m.InsertSpecialtyFn == nil {
+		panic("InsertSpecialtyFn not implemented inside mock")
+	}
+	return m.InsertSpecialtyFn(ctx, specialty)
+}
+
+func (m *MockSpecialtyRepo) UpdateSpecialty(ctx context.Context, specialty models.Specialty) error {
+	if m.UpdateSpecialtyFn == nil {
+		panic("UpdateSpecialtyFn not implemented inside mock")
+	}
+	return m.UpdateSpecialtyFn(ctx, specialty)
+}
+
+func (m *MockSpecialtyRepo) DeleteSpecialty(ctx context.Context, specialtyID primitive.ObjectID) error {
+	if m.DeleteSpecialtyFn == nil {
+		panic("DeleteSpecialtyFn not implemented inside mock")
+	}
+	return m.DeleteSpecialtyFn(ctx, specialtyID)
+}
+
+func (m *MockSpecialtyRepo) GetSpecialties(ctx context.Context, filter bson.M) ([]models.Specialty, error) {
+	if m.GetSpecialtiesFn == nil {
+		panic("GetSpecialtiesFn not implemented inside mock")
+	}
+	return m.GetSpecialtiesFn(ctx, filter)
+}
+
+func (m *MockSpecialtyRepo) CountSpecialties(ctx context.Context, specialtyIDs []primitive.ObjectID) (int64, error) {
+	if m.CountSpecialtiesFn == nil {
+		panic("CountSpecialtiesFn not implemented inside mock")
+	}
+	return m.CountSpecialtiesFn(ctx, specialtyIDs)
+}
+
+func (m *MockSpecialtyRepo) SetDoctorSpecialties(ctx context.Context, doctorID primitive.ObjectID, specialtyIDs []primitive.ObjectID) error {
+	if m.SetDoctorSpecialtiesFn == nil {
+		panic("SetDoctorSpecialtiesFn not implemented inside mock")
+	}
+	return m.SetDoctorSpecialtiesFn(ctx, doctorID, specialtyIDs)
+}
@@ -0,0 +1,178 @@
+// Package service contains service layer implementation for specialty module
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	interfaces "AlShifa/Specialty/Interfaces"
+	specialtyStructs "AlShifa/Specialty/Structs"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type SpecialtyService struct {
+	Repo interfaces.IRepository
+}
+
+func NewSpecialtyService(repo interfaces.IRepository) *SpecialtyService {
+	return &SpecialtyService{
+		Repo: repo,
+	}
+}
+
+// this ensures this service layer implements all methods of service layer interface
+var _ interfaces.IService = (*SpecialtyService)(nil)
+
+// CreateSpecialty adds specialty to the catalogue, name and synonyms cant be used by another specialty
+func (service *SpecialtyService) CreateSpecialty(ctx context.Context, request specialtyStructs.SpecialtyRequest) (*models.Specialty, *structs.IAppError) {
+	now := time.Now().UTC()
+	specialty := models.Specialty{
+		ID:        primitive.NewObjectID(),
+		Name:      strings.TrimSpace(request.Name),
+		Synonyms:  request.Synonyms,
+		Terms:     terms(request),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := service.Repo.InsertSpecialty(ctx, specialty); err != nil {
+		if errors.Is(err, interfaces.ErrTermTaken) {
+			return nil, utils.ReturnAppError(err, http.StatusConflict, "Unable To Add Specialty", "Name Or Synonym Is Used By Another Specialty")
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Add Specialty", "Server Error")
+	}
+	return &specialty, nil
+}
+
+// UpdateSpecialty replaces name and synonyms of specialty, doctors keep it as it is referenced by id
+func (service *SpecialtyService) UpdateSpecialty(ctx context.Context, specialtyID primitive.ObjectID, request specialtyStructs.SpecialtyRequest) (*models.Specialty, *structs.IAppError) {
+	specialty := models.Specialty{
+		ID:        specialtyID,
+		Name:      strings.TrimSpace(request.Name),
+		Synonyms:  request.Synonyms,
+		Terms:     terms(request),
+		UpdatedAt: time.Now().UTC(),
+	}
+
+	if err := service.Repo.UpdateSpecialty(ctx, specialty); err != nil {
+		switch {
+		case errors.Is(err, interfaces.ErrTermTaken):
+			return nil, utils.ReturnAppError(err, http.StatusConflict, "Unable To Update Specialty", "Name Or Synonym Is Used By Another Specialty")
+		case err == mongo.ErrNoDocuments:
+			return nil, utils.ReturnAppError(err, http.StatusNotFound, "Specialty Not Found", "Invalid Specialty")
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Update Specialty", "Server Error")
+	}
+
+	updated, err := service.Repo.GetSpecialties(ctx, bson.M{"_id": specialtyID})
+	if err != nil || len(updated) == 0 {
+		//update went through, only reading it back failed
+		return &specialty, nil
+	}
+	return &updated[0], nil
+}
+
+// DeleteSpecialty removes specialty from the catalogue and from doctors who picked it
+func (service *SpecialtyService) DeleteSpecialty(ctx context.Context, specialtyID primitive.ObjectID) *structs.IAppError {
+	if err := service.Repo.DeleteSpecialty(ctx, specialtyID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return utils.ReturnAppError(err, http.StatusNotFound, "Specialty Not Found", "Invalid Specialty")
+		}
+		return utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Delete Specialty", "Server Error")
+	}
+	return nil
+}
+
+// GetSpecialties lists the catalogue, only specialties matching term when it is given
+func (service *SpecialtyService) GetSpecialties(ctx context.Context, term string) ([]models.Specialty, *structs.IAppError) {
+	var (
+		specialties []models.Specialty
+		err         error
+	)
+	if utils.NormaliseTerm(term) == "" {
+		specialties, err = service.Repo.GetSpecialties(ctx, bson.M{})
+	} else {
+		specialties, err = service.match(ctx, term)
+	}
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Specialties", "Server Error")
+	}
+	return specialties, nil
+}
+
+// MatchSpecialties resolves what a patient searched for to ids of specialties, empty when nothing matches
+func (service *SpecialtyService) MatchSpecialties(ctx context.Context, term string) ([]primitive.ObjectID, *structs.IAppError) {
+	specialties, err := service.match(ctx, term)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Search Specialties", "Server Error")
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(specialties))
+	for _, specialty := range specialties {
+		ids = append(ids, specialty.ID)
+	}
+	return ids, nil
+}
+
+// SetDoctorSpecialties replaces specialties of doctor with the given catalogue entries
+func (service *SpecialtyService) SetDoctorSpecialties(ctx context.Context, doctorID primitive.ObjectID, specialtyIDs []primitive.ObjectID) *structs.IAppError {
+	ids := []primitive.ObjectID{}
+	for _, id := range specialtyIDs {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) > 0 {
+		count, err := service.Repo.CountSpecialties(ctx, ids)
+		if err != nil {
+			return utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Update Specialties", "Server Error")
+		}
+		if count != int64(len(ids)) {
+			return utils.ReturnAppError(errors.New("unknown specialty"), http.StatusBadRequest, "Unable To Update Specialties", "Specialty Not In Catalogue")
+		}
+	}
+
+	if err := service.Repo.SetDoctorSpecialties(ctx, doctorID, ids); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return utils.ReturnAppError(err, http.StatusNotFound, "Doctor Not Found", "Invalid Doctor")
+		}
+		return utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Update Specialties", "Server Error")
+	}
+	return nil
+}
+
+// match finds specialties whose name or synonym is term, falling back to those starting with it so derma finds Dermatology
+func (service *SpecialtyService) match(ctx context.Context, term string) ([]models.Specialty, error) {
+	term = utils.NormaliseTerm(term)
+	if term == "" {
+		return nil, nil
+	}
+
+	specialties, err := service.Repo.GetSpecialties(ctx, bson.M{"terms": term})
+	if err != nil || len(specialties) > 0 {
+		return specialties, err
+	}
+	return service.Repo.GetSpecialties(ctx, bson.M{"terms": bson.M{"$regex": "^" + regexp.QuoteMeta(term)}})
+}
+
+// terms returns normalised name and synonyms of request without repeats
+func terms(request specialtyStructs.SpecialtyRequest) []string {
+	terms := []string{utils.NormaliseTerm(request.Name)}
+	for _, synonym := range request.Synonyms {
+		if term := utils.NormaliseTerm(synonym); !slices.Contains(terms, term) {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
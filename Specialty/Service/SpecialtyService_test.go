@@ -0,0 +1,131 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	interfaces "AlShifa/Specialty/Interfaces"
+	specialtyStructs "AlShifa/Specialty/Structs"
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCreateSpecialty(t *testing.T) {
+	var inserted models.Specialty
+	mockRepo := &MockSpecialtyRepo{
+		InsertSpecialtyFn: func(ctx context.Context, specialty models.Specialty) error {
+			inserted = specialty
+			return nil
+		},
+	}
+
+	_, appErr := NewSpecialtyService(mockRepo).CreateSpecialty(context.Background(), specialtyStructs.SpecialtyRequest{
+		Name:     "Dermatology",
+		Synonyms: []string{"Skin", " skin ", "Skin  Specialist"},
+	})
+	if appErr != nil {
+		t.Fatalf("expected no error but got %v", appErr)
+	}
+	//terms are normalised and repeats dropped so they fit the unique index
+	if expected := []string{"dermatology", "skin", "skin specialist"}; !reflect.DeepEqual(inserted.Terms, expected) {
+		t.Fatalf("expected terms %v got %v", expected, inserted.Terms)
+	}
+
+	mockRepo.InsertSpecialtyFn = func(ctx context.Context, specialty models.Specialty) error {
+		return interfaces.ErrTermTaken
+	}
+	if _, appErr := NewSpecialtyService(mockRepo).CreateSpecialty(context.Background(), specialtyStructs.SpecialtyRequest{Name: "Skin"}); appErr == nil || appErr.StatusCode != http.StatusConflict {
+		t.Fatalf("expected conflict got %v", appErr)
+	}
+}
+
+func TestMatchSpecialties(t *testing.T) {
+	dermatology := models.Specialty{ID: primitive.NewObjectID(), Name: "Dermatology", Terms: []string{"dermatology", "skin"}}
+
+	testCases := []struct {
+		Name     string
+		Term     string
+		Expected int
+	}{
+		{Name: "Synonym matches exactly", Term: " SKIN ", Expected: 1},
+		{Name: "Start of a term matches", Term: "derma", Expected: 1},
+		{Name: "Unknown term matches nothing", Term: "cardio", Expected: 0},
+		{Name: "Empty term matches nothing", Term: "  ", Expected: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			mockRepo := &MockSpecialtyRepo{
+				GetSpecialtiesFn: func(ctx context.Context, filter bson.M) ([]models.Specialty, error) {
+					switch term := filter["terms"].(type) {
+					case string:
+						if term == "skin" {
+							return []models.Specialty{dermatology}, nil
+						}
+					case bson.M:
+						if term["$regex"] == "^derma" {
+							return []models.Specialty{dermatology}, nil
+						}
+					}
+					return []models.Specialty{}, nil
+				},
+			}
+
+			ids, appErr := NewSpecialtyService(mockRepo).MatchSpecialties(context.Background(), tc.Term)
+			if appErr != nil {
+				t.Fatalf("expected no error but got %v", appErr)
+			}
+			if len(ids) != tc.Expected || (tc.Expected == 1 && ids[0] != dermatology.ID) {
+				t.Fatalf("expected %d matches got %v", tc.Expected, ids)
+			}
+		})
+	}
+}
+
+func TestSetDoctorSpecialties(t *testing.T) {
+	first, second := primitive.NewObjectID(), primitive.NewObjectID()
+
+	testCases := []struct {
+		Name               string
+		Specialties        []primitive.ObjectID
+		Known              int64
+		ExpectedSaved      int
+		ExpectedStatusCode int
+	}{
+		{Name: "Repeated specialty is saved once", Specialties: []primitive.ObjectID{first, second, first}, Known: 2, ExpectedSaved: 2},
+		{Name: "Specialty outside catalogue is refused", Specialties: []primitive.ObjectID{first, second}, Known: 1, ExpectedStatusCode: http.StatusBadRequest},
+		{Name: "Doctor can clear specialties", Specialties: nil, ExpectedSaved: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			saved := -1
+			mockRepo := &MockSpecialtyRepo{
+				CountSpecialtiesFn: func(ctx context.Context, specialtyIDs []primitive.ObjectID) (int64, error) {
+					return tc.Known, nil
+				},
+				SetDoctorSpecialtiesFn: func(ctx context.Context, doctorID primitive.ObjectID, specialtyIDs []primitive.ObjectID) error {
+					saved = len(specialtyIDs)
+					return nil
+				},
+			}
+
+			appErr := NewSpecialtyService(mockRepo).SetDoctorSpecialties(context.Background(), primitive.NewObjectID(), tc.Specialties)
+			if tc.ExpectedStatusCode != 0 {
+				if appErr == nil || appErr.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status %d got %v", tc.ExpectedStatusCode, appErr)
+				}
+				return
+			}
+			if appErr != nil {
+				t.Fatalf("expected no error but got %v", appErr)
+			}
+			if saved != tc.ExpectedSaved {
+				t.Fatalf("expected %d specialties saved got %d", tc.ExpectedSaved, saved)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+// Package specialty manages the catalogue of medical specialties doctors pick from and patients search by.
+package specialty
+
+import (
+	internals "AlShifa/Internals"
+	middleware "AlShifa/Middleware"
+	controller "AlShifa/Specialty/Controller"
+	interfaces "AlShifa/Specialty/Interfaces"
+	repository "AlShifa/Specialty/Repository"
+	service "AlShifa/Specialty/Service"
+	utils "AlShifa/Utils"
+	"context"
+	"log"
+)
+
+// InitialiseSpecialtyModule registers specialty routes and returns the service so doctor search can resolve specialties
+func InitialiseSpecialtyModule(app *internals.App) interfaces.IService {
+	repository := repository.NewRepository(app.DB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout*5)
+	defer cancel()
+	if err := repository.CreateIndexes(ctx); err != nil {
+		log.Fatal("Failed to create specialty indexes", err)
+	}
+
+	service := service.NewSpecialtyService(repository)
+	controller := controller.NewController(service)
+	app.Server.HandleFunc(utils.MakeURL("GET", "/specialties"), middleware.JwtAuthMiddleware(controller.GetSpecialties))
+	app.Server.HandleFunc(utils.MakeURL("POST", "/specialty"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.CreateSpecialty, utils.RoleAdmin)))
+	app.Server.HandleFunc(utils.MakeURL("PUT", "/specialty/{id}"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.UpdateSpecialty, utils.RoleAdmin)))
+	app.Server.HandleFunc(utils.MakeURL("DELETE", "/specialty/{id}"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.DeleteSpecialty, utils.RoleAdmin)))
+	app.Server.HandleFunc(utils.MakeURL("PUT", "/doctor/specialties"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.SetDoctorSpecialties, utils.RoleDoctor)))
+	return service
+}
@@ -0,0 +1,15 @@
+// Package structs contains request structures of the specialty module
+package structs
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// SpecialtyRequest is sent by admin to add or edit a catalogue entry
+type SpecialtyRequest struct {
+	Name     string   `json:"name"`
+	Synonyms []string `json:"synonyms"`
+}
+
+// DoctorSpecialties replaces specialties of a doctor
+type DoctorSpecialties struct {
+	Specialties []primitive.ObjectID `json:"specialties"`
+}
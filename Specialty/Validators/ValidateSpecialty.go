@@ -0,0 +1,53 @@
+// Package validators contains validation functions for specialty module
+package validators
+
+import (
+	specialtyStructs "AlShifa/Specialty/Structs"
+	utils "AlShifa/Utils"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ValidateSpecialty validates catalogue entry sent by admin and returns a map of field errors
+func ValidateSpecialty(request *specialtyStructs.SpecialtyRequest) map[string]string {
+	errors := make(map[string]string)
+
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" {
+		errors["name"] = "name is required"
+	} else if utf8.RuneCountInString(request.Name) > utils.MaxSpecialtyTermLength {
+		errors["name"] = "name is too long"
+	}
+
+	if len(request.Synonyms) > utils.MaxSpecialtySynonyms {
+		errors["synonyms"] = fmt.Sprintf("at most %d synonyms are allowed", utils.MaxSpecialtySynonyms)
+	}
+	for i, synonym := range request.Synonyms {
+		request.Synonyms[i] = strings.TrimSpace(synonym)
+		if request.Synonyms[i] == "" || utf8.RuneCountInString(request.Synonyms[i]) > utils.MaxSpecialtyTermLength {
+			errors["synonyms"] = "synonyms cant be empty or too long"
+			break
+		}
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
+
+// ValidateDoctorSpecialties validates specialties picked by a doctor
+func ValidateDoctorSpecialties(request *specialtyStructs.DoctorSpecialties) map[string]string {
+	if len(request.Specialties) > utils.MaxDoctorSpecialties {
+		return map[string]string{"specialties": fmt.Sprintf("at most %d specialties can be picked", utils.MaxDoctorSpecialties)}
+	}
+	for _, id := range request.Specialties {
+		if id == primitive.NilObjectID {
+			return map[string]string{"specialties": "invalid specialty"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+// Package controller provides HTTP handlers for clinic staff.
+package controller
+
+import (
+	middleware "AlShifa/Middleware"
+	interfaces "AlShifa/Staff/Interfaces"
+	staffStructs "AlShifa/Staff/Structs"
+	validators "AlShifa/Staff/Validators"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Controller struct {
+	Service interfaces.IService
+}
+
+func NewController(service interfaces.IService) *Controller {
+	return &Controller{
+		Service: service,
+	}
+}
+
+func (controller *Controller) InviteStaff(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	ownerID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	clinicID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Clinic ID", "Invalid ID"))
+		return
+	}
+
+	var invite staffStructs.InviteRequest
+	if err := json.NewDecoder(req.Body).Decode(&invite); err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invite Failed", "Invalid Json"))
+		return
+	}
+
+	validationErrors := validators.ValidateInvite(&invite)
+	if validationErrors != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(validationErrors, 400, "Invite Failed", "Invalid Details"))
+		return
+	}
+
+	staff, appErr := controller.Service.InviteStaff(ctx, ownerID, clinicID, invite)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusCreated, utils.ReturnAppSuccess(201, "Staff Invited Successfully", staff))
+}
+
+func (controller *Controller) GetClinicStaff(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	ownerID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	clinicID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Clinic ID", "Invalid ID"))
+		return
+	}
+
+	staff, appErr := controller.Service.GetClinicStaff(ctx, ownerID, clinicID)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Staff Fetched Successfully", staff))
+}
+
+func (controller *Controller) JoinStaff(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	var request staffStructs.JoinRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Unable To Join", "Invalid Json"))
+		return
+	}
+
+	validationErrors := validators.ValidateJoin(&request)
+	if validationErrors != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(validationErrors, 400, "Unable To Join", "Invalid Details"))
+		return
+	}
+
+	staff, appErr := controller.Service.JoinStaff(ctx, request)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Joined Successfully", staff))
+}
+
+func (controller *Controller) LoginStaff(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	var loginDetails structs.LoginDetails
+	if err := json.NewDecoder(req.Body).Decode(&loginDetails); err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Login Failed", "Invalid Json"))
+		return
+	}
+
+	jwtToken, appErr := controller.Service.LoginStaff(ctx, loginDetails.Email, loginDetails.Password)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Login Successful", utils.JwtPrefix+jwtToken))
+}
+
+func (controller *Controller) SetStaffPermissions(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	ownerID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	staffID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Staff ID", "Invalid ID"))
+		return
+	}
+
+	var request staffStructs.PermissionsRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Update Failed", "Invalid Json"))
+		return
+	}
+
+	validationErrors := validators.ValidatePermissions(&request)
+	if validationErrors != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(validationErrors, 400, "Update Failed", "Invalid Details"))
+		return
+	}
+
+	staff, appErr := controller.Service.SetStaffPermissions(ctx, ownerID, staffID, request.Permissions)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Permissions Updated Successfully", staff))
+}
+
+func (controller *Controller) RevokeStaff(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	ownerID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	staffID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Staff ID", "Invalid ID"))
+		return
+	}
+
+	if appErr := controller.Service.RevokeStaff(ctx, ownerID, staffID); appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Staff Revoked Successfully", nil))
+}
@@ -0,0 +1,6 @@
+package interfaces
+
+import "errors"
+
+// ErrStaffExists is returned when email already belongs to invited or active staff
+var ErrStaffExists = errors.New("staff already exists")
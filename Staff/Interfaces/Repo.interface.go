@@ -0,0 +1,20 @@
+// Package interfaces contains interfaces for Staff module
+package interfaces
+
+import (
+	"AlShifa/Clinic/models"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IRepository defines the methods for loose coupling between the staff repository and its implementation.
+type IRepository interface {
+	CreateIndexes(ctx context.Context) error
+	InsertStaff(ctx context.Context, staff models.Staff) error
+	GetStaff(ctx context.Context, filter bson.M) (models.Staff, error)
+	GetStaffList(ctx context.Context, filter bson.M) ([]models.Staff, error)
+	UpdateStaff(ctx context.Context, filter bson.M, update bson.M) error
+	GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error)
+}
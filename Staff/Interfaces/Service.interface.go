@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"AlShifa/Clinic/models"
+	staffStructs "AlShifa/Staff/Structs"
+	structs "AlShifa/Structs"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IService interface contains functions that staff service layer must implement( to be used by handlers)
+type IService interface {
+	InviteStaff(ctx context.Context, ownerID primitive.ObjectID, clinicID primitive.ObjectID, invite staffStructs.InviteRequest) (*models.Staff, *structs.IAppError)
+	GetClinicStaff(ctx context.Context, ownerID primitive.ObjectID, clinicID primitive.ObjectID) ([]models.Staff, *structs.IAppError)
+	JoinStaff(ctx context.Context, request staffStructs.JoinRequest) (*models.Staff, *structs.IAppError)
+	LoginStaff(ctx context.Context, email string, password string) (string, *structs.IAppError)
+	SetStaffPermissions(ctx context.Context, ownerID primitive.ObjectID, staffID primitive.ObjectID, permissions []string) (*models.Staff, *structs.IAppError)
+	RevokeStaff(ctx context.Context, ownerID primitive.ObjectID, staffID primitive.ObjectID) *structs.IAppError
+	StaffAccess(ctx context.Context, staffID primitive.ObjectID) (primitive.ObjectID, []string, bool, error)
+}
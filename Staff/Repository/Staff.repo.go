@@ -0,0 +1,96 @@
+// Package repository provides the implementation of the repository layer for clinic staff in MongoDB.
+package repository
+
+import (
+	"AlShifa/Clinic/models"
+	interfaces "AlShifa/Staff/Interfaces"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Repo is the MongoDB implementation of the staff IRepository interface.
+type Repo struct {
+	DB *mongo.Database
+}
+
+// this ensures this repo implements all methods of repository interface
+var _ interfaces.IRepository = (*Repo)(nil)
+
+// NewRepository creates a new staff repository with the specified database.
+func NewRepository(db *mongo.Database) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+// CreateIndexes creates indexes staff module relies on, the partial unique index lets an email belong to only one
+// invited or active staff member while keeping revoked ones, invite tokens are looked up when staff join
+func (r *Repo) CreateIndexes(ctx context.Context) error {
+	_, err := r.DB.Collection("Staff").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"active": true}),
+		},
+		{
+			Keys:    bson.D{{Key: "inviteToken", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			Keys: bson.D{{Key: "clinic", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+	})
+	return err
+}
+
+func (r *Repo) InsertStaff(ctx context.Context, staff models.Staff) error {
+	_, err := r.DB.Collection("Staff").InsertOne(ctx, staff)
+	if mongo.IsDuplicateKeyError(err) {
+		return interfaces.ErrStaffExists
+	}
+	return err
+}
+
+func (r *Repo) GetStaff(ctx context.Context, filter bson.M) (models.Staff, error) {
+	var staff models.Staff
+	err := r.DB.Collection("Staff").FindOne(ctx, filter).Decode(&staff)
+	return staff, err
+}
+
+// GetStaffList returns staff matching filter, latest first
+func (r *Repo) GetStaffList(ctx context.Context, filter bson.M) ([]models.Staff, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	cursor, err := r.DB.Collection("Staff").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	staff := []models.Staff{}
+	if err := cursor.All(ctx, &staff); err != nil {
+		return nil, err
+	}
+	return staff, nil
+}
+
+// UpdateStaff applies update to staff matching filter, returns mongo.ErrNoDocuments if none matches so callers can
+// put the expected state in filter
+func (r *Repo) UpdateStaff(ctx context.Context, filter bson.M, update bson.M) error {
+	res, err := r.DB.Collection("Staff").UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *Repo) GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+	var clinic models.Clinic
+	err := r.DB.Collection("Clinic").FindOne(ctx, bson.M{"_id": clinicID}).Decode(&clinic)
+	return clinic, err
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	interfaces "AlShifa/Staff/Interfaces"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockStaffRepo struct {
+	CreateIndexesFn func(ctx context.Context) error
+	InsertStaffFn   func(ctx context.Context, staff models.Staff) error
+	GetStaffFn      func(ctx context.Context, filter bson.M) (models.Staff, error)
+	GetStaffListFn  func(ctx context.Context, filter bson.M) ([]models.Staff, error)
+	UpdateStaffFn   func(ctx context.Context, filter bson.M, update bson.M) error
+	GetClinicFn     func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error)
+}
+
+var _ interfaces.IRepository = (*MockStaffRepo)(nil)
+
+func (m *MockStaffRepo) CreateIndexes(ctx context.Context) error {
+	if m.CreateIndexesFn == nil {
+		panic("CreateIndexesFn not implemented inside mock")
+	}
+	return m.CreateIndexesFn(ctx)
+}
+
+func (m *MockStaffRepo) InsertStaff(ctx context.Context, staff models.Staff) error {
+	if m.InsertStaffFn == nil {
+		panic("InsertStaffFn not implemented inside mock")
+	}
+	return m.InsertStaffFn(ctx, staff)
+}
+
+func (m *MockStaffRepo) GetStaff(ctx context.Context, filter bson.M) (models.Staff, error) {
+	if m.GetStaffFn == nil {
+		panic("GetStaffFn not implemented inside mock")
+	}
+	return m.GetStaffFn(ctx, filter)
+}
+
+func (m *MockStaffRepo) GetStaffList(ctx context.Context, filter bson.M) ([]models.Staff, error) {
+	if m.GetStaffListFn == nil {
+		panic("GetStaffListFn not implemented inside mock")
+	}
+	return m.GetStaffListFn(ctx, filter)
+}
+
+func (m *MockStaffRepo) UpdateStaff(ctx context.Context, filter bson.M, update bson.M) error {
+	if m.UpdateStaffFn == nil {
+		panic("UpdateStaffFn not implemented inside mock")
+	}
+	return m.UpdateStaffFn(ctx, filter, update)
+}
+
+func (m *MockStaffRepo) GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+	if m.GetClinicFn == nil {
+		panic("GetClinicFn not implemented inside mock")
+	}
+	return m.GetClinicFn(ctx, clinicID)
+}
@@ -0,0 +1,235 @@
+// Package service contains service layer implementation for staff module
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	interfaces "AlShifa/Staff/Interfaces"
+	staffStructs "AlShifa/Staff/Structs"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type StaffService struct {
+	Repo interfaces.IRepository
+}
+
+func NewStaffService(repo interfaces.IRepository) *StaffService {
+	return &StaffService{
+		Repo: repo,
+	}
+}
+
+// this ensures this service layer implements all methods of service layer interface
+var _ interfaces.IService = (*StaffService)(nil)
+
+// InviteStaff invites someone to staff of clinic of owner, the returned staff carries the invite token owner hands over
+// to them to join with
+func (service *StaffService) InviteStaff(ctx context.Context, ownerID primitive.ObjectID, clinicID primitive.ObjectID, invite staffStructs.InviteRequest) (*models.Staff, *structs.IAppError) {
+	if appErr := service.ownClinic(ctx, ownerID, clinicID); appErr != nil {
+		return nil, appErr
+	}
+
+	token, err := utils.GenerateSecretToken(utils.StaffInviteTokenBytes)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Invite Failed", "Server Error")
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(utils.StaffInviteExpiry)
+	staff := models.Staff{
+		ID:              primitive.NewObjectID(),
+		Clinic:          clinicID,
+		InvitedBy:       ownerID,
+		Name:            invite.Name,
+		Email:           invite.Email,
+		Mobile:          invite.Mobile,
+		Role:            utils.RoleStaff,
+		Permissions:     invite.Permissions,
+		Status:          utils.StaffStatusInvited,
+		Active:          true,
+		InviteToken:     token,
+		InviteExpiresAt: &expiresAt,
+		CreatedAt:       now,
+	}
+	if err := service.Repo.InsertStaff(ctx, staff); err != nil {
+		if errors.Is(err, interfaces.ErrStaffExists) {
+			return nil, utils.ReturnAppError(err, http.StatusConflict, "Invite Failed", "Email Already Belongs To Staff")
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Invite Failed", "Server Error")
+	}
+	return &staff, nil
+}
+
+// GetClinicStaff returns staff of clinic of owner including revoked ones, invite tokens are not shown again
+func (service *StaffService) GetClinicStaff(ctx context.Context, ownerID primitive.ObjectID, clinicID primitive.ObjectID) ([]models.Staff, *structs.IAppError) {
+	if appErr := service.ownClinic(ctx, ownerID, clinicID); appErr != nil {
+		return nil, appErr
+	}
+
+	staff, err := service.Repo.GetStaffList(ctx, bson.M{"clinic": clinicID})
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Staff", "Server Error")
+	}
+	for i := range staff {
+		staff[i].InviteToken = ""
+	}
+	return staff, nil
+}
+
+// JoinStaff activates invited staff holding token with the password they chose, the token works only once
+func (service *StaffService) JoinStaff(ctx context.Context, request staffStructs.JoinRequest) (*models.Staff, *structs.IAppError) {
+	staff, err := service.Repo.GetStaff(ctx, bson.M{"inviteToken": request.Token, "status": utils.StaffStatusInvited})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.ReturnAppError(err, http.StatusNotFound, "Invite Not Found", "Invalid Invite Token")
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Join", "Server Error")
+	}
+	now := time.Now().UTC()
+	if staff.InviteExpiresAt == nil || !staff.InviteExpiresAt.After(now) {
+		return nil, utils.ReturnAppError(errors.New("invite expired"), http.StatusGone, "Invite Expired", "Ask Clinic Owner For A New Invite")
+	}
+
+	hashedPassword, err := utils.HashPasswordArgon2id(request.Password)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Join", "Server Error")
+	}
+
+	//token and status are matched again so an invite revoked or used meanwhile is not joined
+	err = service.Repo.UpdateStaff(ctx, bson.M{"_id": staff.ID, "inviteToken": request.Token, "status": utils.StaffStatusInvited}, bson.M{
+		"$set":   bson.M{"password": hashedPassword, "status": utils.StaffStatusActive, "joinedAt": now},
+		"$unset": bson.M{"inviteToken": "", "inviteExpiresAt": ""},
+	})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.ReturnAppError(err, http.StatusNotFound, "Invite Not Found", "Invalid Invite Token")
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Join", "Server Error")
+	}
+
+	staff.Status, staff.JoinedAt = utils.StaffStatusActive, &now
+	staff.InviteToken, staff.InviteExpiresAt = "", nil
+	return &staff, nil
+}
+
+// LoginStaff logs in staff who joined and are not revoked
+func (service *StaffService) LoginStaff(ctx context.Context, email string, password string) (string, *structs.IAppError) {
+	staff, err := service.Repo.GetStaff(ctx, bson.M{"email": strings.ToLower(strings.TrimSpace(email)), "status": utils.StaffStatusActive})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return "", utils.ReturnAppError(err, http.StatusNotFound, "Staff Not Found", "Invalid Email or Password")
+		}
+		return "", utils.ReturnAppError(err, http.StatusInternalServerError, "Login Failed", "Server Error")
+	}
+
+	passwordMatches, err := utils.VerifyPasswordArgon2id(password, staff.Password)
+	if err != nil || !passwordMatches {
+		return "", utils.ReturnAppError(err, http.StatusUnauthorized, "Unauthorized", "Invalid Email or Password")
+	}
+
+	token, err := utils.GenerateJWT(staff.ID.Hex(), staff.Role)
+	if err != nil {
+		return "", utils.ReturnAppError(err, http.StatusInternalServerError, "Login Failed", "Server Error")
+	}
+	return token, nil
+}
+
+// SetStaffPermissions replaces permissions of invited or active staff of clinic of owner, they apply from the next call
+func (service *StaffService) SetStaffPermissions(ctx context.Context, ownerID primitive.ObjectID, staffID primitive.ObjectID, permissions []string) (*models.Staff, *structs.IAppError) {
+	staff, appErr := service.staffOfOwner(ctx, ownerID, staffID)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if staff.Status == utils.StaffStatusRevoked {
+		return nil, utils.ReturnAppError(errors.New("staff is revoked"), http.StatusConflict, "Update Failed", "Staff Is Revoked")
+	}
+
+	if err := service.Repo.UpdateStaff(ctx, bson.M{"_id": staffID, "active": true}, bson.M{"$set": bson.M{"permissions": permissions}}); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.ReturnAppError(err, http.StatusConflict, "Update Failed", "Staff Is Revoked")
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Update Failed", "Server Error")
+	}
+
+	staff.Permissions = permissions
+	return &staff, nil
+}
+
+// RevokeStaff takes away access of staff of clinic of owner at once, a pending invite can no longer be used either.
+// revoked staff are kept so their past actions can be traced
+func (service *StaffService) RevokeStaff(ctx context.Context, ownerID primitive.ObjectID, staffID primitive.ObjectID) *structs.IAppError {
+	staff, appErr := service.staffOfOwner(ctx, ownerID, staffID)
+	if appErr != nil {
+		return appErr
+	}
+	if staff.Status == utils.StaffStatusRevoked {
+		return nil
+	}
+
+	err := service.Repo.UpdateStaff(ctx, bson.M{"_id": staffID}, bson.M{
+		"$set":   bson.M{"status": utils.StaffStatusRevoked, "revokedAt": time.Now().UTC()},
+		"$unset": bson.M{"active": "", "inviteToken": "", "inviteExpiresAt": ""},
+	})
+	if err != nil {
+		return utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Revoke Staff", "Server Error")
+	}
+	return nil
+}
+
+// StaffAccess returns the clinic staff member works at and their permissions for PermissionGuardMiddleware, ok is false
+// for staff who are yet to join or were revoked
+func (service *StaffService) StaffAccess(ctx context.Context, staffID primitive.ObjectID) (primitive.ObjectID, []string, bool, error) {
+	staff, err := service.Repo.GetStaff(ctx, bson.M{"_id": staffID})
+	if err == mongo.ErrNoDocuments {
+		return primitive.NilObjectID, nil, false, nil
+	}
+	if err != nil {
+		return primitive.NilObjectID, nil, false, err
+	}
+	if staff.Status != utils.StaffStatusActive {
+		return primitive.NilObjectID, nil, false, nil
+	}
+	return staff.Clinic, staff.Permissions, true, nil
+}
+
+// staffOfOwner fetches staff member and makes sure they are staff of clinic of owner, staff of others are reported as
+// not found
+func (service *StaffService) staffOfOwner(ctx context.Context, ownerID primitive.ObjectID, staffID primitive.ObjectID) (models.Staff, *structs.IAppError) {
+	staff, err := service.Repo.GetStaff(ctx, bson.M{"_id": staffID})
+	if err != nil && err != mongo.ErrNoDocuments {
+		return models.Staff{}, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Staff", "Server Error")
+	}
+	notFound := utils.ReturnAppError(errors.New("staff of someone else"), http.StatusNotFound, "Staff Not Found", "Invalid Staff")
+	if err == mongo.ErrNoDocuments {
+		return models.Staff{}, notFound
+	}
+	if appErr := service.ownClinic(ctx, ownerID, staff.Clinic); appErr != nil {
+		if appErr.StatusCode == http.StatusNotFound {
+			return models.Staff{}, notFound
+		}
+		return models.Staff{}, appErr
+	}
+	staff.InviteToken = ""
+	return staff, nil
+}
+
+// ownClinic makes sure clinic exists and belongs to owner, clinics of others are reported as not found
+func (service *StaffService) ownClinic(ctx context.Context, ownerID primitive.ObjectID, clinicID primitive.ObjectID) *structs.IAppError {
+	clinic, err := service.Repo.GetClinic(ctx, clinicID)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Clinic", "Server Error")
+	}
+	if err == mongo.ErrNoDocuments || clinic.Owner != ownerID {
+		return utils.ReturnAppError(errors.New("clinic of someone else"), http.StatusNotFound, "Clinic Not Found", "Invalid Clinic")
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	interfaces "AlShifa/Staff/Interfaces"
+	staffStructs "AlShifa/Staff/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestInviteStaff(t *testing.T) {
+	ownerID, clinicID := primitive.NewObjectID(), primitive.NewObjectID()
+
+	var inserted models.Staff
+	mockRepo := &MockStaffRepo{
+		GetClinicFn: func(ctx context.Context, id primitive.ObjectID) (models.Clinic, error) {
+			return models.Clinic{ID: id, Owner: ownerID}, nil
+		},
+		InsertStaffFn: func(ctx context.Context, staff models.Staff) error {
+			if staff.Email == "taken@alshifa.in" {
+				return interfaces.ErrStaffExists
+			}
+			inserted = staff
+			return nil
+		},
+	}
+
+	testCases := []struct {
+		Name               string
+		Owner              primitive.ObjectID
+		Email              string
+		ExpectedStatusCode int
+	}{
+		{Name: "Owner invites receptionist", Owner: ownerID, Email: "desk@alshifa.in"},
+		{Name: "Owner of another clinic", Owner: primitive.NewObjectID(), Email: "desk@alshifa.in", ExpectedStatusCode: http.StatusNotFound},
+		{Name: "Email already belongs to staff", Owner: ownerID, Email: "taken@alshifa.in", ExpectedStatusCode: http.StatusConflict},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			invite := staffStructs.InviteRequest{Name: "Front Desk", Email: tc.Email, Mobile: 9876543210, Permissions: []string{utils.PermissionManageQueue}}
+			staff, appErr := NewStaffService(mockRepo).InviteStaff(context.Background(), tc.Owner, clinicID, invite)
+			if tc.ExpectedStatusCode != 0 {
+				if appErr == nil || appErr.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status %d got %v", tc.ExpectedStatusCode, appErr)
+				}
+				return
+			}
+			if appErr != nil {
+				t.Fatalf("expected no error but got %v", appErr)
+			}
+			if staff.Status != utils.StaffStatusInvited || staff.Role != utils.RoleStaff || staff.InviteToken == "" || inserted.ID != staff.ID {
+				t.Fatalf("expected invited staff with a token got %+v", staff)
+			}
+		})
+	}
+}
+
+func TestJoinStaff(t *testing.T) {
+	future, past := time.Now().Add(time.Hour), time.Now().Add(-time.Hour)
+	invites := map[string]models.Staff{
+		"valid":   {ID: primitive.NewObjectID(), Status: utils.StaffStatusInvited, InviteToken: "valid", InviteExpiresAt: &future},
+		"expired": {ID: primitive.NewObjectID(), Status: utils.StaffStatusInvited, InviteToken: "expired", InviteExpiresAt: &past},
+	}
+
+	var updated bson.M
+	mockRepo := &MockStaffRepo{
+		GetStaffFn: func(ctx context.Context, filter bson.M) (models.Staff, error) {
+			staff, ok := invites[filter["inviteToken"].(string)]
+			if !ok {
+				return models.Staff{}, mongo.ErrNoDocuments
+			}
+			return staff, nil
+		},
+		UpdateStaffFn: func(ctx context.Context, filter bson.M, update bson.M) error {
+			updated = update
+			return nil
+		},
+	}
+
+	testCases := []struct {
+		Name               string
+		Token              string
+		ExpectedStatusCode int
+	}{
+		{Name: "Invite is accepted", Token: "valid"},
+		{Name: "Invite expired", Token: "expired", ExpectedStatusCode: http.StatusGone},
+		{Name: "Unknown token", Token: "guess", ExpectedStatusCode: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			staff, appErr := NewStaffService(mockRepo).JoinStaff(context.Background(), staffStructs.JoinRequest{Token: tc.Token, Password: "Reception@123"})
+			if tc.ExpectedStatusCode != 0 {
+				if appErr == nil || appErr.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status %d got %v", tc.ExpectedStatusCode, appErr)
+				}
+				return
+			}
+			if appErr != nil {
+				t.Fatalf("expected no error but got %v", appErr)
+			}
+			set := updated["$set"].(bson.M)
+			if staff.Status != utils.StaffStatusActive || staff.InviteToken != "" || set["password"] == "Reception@123" {
+				t.Fatalf("expected active staff with hashed password got %+v and update %v", staff, updated)
+			}
+		})
+	}
+}
+
+func TestStaffAccess(t *testing.T) {
+	clinicID := primitive.NewObjectID()
+	members := map[primitive.ObjectID]models.Staff{}
+	for _, status := range []string{utils.StaffStatusInvited, utils.StaffStatusActive, utils.StaffStatusRevoked} {
+		id := primitive.NewObjectID()
+		members[id] = models.Staff{ID: id, Clinic: clinicID, Status: status, Permissions: []string{utils.PermissionViewWallet}}
+	}
+
+	mockRepo := &MockStaffRepo{
+		GetStaffFn: func(ctx context.Context, filter bson.M) (models.Staff, error) {
+			staff, ok := members[filter["_id"].(primitive.ObjectID)]
+			if !ok {
+				return models.Staff{}, mongo.ErrNoDocuments
+			}
+			return staff, nil
+		},
+	}
+	service := NewStaffService(mockRepo)
+
+	//only staff who joined and are not revoked get access
+	for id, staff := range members {
+		clinic, permissions, ok, err := service.StaffAccess(context.Background(), id)
+		if err != nil {
+			t.Fatalf("expected no error but got %v", err)
+		}
+		if ok != (staff.Status == utils.StaffStatusActive) {
+			t.Fatalf("expected access of %s staff to be %v", staff.Status, !ok)
+		}
+		if ok && (clinic != clinicID || len(permissions) != 1) {
+			t.Fatalf("expected clinic and permissions of staff got %v %v", clinic, permissions)
+		}
+	}
+
+	if _, _, ok, err := service.StaffAccess(context.Background(), primitive.NewObjectID()); ok || err != nil {
+		t.Fatalf("expected unknown staff to have no access got %v %v", ok, err)
+	}
+}
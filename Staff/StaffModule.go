@@ -0,0 +1,36 @@
+// Package staff lets clinic owners invite receptionists and other staff to a clinic with the permissions they need
+// instead of sharing their own login.
+package staff
+
+import (
+	internals "AlShifa/Internals"
+	middleware "AlShifa/Middleware"
+	controller "AlShifa/Staff/Controller"
+	repository "AlShifa/Staff/Repository"
+	service "AlShifa/Staff/Service"
+	utils "AlShifa/Utils"
+	"context"
+	"log"
+)
+
+// InitialiseStaffModule registers staff routes and lets PermissionGuardMiddleware look up permissions of staff
+func InitialiseStaffModule(app *internals.App) {
+	repository := repository.NewRepository(app.DB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout*5)
+	defer cancel()
+	if err := repository.CreateIndexes(ctx); err != nil {
+		log.Fatal("Failed to create staff indexes", err)
+	}
+
+	service := service.NewStaffService(repository)
+	middleware.SetStaffAccess(service.StaffAccess)
+
+	controller := controller.NewController(service)
+	app.Server.HandleFunc(utils.MakeURL("POST", "/clinic/{id}/staff"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.InviteStaff, utils.RoleClinicOwner)))
+	app.Server.HandleFunc(utils.MakeURL("GET", "/clinic/{id}/staff"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.GetClinicStaff, utils.RoleClinicOwner)))
+	app.Server.HandleFunc(utils.MakeURL("PUT", "/staff/{id}/permissions"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.SetStaffPermissions, utils.RoleClinicOwner)))
+	app.Server.HandleFunc(utils.MakeURL("DELETE", "/staff/{id}"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.RevokeStaff, utils.RoleClinicOwner)))
+	app.Server.HandleFunc(utils.MakeURL("POST", "/staff/join"), controller.JoinStaff)
+	app.Server.HandleFunc(utils.MakeURL("POST", "/staff/login"), controller.LoginStaff)
+}
@@ -0,0 +1,21 @@
+// Package structs contains request structures of the staff module
+package structs
+
+// InviteRequest is sent by clinic owner to invite someone to staff of their clinic with the permissions they get there
+type InviteRequest struct {
+	Name        string   `json:"name"`
+	Email       string   `json:"email"`
+	Mobile      int64    `json:"mobile"`
+	Permissions []string `json:"permissions"`
+}
+
+// PermissionsRequest replaces permissions of a staff member
+type PermissionsRequest struct {
+	Permissions []string `json:"permissions"`
+}
+
+// JoinRequest is sent by an invited staff member with the token of their invite and the password they will log in with
+type JoinRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
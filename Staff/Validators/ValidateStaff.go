@@ -0,0 +1,119 @@
+// Package validators contains validation functions for staff module
+package validators
+
+import (
+	staffStructs "AlShifa/Staff/Structs"
+	utils "AlShifa/Utils"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// StaffPermissions lists what owners can let their staff do
+var StaffPermissions = []string{
+	utils.PermissionManageAppointments,
+	utils.PermissionManageQueue,
+	utils.PermissionViewWallet,
+	utils.PermissionEditClinic,
+}
+
+// ValidateInvite validates invite sent by clinic owner and returns a map of field errors
+func ValidateInvite(invite *staffStructs.InviteRequest) map[string]string {
+	errors := make(map[string]string)
+
+	invite.Name = strings.TrimSpace(invite.Name)
+	if invite.Name == "" {
+		errors["name"] = utils.NameMissingErrMsg
+	} else if len(invite.Name) > utils.MaxNameLength {
+		errors["name"] = utils.LongNameErrMsg
+	} else if len(invite.Name) < utils.MinNameLength {
+		errors["name"] = utils.ShortNameErrMsg
+	}
+
+	invite.Email = strings.ToLower(strings.TrimSpace(invite.Email))
+	if invite.Email == "" {
+		errors["email"] = utils.EmailMissingErrMsg
+	} else if len(invite.Email) > utils.MaxEmailLength {
+		errors["email"] = utils.LongEmailErrMsg
+	} else if !regexp.MustCompile(utils.EmailRegex).MatchString(invite.Email) {
+		errors["email"] = utils.InvalidEmailFormatMsg
+	}
+
+	if len(strconv.FormatInt(invite.Mobile, 10)) != utils.MobileLength {
+		errors["mobile"] = utils.InvalidMobileNumberMsg
+	}
+
+	if msg := validatePermissions(invite.Permissions); msg != "" {
+		errors["permissions"] = msg
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
+
+// ValidatePermissions validates permissions owner gives to a staff member
+func ValidatePermissions(request *staffStructs.PermissionsRequest) map[string]string {
+	if msg := validatePermissions(request.Permissions); msg != "" {
+		return map[string]string{"permissions": msg}
+	}
+	return nil
+}
+
+// ValidateJoin validates token and password sent by an invited staff member
+func ValidateJoin(request *staffStructs.JoinRequest) map[string]string {
+	errors := make(map[string]string)
+
+	request.Token = strings.TrimSpace(request.Token)
+	if request.Token == "" {
+		errors["token"] = "invite token is required"
+	}
+
+	password := request.Password
+	if password == "" {
+		errors["password"] = utils.PasswordMissingErrMsg
+	} else if len(password) < utils.MinPasswordLength {
+		errors["password"] = utils.ShortPasswordErrMsg
+	} else if len(password) > utils.MaxPasswordLength {
+		errors["password"] = utils.LongPasswordErrMsg
+	} else {
+		var hasUpper, hasLower, hasDigit, hasSpecial bool
+		for _, r := range password {
+			switch {
+			case unicode.IsUpper(r):
+				hasUpper = true
+			case unicode.IsLower(r):
+				hasLower = true
+			case unicode.IsDigit(r):
+				hasDigit = true
+			case unicode.IsPunct(r) || unicode.IsSymbol(r):
+				hasSpecial = true
+			}
+		}
+		if !(hasUpper && hasLower && hasDigit && hasSpecial) {
+			errors["password"] = utils.PasswordWeakErrMsg
+		}
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
+
+// validatePermissions returns what is wrong with permissions, empty when they are fine. staff without any permission
+// could do nothing so at least one is required
+func validatePermissions(permissions []string) string {
+	if len(permissions) == 0 {
+		return "at least one permission is required"
+	}
+	for _, permission := range permissions {
+		if !slices.Contains(StaffPermissions, permission) {
+			return "invalid permission " + permission
+		}
+	}
+	return ""
+}
@@ -0,0 +1,35 @@
+package teleconsult
+
+import (
+	utils "AlShifa/Utils"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LocalRoomPath is where rooms of the local provider are served
+const LocalRoomPath = "/teleconsult/room"
+
+// NewRoomProviderFromEnv builds the room provider named by TELECONSULT_PROVIDER, only local exists for now and it is the default.
+// links are signed with TELECONSULT_SECRET, TELECONSULT_BASE_URL makes them absolute
+func NewRoomProviderFromEnv() (RoomProvider, error) {
+	provider := strings.ToLower(strings.TrimSpace(os.Getenv("TELECONSULT_PROVIDER")))
+	if provider == "" {
+		provider = "local"
+	}
+
+	switch provider {
+	case "local":
+		secret := os.Getenv("TELECONSULT_SECRET")
+		if secret == "" {
+			return nil, errors.New("TELECONSULT_SECRET is required to sign join links")
+		}
+		return &LocalRoomProvider{
+			BaseURL: strings.TrimRight(os.Getenv("TELECONSULT_BASE_URL"), "/") + utils.MakeURL("", LocalRoomPath),
+			Secret:  []byte(secret),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown teleconsult provider %q", provider)
+	}
+}
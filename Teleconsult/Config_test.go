@@ -0,0 +1,38 @@
+package teleconsult
+
+import "testing"
+
+func TestNewRoomProviderFromEnv(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		Provider    string
+		Secret      string
+		ExpectedErr bool
+	}{
+		{Name: "Local provider signs with its own secret", Provider: "", Secret: "room-secret"},
+		{Name: "Secret of auth tokens isnt used for join links", Provider: "local", Secret: "", ExpectedErr: true},
+		{Name: "Unknown provider fails", Provider: "zoom", Secret: "room-secret", ExpectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Setenv("JWT_SECRET", "jwt-secret")
+			t.Setenv("TELECONSULT_PROVIDER", tc.Provider)
+			t.Setenv("TELECONSULT_SECRET", tc.Secret)
+
+			provider, err := NewRoomProviderFromEnv()
+			if tc.ExpectedErr {
+				if err == nil {
+					t.Fatalf("expected provider to fail but got %+v", provider)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected provider but got %v", err)
+			}
+			if local, ok := provider.(*LocalRoomProvider); !ok || string(local.Secret) != tc.Secret {
+				t.Fatalf("expected local provider signing with TELECONSULT_SECRET got %+v", provider)
+			}
+		})
+	}
+}
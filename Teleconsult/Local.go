@@ -0,0 +1,101 @@
+package teleconsult
+
+import (
+	"AlShifa/Clinic/models"
+	utils "AlShifa/Utils"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// LocalRoomProvider stands in for a real video provider during development. rooms are named after the appointment
+// and links point back to this server, which only checks the signature and tells who is joining which room
+type LocalRoomProvider struct {
+	BaseURL string // rooms are served under it, like http://localhost:8000/v1/teleconsult/room
+	Secret  []byte
+}
+
+// this ensures local room provider implements room provider interface
+var _ RoomProvider = (*LocalRoomProvider)(nil)
+
+// LocalRoom is what the local room page returns to a participant holding a valid link
+type LocalRoom struct {
+	Room        string    `json:"room"`
+	Participant string    `json:"participant"`
+	ValidTill   time.Time `json:"validTill"`
+}
+
+func (provider *LocalRoomProvider) CreateRoom(ctx context.Context, appointment models.Appointment) (string, error) {
+	return "local-" + appointment.ID.Hex(), nil
+}
+
+func (provider *LocalRoomProvider) JoinURL(room string, participant string, validFrom time.Time, validTill time.Time) (string, error) {
+	if !validTill.After(validFrom) {
+		return "", errors.New("join link must be valid for some time")
+	}
+
+	query := url.Values{}
+	query.Set("participant", participant)
+	query.Set("nbf", strconv.FormatInt(validFrom.Unix(), 10))
+	query.Set("exp", strconv.FormatInt(validTill.Unix(), 10))
+	query.Set("sig", provider.sign(room, participant, validFrom.Unix(), validTill.Unix()))
+	return provider.BaseURL + "/" + url.PathEscape(room) + "?" + query.Encode(), nil
+}
+
+// Verify checks that the link parameters were signed by this provider and that the link is open at now
+func (provider *LocalRoomProvider) Verify(room string, query url.Values, now time.Time) (LocalRoom, error) {
+	participant := query.Get("participant")
+	notBefore, err := strconv.ParseInt(query.Get("nbf"), 10, 64)
+	if err != nil {
+		return LocalRoom{}, ErrInvalidLink
+	}
+	expiry, err := strconv.ParseInt(query.Get("exp"), 10, 64)
+	if err != nil {
+		return LocalRoom{}, ErrInvalidLink
+	}
+
+	expected := provider.sign(room, participant, notBefore, expiry)
+	if !hmac.Equal([]byte(expected), []byte(query.Get("sig"))) {
+		return LocalRoom{}, ErrInvalidLink
+	}
+
+	if now.Before(time.Unix(notBefore, 0)) {
+		return LocalRoom{}, ErrLinkNotOpen
+	}
+	if !now.Before(time.Unix(expiry, 0)) {
+		return LocalRoom{}, ErrLinkExpired
+	}
+
+	return LocalRoom{Room: room, Participant: participant, ValidTill: time.Unix(expiry, 0).UTC()}, nil
+}
+
+// ServeRoom is the page join links of the local provider open, there is no video so it only reports the room joined
+func (provider *LocalRoomProvider) ServeRoom(res http.ResponseWriter, req *http.Request) {
+	room, err := provider.Verify(req.PathValue("room"), req.URL.Query(), time.Now())
+	switch {
+	case errors.Is(err, ErrLinkNotOpen):
+		_ = utils.WriteResponse(res, http.StatusForbidden, utils.ReturnAppError(err, http.StatusForbidden, "Room Is Not Open Yet", "Join Closer To Appointment Time"))
+		return
+	case errors.Is(err, ErrLinkExpired):
+		_ = utils.WriteResponse(res, http.StatusGone, utils.ReturnAppError(err, http.StatusGone, "Join Link Expired", "Request A New Link"))
+		return
+	case err != nil:
+		_ = utils.WriteResponse(res, http.StatusForbidden, utils.ReturnAppError(err, http.StatusForbidden, "Invalid Join Link", "Link Is Tampered Or Incomplete"))
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Joined Room Successfully", room))
+}
+
+// sign returns hex hmac of every link parameter so none of them can be changed
+func (provider *LocalRoomProvider) sign(room string, participant string, notBefore int64, expiry int64) string {
+	mac := hmac.New(sha256.New, provider.Secret)
+	mac.Write([]byte(room + "\n" + participant + "\n" + strconv.FormatInt(notBefore, 10) + "\n" + strconv.FormatInt(expiry, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
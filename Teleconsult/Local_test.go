@@ -0,0 +1,54 @@
+package teleconsult
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalJoinURL(t *testing.T) {
+	provider := &LocalRoomProvider{BaseURL: "http://localhost/v1/teleconsult/room", Secret: []byte("secret")}
+	validFrom := time.Date(2025, 3, 10, 9, 45, 0, 0, time.UTC)
+	validTill := validFrom.Add(time.Hour)
+
+	link, err := provider.JoinURL("local-room", ParticipantPatient, validFrom, validTill)
+	if err != nil {
+		t.Fatalf("expected join url got %v", err)
+	}
+	parsed, err := url.Parse(link)
+	if err != nil || !strings.HasSuffix(parsed.Path, "/local-room") {
+		t.Fatalf("expected link to room got %s", link)
+	}
+
+	testCases := []struct {
+		Name        string
+		Room        string
+		Query       func(url.Values)
+		At          time.Time
+		ExpectedErr error
+	}{
+		{Name: "Open link is accepted", Room: "local-room", Query: func(url.Values) {}, At: validFrom.Add(time.Minute)},
+		{Name: "Link is refused before it opens", Room: "local-room", Query: func(url.Values) {}, At: validFrom.Add(-time.Minute), ExpectedErr: ErrLinkNotOpen},
+		{Name: "Link is refused once expired", Room: "local-room", Query: func(url.Values) {}, At: validTill, ExpectedErr: ErrLinkExpired},
+		{Name: "Link of another room is refused", Room: "local-other", Query: func(url.Values) {}, At: validFrom, ExpectedErr: ErrInvalidLink},
+		{Name: "Patient cant join as doctor", Room: "local-room", Query: func(query url.Values) { query.Set("participant", ParticipantDoctor) }, At: validFrom, ExpectedErr: ErrInvalidLink},
+		{Name: "Expiry cant be extended", Room: "local-room", Query: func(query url.Values) { query.Set("exp", "9999999999") }, At: validFrom, ExpectedErr: ErrInvalidLink},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			query := parsed.Query()
+			tc.Query(query)
+
+			room, err := provider.Verify(tc.Room, query, tc.At)
+			if !errors.Is(err, tc.ExpectedErr) {
+				t.Fatalf("expected error %v got %v", tc.ExpectedErr, err)
+			}
+			if err == nil && room.Participant != ParticipantPatient {
+				t.Fatalf("expected patient to join got %+v", room)
+			}
+		})
+	}
+}
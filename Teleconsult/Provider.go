@@ -0,0 +1,28 @@
+// Package teleconsult creates video rooms for teleconsultation appointments and signed links to join them.
+package teleconsult
+
+import (
+	"AlShifa/Clinic/models"
+	"context"
+	"errors"
+	"time"
+)
+
+// participants of a video room, a join link is made for one of them
+const (
+	ParticipantDoctor  = "doctor"
+	ParticipantPatient = "patient"
+)
+
+var (
+	ErrInvalidLink = errors.New("join link is invalid")
+	ErrLinkNotOpen = errors.New("join link is not open yet")
+	ErrLinkExpired = errors.New("join link has expired")
+)
+
+// RoomProvider creates video rooms for appointments and links to join them. a join link lets the participant in
+// only between validFrom and validTill so a leaked link is useless once the consultation is over
+type RoomProvider interface {
+	CreateRoom(ctx context.Context, appointment models.Appointment) (string, error)
+	JoinURL(room string, participant string, validFrom time.Time, validTill time.Time) (string, error)
+}
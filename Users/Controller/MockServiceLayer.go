@@ -4,6 +4,7 @@ import (
 	structs "AlShifa/Structs"
 	interfaces "AlShifa/Users/Interfaces"
 	models "AlShifa/Users/Models"
+	userModuleStructs "AlShifa/Users/Structs"
 	"context"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -12,9 +13,12 @@ import (
 
 type MockUserService struct {
 	AddUserFn        func(ctx context.Context, user models.User) *structs.IAppError
+	SendMobileOTPFn  func(ctx context.Context, mobile int) *structs.IAppError
 	LoginUserFn      func(ctx context.Context, email, password string) (string, *structs.IAppError)
 	SearchUserFn     func(ctx context.Context, filter bson.M) (*models.User, *structs.IAppError)
 	SearchUserByIDFn func(ctx context.Context, userID primitive.ObjectID) (*models.User, *structs.IAppError)
+	GetHistoryFn     func(ctx context.Context, userID primitive.ObjectID, filter userModuleStructs.AppointmentHistoryFilter, cursor string) (*userModuleStructs.AppointmentHistoryPage, *structs.IAppError)
+	PatchUserFn      func(ctx context.Context, actorID primitive.ObjectID, actorRole string, userID primitive.ObjectID, patch []byte) (*models.User, *structs.IAppError)
 }
 
 var _ interfaces.IService = (*MockUserService)(nil)
@@ -26,6 +30,13 @@ func (m *MockUserService) AddUser(ctx context.Context, user models.User) *struct
 	return nil
 }
 
+func (m *MockUserService) SendMobileOTP(ctx context.Context, mobile int) *structs.IAppError {
+	if m.SendMobileOTPFn != nil {
+		return m.SendMobileOTPFn(ctx, mobile)
+	}
+	return nil
+}
+
 func (m *MockUserService) LoginUser(ctx context.Context, email, password string) (string, *structs.IAppError) {
 	if m.LoginUserFn != nil {
 		return m.LoginUserFn(ctx, email, password)
@@ -46,3 +57,17 @@ func (m *MockUserService) SearchUserByID(ctx context.Context, userID primitive.O
 	}
 	return nil, nil
 }
+
+func (m *MockUserService) GetAppointmentHistory(ctx context.Context, userID primitive.ObjectID, filter userModuleStructs.AppointmentHistoryFilter, cursor string) (*userModuleStructs.AppointmentHistoryPage, *structs.IAppError) {
+	if m.GetHistoryFn != nil {
+		return m.GetHistoryFn(ctx, userID, filter, cursor)
+	}
+	return nil, nil
+}
+
+func (m *MockUserService) PatchUser(ctx context.Context, actorID primitive.ObjectID, actorRole string, userID primitive.ObjectID, patch []byte) (*models.User, *structs.IAppError) {
+	if m.PatchUserFn != nil {
+		return m.PatchUserFn(ctx, actorID, actorRole, userID, patch)
+	}
+	return nil, nil
+}
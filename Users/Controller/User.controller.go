@@ -13,7 +13,11 @@ import (
 
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -66,6 +70,33 @@ func (controller *UserController) RegisterUser(res http.ResponseWriter, req *htt
 	})
 }
 
+// SendMobileOTP sends a verification code to mobile, registering with a mobile clinic staff saw as walk-in needs it
+func (controller *UserController) SendMobileOTP(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	var request userModuleStructs.MobileOTPRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, http.StatusBadRequest, "Unable To Send OTP", "Invalid Json"))
+		return
+	}
+	if request.Mobile <= 0 || len(strconv.Itoa(request.Mobile)) != utils.MobileLength {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(nil, http.StatusBadRequest, "Unable To Send OTP", "Mobile Number Must Be 10 Digits"))
+		return
+	}
+
+	if err := controller.Service.SendMobileOTP(ctx, request.Mobile); err != nil {
+		_ = utils.WriteResponse(res, err.StatusCode, err)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, structs.IAppSuccess{
+		Message:    "OTP Sent If Mobile Needs Verification",
+		Data:       nil,
+		StatusCode: http.StatusOK,
+	})
+}
+
 func (controller *UserController) SearchUser(res http.ResponseWriter, req *http.Request) {
 	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
 	defer cancel()
@@ -144,3 +175,96 @@ func (controller *UserController) LoginUser(res http.ResponseWriter, req *http.R
 	})
 
 }
+
+// GetAppointmentHistory returns a page of appointments of logged in user. status takes a comma separated list, from and to
+// are dates (both inclusive), when is upcoming or past and cursor is the nextCursor of the previous page
+func (controller *UserController) GetAppointmentHistory(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	userID, _, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	params := req.URL.Query()
+	filter := userModuleStructs.AppointmentHistoryFilter{When: params.Get("when")}
+	if value := params.Get("status"); value != "" {
+		for _, status := range strings.Split(value, ",") {
+			filter.Statuses = append(filter.Statuses, strings.TrimSpace(status))
+		}
+	}
+
+	//dates are days as patients see them
+	loc, err := time.LoadLocation(utils.DefaultTimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	if value := params.Get("from"); value != "" {
+		if filter.From, err = time.ParseInLocation(utils.DateLayout, value, loc); err != nil {
+			_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid From Date", "Date must be in YYYY-MM-DD format"))
+			return
+		}
+	}
+	if value := params.Get("to"); value != "" {
+		to, err := time.ParseInLocation(utils.DateLayout, value, loc)
+		if err != nil {
+			_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid To Date", "Date must be in YYYY-MM-DD format"))
+			return
+		}
+		filter.To = to.AddDate(0, 0, 1)
+	}
+
+	if value := params.Get("limit"); value != "" {
+		if filter.Limit, err = strconv.ParseInt(value, 10, 64); err != nil {
+			_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Limit", "Limit must be a number"))
+			return
+		}
+	}
+
+	validationErrors := validators.ValidateHistoryFilter(&filter)
+	if validationErrors != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(validationErrors, 400, "Unable To Fetch Appointments", "Invalid Filters"))
+		return
+	}
+
+	page, appErr := controller.Service.GetAppointmentHistory(ctx, userID, filter, params.Get("cursor"))
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "Fetched Successfully", page))
+}
+
+func (controller *UserController) PatchUser(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	actorID, actorRole, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid User ID", "Invalid ID"))
+		return
+	}
+
+	patch, err := io.ReadAll(http.MaxBytesReader(res, req.Body, utils.MaxPatchSize))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Update Failed", "Invalid Body"))
+		return
+	}
+
+	user, appErr := controller.Service.PatchUser(ctx, actorID, actorRole, userID, patch)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusOK, utils.ReturnAppSuccess(200, "User Updated Successfully", user))
+}
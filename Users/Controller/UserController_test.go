@@ -4,6 +4,8 @@ import (
 	middleware "AlShifa/Middleware"
 	structs "AlShifa/Structs"
 	models "AlShifa/Users/Models"
+	userModuleStructs "AlShifa/Users/Structs"
+	utils "AlShifa/Utils"
 	"context"
 	"fmt"
 	"net/http"
@@ -276,3 +278,42 @@ func TestSearchUser(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAppointmentHistory(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		Query              string
+		ExpectedStatusCode int
+	}{
+		{Name: "History with filters", Query: "?status=Completed,NoShow&from=2025-01-01&to=2025-01-31&limit=10", ExpectedStatusCode: http.StatusOK},
+		{Name: "Unknown status", Query: "?status=Done", ExpectedStatusCode: http.StatusBadRequest},
+		{Name: "Malformed date", Query: "?from=01-01-2025", ExpectedStatusCode: http.StatusBadRequest},
+		{Name: "Limit too large", Query: "?limit=1000", ExpectedStatusCode: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			mockService := &MockUserService{
+				GetHistoryFn: func(ctx context.Context, userID primitive.ObjectID, filter userModuleStructs.AppointmentHistoryFilter, cursor string) (*userModuleStructs.AppointmentHistoryPage, *structs.IAppError) {
+					//to date is inclusive so filter ends at start of the next day
+					if len(filter.Statuses) != 2 || filter.To.Sub(filter.From) != 31*24*time.Hour {
+						t.Errorf("unexpected filter %+v", filter)
+					}
+					return &userModuleStructs.AppointmentHistoryPage{}, nil
+				},
+			}
+
+			ctx := context.WithValue(context.Background(), middleware.ContextUserIDKey, primitive.NewObjectID().Hex())
+			ctx = context.WithValue(ctx, middleware.ContextUserRoleKey, utils.RoleUser)
+			req := httptest.NewRequestWithContext(ctx, "GET", "/user/appointments"+tc.Query, nil)
+			res := httptest.NewRecorder()
+
+			ReturnNewController(mockService).GetAppointmentHistory(res, req)
+
+			if res.Code != tc.ExpectedStatusCode {
+				fmt.Print(res.Body)
+				t.Fatalf("expected %d got %d", tc.ExpectedStatusCode, res.Code)
+			}
+		})
+	}
+}
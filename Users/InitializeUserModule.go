@@ -4,17 +4,35 @@ package users
 import (
 	internals "AlShifa/Internals"
 	middleware "AlShifa/Middleware"
+	notifications "AlShifa/Notifications"
 	controller "AlShifa/Users/Controller"
 	repository "AlShifa/Users/Repository"
 	service "AlShifa/Users/Service"
 	utils "AlShifa/Utils"
+	"context"
+	"log"
 )
 
 func InitialiseUserModule(app *internals.App) {
 	repository := repository.ReturnNewRepository(app.DB)
-	service := service.ReturnNewService(repository)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout*5)
+	defer cancel()
+	if err := repository.CreateIndexes(ctx); err != nil {
+		log.Fatal("Failed to create user indexes", err)
+	}
+
+	notifier, err := notifications.NewNotifierFromEnv(notifications.NewMongoContactBook(app.DB))
+	if err != nil {
+		log.Fatal("Failed to configure notifier", err)
+	}
+
+	service := service.ReturnNewService(repository, notifier)
 	controller := controller.ReturnNewController(service)
 	app.Server.HandleFunc(utils.MakeURL("POST", "/user/register"), controller.RegisterUser)
+	app.Server.HandleFunc(utils.MakeURL("POST", "/user/register/otp"), controller.SendMobileOTP)
 	app.Server.HandleFunc(utils.MakeURL("POST", "/user/login"), controller.LoginUser)
 	app.Server.HandleFunc(utils.MakeURL("GET", "/user/details"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.SearchUser, utils.RoleUser)))
+	app.Server.HandleFunc(utils.MakeURL("GET", "/user/appointments"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.GetAppointmentHistory, utils.RoleUser)))
+	app.Server.HandleFunc(utils.MakeURL("PATCH", "/user/{id}"), middleware.JwtAuthMiddleware(middleware.RoleGuardMiddleware(controller.PatchUser, utils.RoleUser, utils.RoleAdmin)))
 }
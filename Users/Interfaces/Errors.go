@@ -0,0 +1,6 @@
+package interfaces
+
+import "errors"
+
+// ErrMobileOTPSentRecently is returned when an otp was asked for the mobile within utils.MobileOTPResendAfter
+var ErrMobileOTPSentRecently = errors.New("otp sent to mobile recently")
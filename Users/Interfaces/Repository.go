@@ -3,7 +3,9 @@ package interfaces
 
 import (
 	models "AlShifa/Users/Models"
+	userModuleStructs "AlShifa/Users/Structs"
 	"context"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -13,4 +15,10 @@ type IRepository interface {
 	RegisterUser(ctx context.Context, user models.User) error
 	SearchUserByID(ctx context.Context, userID primitive.ObjectID) (*models.User, error)
 	SearchUser(ctx context.Context, filter bson.M) (*models.User, error)
+	ClaimWalkInUser(ctx context.Context, walkInID primitive.ObjectID, user models.User) error
+	ThrottleMobileOTP(ctx context.Context, mobile int, now time.Time) error
+	SetMobileOTP(ctx context.Context, walkInID primitive.ObjectID, otp models.MobileOTP) error
+	CountMobileOTPAttempt(ctx context.Context, walkInID primitive.ObjectID) error
+	GetAppointmentHistory(ctx context.Context, userID primitive.ObjectID, filter userModuleStructs.AppointmentHistoryFilter) ([]userModuleStructs.AppointmentHistoryItem, error)
+	UpdateUser(ctx context.Context, userID primitive.ObjectID, set bson.M) error
 }
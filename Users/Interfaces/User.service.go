@@ -3,6 +3,7 @@ package interfaces
 import (
 	structs "AlShifa/Structs"
 	models "AlShifa/Users/Models"
+	userModuleStructs "AlShifa/Users/Structs"
 	"context"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -11,7 +12,10 @@ import (
 
 type IService interface {
 	AddUser(ctx context.Context, user models.User) *structs.IAppError
+	SendMobileOTP(ctx context.Context, mobile int) *structs.IAppError
 	SearchUserByID(ctx context.Context, userID primitive.ObjectID) (*models.User, *structs.IAppError)
 	SearchUser(ctx context.Context, filter bson.M) (*models.User, *structs.IAppError)
 	LoginUser(ctx context.Context, email string, password string) (string, *structs.IAppError)
+	GetAppointmentHistory(ctx context.Context, userID primitive.ObjectID, filter userModuleStructs.AppointmentHistoryFilter, cursor string) (*userModuleStructs.AppointmentHistoryPage, *structs.IAppError)
+	PatchUser(ctx context.Context, actorID primitive.ObjectID, actorRole string, userID primitive.ObjectID, patch []byte) (*models.User, *structs.IAppError)
 }
@@ -21,4 +21,20 @@ type User struct {
 	Appointments     []models.Appointment `json:"appointments" bson:"appointments"`
 	Mobile           int                  `json:"mobile" bson:"mobile"`
 	Pincode          int                  `json:"pincode" bson:"pincode"`
+	// walk-in records are created by clinic staff from a name and mobile, registering with that mobile claims them
+	WalkIn    bool                `json:"walkIn,omitempty" bson:"walkIn,omitempty"`
+	CreatedBy *primitive.ObjectID `json:"createdBy,omitempty" bson:"createdBy,omitempty"`
+	ClaimedAt *time.Time          `json:"claimedAt,omitempty" bson:"claimedAt,omitempty"`
+	// only verified mobiles get visits registered by staff, claiming a walk-in record verifies the mobile with an otp
+	MobileVerified bool       `json:"mobileVerified,omitempty" bson:"mobileVerified,omitempty"`
+	MobileOTP      *MobileOTP `json:"-" bson:"mobileOTP,omitempty"`
+	OTP            string     `json:"otp,omitempty" bson:"-"` // sent with registration, never stored
+}
+
+// MobileOTP is the code sent to mobile of a walk-in record, only its hash is stored
+type MobileOTP struct {
+	CodeHash  string    `bson:"codeHash"`
+	SentAt    time.Time `bson:"sentAt"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+	Attempts  int       `bson:"attempts"`
 }
@@ -4,11 +4,15 @@ package repository
 import (
 	interfaces "AlShifa/Users/Interfaces"
 	models "AlShifa/Users/Models"
+	userModuleStructs "AlShifa/Users/Structs"
+	utils "AlShifa/Utils"
 	"context"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type Repository struct {
@@ -23,6 +27,23 @@ func ReturnNewRepository(db *mongo.Database) *Repository {
 
 var _ interfaces.IRepository = (*Repository)(nil)
 
+// CreateIndexes creates indexes user module relies on, appointment history is read page by page in date order of a user
+// and otp throttles of mobiles are dropped once they lapse
+func (repo *Repository) CreateIndexes(ctx context.Context) error {
+	_, err := repo.DB.Collection("Appointment").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user", Value: 1}, {Key: "appointmentDate", Value: -1}, {Key: "_id", Value: -1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = repo.DB.Collection("MobileOTPThrottle").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
 func (repo *Repository) RegisterUser(ctx context.Context, user models.User) error {
 	_, err := repo.DB.Collection("User").InsertOne(ctx, user)
 	if err != nil {
@@ -48,3 +69,146 @@ func (repo *Repository) SearchUser(ctx context.Context, filter bson.M) (*models.
 	}
 	return &user, nil
 }
+
+// ClaimWalkInUser turns walk-in record into the registered user, the id stays the same so appointments and tokens
+// booked for the walk-in remain with the user. returns mongo.ErrNoDocuments if the record was claimed meanwhile
+func (repo *Repository) ClaimWalkInUser(ctx context.Context, walkInID primitive.ObjectID, user models.User) error {
+	update := bson.M{
+		"$set": bson.M{
+			"name":           user.Name,
+			"email":          user.Email,
+			"password":       user.Password,
+			"age":            user.Age,
+			"address":        user.Address,
+			"pincode":        user.Pincode,
+			"role":           user.Role,
+			"claimedAt":      user.ClaimedAt,
+			"mobileVerified": true,
+		},
+		"$unset": bson.M{"walkIn": "", "mobileOTP": ""},
+	}
+	result, err := repo.DB.Collection("User").UpdateOne(ctx, bson.M{"_id": walkInID, "walkIn": true}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// ThrottleMobileOTP records an otp request for mobile, returns interfaces.ErrMobileOTPSentRecently if the last one
+// was within utils.MobileOTPResendAfter. it is kept per mobile whether or not a walk-in record has it
+func (repo *Repository) ThrottleMobileOTP(ctx context.Context, mobile int, now time.Time) error {
+	_, err := repo.DB.Collection("MobileOTPThrottle").UpdateOne(ctx,
+		bson.M{"_id": mobile, "expiresAt": bson.M{"$lte": now}},
+		bson.M{"$set": bson.M{"sentAt": now, "expiresAt": now.Add(utils.MobileOTPResendAfter)}},
+		options.Update().SetUpsert(true),
+	)
+	//a throttle which hasnt lapsed doesnt match the filter so the upsert collides with it
+	if mongo.IsDuplicateKeyError(err) {
+		return interfaces.ErrMobileOTPSentRecently
+	}
+	return err
+}
+
+// SetMobileOTP replaces otp of walk-in record, returns mongo.ErrNoDocuments if the record was claimed meanwhile
+func (repo *Repository) SetMobileOTP(ctx context.Context, walkInID primitive.ObjectID, otp models.MobileOTP) error {
+	result, err := repo.DB.Collection("User").UpdateOne(ctx, bson.M{"_id": walkInID, "walkIn": true}, bson.M{"$set": bson.M{"mobileOTP": otp}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// CountMobileOTPAttempt counts a wrong code tried against otp of walk-in record
+func (repo *Repository) CountMobileOTPAttempt(ctx context.Context, walkInID primitive.ObjectID) error {
+	_, err := repo.DB.Collection("User").UpdateOne(ctx, bson.M{"_id": walkInID, "walkIn": true, "mobileOTP": bson.M{"$exists": true}}, bson.M{"$inc": bson.M{"mobileOTP.attempts": 1}})
+	return err
+}
+
+// GetAppointmentHistory returns a page of appointments of user sorted by date with names of their doctor and clinic.
+// paging continues after filter.After using date and id so pages stay stable while new appointments are booked
+func (repo *Repository) GetAppointmentHistory(ctx context.Context, userID primitive.ObjectID, filter userModuleStructs.AppointmentHistoryFilter) ([]userModuleStructs.AppointmentHistoryItem, error) {
+	match := bson.M{"user": userID}
+	if len(filter.Statuses) > 0 {
+		match["status"] = bson.M{"$in": filter.Statuses}
+	}
+
+	dateRange := bson.M{}
+	if !filter.From.IsZero() {
+		dateRange["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		dateRange["$lt"] = filter.To
+	}
+	if len(dateRange) > 0 {
+		match["appointmentDate"] = dateRange
+	}
+
+	order, after := -1, "$lt"
+	if filter.Ascending {
+		order, after = 1, "$gt"
+	}
+	if filter.After != nil {
+		match["$or"] = bson.A{
+			bson.M{"appointmentDate": bson.M{after: filter.After.Date}},
+			bson.M{"appointmentDate": filter.After.Date, "_id": bson.M{after: filter.After.ID}},
+		}
+	}
+
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: match}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "appointmentDate", Value: order}, {Key: "_id", Value: order}}}},
+		bson.D{{Key: "$limit", Value: filter.Limit}},
+		//lookups run after limit so only the page is expanded
+		bson.D{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: "Doctor"},
+			{Key: "localField", Value: "doctor"},
+			{Key: "foreignField", Value: "_id"},
+			{Key: "as", Value: "doctorDetails"},
+		}}},
+		bson.D{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: "Clinic"},
+			{Key: "localField", Value: "clinic"},
+			{Key: "foreignField", Value: "_id"},
+			{Key: "as", Value: "clinicDetails"},
+		}}},
+		bson.D{{Key: "$addFields", Value: bson.D{
+			{Key: "doctorName", Value: bson.D{{Key: "$arrayElemAt", Value: bson.A{"$doctorDetails.name", 0}}}},
+			{Key: "clinicName", Value: bson.D{{Key: "$arrayElemAt", Value: bson.A{"$clinicDetails.name", 0}}}},
+			{Key: "clinicAddress", Value: bson.D{{Key: "$arrayElemAt", Value: bson.A{"$clinicDetails.address", 0}}}},
+		}}},
+		bson.D{{Key: "$project", Value: bson.D{
+			{Key: "doctorDetails", Value: 0},
+			{Key: "clinicDetails", Value: 0},
+		}}},
+	}
+
+	cursor, err := repo.DB.Collection("Appointment").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []userModuleStructs.AppointmentHistoryItem
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// UpdateUser sets fields of user, returns mongo.ErrNoDocuments if there is no such user
+func (repo *Repository) UpdateUser(ctx context.Context, userID primitive.ObjectID, set bson.M) error {
+	result, err := repo.DB.Collection("User").UpdateOne(ctx, bson.M{"_id": userID}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	structs "AlShifa/Structs"
+	userModuleStructs "AlShifa/Users/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetAppointmentHistory returns a page of appointments of user, cursor is the NextCursor of the previous page and empty for the first one
+func (s *Service) GetAppointmentHistory(ctx context.Context, userID primitive.ObjectID, filter userModuleStructs.AppointmentHistoryFilter, cursor string) (*userModuleStructs.AppointmentHistoryPage, *structs.IAppError) {
+	if cursor != "" {
+		after, err := decodeHistoryCursor(cursor)
+		if err != nil {
+			return nil, utils.ReturnAppError(err, http.StatusBadRequest, "Invalid Cursor", "Cursor Is Malformed")
+		}
+		filter.After = &after
+	}
+
+	now := time.Now().UTC()
+	switch filter.When {
+	case utils.HistoryUpcoming:
+		if filter.From.Before(now) {
+			filter.From = now
+		}
+		filter.Ascending = true
+	case utils.HistoryPast:
+		if filter.To.IsZero() || filter.To.After(now) {
+			filter.To = now
+		}
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = utils.DefaultHistoryPageSize
+	}
+	limit := filter.Limit
+	//one extra appointment tells if there is a next page
+	filter.Limit++
+
+	appointments, err := s.repo.GetAppointmentHistory(ctx, userID, filter)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Appointments", "Server Error")
+	}
+
+	page := &userModuleStructs.AppointmentHistoryPage{Appointments: appointments}
+	if int64(len(appointments)) > limit {
+		page.Appointments = appointments[:limit]
+		last := page.Appointments[limit-1]
+		page.NextCursor = encodeHistoryCursor(userModuleStructs.HistoryCursor{Date: last.AppointmentDate, ID: last.ID})
+	}
+	if page.Appointments == nil {
+		page.Appointments = []userModuleStructs.AppointmentHistoryItem{}
+	}
+	return page, nil
+}
+
+// encodeHistoryCursor makes an opaque cursor out of date (in milliseconds as stored by mongo) and id of an appointment
+func encodeHistoryCursor(cursor userModuleStructs.HistoryCursor) string {
+	raw := strconv.FormatInt(cursor.Date.UnixMilli(), 10) + ":" + cursor.ID.Hex()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeHistoryCursor(cursor string) (userModuleStructs.HistoryCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return userModuleStructs.HistoryCursor{}, err
+	}
+
+	millis, hex, found := strings.Cut(string(raw), ":")
+	if !found {
+		return userModuleStructs.HistoryCursor{}, errors.New("cursor without id")
+	}
+	unixMilli, err := strconv.ParseInt(millis, 10, 64)
+	if err != nil {
+		return userModuleStructs.HistoryCursor{}, err
+	}
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return userModuleStructs.HistoryCursor{}, err
+	}
+	return userModuleStructs.HistoryCursor{Date: time.UnixMilli(unixMilli).UTC(), ID: id}, nil
+}
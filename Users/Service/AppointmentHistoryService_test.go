@@ -0,0 +1,92 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	userModuleStructs "AlShifa/Users/Structs"
+	utils "AlShifa/Utils"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGetAppointmentHistory(t *testing.T) {
+	userID := primitive.NewObjectID()
+	start := time.Date(2025, 3, 10, 4, 30, 0, 0, time.UTC)
+	var history []userModuleStructs.AppointmentHistoryItem
+	for i := 0; i < 5; i++ {
+		history = append(history, userModuleStructs.AppointmentHistoryItem{
+			Appointment: models.Appointment{ID: primitive.NewObjectID(), User: userID, AppointmentDate: start.AddDate(0, 0, -i)},
+			DoctorName:  "Dr Saqlain",
+		})
+	}
+
+	//mock repo pages through history like the repo does, newest first after the cursor
+	mockRepo := &MockUserRepo{
+		GetHistoryFn: func(ctx context.Context, userID primitive.ObjectID, filter userModuleStructs.AppointmentHistoryFilter) ([]userModuleStructs.AppointmentHistoryItem, error) {
+			var page []userModuleStructs.AppointmentHistoryItem
+			for _, item := range history {
+				if filter.After != nil && !item.AppointmentDate.Before(filter.After.Date) {
+					continue
+				}
+				if int64(len(page)) < filter.Limit {
+					page = append(page, item)
+				}
+			}
+			return page, nil
+		},
+	}
+	service := ReturnNewService(mockRepo, nil)
+
+	var seen []primitive.ObjectID
+	cursor := ""
+	for pages := 0; pages < 3; pages++ {
+		page, err := service.GetAppointmentHistory(context.Background(), userID, userModuleStructs.AppointmentHistoryFilter{Limit: 2}, cursor)
+		if err != nil {
+			t.Fatalf("expected page got %v", err)
+		}
+		for _, item := range page.Appointments {
+			seen = append(seen, item.ID)
+		}
+		cursor = page.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+
+	if len(seen) != len(history) || cursor != "" {
+		t.Fatalf("expected all %d appointments over pages got %d with cursor %q", len(history), len(seen), cursor)
+	}
+	for i := range history {
+		if seen[i] != history[i].ID {
+			t.Fatalf("expected appointment %d to be %s got %s", i, history[i].ID.Hex(), seen[i].Hex())
+		}
+	}
+
+	if _, err := service.GetAppointmentHistory(context.Background(), userID, userModuleStructs.AppointmentHistoryFilter{}, "not-a-cursor"); err == nil || err.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected bad request for malformed cursor got %v", err)
+	}
+}
+
+func TestGetAppointmentHistoryUpcoming(t *testing.T) {
+	var got userModuleStructs.AppointmentHistoryFilter
+	mockRepo := &MockUserRepo{
+		GetHistoryFn: func(ctx context.Context, userID primitive.ObjectID, filter userModuleStructs.AppointmentHistoryFilter) ([]userModuleStructs.AppointmentHistoryItem, error) {
+			got = filter
+			return nil, nil
+		},
+	}
+
+	page, err := ReturnNewService(mockRepo, nil).GetAppointmentHistory(context.Background(), primitive.NewObjectID(), userModuleStructs.AppointmentHistoryFilter{When: utils.HistoryUpcoming}, "")
+	if err != nil {
+		t.Fatalf("expected page got %v", err)
+	}
+	if page.Appointments == nil || page.NextCursor != "" {
+		t.Fatalf("expected empty last page got %+v", page)
+	}
+	if !got.Ascending || time.Since(got.From) > time.Minute || got.Limit != utils.DefaultHistoryPageSize+1 {
+		t.Fatalf("expected upcoming appointments soonest first got %+v", got)
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	notifications "AlShifa/Notifications"
+	structs "AlShifa/Structs"
+	interfaces "AlShifa/Users/Interfaces"
+	models "AlShifa/Users/Models"
+	utils "AlShifa/Utils"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SendMobileOTP sends a code to mobile when clinic staff registered a walk-in with it, registering with that mobile
+// needs the code to claim the record. requests are throttled per mobile and failures after the lookup are only logged
+// so the answer is the same whether or not there is a record and mobiles cant be probed
+func (s *Service) SendMobileOTP(ctx context.Context, mobile int) *structs.IAppError {
+	now := time.Now()
+	if err := s.repo.ThrottleMobileOTP(ctx, mobile, now); err != nil {
+		if err == interfaces.ErrMobileOTPSentRecently {
+			return utils.ReturnAppError(err, http.StatusTooManyRequests, "Unable To Send OTP", "Wait A Minute Before Requesting Another OTP")
+		}
+		return utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Send OTP", "Server Error")
+	}
+
+	walkIn, err := s.repo.SearchUser(ctx, bson.M{"mobile": mobile, "walkIn": true})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		return utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Send OTP", "Server Error")
+	}
+	if walkIn == nil {
+		return nil
+	}
+
+	if err := s.sendOTPTo(ctx, walkIn.ID, now); err != nil {
+		log.Println("failed to send otp to mobile of walk-in record:", err)
+	}
+	return nil
+}
+
+// sendOTPTo stores hash of a new code on walk-in record and sends the code to its mobile
+func (s *Service) sendOTPTo(ctx context.Context, walkInID primitive.ObjectID, now time.Time) error {
+	code, err := generateOTP(utils.MobileOTPDigits)
+	if err != nil {
+		return err
+	}
+	codeHash, err := utils.HashPasswordArgon2id(code)
+	if err != nil {
+		return err
+	}
+
+	otp := models.MobileOTP{CodeHash: codeHash, SentAt: now, ExpiresAt: now.Add(utils.MobileOTPValidity)}
+	if err := s.repo.SetMobileOTP(ctx, walkInID, otp); err != nil {
+		//claimed between the lookup and now, nothing left to verify
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		return err
+	}
+
+	//contact book finds the mobile of the walk-in record itself
+	return s.notifier.Notify(ctx, notifications.Message{
+		Recipient: walkInID,
+		Subject:   "Verify your mobile",
+		Body:      fmt.Sprintf("%s is your AlShifa verification code, it is valid for %d minutes", code, int(utils.MobileOTPValidity.Minutes())),
+	})
+}
+
+// verifyMobileOTP checks code sent with registration against the otp of walk-in record, wrong codes are counted
+func (s *Service) verifyMobileOTP(ctx context.Context, walkIn models.User, code string) *structs.IAppError {
+	if code == "" {
+		return utils.ReturnAppError(errors.New("otp missing"), http.StatusForbidden, "Mobile Verification Required", "Request An OTP For This Mobile And Register With It")
+	}
+	otp := walkIn.MobileOTP
+	if otp == nil || time.Now().After(otp.ExpiresAt) {
+		return utils.ReturnAppError(errors.New("otp expired"), http.StatusForbidden, "Mobile Verification Failed", "OTP Expired, Request A New One")
+	}
+	if otp.Attempts >= utils.MaxMobileOTPAttempts {
+		return utils.ReturnAppError(errors.New("too many attempts"), http.StatusTooManyRequests, "Mobile Verification Failed", "Too Many Wrong Attempts, Request A New OTP")
+	}
+
+	ok, err := utils.VerifyPasswordArgon2id(code, otp.CodeHash)
+	if err != nil {
+		return utils.ReturnAppError(err, http.StatusInternalServerError, "Registration Failed", "Server Error")
+	}
+	if !ok {
+		if err := s.repo.CountMobileOTPAttempt(ctx, walkIn.ID); err != nil {
+			return utils.ReturnAppError(err, http.StatusInternalServerError, "Registration Failed", "Server Error")
+		}
+		return utils.ReturnAppError(errors.New("wrong otp"), http.StatusForbidden, "Mobile Verification Failed", "Invalid OTP")
+	}
+	return nil
+}
+
+// generateOTP returns a code of n random digits, leading zeros included
+func generateOTP(n int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+	value, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", n, value), nil
+}
@@ -3,7 +3,9 @@ package service
 import (
 	interfaces "AlShifa/Users/Interfaces"
 	models "AlShifa/Users/Models"
+	userModuleStructs "AlShifa/Users/Structs"
 	"context"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -14,6 +16,12 @@ type MockUserRepo struct {
 	LoginUserFn      func(ctx context.Context, email string, password string) (string, error)
 	SearchUserFn     func(ctx context.Context, filter bson.M) (*models.User, error)
 	SearchUserByIDFn func(ctx context.Context, userID primitive.ObjectID) (*models.User, error)
+	ClaimWalkInFn    func(ctx context.Context, walkInID primitive.ObjectID, user models.User) error
+	ThrottleOTPFn    func(ctx context.Context, mobile int, now time.Time) error
+	SetMobileOTPFn   func(ctx context.Context, walkInID primitive.ObjectID, otp models.MobileOTP) error
+	CountAttemptFn   func(ctx context.Context, walkInID primitive.ObjectID) error
+	GetHistoryFn     func(ctx context.Context, userID primitive.ObjectID, filter userModuleStructs.AppointmentHistoryFilter) ([]userModuleStructs.AppointmentHistoryItem, error)
+	UpdateUserFn     func(ctx context.Context, userID primitive.ObjectID, set bson.M) error
 }
 
 var _ interfaces.IRepository = (*MockUserRepo)(nil)
@@ -45,3 +53,45 @@ func (m *MockUserRepo) SearchUserByID(ctx context.Context, userID primitive.Obje
 	}
 	return m.SearchUserByIDFn(ctx, userID)
 }
+
+func (m *MockUserRepo) ClaimWalkInUser(ctx context.Context, walkInID primitive.ObjectID, user models.User) error {
+	if m.ClaimWalkInFn == nil {
+		panic("ClaimWalkInUser not implemented inside mock")
+	}
+	return m.ClaimWalkInFn(ctx, walkInID, user)
+}
+
+func (m *MockUserRepo) ThrottleMobileOTP(ctx context.Context, mobile int, now time.Time) error {
+	if m.ThrottleOTPFn == nil {
+		panic("ThrottleOTPFn not implemented inside mock")
+	}
+	return m.ThrottleOTPFn(ctx, mobile, now)
+}
+
+func (m *MockUserRepo) SetMobileOTP(ctx context.Context, walkInID primitive.ObjectID, otp models.MobileOTP) error {
+	if m.SetMobileOTPFn == nil {
+		panic("SetMobileOTPFn not implemented inside mock")
+	}
+	return m.SetMobileOTPFn(ctx, walkInID, otp)
+}
+
+func (m *MockUserRepo) CountMobileOTPAttempt(ctx context.Context, walkInID primitive.ObjectID) error {
+	if m.CountAttemptFn == nil {
+		panic("CountAttemptFn not implemented inside mock")
+	}
+	return m.CountAttemptFn(ctx, walkInID)
+}
+
+func (m *MockUserRepo) GetAppointmentHistory(ctx context.Context, userID primitive.ObjectID, filter userModuleStructs.AppointmentHistoryFilter) ([]userModuleStructs.AppointmentHistoryItem, error) {
+	if m.GetHistoryFn == nil {
+		panic("GetHistoryFn not implemented inside mock")
+	}
+	return m.GetHistoryFn(ctx, userID, filter)
+}
+
+func (m *MockUserRepo) UpdateUser(ctx context.Context, userID primitive.ObjectID, set bson.M) error {
+	if m.UpdateUserFn == nil {
+		panic("UpdateUserFn not implemented inside mock")
+	}
+	return m.UpdateUserFn(ctx, userID, set)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	structs "AlShifa/Structs"
+	models "AlShifa/Users/Models"
+	validators "AlShifa/Users/Validators"
+	utils "AlShifa/Utils"
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// PatchUser applies json merge patch on profile of user and validates the merged result the same way registration does,
+// users can patch only themselves and admin anyone
+func (s *Service) PatchUser(ctx context.Context, actorID primitive.ObjectID, actorRole string, userID primitive.ObjectID, patch []byte) (*models.User, *structs.IAppError) {
+	if actorRole != utils.RoleAdmin && actorID != userID {
+		return nil, utils.ReturnAppError(errors.New("profile of someone else"), http.StatusForbidden, "Update Failed", "Cannot Update Someone Else")
+	}
+
+	user, err := s.repo.SearchUserByID(ctx, userID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.ReturnAppError(err, http.StatusNotFound, "User Not Found", "User Doesnt Exist")
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Update Failed", "Server Error")
+	}
+
+	fields, forbidden, err := utils.MergePatch(user, patch, validators.UserPatchFields[actorRole])
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusBadRequest, "Update Failed", "Invalid Json")
+	}
+	if forbidden != nil {
+		return nil, utils.ReturnAppError(forbidden, http.StatusForbidden, "Update Failed", "Fields Cannot Be Changed")
+	}
+
+	//password is stored hashed and isnt patched here so it would never pass the registration rules
+	validationErrors := validators.ValidateUser(user)
+	delete(validationErrors, "password")
+	if len(validationErrors) > 0 {
+		return nil, utils.ReturnAppError(validationErrors, http.StatusBadRequest, "Update Failed", "Invalid Details")
+	}
+
+	if slices.Contains(fields, "email") || slices.Contains(fields, "mobile") {
+		_, err := s.repo.SearchUser(ctx, bson.M{"_id": bson.M{"$ne": userID}, "$or": []bson.M{{"email": user.Email}, {"mobile": user.Mobile}}})
+		if err == nil {
+			return nil, utils.ReturnAppError(errors.New("user already exists"), http.StatusConflict, "This Email or Mobile Already Exists", "Duplicate Email or Mobile")
+		}
+		if err != mongo.ErrNoDocuments {
+			return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Update Failed", "Server Error")
+		}
+	}
+
+	//verification was of the old mobile, staff shouldnt register visits against the new one until it is verified
+	if slices.Contains(fields, "mobile") && user.MobileVerified {
+		user.MobileVerified = false
+		fields = append(fields, "mobileVerified")
+	}
+
+	if len(fields) > 0 {
+		set, err := utils.PatchSet(user, fields)
+		if err != nil {
+			return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Update Failed", "Server Error")
+		}
+		if err := s.repo.UpdateUser(ctx, userID, set); err != nil {
+			return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Update Failed", "Server Error")
+		}
+	}
+
+	user.Password = ""
+	return user, nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	models "AlShifa/Users/Models"
+	utils "AlShifa/Utils"
+	"context"
+	"net/http"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestPatchUser(t *testing.T) {
+	userID := primitive.NewObjectID()
+
+	testCases := []struct {
+		Name               string
+		ActorID            primitive.ObjectID
+		ActorRole          string
+		Patch              string
+		Duplicate          bool
+		Verified           bool
+		ExpectedFields     []string
+		ExpectedStatusCode int
+	}{
+		{Name: "User changes address", ActorID: userID, ActorRole: utils.RoleUser, Patch: `{"address":"Rajbagh Srinagar"}`, ExpectedFields: []string{"address"}},
+		{Name: "User cannot change email", ActorID: userID, ActorRole: utils.RoleUser, Patch: `{"email":"new@gmail.com"}`, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "User cannot change someone else", ActorID: primitive.NewObjectID(), ActorRole: utils.RoleUser, Patch: `{"address":"Rajbagh Srinagar"}`, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Merged result is validated", ActorID: userID, ActorRole: utils.RoleUser, Patch: `{"name":null}`, ExpectedStatusCode: http.StatusBadRequest},
+		{Name: "Admin changes mobile", ActorID: primitive.NewObjectID(), ActorRole: utils.RoleAdmin, Patch: `{"mobile":9123456780}`, ExpectedFields: []string{"mobile"}},
+		{Name: "Changed mobile has to be verified again", ActorID: primitive.NewObjectID(), ActorRole: utils.RoleAdmin, Patch: `{"mobile":9123456780}`, Verified: true, ExpectedFields: []string{"mobile", "mobileVerified"}},
+		{Name: "Admin cannot reuse mobile of another user", ActorID: primitive.NewObjectID(), ActorRole: utils.RoleAdmin, Patch: `{"mobile":9123456780}`, Duplicate: true, ExpectedStatusCode: http.StatusConflict},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var saved bson.M
+			mockRepo := &MockUserRepo{
+				SearchUserByIDFn: func(ctx context.Context, userID primitive.ObjectID) (*models.User, error) {
+					user := ReturnDummyUser()
+					user.ID = userID
+					user.Password = "$argon2id$v=19$m=65536,t=1,p=4$c2FsdA$aGFzaA"
+					user.Mobile = 9876543210
+					user.Pincode = 190001
+					user.MobileVerified = tc.Verified
+					return &user, nil
+				},
+				SearchUserFn: func(ctx context.Context, filter bson.M) (*models.User, error) {
+					if tc.Duplicate {
+						return &models.User{}, nil
+					}
+					return nil, mongo.ErrNoDocuments
+				},
+				UpdateUserFn: func(ctx context.Context, userID primitive.ObjectID, set bson.M) error {
+					saved = set
+					return nil
+				},
+			}
+
+			user, appErr := ReturnNewService(mockRepo, nil).PatchUser(context.Background(), tc.ActorID, tc.ActorRole, userID, []byte(tc.Patch))
+			if tc.ExpectedStatusCode != 0 {
+				if appErr == nil || appErr.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status %d got %v", tc.ExpectedStatusCode, appErr)
+				}
+				if saved != nil {
+					t.Fatalf("expected nothing to be saved got %v", saved)
+				}
+				return
+			}
+			if appErr != nil {
+				t.Fatalf("expected no error but got %v", appErr)
+			}
+			//only patched fields are written back
+			if len(saved) != len(tc.ExpectedFields) {
+				t.Fatalf("expected only %v to be saved got %v", tc.ExpectedFields, saved)
+			}
+			for _, field := range tc.ExpectedFields {
+				if _, ok := saved[field]; !ok {
+					t.Fatalf("expected %s to be saved got %v", field, saved)
+				}
+			}
+			if user.Password != "" {
+				t.Fatalf("password returned with user")
+			}
+		})
+	}
+}
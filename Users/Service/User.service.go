@@ -2,6 +2,7 @@
 package service
 
 import (
+	notifications "AlShifa/Notifications"
 	structs "AlShifa/Structs"
 	interfaces "AlShifa/Users/Interfaces"
 	models "AlShifa/Users/Models"
@@ -18,14 +19,16 @@ import (
 )
 
 type Service struct {
-	repo interfaces.IRepository
+	repo     interfaces.IRepository
+	notifier notifications.Notifier
 }
 
 var _ interfaces.IService = (*Service)(nil)
 
-func ReturnNewService(repo interfaces.IRepository) *Service {
+func ReturnNewService(repo interfaces.IRepository, notifier notifications.Notifier) *Service {
 	return &Service{
-		repo: repo,
+		repo:     repo,
+		notifier: notifier,
 	}
 }
 
@@ -74,6 +77,41 @@ func (s *Service) AddUser(ctx context.Context, user models.User) *structs.IAppEr
 
 	user.Password = hashedPassword
 
+	//patient seen at a clinic as walk-in already has a record, claim it so their appointment history is kept. staff type
+	//the mobile without checking it so whoever registers has to prove they own the mobile first
+	walkIn, walkInErr := s.repo.SearchUser(ctx, bson.M{"mobile": user.Mobile, "walkIn": true})
+	if walkInErr != nil && walkInErr != mongo.ErrNoDocuments {
+		return &structs.IAppError{
+			Message:    "Registration Failed",
+			StatusCode: 500,
+			Reason:     "Server Error",
+			ErrorObj:   walkInErr,
+		}
+	}
+	if walkIn != nil {
+		if appErr := s.verifyMobileOTP(ctx, *walkIn, user.OTP); appErr != nil {
+			return appErr
+		}
+		claimedAt := time.Now()
+		user.ClaimedAt = &claimedAt
+		if err := s.repo.ClaimWalkInUser(ctx, walkIn.ID, user); err != nil {
+			if err == mongo.ErrNoDocuments {
+				return &structs.IAppError{
+					Message:    "Registration Failed",
+					StatusCode: http.StatusConflict,
+					Reason:     "Walk-in Record Was Claimed Meanwhile",
+					ErrorObj:   err,
+				}
+			}
+			return &structs.IAppError{
+				Message:    "Failed to Register User",
+				ErrorObj:   err,
+				StatusCode: 500,
+			}
+		}
+		return nil
+	}
+
 	if err := s.repo.RegisterUser(ctx, user); err != nil {
 		return &structs.IAppError{
 			Message:    "Failed to Register User",
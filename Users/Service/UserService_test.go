@@ -1,11 +1,16 @@
 package service
 
 import (
+	notifications "AlShifa/Notifications"
 	structs "AlShifa/Structs"
+	interfaces "AlShifa/Users/Interfaces"
 	models "AlShifa/Users/Models"
+	utils "AlShifa/Utils"
 	"context"
 	"errors"
+	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -116,7 +121,7 @@ func TestUserServiceAddUser(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.Name, func(t *testing.T) {
-			service := ReturnNewService(tc.mockRepo)
+			service := ReturnNewService(tc.mockRepo, nil)
 			err := service.AddUser(context.Background(), tc.Data)
 			if !reflect.DeepEqual(err, tc.ExpectedErr) {
 				t.Fatalf("Expected %v to be equal to %v ", err, tc.ExpectedErr)
@@ -126,6 +131,173 @@ func TestUserServiceAddUser(t *testing.T) {
 	}
 }
 
+func TestUserServiceAddUserClaimsWalkIn(t *testing.T) {
+	codeHash, err := utils.HashPasswordArgon2id("123456")
+	if err != nil {
+		t.Fatalf("failed to hash otp %v", err)
+	}
+	validOTP := &models.MobileOTP{CodeHash: codeHash, SentAt: time.Now(), ExpiresAt: time.Now().Add(utils.MobileOTPValidity)}
+	expiredOTP := &models.MobileOTP{CodeHash: codeHash, SentAt: time.Now().Add(-time.Hour), ExpiresAt: time.Now().Add(-time.Minute)}
+	exhaustedOTP := &models.MobileOTP{CodeHash: codeHash, SentAt: time.Now(), ExpiresAt: time.Now().Add(utils.MobileOTPValidity), Attempts: utils.MaxMobileOTPAttempts}
+
+	testCases := []struct {
+		Name               string
+		OTP                string
+		Sent               *models.MobileOTP
+		ClaimErr           error
+		ExpectedStatusCode int
+		ExpectedAttempt    bool
+	}{
+		{Name: "Right otp claims walk-in", OTP: "123456", Sent: validOTP},
+		{Name: "Walk-in cant be claimed without otp", OTP: "", Sent: validOTP, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Walk-in cant be claimed when no otp was sent", OTP: "123456", Sent: nil, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Wrong otp is counted", OTP: "654321", Sent: validOTP, ExpectedStatusCode: http.StatusForbidden, ExpectedAttempt: true},
+		{Name: "Expired otp is rejected", OTP: "123456", Sent: expiredOTP, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Walk-in claimed meanwhile is a conflict", OTP: "123456", Sent: validOTP, ClaimErr: mongo.ErrNoDocuments, ExpectedStatusCode: http.StatusConflict},
+		{Name: "Right otp is rejected after too many wrong ones", OTP: "123456", Sent: exhaustedOTP, ExpectedStatusCode: http.StatusTooManyRequests},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			walkInID := primitive.NewObjectID()
+			var claimed primitive.ObjectID
+			attempted := false
+			mockRepo := &MockUserRepo{
+				SearchUserFn: func(ctx context.Context, filter bson.M) (*models.User, error) {
+					//only the walk-in lookup finds a record
+					if filter["walkIn"] != true {
+						return nil, mongo.ErrNoDocuments
+					}
+					return &models.User{ID: walkInID, Name: "Saqlain", Mobile: filter["mobile"].(int), WalkIn: true, MobileOTP: tc.Sent}, nil
+				},
+				ClaimWalkInFn: func(ctx context.Context, walkInID primitive.ObjectID, user models.User) error {
+					if user.Password == "Saqlain@123" || user.ClaimedAt == nil {
+						t.Errorf("expected hashed password and claim time got %+v", user)
+					}
+					if tc.ClaimErr != nil {
+						return tc.ClaimErr
+					}
+					claimed = walkInID
+					return nil
+				},
+				CountAttemptFn: func(ctx context.Context, id primitive.ObjectID) error {
+					attempted = id == walkInID
+					return nil
+				},
+			}
+
+			user := ReturnDummyUser()
+			user.Mobile = 9876543210
+			user.OTP = tc.OTP
+			appErr := ReturnNewService(mockRepo, nil).AddUser(context.Background(), user)
+
+			if attempted != tc.ExpectedAttempt {
+				t.Fatalf("expected wrong attempt to be counted %v but got %v", tc.ExpectedAttempt, attempted)
+			}
+			if tc.ExpectedStatusCode != 0 {
+				if appErr == nil || appErr.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status code %d but got %v", tc.ExpectedStatusCode, appErr)
+				}
+				if !claimed.IsZero() {
+					t.Fatalf("expected walk-in not to be claimed")
+				}
+				return
+			}
+			if appErr != nil {
+				t.Fatalf("expected registration to claim walk-in got %v", appErr)
+			}
+			if claimed != walkInID {
+				t.Fatalf("expected walk-in %s to be claimed got %s", walkInID.Hex(), claimed.Hex())
+			}
+		})
+	}
+}
+
+// recordingNotifier remembers messages it was asked to send and fails them with err
+type recordingNotifier struct {
+	sent []notifications.Message
+	err  error
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, message notifications.Message) error {
+	n.sent = append(n.sent, message)
+	return n.err
+}
+
+func TestSendMobileOTP(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		WalkIn             bool
+		Throttled          bool
+		NotifyErr          error
+		ExpectedStatusCode int
+		ExpectedSent       bool
+	}{
+		{Name: "Otp is sent to mobile of walk-in record", WalkIn: true, ExpectedSent: true},
+		{Name: "Mobile without walk-in record gets the same answer without otp", WalkIn: false},
+		{Name: "Otp isnt sent again right away", WalkIn: true, Throttled: true, ExpectedStatusCode: http.StatusTooManyRequests},
+		{Name: "Mobile without walk-in record is throttled the same way", WalkIn: false, Throttled: true, ExpectedStatusCode: http.StatusTooManyRequests},
+		{Name: "Failure to send isnt told apart from mobile without record", WalkIn: true, NotifyErr: errors.New("error from mock notifier"), ExpectedSent: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			walkInID := primitive.NewObjectID()
+			var stored *models.MobileOTP
+			mockRepo := &MockUserRepo{
+				ThrottleOTPFn: func(ctx context.Context, mobile int, now time.Time) error {
+					if tc.Throttled {
+						return interfaces.ErrMobileOTPSentRecently
+					}
+					return nil
+				},
+				SearchUserFn: func(ctx context.Context, filter bson.M) (*models.User, error) {
+					if !tc.WalkIn {
+						return nil, mongo.ErrNoDocuments
+					}
+					return &models.User{ID: walkInID, Mobile: filter["mobile"].(int), WalkIn: true}, nil
+				},
+				SetMobileOTPFn: func(ctx context.Context, id primitive.ObjectID, otp models.MobileOTP) error {
+					if id != walkInID {
+						t.Fatalf("expected otp of walk-in %s to be stored but got %s", walkInID.Hex(), id.Hex())
+					}
+					stored = &otp
+					return nil
+				},
+			}
+			notifier := &recordingNotifier{err: tc.NotifyErr}
+
+			appErr := ReturnNewService(mockRepo, notifier).SendMobileOTP(context.Background(), 9876543210)
+
+			if tc.ExpectedStatusCode != 0 {
+				if appErr == nil || appErr.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status code %d but got %v", tc.ExpectedStatusCode, appErr)
+				}
+			} else if appErr != nil {
+				t.Fatalf("expected no error but got %v", appErr)
+			}
+			if !tc.ExpectedSent {
+				if stored != nil || len(notifier.sent) != 0 {
+					t.Fatalf("expected no otp to be sent but got %+v", notifier.sent)
+				}
+				return
+			}
+
+			if len(notifier.sent) != 1 || notifier.sent[0].Recipient != walkInID {
+				t.Fatalf("expected otp to be sent to walk-in %s but got %+v", walkInID.Hex(), notifier.sent)
+			}
+			//only hash of the code is stored and it matches the code sent
+			code := strings.Fields(notifier.sent[0].Body)[0]
+			if stored == nil || stored.CodeHash == code || stored.Attempts != 0 {
+				t.Fatalf("expected fresh hashed otp to be stored but got %+v", stored)
+			}
+			if ok, _ := utils.VerifyPasswordArgon2id(code, stored.CodeHash); !ok || len(code) != utils.MobileOTPDigits {
+				t.Fatalf("expected stored hash to match code %s", code)
+			}
+		})
+	}
+}
+
 func TestSearchUserByID(t *testing.T) {
 	testCases := []struct {
 		Name         string
@@ -190,7 +362,7 @@ func TestSearchUserByID(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.Name, func(t *testing.T) {
-			service := ReturnNewService(tc.mockRepo)
+			service := ReturnNewService(tc.mockRepo, nil)
 			user, err := service.SearchUserByID(context.Background(), tc.UserID)
 
 			if !reflect.DeepEqual(tc.expectedErr, err) {
@@ -249,7 +421,7 @@ func TestSearchUser(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.Name, func(t *testing.T) {
-			service := ReturnNewService(tc.mockRepo)
+			service := ReturnNewService(tc.mockRepo, nil)
 			user, err := service.SearchUser(context.Background(), bson.M{})
 
 			if !reflect.DeepEqual(tc.expectedErr, err) {
@@ -0,0 +1,39 @@
+package structs
+
+import (
+	"AlShifa/Clinic/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AppointmentHistoryItem is an appointment of user along with names of its doctor and clinic
+type AppointmentHistoryItem struct {
+	models.Appointment `bson:",inline"`
+	DoctorName         string `json:"doctorName" bson:"doctorName"`
+	ClinicName         string `json:"clinicName" bson:"clinicName"`
+	ClinicAddress      string `json:"clinicAddress" bson:"clinicAddress"`
+}
+
+// AppointmentHistoryFilter narrows down appointments of a user, zero values mean no restriction
+type AppointmentHistoryFilter struct {
+	Statuses  []string
+	From      time.Time // inclusive
+	To        time.Time // exclusive
+	When      string    // upcoming or past, relative to now
+	Ascending bool      // oldest first, upcoming appointments are listed this way
+	Limit     int64
+	After     *HistoryCursor
+}
+
+// HistoryCursor points at the last appointment of a page, next page starts right after it
+type HistoryCursor struct {
+	Date time.Time
+	ID   primitive.ObjectID
+}
+
+// AppointmentHistoryPage is one page of appointment history, NextCursor is empty on the last page
+type AppointmentHistoryPage struct {
+	Appointments []AppointmentHistoryItem `json:"appointments"`
+	NextCursor   string                   `json:"nextCursor,omitempty"`
+}
@@ -0,0 +1,6 @@
+package structs
+
+// MobileOTPRequest asks for a code to verify mobile before registering with it
+type MobileOTPRequest struct {
+	Mobile int `json:"mobile" bson:"mobile"`
+}
@@ -0,0 +1,10 @@
+package validators
+
+import utils "AlShifa/Utils"
+
+// UserPatchFields are the fields each role can patch on a user, names are the same in json and bson. email and mobile
+// identify the account (mobile also claims walk-in records) so only admin can change them
+var UserPatchFields = map[string][]string{
+	utils.RoleUser:  {"name", "age", "address", "pincode"},
+	utils.RoleAdmin: {"name", "age", "address", "pincode", "email", "mobile"},
+}
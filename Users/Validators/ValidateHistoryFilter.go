@@ -0,0 +1,49 @@
+package validators
+
+import (
+	structs "AlShifa/Users/Structs"
+	utils "AlShifa/Utils"
+	"fmt"
+	"slices"
+)
+
+var appointmentStatuses = []string{
+	utils.AppointmentStatusRequested,
+	utils.AppointmentStatusConfirmed,
+	utils.AppointmentStatusCheckedIn,
+	utils.AppointmentStatusInConsultation,
+	utils.AppointmentStatusCompleted,
+	utils.AppointmentStatusCancelled,
+	utils.AppointmentStatusNoShow,
+	utils.AppointmentStatusRescheduled,
+	utils.AppointmentStatusNeedsReschedule,
+}
+
+// ValidateHistoryFilter validates filters of appointment history sent by a user and returns a map of field errors
+func ValidateHistoryFilter(filter *structs.AppointmentHistoryFilter) map[string]string {
+	errors := make(map[string]string)
+
+	for _, status := range filter.Statuses {
+		if !slices.Contains(appointmentStatuses, status) {
+			errors["status"] = status + " is not an appointment status"
+			break
+		}
+	}
+
+	if filter.When != "" && filter.When != utils.HistoryUpcoming && filter.When != utils.HistoryPast {
+		errors["when"] = "when must be upcoming or past"
+	}
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.To.After(filter.From) {
+		errors["to"] = "to must not be before from"
+	}
+
+	if filter.Limit < 0 || filter.Limit > utils.MaxHistoryPageSize {
+		errors["limit"] = fmt.Sprintf("limit must be between 1 and %d", utils.MaxHistoryPageSize)
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ErrInvalidPatch is returned when merge patch is not a json object
+var ErrInvalidPatch = errors.New("merge patch must be a json object")
+
+// MergePatch applies json merge patch (RFC 7396) on target which must be a pointer to struct. only top level fields in
+// allowed can be patched, others are returned as field errors and target is left untouched. names of patched fields
+// are returned so only they have to be written back
+func MergePatch(target any, patch []byte, allowed []string) ([]string, map[string]string, error) {
+	var changes map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &changes); err != nil || changes == nil {
+		return nil, nil, ErrInvalidPatch
+	}
+
+	fields := make([]string, 0, len(changes))
+	forbidden := make(map[string]string)
+	for field := range changes {
+		if !slices.Contains(allowed, field) {
+			forbidden[field] = "field cannot be changed"
+			continue
+		}
+		fields = append(fields, field)
+	}
+	if len(forbidden) > 0 {
+		return nil, forbidden, nil
+	}
+	sort.Strings(fields)
+
+	current, err := json.Marshal(target)
+	if err != nil {
+		return nil, nil, err
+	}
+	document, err := decodeNumbers(current)
+	if err != nil {
+		return nil, nil, err
+	}
+	patchDocument, err := decodeNumbers(patch)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	merged, err := json.Marshal(mergeValue(document, patchDocument))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	//decode into a zero value so fields removed by null become zero
+	value := reflect.New(reflect.TypeOf(target).Elem())
+	if err := json.Unmarshal(merged, value.Interface()); err != nil {
+		return nil, nil, err
+	}
+	reflect.ValueOf(target).Elem().Set(value.Elem())
+	return fields, nil, nil
+}
+
+// PatchSet returns stored values of patched fields of target ready for $set, fields have to be named the same in json and bson
+func PatchSet(target any, fields []string) (bson.M, error) {
+	raw, err := bson.Marshal(target)
+	if err != nil {
+		return nil, err
+	}
+	var document bson.M
+	if err := bson.Unmarshal(raw, &document); err != nil {
+		return nil, err
+	}
+
+	set := make(bson.M, len(fields))
+	for _, field := range fields {
+		//zero values tagged omitempty are not marshalled at all
+		set[field] = document[field]
+	}
+	return set, nil
+}
+
+// mergeValue merges patch into target as RFC 7396 describes, objects are merged key by key and everything else is replaced
+func mergeValue(target any, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = make(map[string]any)
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergeValue(targetObject[key], value)
+	}
+	return targetObject
+}
+
+// decodeNumbers decodes json keeping numbers as they are, mobiles dont survive a round trip through float64
+func decodeNumbers(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	err := decoder.Decode(&value)
+	return value, err
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+type patchTarget struct {
+	Name    string   `json:"name"`
+	Mobile  int64    `json:"mobile"`
+	Plan    string   `json:"plan"`
+	Tags    []string `json:"tags"`
+	Address struct {
+		Line    string `json:"line"`
+		Pincode int32  `json:"pincode"`
+	} `json:"address"`
+}
+
+func TestMergePatch(t *testing.T) {
+	target := patchTarget{Name: "Shifa", Mobile: 9876543210, Plan: "Basic", Tags: []string{"a", "b"}}
+	target.Address.Line = "Lal Chowk"
+	target.Address.Pincode = 190001
+
+	fields, forbidden, err := MergePatch(&target, []byte(`{"tags":["c"],"address":{"line":"Rajbagh"},"name":null}`), []string{"name", "tags", "address"})
+	if err != nil || forbidden != nil {
+		t.Fatalf("expected patch to apply got %v %v", err, forbidden)
+	}
+	if len(fields) != 3 || fields[0] != "address" || fields[2] != "tags" {
+		t.Fatalf("unexpected patched fields %v", fields)
+	}
+
+	//null removes, arrays are replaced and objects are merged
+	if target.Name != "" || len(target.Tags) != 1 || target.Address.Line != "Rajbagh" || target.Address.Pincode != 190001 {
+		t.Fatalf("unexpected merge result %+v", target)
+	}
+	if target.Mobile != 9876543210 || target.Plan != "Basic" {
+		t.Fatalf("fields not in patch changed %+v", target)
+	}
+
+	_, forbidden, err = MergePatch(&target, []byte(`{"plan":"Premium","name":"x"}`), []string{"name"})
+	if err != nil || forbidden["plan"] == "" || target.Plan != "Basic" {
+		t.Fatalf("expected plan to be refused got %v %v %+v", err, forbidden, target)
+	}
+
+	if _, _, err := MergePatch(&target, []byte(`["name"]`), []string{"name"}); !errors.Is(err, ErrInvalidPatch) {
+		t.Fatalf("expected invalid patch error got %v", err)
+	}
+}
+
+func TestPatchSet(t *testing.T) {
+	target := struct {
+		Name   string `bson:"name"`
+		Mobile int64  `bson:"mobile"`
+	}{Name: "Shifa", Mobile: 9876543210}
+
+	set, err := PatchSet(&target, []string{"mobile"})
+	if err != nil || len(set) != 1 || set["mobile"] != int64(9876543210) {
+		t.Fatalf("unexpected set %v %v", set, err)
+	}
+}
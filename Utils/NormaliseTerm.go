@@ -0,0 +1,8 @@
+package utils
+
+import "strings"
+
+// NormaliseTerm lowercases search term and collapses its whitespace so "Skin  Care " and "skin care" match
+func NormaliseTerm(term string) string {
+	return strings.ToLower(strings.Join(strings.Fields(term), " "))
+}
@@ -7,11 +7,184 @@ const (
 	APIVERSION     = "/v1"
 	JwtExpiryTime  = time.Hour * 24 * 7
 
+	//ActiveClinicHeader lets owners of many clinics pick the clinic a call is about
+	ActiveClinicHeader = "X-Clinic-ID"
+
 	//Roles
 	RoleUser        = "User"
 	RoleAdmin       = "Admin"
 	RoleDoctor      = "Doctor"
 	RoleClinicOwner = "ClinicOwner"
+	RoleStaff       = "Staff"
+
+	//Appointment Status
+	AppointmentStatusRequested       = "Requested"
+	AppointmentStatusConfirmed       = "Confirmed"
+	AppointmentStatusCheckedIn       = "CheckedIn"
+	AppointmentStatusInConsultation  = "InConsultation"
+	AppointmentStatusCompleted       = "Completed"
+	AppointmentStatusCancelled       = "Cancelled"
+	AppointmentStatusNoShow          = "NoShow"
+	AppointmentStatusRescheduled     = "Rescheduled"
+	AppointmentStatusNeedsReschedule = "NeedsReschedule"
+
+	//Waitlist Status
+	WaitlistStatusWaiting  = "Waiting"
+	WaitlistStatusOffered  = "Offered"
+	WaitlistStatusAccepted = "Accepted"
+	WaitlistStatusExpired  = "Expired"
+	WaitlistStatusLeft     = "Left"
+
+	//Affiliation Status
+	AffiliationStatusPending   = "Pending"
+	AffiliationStatusAccepted  = "Accepted"
+	AffiliationStatusDeclined  = "Declined"
+	AffiliationStatusWithdrawn = "Withdrawn"
+	AffiliationStatusEnded     = "Ended"
+	MaxAffiliationReasonLength = 200
+
+	//Clinic staff, owners invite them to a clinic and pick what they can do there
+	StaffStatusInvited           = "Invited"
+	StaffStatusActive            = "Active"
+	StaffStatusRevoked           = "Revoked"
+	PermissionManageAppointments = "manageAppointments"
+	PermissionManageQueue        = "manageQueue"
+	PermissionViewWallet         = "viewWallet"
+	PermissionEditClinic         = "editClinic"
+	StaffInviteTokenBytes        = 32
+	StaffInviteExpiry            = 7 * 24 * time.Hour
+
+	//Clinic lifecycle, clinics which arent active or are deleted cant be found or booked
+	ClinicStatusActive          = "Active"
+	ClinicStatusSuspended       = "Suspended" // by admin, only admin lifts it
+	ClinicStatusClosed          = "Closed"
+	MaxClinicStatusReasonLength = 200
+
+	//Queue Token Status
+	QueueTokenStatusWaiting   = "Waiting"
+	QueueTokenStatusCalled    = "Called"
+	QueueTokenStatusSkipped   = "Skipped"
+	QueueTokenStatusServed    = "Served"
+	QueueTokenStatusCancelled = "Cancelled"
+
+	//Scheduling
+	DefaultSlotMinutes  = 15
+	MinSlotMinutes      = 5
+	MaxSlotMinutes      = 240
+	MaxSlotsPerSession  = 128 // slot numbers are stored as int8
+	DefaultSlotCapacity = 1
+	DefaultTimeZone     = "Asia/Kolkata"
+	MaxSlotRangeDays    = 31
+	DateLayout          = "2006-01-02"
+	DisplayTimeLayout   = "02 Jan 2006 03:04 PM"
+	SlotHoldDuration    = 5 * time.Minute
+	WaitlistOfferWindow = 15 * time.Minute
+	WaitlistSweepPeriod = 30 * time.Second
+	QueueHeartbeat      = 15 * time.Second
+	MaxLeaveDays        = 365
+	BulkRequestTimeout  = 30 * time.Second
+
+	//Opening hours of clinics computed from their season timings, working days and closures
+	OpeningStatusOpen    = "Open"
+	OpeningStatusClosed  = "Closed"
+	OpeningLookaheadDays = 7 // a closed clinic reports when it opens next within these many days
+	MaxOpenAtDays        = 365
+
+	//Next available slots of doctors across their clinics
+	DefaultNextSlots        = 3
+	MaxNextSlots            = 20
+	DefaultNextSlotDays     = 14
+	MaxNextAvailableDoctors = 50 // doctors of one listing page
+
+	//Calendar feeds
+	CalendarFeedPastDays   = 30
+	CalendarFeedFutureDays = 180
+	CalendarFeedTokenBytes = 32
+
+	//Jobs
+	JobStatusPending    = "Pending"
+	JobStatusRunning    = "Running"
+	JobStatusDone       = "Done"
+	JobStatusSkipped    = "Skipped"
+	JobStatusFailed     = "Failed"
+	JobKindReminder     = "AppointmentReminder"
+	JobLease            = 2 * time.Minute
+	JobMaxAttempts      = 5
+	JobRetryBackoff     = time.Minute
+	JobRetention        = 30 * 24 * time.Hour
+	ReminderSweepPeriod = time.Minute
+	DefaultReminders    = "24h,2h" // REMINDER_OFFSETS overrides it
+
+	//No show policy
+	NoShowActionBlock        = "block"
+	NoShowActionPrepay       = "prepay"
+	MaxNoShowWindowDays      = 365
+	MaxNoShowRestrictionDays = 365
+	DefaultNoShowReportDays  = 90
+	NoShowReportLimit        = 20
+
+	//what clinic staff do for a walk-in patient
+	WalkInModeToken       = "token"
+	WalkInModeAppointment = "appointment"
+	MobileOTPDigits       = 6
+	MobileOTPValidity     = 10 * time.Minute
+	MobileOTPResendAfter  = time.Minute
+	MaxMobileOTPAttempts  = 5 // wrong codes tried before a new otp has to be requested
+
+	//Consultation modes, Both is only used for doctor sessions
+	ConsultationModeInClinic = "InClinic"
+	ConsultationModeVideo    = "Video"
+	ConsultationModeBoth     = "Both"
+	JoinLinkOpensBefore      = 15 * time.Minute
+	JoinLinkGrace            = 30 * time.Minute // link stays valid this long after slot ends
+	MaxOnlineHours           = 4
+
+	//Appointment history
+	HistoryUpcoming        = "upcoming"
+	HistoryPast            = "past"
+	DefaultHistoryPageSize = 20
+	MaxHistoryPageSize     = 100
+
+	//Specialties
+	MaxDoctorSpecialties   = 5
+	MaxSpecialtySynonyms   = 20
+	MaxSpecialtyTermLength = 50
+
+	//Nearby clinics, distances are in kilometres
+	DefaultNearbyRadius  = 5.0
+	MaxNearbyRadius      = 50.0
+	DefaultNearbyClinics = 20
+	MaxNearbyClinics     = 50
+
+	//Reviews
+	MinReviewRating           = 1
+	MaxReviewRating           = 5
+	MaxReviewCommentLength    = 1000
+	MaxReviewReplyLength      = 1000
+	MaxModerationReasonLength = 200
+	DefaultReviewPageSize     = 20
+	MaxReviewPageSize         = 50
+
+	//Unified search
+	SearchTypeDoctor        = "doctor"
+	SearchTypeClinic        = "clinic"
+	SearchTypeSpecialty     = "specialty"
+	DefaultSearchResults    = 20
+	MaxSearchResults        = 50
+	DefaultSuggestions      = 8
+	MaxSuggestions          = 20
+	MaxSearchQueryLength    = 100
+	SearchCandidates        = 100 // hits fetched per collection before ranking
+	MinPrefixLength         = 2
+	MaxPrefixExpansions     = 10
+	SearchVocabularyRefresh = 10 * time.Minute
+
+	//Profile updates
+	MaxPatchSize = 64 << 10 // bytes of a merge patch body
+
+	//what happens to appointments falling in a leave
+	LeaveActionCancel     = "cancel"
+	LeaveActionReschedule = "reschedule"
 
 	// Name
 	MinNameLength = 2
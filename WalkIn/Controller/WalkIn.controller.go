@@ -0,0 +1,62 @@
+// Package controller provides HTTP handlers for registering walk-in patients.
+package controller
+
+import (
+	middleware "AlShifa/Middleware"
+	utils "AlShifa/Utils"
+	interfaces "AlShifa/WalkIn/Interfaces"
+	walkInStructs "AlShifa/WalkIn/Structs"
+	validators "AlShifa/WalkIn/Validators"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Controller struct {
+	Service interfaces.IService
+}
+
+func NewController(service interfaces.IService) *Controller {
+	return &Controller{
+		Service: service,
+	}
+}
+
+func (controller *Controller) RegisterWalkIn(res http.ResponseWriter, req *http.Request) {
+	ctx, cancel := context.WithTimeout(req.Context(), utils.RequestTimeout)
+	defer cancel()
+
+	actorID, actorRole, authErr := middleware.AuthenticatedUser(req)
+	if authErr != nil {
+		_ = utils.WriteResponse(res, authErr.StatusCode, authErr)
+		return
+	}
+
+	clinicID, err := primitive.ObjectIDFromHex(req.PathValue("id"))
+	if err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Invalid Clinic ID", "Invalid ID"))
+		return
+	}
+
+	var request walkInStructs.WalkInRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(err, 400, "Walk-in Registration Failed", "Invalid Json"))
+		return
+	}
+
+	validationErrors := validators.ValidateWalkIn(&request)
+	if validationErrors != nil {
+		_ = utils.WriteResponse(res, http.StatusBadRequest, utils.ReturnAppError(validationErrors, 400, "Walk-in Registration Failed", "Invalid Details"))
+		return
+	}
+
+	response, appErr := controller.Service.RegisterWalkIn(ctx, actorID, actorRole, clinicID, request)
+	if appErr != nil {
+		_ = utils.WriteResponse(res, appErr.StatusCode, appErr)
+		return
+	}
+
+	_ = utils.WriteResponse(res, http.StatusCreated, utils.ReturnAppSuccess(201, "Walk-in Registered Successfully", response))
+}
@@ -0,0 +1,16 @@
+// Package interfaces contains interfaces for WalkIn module
+package interfaces
+
+import (
+	"AlShifa/Clinic/models"
+	walkInStructs "AlShifa/WalkIn/Structs"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IRepository defines the methods for loose coupling between the walk-in repository and its implementation.
+type IRepository interface {
+	FindOrCreatePatient(ctx context.Context, name string, mobile int, createdBy primitive.ObjectID) (walkInStructs.WalkInPatient, error)
+	GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error)
+}
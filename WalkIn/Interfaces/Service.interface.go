@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	structs "AlShifa/Structs"
+	walkInStructs "AlShifa/WalkIn/Structs"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IService interface contains functions that walk-in service layer must implement( to be used by handlers)
+type IService interface {
+	RegisterWalkIn(ctx context.Context, actorID primitive.ObjectID, actorRole string, clinicID primitive.ObjectID, request walkInStructs.WalkInRequest) (*walkInStructs.WalkInResponse, *structs.IAppError)
+}
@@ -0,0 +1,75 @@
+// Package repository provides the implementation of the repository layer for walk-in patients in MongoDB.
+package repository
+
+import (
+	"AlShifa/Clinic/models"
+	utils "AlShifa/Utils"
+	interfaces "AlShifa/WalkIn/Interfaces"
+	walkInStructs "AlShifa/WalkIn/Structs"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Repo is the MongoDB implementation of the walk-in IRepository interface.
+type Repo struct {
+	DB *mongo.Database
+}
+
+// this ensures this repo implements all methods of repository interface
+var _ interfaces.IRepository = (*Repo)(nil)
+
+// NewRepository creates a new walk-in repository with the specified database.
+func NewRepository(db *mongo.Database) *Repo {
+	return &Repo{
+		DB: db,
+	}
+}
+
+// CreateIndexes makes mobile unique among walk-in records so two desks registering the same patient share one record
+func (r *Repo) CreateIndexes(ctx context.Context) error {
+	_, err := r.DB.Collection("User").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "mobile", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"walkIn": true}),
+	})
+	return err
+}
+
+// FindOrCreatePatient returns the walk-in record or registered user with verified mobile, creating a walk-in record when
+// there is none. staff type the mobile of the patient so registered users who never verified it dont get their visits
+func (r *Repo) FindOrCreatePatient(ctx context.Context, name string, mobile int, createdBy primitive.ObjectID) (walkInStructs.WalkInPatient, error) {
+	update := bson.M{"$setOnInsert": bson.M{
+		"_id":              primitive.NewObjectID(),
+		"name":             name,
+		"email":            "",
+		"role":             utils.RoleUser,
+		"registrationDate": time.Now(),
+		"appointmentIDS":   nil,
+		"appointments":     nil,
+		"walkIn":           true,
+		"createdBy":        createdBy,
+	}}
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"name": 1, "mobile": 1, "walkIn": 1})
+
+	filter := bson.M{"mobile": mobile, "$or": []bson.M{{"walkIn": true}, {"mobileVerified": true}}}
+	var patient walkInStructs.WalkInPatient
+	err := r.DB.Collection("User").FindOneAndUpdate(ctx, filter, update, opts).Decode(&patient)
+	//another desk created the walk-in between our lookup and insert, it is there now
+	if mongo.IsDuplicateKeyError(err) {
+		err = r.DB.Collection("User").FindOne(ctx, filter, options.FindOne().SetProjection(bson.M{"name": 1, "mobile": 1, "walkIn": 1})).Decode(&patient)
+	}
+	return patient, err
+}
+
+func (r *Repo) GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+	var clinic models.Clinic
+	err := r.DB.Collection("Clinic").FindOne(ctx, bson.M{"_id": clinicID}).Decode(&clinic)
+	return clinic, err
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"AlShifa/Clinic/models"
+	interfaces "AlShifa/WalkIn/Interfaces"
+	walkInStructs "AlShifa/WalkIn/Structs"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type MockWalkInRepo struct {
+	FindOrCreatePatientFn func(ctx context.Context, name string, mobile int, createdBy primitive.ObjectID) (walkInStructs.WalkInPatient, error)
+	GetClinicFn           func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error)
+}
+
+var _ interfaces.IRepository = (*MockWalkInRepo)(nil)
+
+func (m *MockWalkInRepo) FindOrCreatePatient(ctx context.Context, name string, mobile int, createdBy primitive.ObjectID) (walkInStructs.WalkInPatient, error) {
+	if m.FindOrCreatePatientFn == nil {
+		panic("FindOrCreatePatientFn not implemented inside mock")
+	}
+	return m.FindOrCreatePatientFn(ctx, name, mobile, createdBy)
+}
+
+func (m *MockWalkInRepo) GetClinic(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+	if m.GetClinicFn == nil {
+		panic("GetClinicFn not implemented inside mock")
+	}
+	return m.GetClinicFn(ctx, clinicID)
+}
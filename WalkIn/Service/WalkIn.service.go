@@ -0,0 +1,82 @@
+// Package service contains service layer implementation for walk-in module
+package service
+
+import (
+	appointmentInterfaces "AlShifa/Appointment/Interfaces"
+	"AlShifa/Clinic/models"
+	middleware "AlShifa/Middleware"
+	queueInterfaces "AlShifa/Queue/Interfaces"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	interfaces "AlShifa/WalkIn/Interfaces"
+	walkInStructs "AlShifa/WalkIn/Structs"
+	"context"
+	"errors"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type WalkInService struct {
+	Repo         interfaces.IRepository
+	Appointments appointmentInterfaces.IService
+	Queue        queueInterfaces.IService
+}
+
+// NewWalkInService creates walk-in service which books through appointments and issues tokens through queue
+func NewWalkInService(repo interfaces.IRepository, appointments appointmentInterfaces.IService, queue queueInterfaces.IService) *WalkInService {
+	return &WalkInService{
+		Repo:         repo,
+		Appointments: appointments,
+		Queue:        queue,
+	}
+}
+
+// this ensures this service layer implements all methods of service layer interface
+var _ interfaces.IService = (*WalkInService)(nil)
+
+// RegisterWalkIn finds or creates the patient record for mobile and books an appointment or issues a token for it in one
+// call. a patient who registered and verified their mobile already keeps their own record and name
+func (service *WalkInService) RegisterWalkIn(ctx context.Context, actorID primitive.ObjectID, actorRole string, clinicID primitive.ObjectID, request walkInStructs.WalkInRequest) (*walkInStructs.WalkInResponse, *structs.IAppError) {
+	clinic, err := service.Repo.GetClinic(ctx, clinicID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, utils.ReturnAppError(err, http.StatusNotFound, "Clinic Not Found", "Invalid Clinic")
+		}
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Unable To Fetch Clinic", "Server Error")
+	}
+
+	//checked before the record is created so that only staff of the clinic create walk-ins
+	allowed := (actorRole == utils.RoleDoctor && actorID == request.Doctor) || (actorRole != utils.RoleDoctor && middleware.ActsForClinic(ctx, actorID, clinic.Owner, clinic.ID))
+	if !allowed {
+		return nil, utils.ReturnAppError(errors.New("not staff of clinic"), http.StatusForbidden, "Walk-in Registration Failed", "Only Doctor Or Staff Of Clinic Can Register Walk-in Patients")
+	}
+
+	patient, err := service.Repo.FindOrCreatePatient(ctx, request.Name, request.Mobile, actorID)
+	if err != nil {
+		return nil, utils.ReturnAppError(err, http.StatusInternalServerError, "Walk-in Registration Failed", "Server Error")
+	}
+
+	response := walkInStructs.WalkInResponse{Patient: patient}
+	if request.Mode == utils.WalkInModeAppointment {
+		appointment, appErr := service.Appointments.BookWalkInAppointment(ctx, actorID, actorRole, patient.ID, models.Appointment{
+			Doctor:          request.Doctor,
+			Clinic:          clinicID,
+			AppointmentDate: request.AppointmentDate,
+			Slot:            request.Slot,
+		})
+		if appErr != nil {
+			return nil, appErr
+		}
+		response.Appointment = appointment
+		return &response, nil
+	}
+
+	token, appErr := service.Queue.IssueToken(ctx, actorID, actorRole, request.Doctor, clinicID, &patient.ID, patient.Name)
+	if appErr != nil {
+		return nil, appErr
+	}
+	response.Token = token
+	return &response, nil
+}
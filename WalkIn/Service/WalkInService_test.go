@@ -0,0 +1,107 @@
+package service
+
+import (
+	appointmentInterfaces "AlShifa/Appointment/Interfaces"
+	"AlShifa/Clinic/models"
+	queueInterfaces "AlShifa/Queue/Interfaces"
+	structs "AlShifa/Structs"
+	utils "AlShifa/Utils"
+	walkInStructs "AlShifa/WalkIn/Structs"
+	"context"
+	"net/http"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// recordingAppointments stands in for appointment service and records walk-in bookings
+type recordingAppointments struct {
+	appointmentInterfaces.IService
+	bookedFor *primitive.ObjectID
+}
+
+func (r *recordingAppointments) BookWalkInAppointment(ctx context.Context, actorID primitive.ObjectID, actorRole string, userID primitive.ObjectID, appointment models.Appointment) (*models.Appointment, *structs.IAppError) {
+	r.bookedFor = &userID
+	appointment.User = userID
+	appointment.Status = utils.AppointmentStatusConfirmed
+	return &appointment, nil
+}
+
+// recordingQueue stands in for queue service and records issued tokens
+type recordingQueue struct {
+	queueInterfaces.IService
+	issuedFor *primitive.ObjectID
+}
+
+func (r *recordingQueue) IssueToken(ctx context.Context, actorID primitive.ObjectID, actorRole string, doctorID primitive.ObjectID, clinicID primitive.ObjectID, userID *primitive.ObjectID, patientName string) (*models.QueueToken, *structs.IAppError) {
+	r.issuedFor = userID
+	return &models.QueueToken{User: userID, PatientName: patientName, Number: 1}, nil
+}
+
+func TestRegisterWalkIn(t *testing.T) {
+	ownerID := primitive.NewObjectID()
+	doctorID := primitive.NewObjectID()
+	patientID := primitive.NewObjectID()
+
+	testCases := []struct {
+		Name               string
+		ActorID            primitive.ObjectID
+		ActorRole          string
+		Mode               string
+		ExpectedStatusCode int
+	}{
+		{Name: "Owner issues token for walk-in", ActorID: ownerID, ActorRole: utils.RoleClinicOwner, Mode: utils.WalkInModeToken},
+		{Name: "Doctor books appointment for walk-in", ActorID: doctorID, ActorRole: utils.RoleDoctor, Mode: utils.WalkInModeAppointment},
+		{Name: "Doctor cannot register walk-in for another doctor", ActorID: primitive.NewObjectID(), ActorRole: utils.RoleDoctor, Mode: utils.WalkInModeToken, ExpectedStatusCode: http.StatusForbidden},
+		{Name: "Owner of another clinic cannot register walk-in", ActorID: primitive.NewObjectID(), ActorRole: utils.RoleClinicOwner, Mode: utils.WalkInModeToken, ExpectedStatusCode: http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			created := false
+			mockRepo := &MockWalkInRepo{
+				GetClinicFn: func(ctx context.Context, clinicID primitive.ObjectID) (models.Clinic, error) {
+					return models.Clinic{ID: clinicID, Owner: ownerID}, nil
+				},
+				FindOrCreatePatientFn: func(ctx context.Context, name string, mobile int, createdBy primitive.ObjectID) (walkInStructs.WalkInPatient, error) {
+					created = true
+					return walkInStructs.WalkInPatient{ID: patientID, Name: name, Mobile: mobile, WalkIn: true}, nil
+				},
+			}
+			appointments := &recordingAppointments{}
+			queue := &recordingQueue{}
+			walkInService := NewWalkInService(mockRepo, appointments, queue)
+
+			response, appErr := walkInService.RegisterWalkIn(context.Background(), tc.ActorID, tc.ActorRole, primitive.NewObjectID(), walkInStructs.WalkInRequest{
+				Name:   "Aamir Lone",
+				Mobile: 9876543210,
+				Doctor: doctorID,
+				Mode:   tc.Mode,
+			})
+
+			if tc.ExpectedStatusCode != 0 {
+				if appErr == nil || appErr.StatusCode != tc.ExpectedStatusCode {
+					t.Fatalf("expected status %d got %v", tc.ExpectedStatusCode, appErr)
+				}
+				if created {
+					t.Fatalf("expected no patient record for a forbidden request")
+				}
+				return
+			}
+			if appErr != nil {
+				t.Fatalf("expected walk-in to be registered got %v", appErr)
+			}
+
+			switch tc.Mode {
+			case utils.WalkInModeToken:
+				if queue.issuedFor == nil || *queue.issuedFor != patientID || response.Token == nil {
+					t.Fatalf("expected token for walk-in patient got %+v", response)
+				}
+			case utils.WalkInModeAppointment:
+				if appointments.bookedFor == nil || *appointments.bookedFor != patientID || response.Appointment == nil {
+					t.Fatalf("expected appointment for walk-in patient got %+v", response)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+// Package structs contains request and response structures of the walk-in module
+package structs
+
+import (
+	"AlShifa/Clinic/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WalkInRequest registers a patient who came to the clinic without the app and books them or gives them a token
+type WalkInRequest struct {
+	Name            string             `json:"name"`
+	Mobile          int                `json:"mobile"`
+	Doctor          primitive.ObjectID `json:"doctor"`
+	Mode            string             `json:"mode"`            // token or appointment
+	AppointmentDate time.Time          `json:"appointmentDate"` // appointment mode only
+	Slot            int8               `json:"slot"`            // appointment mode only
+}
+
+// WalkInPatient is the patient record used for the walk-in, registered patients keep their own record
+type WalkInPatient struct {
+	ID     primitive.ObjectID `json:"id" bson:"_id"`
+	Name   string             `json:"name" bson:"name"`
+	Mobile int                `json:"mobile" bson:"mobile"`
+	WalkIn bool               `json:"walkIn" bson:"walkIn"`
+}
+
+type WalkInResponse struct {
+	Patient     WalkInPatient       `json:"patient"`
+	Appointment *models.Appointment `json:"appointment,omitempty"`
+	Token       *models.QueueToken  `json:"token,omitempty"`
+}
@@ -0,0 +1,59 @@
+// Package validators contains validation functions for walk-in module
+package validators
+
+import (
+	utils "AlShifa/Utils"
+	walkInStructs "AlShifa/WalkIn/Structs"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ValidateWalkIn validates walk-in details sent by clinic staff and returns a map of field errors
+func ValidateWalkIn(request *walkInStructs.WalkInRequest) map[string]string {
+	errors := make(map[string]string)
+
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" {
+		errors["name"] = "name is required"
+	} else if len(request.Name) < utils.MinNameLength || len(request.Name) > utils.MaxNameLength {
+		errors["name"] = "name length is invalid"
+	} else {
+		for _, r := range request.Name {
+			if !unicode.IsLetter(r) && r != ' ' {
+				errors["name"] = "name must contain only letters and spaces"
+				break
+			}
+		}
+	}
+
+	if request.Mobile <= 0 {
+		errors["mobile"] = "mobile number is required"
+	} else if len(strconv.Itoa(request.Mobile)) != utils.MobileLength {
+		errors["mobile"] = "mobile number must be 10 digits"
+	}
+
+	if request.Doctor == primitive.NilObjectID {
+		errors["doctor"] = "doctor is required"
+	}
+
+	switch request.Mode {
+	case utils.WalkInModeToken:
+	case utils.WalkInModeAppointment:
+		if request.AppointmentDate.IsZero() {
+			errors["appointmentDate"] = "appointment date is required"
+		}
+		if request.Slot < 0 {
+			errors["slot"] = "slot must be a valid slot number"
+		}
+	default:
+		errors["mode"] = "mode must be token or appointment"
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+	return errors
+}
@@ -0,0 +1,30 @@
+// Package walkin lets clinic staff register patients who arrive without the app and book or queue them in one call.
+package walkin
+
+import (
+	appointmentInterfaces "AlShifa/Appointment/Interfaces"
+	internals "AlShifa/Internals"
+	middleware "AlShifa/Middleware"
+	queueInterfaces "AlShifa/Queue/Interfaces"
+	utils "AlShifa/Utils"
+	controller "AlShifa/WalkIn/Controller"
+	repository "AlShifa/WalkIn/Repository"
+	service "AlShifa/WalkIn/Service"
+	"context"
+	"log"
+)
+
+// InitialiseWalkInModule registers walk-in routes, bookings go through appointments and tokens through queue
+func InitialiseWalkInModule(app *internals.App, appointments appointmentInterfaces.IService, queue queueInterfaces.IService) {
+	repository := repository.NewRepository(app.DB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.RequestTimeout*5)
+	defer cancel()
+	if err := repository.CreateIndexes(ctx); err != nil {
+		log.Fatal("Failed to create walk-in indexes", err)
+	}
+
+	service := service.NewWalkInService(repository, appointments, queue)
+	controller := controller.NewController(service)
+	app.Server.HandleFunc(utils.MakeURL("POST", "/clinic/{id}/walk-in"), middleware.JwtAuthMiddleware(middleware.PermissionGuardMiddleware(controller.RegisterWalkIn, utils.PermissionManageAppointments, utils.RoleDoctor, utils.RoleClinicOwner)))
+}
@@ -1,9 +1,19 @@
 package main
 
 import (
+	affiliation "AlShifa/Affiliation"
+	appointment "AlShifa/Appointment"
+	calendar "AlShifa/Calendar"
 	clinic "AlShifa/Clinic"
 	internals "AlShifa/Internals"
+	queue "AlShifa/Queue"
+	reminder "AlShifa/Reminder"
+	review "AlShifa/Review"
+	search "AlShifa/Search"
+	specialty "AlShifa/Specialty"
+	staff "AlShifa/Staff"
 	users "AlShifa/Users"
+	walkin "AlShifa/WalkIn"
 	"fmt"
 	"log"
 	"net/http"
@@ -49,8 +59,18 @@ func main() {
 	}
 
 	//initialise modules
-	clinic.InitialiseClinicModule(&appStore)
+	specialtyService := specialty.InitialiseSpecialtyModule(&appStore)
+	clinicService := clinic.InitialiseClinicModule(&appStore, specialtyService)
 	users.InitialiseUserModule(&appStore)
+	appointmentService := appointment.InitialiseAppointmentModule(&appStore, clinicService)
+	queueService := queue.InitialiseQueueModule(&appStore, appointmentService)
+	calendar.InitialiseCalendarModule(&appStore, appointmentService)
+	reminder.InitialiseReminderModule(&appStore)
+	walkin.InitialiseWalkInModule(&appStore, appointmentService, queueService)
+	affiliation.InitialiseAffiliationModule(&appStore, clinicService)
+	search.InitialiseSearchModule(&appStore)
+	review.InitialiseReviewModule(&appStore)
+	staff.InitialiseStaffModule(&appStore)
 
 	fmt.Print("Server Started")
 